@@ -80,7 +80,11 @@ export const TestUserProvider: React.FC<TestUserProviderProps> = ({
       EntraID: `test-${testUserId}`,
       PhotoURL: '', // No photo for test users
       Community: 'TestCommunity',
+      Communities: ['TestCommunity'],
       IsAdmin: false,
+      Roles: [],
+      IsModerator: false,
+      ModeratedCommunities: [],
     };
 
     // Directly set the user in the store (bypass API fetch)
@@ -1,80 +1,80 @@
-import { useEffect, useState } from 'react';
-import type { LearningPath } from '@/types/learningPath';
+import { useMemo, useState } from 'react';
+import type {
+  LearningPath,
+  LearningPathListQuery,
+} from '@/types/learningPath';
 import type { FilterType, SortType } from '@/types/organize';
-import { FILTER_OPTIONS, SORT_OPTIONS } from '@/types/organize';
+import { FILTER_OPTIONS, SORT_OPTIONS, SORT_PARAMS } from '@/types/organize';
 
 interface UsePathOrganizerProps {
-  allPaths: LearningPath[];
-  isFavorited: (id: string) => boolean;
+  communityName: string | undefined;
+  learningPaths: LearningPath[];
   favorites: LearningPath[];
 }
 
-/** Filters and sorts learning paths by all/recently viewed/bookmarked and by last update/alphabetically */
+/** IDs of the recently viewed learning paths, most recent first */
+function getRecentlyViewedIds(): string[] {
+  const stored = localStorage.getItem('rosetta_recently_viewed');
+  return stored ? (JSON.parse(stored) as string[]) : [];
+}
+
+/**
+ * Turns the all/recently viewed/bookmarked filter and the last update/alphabetical sort into the
+ * query the server lists learning paths with, and orders recently viewed paths by recency
+ */
 export function usePathOrganizer({
-  allPaths,
-  isFavorited,
+  communityName,
+  learningPaths,
   favorites,
 }: UsePathOrganizerProps) {
   const [filter, setFilter] = useState<FilterType>(FILTER_OPTIONS.ALL);
   const [order, setOrder] = useState<SortType>(SORT_OPTIONS.LAST_UPDATE);
-  const [organizedPaths, setOrganizedPaths] = useState<LearningPath[]>([]);
 
-  useEffect(() => {
-    let filteredPaths = [...allPaths];
+  const query = useMemo<LearningPathListQuery | null>(() => {
+    if (!communityName) return null;
 
-    // Apply filter
+    const query: LearningPathListQuery = {
+      community: communityName,
+      sort: SORT_PARAMS[order],
+    };
     switch (filter) {
-      case FILTER_OPTIONS.RECENTLY_VIEWED: {
-        // Get recently viewed LPs directly from localStorage
-        const stored = localStorage.getItem('rosetta_recently_viewed');
-
-        if (stored) {
-          const recentLPs: string[] = JSON.parse(stored) as string[];
-
-          // Match by canonical ID
-          filteredPaths = filteredPaths.filter((p) => recentLPs.includes(p.ID));
-
-          // Sort by recency - order in recentLPs array (most recent first)
-          filteredPaths.sort((a, b) => {
-            const indexA = recentLPs.indexOf(a.ID);
-            const indexB = recentLPs.indexOf(b.ID);
-            return indexA - indexB;
-          });
-        } else {
-          filteredPaths = [];
-        }
+      case FILTER_OPTIONS.RECENTLY_VIEWED:
+        query.ids = getRecentlyViewedIds();
         break;
-      }
       case FILTER_OPTIONS.BOOKMARKED:
-        filteredPaths = filteredPaths.filter((p) => isFavorited(p.ID));
+        query.favorited = true;
         break;
       case FILTER_OPTIONS.ALL:
       default:
-        // No filtering
         break;
     }
+    return query;
+  }, [communityName, filter, order]);
 
-    // Apply sort (only if not Recently Viewed, which has its own sort)
-    if (filter !== FILTER_OPTIONS.RECENTLY_VIEWED) {
-      switch (order) {
-        case SORT_OPTIONS.LAST_UPDATE:
-          filteredPaths.sort(
-            (a, b) =>
-              new Date(b.UpdatedAt).getTime() - new Date(a.UpdatedAt).getTime(),
-          );
-          break;
-        case SORT_OPTIONS.ALPHABETICAL:
-          filteredPaths.sort((a, b) => a.Title.localeCompare(b.Title));
-          break;
+  const organizedPaths = useMemo(() => {
+    switch (filter) {
+      case FILTER_OPTIONS.RECENTLY_VIEWED: {
+        // Only a handful of paths are remembered, so they all arrive in the first page
+        const recentIds = query?.ids ?? [];
+        return [...learningPaths].sort(
+          (a, b) => recentIds.indexOf(a.ID) - recentIds.indexOf(b.ID),
+        );
+      }
+      case FILTER_OPTIONS.BOOKMARKED: {
+        // Paths unbookmarked since they were loaded leave the list right away
+        const favoriteIds = new Set(favorites.map((fav) => fav.ID));
+        return learningPaths.filter((p) => favoriteIds.has(p.ID));
       }
+      case FILTER_OPTIONS.ALL:
+      default:
+        return learningPaths;
     }
-
-    setOrganizedPaths(filteredPaths);
-  }, [filter, order, allPaths, favorites, isFavorited]);
+  }, [filter, query, learningPaths, favorites]);
 
   return {
     filter,
     order,
+    query,
     organizedPaths,
     setFilter,
     setOrder,
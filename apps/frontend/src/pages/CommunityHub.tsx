@@ -1,4 +1,4 @@
-import { useCallback, useEffect, useState } from 'react';
+import { useEffect, useState } from 'react';
 import { useParams, useNavigate } from 'react-router-dom';
 import { useLearningPathStore } from '@/store/learningPathStore';
 import { useUserStore } from '@/store/userStore';
@@ -10,18 +10,23 @@ import AppLayout from '@/layouts/AppLayout';
 import { OrganizeDropdown } from '@/components/learning-paths/OrganizeDropdown';
 import { LearningPathCard } from '@/components/learning-paths/LearningPathCard';
 import { usePathOrganizer } from '@/hooks/usePathOrganizer';
+import { FILTER_OPTIONS } from '@/types/organize';
 
 const DEV_EDITOR_FE_URL = import.meta.env.VITE_DEV_EDITOR_FE_URL as string;
 
 export default function CommunityHub() {
   const { communityname } = useParams<{ communityname?: string }>();
   const navigate = useNavigate();
-  const [allPaths, setAllPaths] = useState<LearningPath[]>([]);
-  const [loading, setLoading] = useState(true);
   const [isDropdownOpen, setIsDropdownOpen] = useState(false);
 
   const {
-    fetchLearningPathsByCommunity,
+    learningPaths,
+    nextCursor,
+    total,
+    isLoading,
+    isLoadingMore,
+    fetchLearningPaths,
+    fetchMoreLearningPaths,
     fetchRecentlyViewed,
     fetchUserFavorites,
     addToFavorites,
@@ -34,40 +39,24 @@ export default function CommunityHub() {
 
   const { user } = useUserStore();
 
-  const { filter, order, organizedPaths, setFilter, setOrder } =
+  const { filter, order, query, organizedPaths, setFilter, setOrder } =
     usePathOrganizer({
-      allPaths,
-      isFavorited,
+      communityName: communityname,
+      learningPaths,
       favorites,
     });
 
-  const fetchCommunityPaths = useCallback(async () => {
-    if (!communityname) return;
-
-    setLoading(true);
-    try {
-      const fetchedPaths = await fetchLearningPathsByCommunity(communityname);
-      // Sort by default (Last Update)
-      const sortedPaths = fetchedPaths.sort(
-        (a, b) =>
-          new Date(b.UpdatedAt).getTime() - new Date(a.UpdatedAt).getTime(),
-      );
-      setAllPaths(sortedPaths);
-      // Fetch recently viewed for filtering
-      fetchRecentlyViewed();
-    } catch (error) {
-      console.error('Error fetching community paths:', error);
-    } finally {
-      setLoading(false);
-    }
-  }, [communityname, fetchLearningPathsByCommunity, fetchRecentlyViewed]);
+  // The server filters and sorts, so every change of the Organize menu starts a new listing
+  useEffect(() => {
+    if (!query) return;
+    void fetchLearningPaths(query).then(fetchRecentlyViewed);
+  }, [query, fetchLearningPaths, fetchRecentlyViewed]);
 
   useEffect(() => {
     if (communityname) {
-      void fetchCommunityPaths();
       void fetchUserFavorites();
     }
-  }, [communityname, fetchCommunityPaths, fetchUserFavorites]);
+  }, [communityname, fetchUserFavorites]);
 
   const handlePathClick = (path: LearningPath) => {
     const url = buildViewUrl(DEV_EDITOR_FE_URL, communityname || '', path.ID);
@@ -90,8 +79,6 @@ export default function CommunityHub() {
   const handleDeletePath = async (pathId: string) => {
     try {
       await deleteLearningPath(pathId);
-      // Update local state to remove the deleted path
-      setAllPaths((prev) => prev.filter((p) => p.ID !== pathId));
     } catch (error) {
       console.error('Error deleting learning path:', error);
       throw error; // Re-throw to let the card component handle the error state
@@ -104,18 +91,19 @@ export default function CommunityHub() {
     description: string,
   ) => {
     try {
-      const updatedPath = await updateLearningPath(pathId, title, description);
-      // Update local state with the updated path
-      setAllPaths((prev) =>
-        prev.map((p) => (p.ID === pathId ? updatedPath : p)),
-      );
+      await updateLearningPath(pathId, title, description);
     } catch (error) {
       console.error('Error updating learning path:', error);
       throw error;
     }
   };
 
-  if (loading) {
+  // Filtered listings load behind the page so the Organize menu stays in place
+  if (
+    isLoading &&
+    learningPaths.length === 0 &&
+    filter === FILTER_OPTIONS.ALL
+  ) {
     return (
       <div className="flex items-center justify-center h-screen">
         <p>Loading...</p>
@@ -123,7 +111,9 @@ export default function CommunityHub() {
     );
   }
 
-  const isUserCommunity = user?.Community === communityname;
+  const isUserCommunity =
+    communityname !== undefined &&
+    (user?.Communities ?? [user?.Community]).includes(communityname);
   const isAdmin = user?.IsAdmin === true;
   const canCreatePath = isUserCommunity || isAdmin;
 
@@ -143,7 +133,7 @@ export default function CommunityHub() {
             <h1 className="text-5xl font">{communityname}</h1>
           </div>
           <div className="ml-auto flex items-center align-center gap-5">
-            {allPaths.length > 0 && (
+            {(total > 0 || filter !== FILTER_OPTIONS.ALL) && (
               <Button
                 variant="secondary"
                 className="!p-4.5 mt-0.5 ml-auto"
@@ -202,9 +192,25 @@ export default function CommunityHub() {
           ))}
         </div>
 
-        {organizedPaths.length === 0 && (
+        {nextCursor && (
+          <div className="flex justify-center py-10">
+            <Button
+              variant="secondary"
+              disabled={isLoadingMore}
+              onClick={() => void fetchMoreLearningPaths()}
+            >
+              {isLoadingMore ? 'Loading...' : 'Load more'}
+            </Button>
+          </div>
+        )}
+
+        {organizedPaths.length === 0 && !nextCursor && !isLoading && (
           <div className="text-center py-12">
-            <p className="text-muted-foreground">No learning paths here yet.</p>
+            <p className="text-muted-foreground">
+              {filter === FILTER_OPTIONS.ALL
+                ? 'No learning paths here yet.'
+                : 'No learning paths match this filter.'}
+            </p>
           </div>
         )}
       </div>
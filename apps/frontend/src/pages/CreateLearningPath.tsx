@@ -1,6 +1,6 @@
 import AppLayout from '@/layouts/AppLayout';
 
-import { cn, buildEditorUrl } from '@shared/utils';
+import { cn, buildEditorUrl, parseProblem } from '@shared/utils';
 import { Button } from '@/components/ui/Button';
 import {
   Card,
@@ -15,7 +15,7 @@ import { Textarea } from '@/components/ui/Textarea';
 import { ChevronRight } from 'lucide-react';
 import { Badge } from '@/components/ui/Badge';
 import { SearchSkillForm } from '@/components/learning-paths/SearchSkillForm';
-import { useState, FormEvent } from 'react';
+import { useState, useRef, FormEvent } from 'react';
 import { X } from 'lucide-react';
 import { useParams } from 'react-router-dom';
 
@@ -34,6 +34,9 @@ export default function CreateLearningPath({
   const [skills, setSkills] = useState<string[]>([]);
   const [searchValue, setSearchValue] = useState('');
   const [errorMessage, setErrorMessage] = useState<string>('');
+  // One Idempotency-Key per distinct submission, so resubmitting after a
+  // network error cannot create the learning path twice
+  const submissionRef = useRef<{ body: string; key: string } | null>(null);
   const handleSearchSubmit = (value: string) => {
     if (value.trim() && !skills.includes(value.trim())) {
       setSkills([...skills, value.trim()]);
@@ -60,45 +63,43 @@ export default function CreateLearningPath({
     // Use new community-scoped endpoint
     const LP_API_URL = `${BE_API_URL}/api/communities/${encodeURIComponent(communityname)}/learning-paths`;
 
+    const body = JSON.stringify(formData);
+    if (submissionRef.current?.body !== body) {
+      submissionRef.current = { body, key: crypto.randomUUID() };
+    }
+
     try {
       const response = await fetch(LP_API_URL, {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
+          'Idempotency-Key': submissionRef.current.key,
         },
         credentials: 'include',
-        body: JSON.stringify(formData),
+        body,
       });
 
-      // Handle specific error statuses
-      if (response.status === 403) {
-        const errorData = (await response.json()) as { error?: string };
-        setErrorMessage(
-          errorData.error ||
-            'You do not have permission to create learning paths for this community.',
-        );
-        return;
-      }
-
-      if (response.status === 409 || response.status === 500) {
-        const errorData = (await response.json().catch(() => ({}))) as {
-          error?: string;
-        };
-        const errorMsg =
-          errorData.error ||
-          'A learning path with this name already exists. Please choose a different name.';
-        setErrorMessage(errorMsg);
-        return;
-      }
-
       if (!response.ok) {
-        const errorData = (await response.json().catch(() => ({}))) as {
-          error?: string;
-        };
-        setErrorMessage(
-          errorData.error ||
-            `An error occurred while creating the learning path. (Status: ${response.status.toString()})`,
-        );
+        const problem = await parseProblem(response);
+        switch (problem?.code) {
+          case 'community_required':
+          case 'community_forbidden':
+            setErrorMessage(
+              problem.detail ||
+                'You do not have permission to create learning paths for this community.',
+            );
+            break;
+          case 'learning_path_title_taken':
+            setErrorMessage(
+              'A learning path with this name already exists. Please choose a different name.',
+            );
+            break;
+          default:
+            setErrorMessage(
+              problem?.detail ||
+                `An error occurred while creating the learning path. (Status: ${response.status.toString()})`,
+            );
+        }
         return;
       }
 
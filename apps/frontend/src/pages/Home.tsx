@@ -2,13 +2,14 @@ import AppLayout from '@/layouts/AppLayout';
 import { useUserStore } from '@/store/userStore';
 import { useEffect, useState } from 'react';
 import { useNavigate } from 'react-router-dom';
+import type { Community } from '@shared/types';
 
 interface communitiesResponse {
-  communities: string[];
+  communities: Community[];
 }
 
 export default function Home() {
-  const [communities, setCommunities] = useState<string[]>([]);
+  const [communities, setCommunities] = useState<Community[]>([]);
   const [loading, setLoading] = useState<boolean>(true);
   const navigate = useNavigate();
 
@@ -47,13 +48,13 @@ export default function Home() {
           <div className=" flex flex-col w-full divide-y divide-gray-300 overflow-hidden animate-in fade-in duration-700">
             {communities.map((community) => (
               <button
-                key={community}
+                key={community.ID}
                 onClick={() =>
-                  void navigate(`/hub/${encodeURIComponent(community)}`)
+                  void navigate(`/hub/${encodeURIComponent(community.Name)}`)
                 }
                 className="text-5xl px-5 py-4 text-left hover:translate-x-5 transition-all duration-200 ease-in-out hover:text-red-500"
               >
-                {community}
+                {community.Name}
               </button>
             ))}
           </div>
@@ -1,6 +1,9 @@
 import { create } from 'zustand';
-import type { LearningPath } from '@shared/types';
-import type { LearningPathStore } from '@/types/learningPath';
+import type { LearningPath, LearningPathPage } from '@shared/types';
+import type {
+  LearningPathListQuery,
+  LearningPathStore,
+} from '@/types/learningPath';
 import { apiFetch, getErrorMessage } from '@/services/api';
 
 // Re-export types for convenience
@@ -9,15 +12,45 @@ export type { LearningPath };
 /** LocalStorage key for recently viewed learning paths */
 const RECENTLY_VIEWED_KEY = 'rosetta_recently_viewed';
 
+/** Learning paths fetched per page; further pages are loaded on demand */
+const PAGE_SIZE = 24;
+
+/**
+ * Fetches one page of the listing for query, starting after cursor.
+ * Returns the failed response instead if the request fails.
+ */
+async function fetchPage(
+  query: LearningPathListQuery,
+  cursor: string | null,
+): Promise<LearningPathPage | Response> {
+  const params = new URLSearchParams({
+    limit: String(PAGE_SIZE),
+    sort: query.sort,
+  });
+  if (query.favorited) params.set('favorited', 'true');
+  if (query.ids) params.set('id', query.ids.join(','));
+  if (cursor) params.set('cursor', cursor);
+
+  const response = await apiFetch(
+    `/api/communities/${encodeURIComponent(query.community)}/learning-paths?${params.toString()}`,
+  );
+  if (!response.ok) return response;
+  return (await response.json()) as LearningPathPage;
+}
+
 /**
  * Zustand store for managing learning path state.
  * Handles fetching, CRUD operations, favorites, and recently viewed paths.
  */
 export const useLearningPathStore = create<LearningPathStore>((set, get) => ({
+  listQuery: null,
   learningPaths: [],
+  nextCursor: null,
+  total: 0,
   favorites: [],
   recentlyViewed: [],
   isLoading: false,
+  isLoadingMore: false,
   error: null,
 
   setError: (error: string | null) => {
@@ -29,43 +62,74 @@ export const useLearningPathStore = create<LearningPathStore>((set, get) => ({
     return state.favorites.some((fav) => fav.ID === id);
   },
 
-  fetchLearningPaths: async () => {
-    set({ isLoading: true, error: null });
+  fetchLearningPaths: async (query: LearningPathListQuery) => {
+    set((state) => ({
+      listQuery: query,
+      isLoading: true,
+      isLoadingMore: false,
+      error: null,
+      // Paths of another community must not show while these load
+      ...(state.listQuery?.community !== query.community
+        ? { learningPaths: [], nextCursor: null, total: 0 }
+        : {}),
+    }));
+
+    if (query.ids?.length === 0) {
+      set({ learningPaths: [], nextCursor: null, total: 0, isLoading: false });
+      return;
+    }
+
     try {
-      const response = await apiFetch('/api/learning-paths');
-      if (!response.ok) {
+      const result = await fetchPage(query, null);
+      // A newer query replaced this one while it was loading
+      if (get().listQuery !== query) return;
+
+      if (result instanceof Response) {
         const errorMessage =
-          response.status === 404
-            ? 'Learning paths not found'
+          result.status === 404
+            ? 'Learning paths not found for this community'
             : 'Failed to fetch learning paths';
         set({ error: errorMessage, isLoading: false });
       } else {
-        const data = (await response.json()) as LearningPath[];
-        set({ learningPaths: data, isLoading: false, error: null });
+        set({
+          learningPaths: result.items,
+          nextCursor: result.nextCursor,
+          total: result.total,
+          isLoading: false,
+          error: null,
+        });
       }
     } catch (error) {
+      if (get().listQuery !== query) return;
       set({ error: getErrorMessage(error), isLoading: false });
       console.error('Error fetching learning paths:', error);
     }
   },
 
-  fetchLearningPathsByCommunity: async (communityName: string) => {
+  fetchMoreLearningPaths: async () => {
+    const { listQuery, nextCursor, isLoading, isLoadingMore } = get();
+    if (!listQuery || !nextCursor || isLoading || isLoadingMore) return;
+
+    set({ isLoadingMore: true });
     try {
-      const response = await apiFetch(
-        `/api/communities/${encodeURIComponent(communityName)}/learning-paths`,
-      );
-      if (!response.ok) {
-        const errorMessage =
-          response.status === 404
-            ? 'Learning paths not found for this community'
-            : 'Failed to fetch community learning paths';
-        throw new Error(errorMessage);
+      const result = await fetchPage(listQuery, nextCursor);
+      if (get().listQuery !== listQuery) return;
+
+      if (result instanceof Response) {
+        set({ error: 'Failed to fetch learning paths', isLoadingMore: false });
+      } else {
+        set((state) => ({
+          learningPaths: [...state.learningPaths, ...result.items],
+          nextCursor: result.nextCursor,
+          total: result.total,
+          isLoadingMore: false,
+          error: null,
+        }));
       }
-      const data = (await response.json()) as LearningPath[];
-      return data;
     } catch (error) {
-      console.error('Error fetching community learning paths:', error);
-      throw error;
+      if (get().listQuery !== listQuery) return;
+      set({ error: getErrorMessage(error), isLoadingMore: false });
+      console.error('Error fetching more learning paths:', error);
     }
   },
 
@@ -95,6 +159,7 @@ export const useLearningPathStore = create<LearningPathStore>((set, get) => ({
       if (response.ok || response.status === 204) {
         set((state) => ({
           learningPaths: state.learningPaths.filter((lp) => lp.ID !== id),
+          total: Math.max(state.total - 1, 0),
           error: null,
         }));
       } else if (response.status === 404) {
@@ -4,18 +4,37 @@ export type { Skill, LearningPath } from '@shared/types';
 // Import for use in this file
 import type { LearningPath } from '@shared/types';
 
+/** What a learning path listing shows; the server filters and sorts, so every page agrees */
+export interface LearningPathListQuery {
+  /** Community whose paths are listed */
+  community: string;
+  sort: 'updated' | 'title';
+  /** Only the paths the user favorited */
+  favorited?: boolean;
+  /** Only these paths; an empty list matches nothing */
+  ids?: string[];
+}
+
 export interface LearningPathStore {
+  /** Query of the listing in learningPaths; null before the first fetch */
+  listQuery: LearningPathListQuery | null;
+  /** Pages of the listing loaded so far */
   learningPaths: LearningPath[];
+  /** Cursor of the next page of learningPaths; null once the last page is loaded */
+  nextCursor: string | null;
+  /** Number of paths matching listQuery across all pages */
+  total: number;
   favorites: LearningPath[];
   isLoading: boolean;
+  isLoadingMore: boolean;
   error: string | null;
   recentlyViewed: LearningPath[];
 
   fetchRecentlyViewed: () => void;
-  fetchLearningPaths: () => Promise<void>;
-  fetchLearningPathsByCommunity: (
-    communityName: string,
-  ) => Promise<LearningPath[]>;
+  /** Replaces learningPaths with the first page of the listing for query */
+  fetchLearningPaths: (query: LearningPathListQuery) => Promise<void>;
+  /** Appends the next page of the current listing, if there is one */
+  fetchMoreLearningPaths: () => Promise<void>;
   fetchUserFavorites: () => Promise<void>;
   addToFavorites: (id: string) => Promise<void>;
   removeFromFavorites: (id: string) => Promise<void>;
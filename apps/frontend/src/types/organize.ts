@@ -1,3 +1,5 @@
+import type { LearningPathListQuery } from '@/types/learningPath';
+
 export type FilterType = 'All' | 'Recently Viewed' | 'Bookmarked';
 export type SortType = 'Last Update' | 'alphabetical';
 
@@ -22,3 +24,9 @@ export const SORT_LABELS: Record<SortType, string> = {
   'Last Update': 'Last Update',
   alphabetical: 'Alphabetical',
 };
+
+/** The listing's sort parameter for each sort option */
+export const SORT_PARAMS: Record<SortType, LearningPathListQuery['sort']> = {
+  'Last Update': 'updated',
+  alphabetical: 'title',
+};
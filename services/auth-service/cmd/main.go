@@ -1,13 +1,23 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/auth-service/internal/controller"
 	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/auth-service/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/auth-service/internal/util"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/authcookie"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/joho/godotenv"
 )
 
@@ -17,15 +27,31 @@ func main() {
 		log.Println("No .env file found, using environment variables")
 	}
 
-	// Initialize OIDC auth service
-	authService, err := service.NewAuthService(
-		os.Getenv("OIDC_ISSUER"),
-		os.Getenv("OIDC_CLIENT_ID"),
-		os.Getenv("OIDC_CLIENT_SECRET"),
-		os.Getenv("OIDC_REDIRECT_URI"),
-	)
-	if err != nil {
-		log.Fatalf("Failed to initialize auth service: %v", err)
+	// Initialize OIDC auth service against Microsoft Entra ID or any OIDC provider (Keycloak,
+	// Google, Okta). DEV_IDP_ENABLED replaces it with a local identity provider so the stack runs
+	// without one.
+	var authService *service.AuthService
+	var devProvider *service.DevIdentityProvider
+	if os.Getenv("DEV_IDP_ENABLED") == "true" {
+		var err error
+		devProvider, err = newDevIdentityProvider()
+		if err != nil {
+			log.Fatalf("Failed to initialize dev identity provider: %v", err)
+		}
+		authService = service.NewDevAuthService(devProvider)
+		log.Printf("WARNING: Development identity provider enabled at %s - never use this in production", devProvider.Issuer)
+	} else {
+		providerConfig, err := newProviderConfig()
+		if err != nil {
+			log.Fatalf("Invalid OIDC provider configuration: %v", err)
+		}
+		authService, err = service.NewAuthService(providerConfig)
+		if err != nil {
+			log.Fatalf("Failed to initialize auth service: %v", err)
+		}
+		if !authService.SupportsGraph() {
+			log.Println("OIDC provider is not Microsoft Entra ID, Graph tokens are not requested")
+		}
 	}
 
 	// Initialize Gin router
@@ -47,8 +73,41 @@ func main() {
 	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
 	r.Use(cors.New(corsConfig))
 
+	// Sign the state, nonce and PKCE verifier kept in a cookie during login. Instances behind a
+	// load balancer must share AUTH_FLOW_SECRET; without it each instance uses a random secret.
+	if os.Getenv("AUTH_FLOW_SECRET") == "" {
+		log.Println("AUTH_FLOW_SECRET not set, using a random secret (logins only complete on the instance that started them)")
+	}
+	flowCodec, err := service.NewLoginFlowCodec([]byte(os.Getenv("AUTH_FLOW_SECRET")))
+	if err != nil {
+		log.Fatalf("Failed to initialize login flow codec: %v", err)
+	}
+
+	// Keep tokens in a server-side session store behind an opaque cookie (SESSION_STORE). Without
+	// it, the tokens themselves are written to cookies.
+	sessionService, revocationStore, err := newSessionService(authService)
+	if err != nil {
+		log.Fatalf("Failed to initialize session store: %v", err)
+	}
+
+	// Record signed-out sessions, tokens and users for the backend to reject, in the session store
+	revocationService := service.NewRevocationService(revocationStore, authService, sessionService)
+	if sessionService != nil {
+		sessionService.OnSessionEnded = revocationService.RevokeSession
+	}
+
+	// Encrypt the token cookies written without a session store (AUTH_COOKIE_KEYS). The backend
+	// must be given the same keys to read them.
+	authCookies, err := authcookie.NewCodecFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to initialize auth cookie codec: %v", err)
+	}
+	if !authCookies.Encrypts() && sessionService == nil {
+		log.Println("AUTH_COOKIE_KEYS not set, token cookies are written unencrypted")
+	}
+
 	// Initialize controller
-	authController := controller.NewAuthController(authService)
+	authController := controller.NewAuthController(authService, flowCodec, sessionService, revocationService, authCookies)
 
 	// Health check
 	r.GET("/health", func(c *gin.Context) {
@@ -63,6 +122,32 @@ func main() {
 	r.GET("/auth/logout", authController.Logout)
 	r.POST("/auth/refresh", authController.RefreshToken)
 
+	// Session management, and session lookups by the backend and backend-editor
+	if sessionService != nil {
+		sessionController := controller.NewSessionController(sessionService, os.Getenv("INTERNAL_API_SECRET"))
+		r.GET("/auth/sessions", sessionController.List)
+		r.DELETE("/auth/sessions/:id", sessionController.Revoke)
+		r.POST("/auth/internal/sessions/resolve", sessionController.Resolve)
+	}
+
+	// The revocation list the backend checks tokens against, and signing a user out everywhere
+	if secret := os.Getenv("INTERNAL_API_SECRET"); secret != "" {
+		revocationController := controller.NewRevocationController(revocationService, secret)
+		r.GET("/auth/internal/revocations", revocationController.List)
+		r.POST("/auth/internal/users/:userId/sign-out", revocationController.SignOutUser)
+	}
+
+	// Development identity provider (DEV_IDP_ENABLED=true only)
+	if devProvider != nil {
+		devController := controller.NewDevIdentityController(devProvider)
+		dev := r.Group(service.DevIdentityProviderPath)
+		dev.GET("/.well-known/openid-configuration", devController.Discovery)
+		dev.GET("/keys", devController.Keys)
+		dev.GET("/authorize", devController.LoginForm)
+		dev.POST("/authorize", devController.Authorize)
+		dev.POST("/token", devController.Token)
+	}
+
 	// Start server
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -74,3 +159,120 @@ func main() {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// newDevIdentityProvider configures the development identity provider from DEV_IDP_* variables.
+// It refuses to start outside development, since anyone can sign in as any of its users.
+func newDevIdentityProvider() (*service.DevIdentityProvider, error) {
+	if !util.IsDevelopment() {
+		return nil, fmt.Errorf("DEV_IDP_ENABLED requires a development ROSETTA_DOMAIN, got %q", os.Getenv("ROSETTA_DOMAIN"))
+	}
+
+	rawUsers := os.Getenv("DEV_IDP_USERS")
+	if rawUsers == "" {
+		rawUsers = "dev@rosetta.local:Dev User"
+	}
+	users, err := service.ParseDevUsers(rawUsers)
+	if err != nil {
+		return nil, err
+	}
+
+	var keyPEM []byte
+	if path := os.Getenv("DEV_IDP_KEY_FILE"); path != "" {
+		if keyPEM, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read DEV_IDP_KEY_FILE: %w", err)
+		}
+	}
+
+	issuer := os.Getenv("DEV_IDP_ISSUER")
+	if issuer == "" {
+		issuer = "http://localhost:3002" + service.DevIdentityProviderPath
+	}
+	clientID := os.Getenv("OIDC_CLIENT_ID")
+	if clientID == "" {
+		clientID = "rosetta-dev"
+	}
+	redirectURI := os.Getenv("OIDC_REDIRECT_URI")
+	if redirectURI == "" {
+		redirectURI = "http://localhost:3002/auth/callback"
+	}
+
+	return service.NewDevIdentityProvider(issuer, clientID, redirectURI, users, keyPEM)
+}
+
+// newProviderConfig reads the OIDC provider from OIDC_* variables. OIDC_ISSUER is an issuer URL
+// or an Entra tenant ID; OIDC_SCOPES (space- or comma-separated) replaces the default scopes and
+// OIDC_AUTH_PARAMS (a query string) adds authorize parameters. OIDC_END_SESSION=true signs users
+// out of the provider when they log out.
+func newProviderConfig() (service.ProviderConfig, error) {
+	cfg := service.ProviderConfig{
+		Issuer:       os.Getenv("OIDC_ISSUER"),
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURI:  os.Getenv("OIDC_REDIRECT_URI"),
+		Scopes:       strings.Fields(strings.ReplaceAll(os.Getenv("OIDC_SCOPES"), ",", " ")),
+		EndSession:   os.Getenv("OIDC_END_SESSION") == "true",
+	}
+	if cfg.Issuer == "" {
+		return cfg, fmt.Errorf("OIDC_ISSUER is required")
+	}
+	if params := os.Getenv("OIDC_AUTH_PARAMS"); params != "" {
+		values, err := url.ParseQuery(params)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid OIDC_AUTH_PARAMS: %w", err)
+		}
+		cfg.AuthParams = values
+	}
+	return cfg, nil
+}
+
+// newSessionService configures the session store from SESSION_* variables, or returns nil when
+// SESSION_STORE is unset. The backend and backend-editor resolve sessions with INTERNAL_API_SECRET.
+// The returned revocation store shares the session store, and keeps revocations in memory when
+// there is none.
+func newSessionService(authService *service.AuthService) (*service.SessionService, service.RevocationStore, error) {
+	kind := os.Getenv("SESSION_STORE")
+	if kind == "" {
+		return nil, service.NewMemoryRevocationStore(), nil
+	}
+	if os.Getenv("INTERNAL_API_SECRET") == "" {
+		return nil, nil, fmt.Errorf("SESSION_STORE requires INTERNAL_API_SECRET")
+	}
+
+	lifetime := 24 * time.Hour
+	if v, err := strconv.Atoi(os.Getenv("SESSION_LIFETIME_HOURS")); err == nil && v > 0 {
+		lifetime = time.Duration(v) * time.Hour
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var store service.SessionStore
+	var revocations service.RevocationStore
+	switch kind {
+	case "memory":
+		store = service.NewMemorySessionStore()
+		revocations = service.NewMemoryRevocationStore()
+	case "postgres":
+		db, err := sql.Open("pgx", os.Getenv("SESSION_DATABASE_URL"))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open SESSION_DATABASE_URL: %w", err)
+		}
+		if store, err = service.NewPostgresSessionStore(ctx, db); err != nil {
+			return nil, nil, err
+		}
+		if revocations, err = service.NewPostgresRevocationStore(ctx, db); err != nil {
+			return nil, nil, err
+		}
+	case "redis":
+		redisStore, err := service.NewRedisSessionStore(ctx, os.Getenv("SESSION_REDIS_URL"))
+		if err != nil {
+			return nil, nil, err
+		}
+		store, revocations = redisStore, redisStore.Revocations()
+	default:
+		return nil, nil, fmt.Errorf("unknown SESSION_STORE %q (use memory, postgres or redis)", kind)
+	}
+
+	log.Printf("Sessions stored in %s, lifetime %s", kind, lifetime)
+	return service.NewSessionService(store, authService, lifetime), revocations, nil
+}
@@ -3,9 +3,11 @@ module dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/auth
 go 1.24.2
 
 require (
+	dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/authcookie v0.0.0
 	github.com/coreos/go-oidc/v3 v3.16.0
 	github.com/gin-contrib/cors v1.7.6
 	github.com/gin-gonic/gin v1.11.0
+	github.com/jackc/pgx/v5 v5.6.0
 	github.com/joho/godotenv v1.5.1
 	github.com/stretchr/testify v1.11.1
 )
@@ -23,6 +25,9 @@ require (
 	github.com/go-playground/validator/v10 v10.27.0 // indirect
 	github.com/goccy/go-json v0.10.5 // indirect
 	github.com/goccy/go-yaml v1.18.0 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
+	github.com/jackc/puddle/v2 v2.2.1 // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
 	github.com/klauspost/cpuid/v2 v2.3.0 // indirect
 	github.com/kr/text v0.2.0 // indirect
@@ -49,3 +54,5 @@ require (
 	google.golang.org/protobuf v1.36.9 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/authcookie => ../authcookie
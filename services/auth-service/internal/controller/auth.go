@@ -1,128 +1,112 @@
 package controller
 
 import (
-	"encoding/json"
-	"fmt"
-	"io"
+	"crypto/subtle"
 	"log"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/auth-service/internal/service"
 	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/auth-service/internal/util"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/authcookie"
 )
 
 type AuthController struct {
 	authService *service.AuthService
+	flowCodec   *service.LoginFlowCodec
+	sessions    *service.SessionService // nil keeps the tokens in cookies instead of a session store
+	revocations *service.RevocationService
+	cookies     *authcookie.Codec // encrypts the token cookies when there is no session store
 }
 
-func NewAuthController(authService *service.AuthService) *AuthController {
+func NewAuthController(authService *service.AuthService, flowCodec *service.LoginFlowCodec, sessions *service.SessionService, revocations *service.RevocationService, cookies *authcookie.Codec) *AuthController {
 	return &AuthController{
 		authService: authService,
+		flowCodec:   flowCodec,
+		sessions:    sessions,
+		revocations: revocations,
+		cookies:     cookies,
 	}
 }
 
-// Login initiates the OAuth login flow by redirecting to Microsoft
-// GET /auth/login
+// Login initiates the OAuth login flow by redirecting to Microsoft, or to the development
+// login form when the development identity provider is enabled. The state, nonce and PKCE
+// verifier are kept in a signed cookie for the callback.
+// GET /auth/login?returnTo=...
 func (ctrl *AuthController) Login(c *gin.Context) {
-	clientID := os.Getenv("OIDC_CLIENT_ID")
-	redirectURI := os.Getenv("OIDC_REDIRECT_URI")
-	tenantID := service.ExtractTenantID(os.Getenv("OIDC_ISSUER"))
-
-	loginURL := fmt.Sprintf(
-		"https://login.microsoftonline.com/%s/oauth2/v2.0/authorize?client_id=%s&response_type=code&redirect_uri=%s&scope=%s",
-		tenantID,
-		clientID,
-		url.QueryEscape(redirectURI),
-		url.QueryEscape(service.OAuthScope),
-	)
-
-	log.Printf("Redirecting to Microsoft login")
-	c.Redirect(http.StatusFound, loginURL)
-}
-
-// Callback handles the OAuth callback from Microsoft
-// GET /auth/callback?code=...
-func (ctrl *AuthController) Callback(c *gin.Context) {
-	code := c.Query("code")
-	if code == "" {
-		log.Println("Error: Authorization code not found in callback")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Authorization code not found"})
-		return
+	returnTo := c.Query("returnTo")
+	if returnTo != "" && !isAllowedRedirect(returnTo) {
+		log.Printf("Warning: Ignoring invalid returnTo URL: %s", returnTo)
+		returnTo = ""
 	}
 
-	clientID := os.Getenv("OIDC_CLIENT_ID")
-	clientSecret := os.Getenv("OIDC_CLIENT_SECRET")
-	redirectURI := os.Getenv("OIDC_REDIRECT_URI")
-	tenantID := service.ExtractTenantID(os.Getenv("OIDC_ISSUER"))
-
-	tokenURL := fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", tenantID)
-
-	data := url.Values{}
-	data.Set("grant_type", "authorization_code")
-	data.Set("code", code)
-	data.Set("redirect_uri", redirectURI)
-	data.Set("client_id", clientID)
-	data.Set("client_secret", clientSecret)
-	data.Set("scope", service.OAuthScope)
-
-	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(data.Encode()))
+	flow, err := service.NewLoginFlow(returnTo)
 	if err != nil {
-		log.Printf("Error: Failed to create token request: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token request"})
+		log.Printf("Error: Failed to start login flow: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
 		return
 	}
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	value, err := ctrl.flowCodec.Encode(flow)
 	if err != nil {
-		log.Printf("Error: Microsoft OAuth API error - Failed to exchange code: %v", err)
-		c.JSON(http.StatusBadGateway, gin.H{"error": "Microsoft OAuth API error"})
+		log.Printf("Error: Failed to start login flow: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
 		return
 	}
-	defer resp.Body.Close()
+	util.SetLoginFlowCookie(c, value, int(service.LoginFlowLifetime.Seconds()))
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		log.Printf("Error: Microsoft OAuth token exchange failed with status %d: %s", resp.StatusCode, string(bodyBytes))
-		c.JSON(http.StatusBadGateway, gin.H{"error": "Microsoft OAuth API error"})
+	log.Printf("Redirecting to identity provider login")
+	c.Redirect(http.StatusFound, ctrl.authService.AuthorizeURL(flow))
+}
+
+// Callback handles the OAuth callback from the identity provider. The state must match the
+// one Login stored in this browser, which stops login CSRF and injected authorization codes.
+// GET /auth/callback?code=...&state=...
+func (ctrl *AuthController) Callback(c *gin.Context) {
+	if providerError := c.Query("error"); providerError != "" {
+		log.Printf("Error: Identity provider returned %s: %s", providerError, c.Query("error_description"))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Login was not completed"})
 		return
 	}
 
-	var tokenResponse map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
-		log.Printf("Error: Failed to decode Microsoft OAuth token response: %v", err)
-		c.JSON(http.StatusBadGateway, gin.H{"error": "Microsoft OAuth API error"})
+	code := c.Query("code")
+	if code == "" {
+		log.Println("Error: Authorization code not found in callback")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Authorization code not found"})
 		return
 	}
 
-	accessToken, ok := tokenResponse["access_token"].(string)
-	if !ok {
-		log.Println("Error: access_token missing from Microsoft OAuth response")
-		c.JSON(http.StatusBadGateway, gin.H{"error": "Microsoft OAuth API error"})
+	flowCookie, err := c.Cookie(util.LoginFlowCookie)
+	if err != nil {
+		log.Println("Error: Login flow cookie not found in callback")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Login session expired, please sign in again"})
 		return
 	}
+	util.ClearLoginFlowCookie(c)
 
-	idToken, ok := tokenResponse["id_token"].(string)
-	if !ok {
-		log.Println("Error: id_token missing from Microsoft OAuth response")
-		c.JSON(http.StatusBadGateway, gin.H{"error": "Microsoft OAuth API error"})
+	flow, err := ctrl.flowCodec.Decode(flowCookie)
+	if err != nil {
+		log.Printf("Error: Invalid login flow cookie: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Login session expired, please sign in again"})
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(c.Query("state")), []byte(flow.State)) != 1 {
+		log.Println("Error: OAuth state does not match the login flow")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid login state"})
 		return
 	}
 
-	refreshToken, ok := tokenResponse["refresh_token"].(string)
-	if !ok {
-		log.Println("Error: refresh_token missing from Microsoft OAuth response")
-		c.JSON(http.StatusBadGateway, gin.H{"error": "Microsoft OAuth API error"})
+	tokens := ctrl.authService.ExchangeCode(code, flow.CodeVerifier)
+	if !tokens.Success {
+		log.Printf("Error: OAuth code exchange failed: %s", tokens.Error)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "OAuth token exchange failed"})
 		return
 	}
+	accessToken, idToken, refreshToken := tokens.AccessToken, tokens.IDToken, tokens.RefreshToken
 
-	validationResult := ctrl.authService.ValidateToken(idToken)
+	validationResult := ctrl.authService.ValidateToken(idToken, flow.Nonce)
 	if !validationResult.Valid {
 		log.Printf("Error: ID token validation failed: %s", validationResult.Error)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid ID token"})
@@ -131,23 +115,46 @@ func (ctrl *AuthController) Callback(c *gin.Context) {
 
 	log.Printf("User successfully authenticated: %s (%s)", validationResult.Email, validationResult.EntraID)
 
-	// Request Graph API access token using the refresh token
-	graphAccessToken, err := ctrl.authService.GetGraphToken(refreshToken)
-	if err != nil {
-		log.Printf("Warning: Failed to get Graph API token: %v", err)
-		graphAccessToken = "" // Continue without graph token
+	graphAccessToken := ctrl.graphToken(refreshToken)
+
+	if ctrl.sessions != nil {
+		secret, session, err := ctrl.sessions.Create(c.Request.Context(), tokens, graphAccessToken, validationResult, c.Request.UserAgent(), c.ClientIP())
+		if err != nil {
+			log.Printf("Error: Failed to create session: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+			return
+		}
+		util.SetSessionCookie(c, secret, int(time.Until(session.ExpiresAt).Seconds()))
 	} else {
-		log.Printf("Graph API token obtained successfully")
+		util.SetCookiesFromTokens(c, ctrl.cookies, accessToken, refreshToken, idToken, graphAccessToken)
 	}
 
-	util.SetCookiesFromTokens(c, accessToken, refreshToken, idToken, graphAccessToken)
-
 	redirectURL := util.GetRedirectURL()
+	if flow.ReturnTo != "" {
+		redirectURL = resolveReturnTo(flow.ReturnTo)
+	}
 	log.Printf("Redirecting user to: %s", redirectURL)
 	c.Redirect(http.StatusFound, redirectURL)
 }
 
-// Logout clears authentication cookies
+// graphToken requests a Microsoft Graph access token using the refresh token. Returns "" for
+// providers other than Entra ID, and when the request fails, since Graph is optional.
+func (ctrl *AuthController) graphToken(refreshToken string) string {
+	if !ctrl.authService.SupportsGraph() {
+		return ""
+	}
+	graphAccessToken, err := ctrl.authService.GetGraphToken(refreshToken)
+	if err != nil {
+		log.Printf("Warning: Failed to get Graph API token: %v", err)
+		return "" // Continue without graph token
+	}
+	log.Printf("Graph API token obtained successfully")
+	return graphAccessToken
+}
+
+// Logout ends the session, revokes its tokens so copies of them stop working too, and clears
+// authentication cookies. With OIDC_END_SESSION, the user is then signed out of the identity
+// provider, which redirects back to the redirect URL.
 // GET /auth/logout
 func (ctrl *AuthController) Logout(c *gin.Context) {
 	redirectTo := c.Query("redirect")
@@ -163,30 +170,54 @@ func (ctrl *AuthController) Logout(c *gin.Context) {
 		redirectTo = util.GetRedirectURL()
 	}
 
-	cookieDomain := util.GetCookieDomain()
-	isSecure := !util.IsDevelopment()
-
-	// Use environment-aware SameSite policy (consistent with SetCookiesFromTokens)
-	if util.IsDevelopment() {
-		c.SetSameSite(http.SameSiteLaxMode)
-	} else {
-		c.SetSameSite(http.SameSiteNoneMode)
+	// Ending a session revokes its tokens; tokens kept in cookies are revoked here
+	ctx := c.Request.Context()
+	var idToken string
+	if ctrl.sessions != nil {
+		if secret, err := c.Cookie(util.SessionCookie); err == nil && secret != "" {
+			if session, err := ctrl.sessions.Get(ctx, secret); err == nil {
+				idToken = session.IDToken
+			}
+			if err := ctrl.sessions.Delete(ctx, secret); err != nil {
+				log.Printf("Warning: Failed to delete session: %v", err)
+			}
+		}
 	}
+	if idToken == "" {
+		idToken, _ = util.ReadTokenCookie(c, ctrl.cookies, "id_token")
+		refreshToken, _ := util.ReadTokenCookie(c, ctrl.cookies, "refresh_token")
+		if err := ctrl.revocations.RevokeTokens(ctx, idToken, refreshToken); err != nil {
+			log.Printf("Warning: Failed to revoke tokens: %v", err)
+		}
+	}
+
+	// Clear all authentication cookies, their chunks and the session cookie
+	util.ClearTokenCookies(c, ctrl.cookies)
+	util.ClearSessionCookie(c)
 
-	// Clear all authentication cookies including graph_access_token
-	c.SetCookie("id_token", "", -1, "/", cookieDomain, isSecure, true)
-	c.SetCookie("access_token", "", -1, "/", cookieDomain, isSecure, true)
-	c.SetCookie("refresh_token", "", -1, "/", cookieDomain, isSecure, true)
-	c.SetCookie("graph_access_token", "", -1, "/", cookieDomain, isSecure, true)
+	if endSessionURL := ctrl.authService.EndSessionURL(idToken, resolveReturnTo(redirectTo)); endSessionURL != "" {
+		log.Printf("User logged out, signing out of the identity provider before redirecting to: %s", redirectTo)
+		c.Redirect(http.StatusFound, endSessionURL)
+		return
+	}
 
 	log.Printf("User logged out, redirecting to: %s", redirectTo)
 	c.Redirect(http.StatusFound, redirectTo)
 }
 
+// resolveReturnTo makes a relative returnTo path absolute against the app's URL, since the
+// callback itself is served by auth-service
+func resolveReturnTo(returnTo string) string {
+	if strings.HasPrefix(returnTo, "/") {
+		return util.GetRedirectURL() + returnTo
+	}
+	return returnTo
+}
+
 // isAllowedRedirect validates that the redirect URL is safe
 func isAllowedRedirect(redirectURL string) bool {
-	// Allow relative paths
-	if strings.HasPrefix(redirectURL, "/") && !strings.HasPrefix(redirectURL, "//") {
+	// Allow relative paths, but not protocol-relative ones ("/\host" is read as "//host" by browsers)
+	if strings.HasPrefix(redirectURL, "/") && !strings.HasPrefix(redirectURL, "//") && !strings.HasPrefix(redirectURL, "/\\") {
 		return true
 	}
 
@@ -213,9 +244,21 @@ func isAllowedRedirect(redirectURL string) bool {
 	return false
 }
 
-// RefreshToken exchanges a refresh token for new tokens
+// RefreshToken exchanges a refresh token for new tokens. With a session cookie, the session's
+// tokens are refreshed on the server and none of them are returned.
 // POST /auth/refresh
 func (ctrl *AuthController) RefreshToken(c *gin.Context) {
+	if secret, err := c.Cookie(util.SessionCookie); err == nil && secret != "" && ctrl.sessions != nil {
+		session, err := ctrl.sessions.Refresh(c.Request.Context(), secret)
+		if err != nil {
+			log.Printf("Session refresh failed: %v", err)
+			c.JSON(http.StatusUnauthorized, service.TokenRefreshResult{Success: false, Error: "Session expired or revoked"})
+			return
+		}
+		c.JSON(http.StatusOK, service.TokenRefreshResult{Success: true, ExpiresIn: int(time.Until(session.TokenExpiresAt).Seconds())})
+		return
+	}
+
 	var refreshToken string
 
 	// Try JSON body first
@@ -226,7 +269,7 @@ func (ctrl *AuthController) RefreshToken(c *gin.Context) {
 		refreshToken = req.RefreshToken
 	} else {
 		// Try refresh_token cookie
-		cookieToken, err := c.Cookie("refresh_token")
+		cookieToken, err := util.ReadTokenCookie(c, ctrl.cookies, "refresh_token")
 		if err != nil {
 			log.Printf("Refresh failed: No refresh token provided - %v", err)
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Refresh token required in body or cookie"})
@@ -237,6 +280,21 @@ func (ctrl *AuthController) RefreshToken(c *gin.Context) {
 
 	log.Printf("Refreshing token (length: %d)", len(refreshToken))
 
+	// The identity provider may still accept refresh tokens that were signed out (Entra ID cannot
+	// revoke them), so the revocation list is checked before and after refreshing
+	ctx := c.Request.Context()
+	revoked, err := ctrl.revocations.RefreshTokenRevoked(ctx, refreshToken)
+	if err != nil {
+		log.Printf("Error: Failed to check refresh token revocation: %v", err)
+		c.JSON(http.StatusServiceUnavailable, service.TokenRefreshResult{Success: false, Error: "Revocation list unavailable"})
+		return
+	}
+	if revoked {
+		log.Printf("Token refresh rejected: refresh token was signed out")
+		c.JSON(http.StatusUnauthorized, service.TokenRefreshResult{Success: false, Error: "Refresh token was signed out"})
+		return
+	}
+
 	result := ctrl.authService.RefreshToken(refreshToken)
 
 	if !result.Success {
@@ -245,18 +303,35 @@ func (ctrl *AuthController) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Token refreshed successfully, expires in: %d seconds", result.ExpiresIn)
-
-	// Request Graph API access token using the refresh token
-	graphAccessToken, err := ctrl.authService.GetGraphToken(result.RefreshToken)
+	// After the user was signed out everywhere, only refresh tokens issued since are accepted.
+	// The ID token cookie was issued along with the refresh token and tells when that was.
+	validation := ctrl.authService.ValidateToken(result.IDToken, "")
+	if !validation.Valid {
+		log.Printf("Token refresh failed: %s", validation.Error)
+		c.JSON(http.StatusUnauthorized, service.TokenRefreshResult{Success: false, Error: "Refreshed ID token is invalid"})
+		return
+	}
+	issuedWith, _ := util.ReadTokenCookie(c, ctrl.cookies, "id_token")
+	signedOut, err := ctrl.revocations.UserSignedOut(ctx, validation.EntraID, issuedWith)
 	if err != nil {
-		log.Printf("Warning: Failed to get Graph API token during refresh: %v", err)
-		graphAccessToken = "" // Continue without graph token
-	} else {
-		log.Printf("Graph API token obtained successfully during refresh")
+		log.Printf("Error: Failed to check user revocation: %v", err)
+		c.JSON(http.StatusServiceUnavailable, service.TokenRefreshResult{Success: false, Error: "Revocation list unavailable"})
+		return
 	}
+	if signedOut {
+		log.Printf("Token refresh rejected: user %s was signed out everywhere", validation.EntraID)
+		if err := ctrl.revocations.RevokeTokens(ctx, result.IDToken, result.RefreshToken); err != nil {
+			log.Printf("Warning: Failed to revoke refreshed tokens: %v", err)
+		}
+		c.JSON(http.StatusUnauthorized, service.TokenRefreshResult{Success: false, Error: "User was signed out"})
+		return
+	}
+
+	log.Printf("Token refreshed successfully, expires in: %d seconds", result.ExpiresIn)
+
+	graphAccessToken := ctrl.graphToken(result.RefreshToken)
 
-	util.SetCookiesFromTokens(c, result.AccessToken, result.RefreshToken, result.IDToken, graphAccessToken)
+	util.SetCookiesFromTokens(c, ctrl.cookies, result.AccessToken, result.RefreshToken, result.IDToken, graphAccessToken)
 
 	c.JSON(http.StatusOK, result)
 }
@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/auth-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// devLoginPage lists the fake users; choosing one completes the authorization request
+var devLoginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Rosetta development sign-in</title>
+<style>
+body { font-family: sans-serif; max-width: 28rem; margin: 4rem auto; }
+button { display: block; width: 100%; margin: .5rem 0; padding: .75rem; text-align: left; cursor: pointer; }
+small { color: #666; }
+</style>
+</head>
+<body>
+<h1>Sign in</h1>
+<p><small>Development identity provider &mdash; no password required.</small></p>
+<form method="post">
+<input type="hidden" name="client_id" value="{{.ClientID}}">
+<input type="hidden" name="redirect_uri" value="{{.RedirectURI}}">
+<input type="hidden" name="state" value="{{.State}}">
+<input type="hidden" name="nonce" value="{{.Nonce}}">
+<input type="hidden" name="code_challenge" value="{{.CodeChallenge}}">
+{{range .Users}}<button type="submit" name="user" value="{{.ID}}">{{.Name}}<br><small>{{.Email}}</small></button>
+{{end}}</form>
+</body>
+</html>
+`))
+
+// DevIdentityController serves the development identity provider: a login form for the fake
+// users plus the discovery, JWKS and token endpoints other services need to verify its tokens
+type DevIdentityController struct {
+	provider *service.DevIdentityProvider
+}
+
+func NewDevIdentityController(provider *service.DevIdentityProvider) *DevIdentityController {
+	return &DevIdentityController{
+		provider: provider,
+	}
+}
+
+// Discovery returns the OpenID Connect discovery document
+// GET /auth/dev/.well-known/openid-configuration
+func (ctrl *DevIdentityController) Discovery(c *gin.Context) {
+	c.JSON(http.StatusOK, ctrl.provider.Discovery())
+}
+
+// Keys returns the signing keys
+// GET /auth/dev/keys
+func (ctrl *DevIdentityController) Keys(c *gin.Context) {
+	c.JSON(http.StatusOK, ctrl.provider.JWKS())
+}
+
+// LoginForm shows the fake users to sign in as
+// GET /auth/dev/authorize?client_id=...&redirect_uri=...
+func (ctrl *DevIdentityController) LoginForm(c *gin.Context) {
+	if c.Query("client_id") != ctrl.provider.ClientID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown client_id"})
+		return
+	}
+	if c.Query("redirect_uri") != ctrl.provider.RedirectURI {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "redirect_uri is not registered"})
+		return
+	}
+	if method := c.Query("code_challenge_method"); c.Query("code_challenge") != "" && method != "S256" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only the S256 code_challenge_method is supported"})
+		return
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	err := devLoginPage.Execute(c.Writer, gin.H{
+		"ClientID":      ctrl.provider.ClientID,
+		"RedirectURI":   ctrl.provider.RedirectURI,
+		"State":         c.Query("state"),
+		"Nonce":         c.Query("nonce"),
+		"CodeChallenge": c.Query("code_challenge"),
+		"Users":         ctrl.provider.Users,
+	})
+	if err != nil {
+		log.Printf("Error: Failed to render dev login form: %v", err)
+	}
+}
+
+// Authorize signs the chosen user in and redirects back to the client with a code
+// POST /auth/dev/authorize
+func (ctrl *DevIdentityController) Authorize(c *gin.Context) {
+	if c.PostForm("client_id") != ctrl.provider.ClientID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown client_id"})
+		return
+	}
+
+	redirectURI := c.PostForm("redirect_uri")
+	code, err := ctrl.provider.IssueCode(c.PostForm("user"), redirectURI, c.PostForm("nonce"), c.PostForm("code_challenge"))
+	if err != nil {
+		log.Printf("Dev sign-in rejected: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := url.Values{}
+	query.Set("code", code)
+	if state := c.PostForm("state"); state != "" {
+		query.Set("state", state)
+	}
+	log.Printf("Dev user %s signed in", c.PostForm("user"))
+	c.Redirect(http.StatusFound, redirectURI+"?"+query.Encode())
+}
+
+// Token redeems an authorization code or refresh token, like an OAuth token endpoint
+// POST /auth/dev/token
+func (ctrl *DevIdentityController) Token(c *gin.Context) {
+	if c.PostForm("client_id") != "" && c.PostForm("client_id") != ctrl.provider.ClientID {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_client"})
+		return
+	}
+
+	var result *service.TokenRefreshResult
+	switch c.PostForm("grant_type") {
+	case "authorization_code":
+		result = ctrl.provider.ExchangeCode(c.PostForm("code"), c.PostForm("redirect_uri"), c.PostForm("code_verifier"))
+	case "refresh_token":
+		result = ctrl.provider.Refresh(c.PostForm("refresh_token"))
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_grant_type"})
+		return
+	}
+
+	if !result.Success {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_grant", "error_description": result.Error})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"token_type":    "Bearer",
+		"access_token":  result.AccessToken,
+		"id_token":      result.IDToken,
+		"refresh_token": result.RefreshToken,
+		"expires_in":    result.ExpiresIn,
+	})
+}
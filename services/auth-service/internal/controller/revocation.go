@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/auth-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// RevocationController hands the revocation list to the backend and signs users out
+// everywhere on its behalf. Only callers presenting INTERNAL_API_SECRET may use it.
+type RevocationController struct {
+	revocations    *service.RevocationService
+	internalSecret string
+}
+
+func NewRevocationController(revocations *service.RevocationService, internalSecret string) *RevocationController {
+	return &RevocationController{
+		revocations:    revocations,
+		internalSecret: internalSecret,
+	}
+}
+
+// List returns the revocations that are still in effect
+// GET /auth/internal/revocations
+func (ctrl *RevocationController) List(c *gin.Context) {
+	if !requireInternalSecret(c, ctrl.internalSecret) {
+		return
+	}
+
+	revocations, err := ctrl.revocations.List(c.Request.Context())
+	if err != nil {
+		log.Printf("Error: Failed to list revocations: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list revocations"})
+		return
+	}
+	if revocations == nil {
+		revocations = []*service.Revocation{}
+	}
+	c.JSON(http.StatusOK, gin.H{"revocations": revocations})
+}
+
+// SignOutUser ends all of a user's sessions and revokes every token issued to the user so far
+// POST /auth/internal/users/:userId/sign-out
+func (ctrl *RevocationController) SignOutUser(c *gin.Context) {
+	if !requireInternalSecret(c, ctrl.internalSecret) {
+		return
+	}
+
+	userID := c.Param("userId")
+	ended, err := ctrl.revocations.SignOutUser(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("Error: Failed to sign out user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign out user"})
+		return
+	}
+
+	log.Printf("User %s signed out everywhere, %d session(s) ended", userID, ended)
+	c.JSON(http.StatusOK, gin.H{"sessionsEnded": ended})
+}
@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/auth-service/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/auth-service/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+// SessionController lets users see and end their sessions, and lets the backend and
+// backend-editor resolve a session cookie into the session's tokens
+type SessionController struct {
+	sessions       *service.SessionService
+	internalSecret string
+}
+
+func NewSessionController(sessions *service.SessionService, internalSecret string) *SessionController {
+	return &SessionController{
+		sessions:       sessions,
+		internalSecret: internalSecret,
+	}
+}
+
+// SessionView is a session as shown to its user, without tokens
+type SessionView struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"userAgent"`
+	IPAddress  string    `json:"ipAddress"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	Current    bool      `json:"current"` // The session making this request
+}
+
+// ResolvedSession is what the internal resolve endpoint returns for a session cookie
+type ResolvedSession struct {
+	ID               string    `json:"id"`
+	UserID           string    `json:"userId"`
+	IDToken          string    `json:"idToken"`
+	GraphAccessToken string    `json:"graphAccessToken,omitempty"`
+	ExpiresAt        time.Time `json:"expiresAt"` // When IDToken expires
+}
+
+// requireInternalSecret checks that the request presents INTERNAL_API_SECRET as a bearer token,
+// or responds with 401
+func requireInternalSecret(c *gin.Context, internalSecret string) bool {
+	presented := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if internalSecret == "" || subtle.ConstantTimeCompare([]byte(presented), []byte(internalSecret)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid internal API secret"})
+		return false
+	}
+	return true
+}
+
+// currentSession returns the session behind the request's cookie, or aborts with 401
+func (ctrl *SessionController) currentSession(c *gin.Context) *service.Session {
+	secret, err := c.Cookie(util.SessionCookie)
+	if err != nil || secret == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not signed in"})
+		c.Abort()
+		return nil
+	}
+
+	session, err := ctrl.sessions.Get(c.Request.Context(), secret)
+	if errors.Is(err, service.ErrSessionNotFound) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session expired or revoked"})
+		c.Abort()
+		return nil
+	}
+	if err != nil {
+		log.Printf("Error: Failed to load session: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load session"})
+		c.Abort()
+		return nil
+	}
+	return session
+}
+
+// List returns the signed-in user's sessions, most recently used first
+// GET /auth/sessions
+func (ctrl *SessionController) List(c *gin.Context) {
+	current := ctrl.currentSession(c)
+	if current == nil {
+		return
+	}
+
+	sessions, err := ctrl.sessions.List(c.Request.Context(), current.UserID)
+	if err != nil {
+		log.Printf("Error: Failed to list sessions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions"})
+		return
+	}
+
+	views := make([]SessionView, 0, len(sessions))
+	for _, session := range sessions {
+		views = append(views, SessionView{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == current.ID,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"sessions": views})
+}
+
+// Revoke ends one of the signed-in user's sessions, e.g. on a lost device
+// DELETE /auth/sessions/:id
+func (ctrl *SessionController) Revoke(c *gin.Context) {
+	current := ctrl.currentSession(c)
+	if current == nil {
+		return
+	}
+
+	err := ctrl.sessions.Revoke(c.Request.Context(), current.UserID, c.Param("id"))
+	if errors.Is(err, service.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error: Failed to revoke session: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	if c.Param("id") == current.ID {
+		util.ClearSessionCookie(c)
+	}
+	log.Printf("Session revoked for user %s", current.UserID)
+	c.Status(http.StatusNoContent)
+}
+
+// Resolve returns the tokens of the session behind a cookie secret, refreshed if they are
+// about to expire. Only callers presenting INTERNAL_API_SECRET may use it.
+// POST /auth/internal/sessions/resolve
+func (ctrl *SessionController) Resolve(c *gin.Context) {
+	if !requireInternalSecret(c, ctrl.internalSecret) {
+		return
+	}
+
+	var req struct {
+		Session string `json:"session" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "session is required"})
+		return
+	}
+
+	session, err := ctrl.sessions.Resolve(c.Request.Context(), req.Session)
+	if errors.Is(err, service.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error: Failed to resolve session: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ResolvedSession{
+		ID:               session.ID,
+		UserID:           session.UserID,
+		IDToken:          session.IDToken,
+		GraphAccessToken: session.GraphAccessToken,
+		ExpiresAt:        session.TokenExpiresAt,
+	})
+}
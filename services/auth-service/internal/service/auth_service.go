@@ -2,27 +2,64 @@ package service
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/coreos/go-oidc/v3/oidc"
 )
 
-// OAuthScope is the standard OAuth scope used for token requests
-const OAuthScope = "api://academy-dev/GeneralAccess openid profile email offline_access"
+// DefaultScopes are requested from providers when OIDC_SCOPES is not set
+var DefaultScopes = []string{oidc.ScopeOpenID, "profile", "email", oidc.ScopeOfflineAccess}
+
+// EntraAPIScope is added to the default scopes on Microsoft Entra ID, so the access token is
+// issued for the Rosetta API
+const EntraAPIScope = "api://academy-dev/GeneralAccess"
+
+// GraphScope is requested for the Microsoft Graph token exchanged after login
+const GraphScope = "https://graph.microsoft.com/.default"
+
+// ErrGraphNotSupported is returned by GetGraphToken for providers other than Microsoft Entra ID
+var ErrGraphNotSupported = errors.New("identity provider does not issue Microsoft Graph tokens")
+
+// ProviderConfig configures the OpenID Connect provider users sign in with
+type ProviderConfig struct {
+	Issuer       string // Issuer URL, or a Microsoft Entra tenant ID
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string
+	Scopes       []string   // DefaultScopes when empty (with EntraAPIScope on Entra ID)
+	AuthParams   url.Values // Extra authorize parameters, e.g. access_type=offline for Google
+	EndSession   bool       // Sign users out of the provider too when they log out
+}
 
 type AuthService struct {
-	provider     *oidc.Provider
-	verifier     *oidc.IDTokenVerifier
-	clientID     string
-	clientSecret string
-	redirectURI  string
-	tenantID     string
+	provider       *oidc.Provider
+	verifier       *oidc.IDTokenVerifier
+	issuedVerifier *oidc.IDTokenVerifier // Also accepts expired ID tokens, for TokenIssued
+	clientID       string
+	clientSecret   string
+	redirectURI    string
+	scopes         []string
+	authParams     url.Values
+
+	// Endpoints from the provider's discovery document
+	authURL       string
+	tokenURL      string
+	revocationURL string // RFC 7009 token revocation; Entra ID has none
+	endSessionURL string // Set only with ProviderConfig.EndSession
+	basicAuth     bool   // Send client credentials with HTTP Basic instead of in the form
+	graph         bool   // Microsoft Entra ID, which also issues Microsoft Graph tokens
+
+	// dev replaces the OIDC provider when the development identity provider is enabled
+	dev *DevIdentityProvider
 }
 
 type TokenValidationResult struct {
@@ -43,39 +80,258 @@ type TokenRefreshResult struct {
 	Error        string `json:"error,omitempty"`
 }
 
-func NewAuthService(issuer, clientID, clientSecret, redirectURI string) (*AuthService, error) {
-	// Extract tenant ID from issuer URL if it's in the Microsoft format
-	tenantID := ExtractTenantID(issuer)
-
+func NewAuthService(cfg ProviderConfig) (*AuthService, error) {
 	ctx := context.Background()
 
-	// Build full issuer URL for Microsoft OIDC
-	fullIssuer := issuer
-	if !strings.Contains(issuer, "login.microsoftonline.com") {
-		fullIssuer = fmt.Sprintf("https://login.microsoftonline.com/%s/v2.0", issuer)
-	} else if !strings.HasSuffix(issuer, "/v2.0") {
-		fullIssuer = fmt.Sprintf("%s/v2.0", issuer)
-	}
-
-	provider, err := oidc.NewProvider(ctx, fullIssuer)
+	issuer := NormalizeIssuer(cfg.Issuer)
+	provider, err := oidc.NewProvider(ctx, issuer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize OIDC provider: %w", err)
 	}
 
-	verifier := provider.Verifier(&oidc.Config{ClientID: clientID})
+	var metadata struct {
+		AuthMethods        []string `json:"token_endpoint_auth_methods_supported"`
+		RevocationEndpoint string   `json:"revocation_endpoint"`
+		EndSessionEndpoint string   `json:"end_session_endpoint"`
+	}
+	if err := provider.Claims(&metadata); err != nil {
+		return nil, fmt.Errorf("failed to read OIDC provider metadata: %w", err)
+	}
+
+	entra := IsEntraIssuer(issuer)
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+		if entra {
+			scopes = append([]string{EntraAPIScope}, DefaultScopes...)
+		}
+	}
+
+	endSessionURL := ""
+	if cfg.EndSession {
+		if metadata.EndSessionEndpoint == "" {
+			return nil, fmt.Errorf("OIDC provider %s has no end_session_endpoint", issuer)
+		}
+		endSessionURL = metadata.EndSessionEndpoint
+	}
 
 	return &AuthService{
-		provider:     provider,
-		verifier:     verifier,
-		clientID:     clientID,
-		clientSecret: clientSecret,
-		redirectURI:  redirectURI,
-		tenantID:     tenantID,
+		provider:       provider,
+		verifier:       provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+		issuedVerifier: provider.Verifier(&oidc.Config{ClientID: cfg.ClientID, SkipExpiryCheck: true}),
+		clientID:       cfg.ClientID,
+		clientSecret:   cfg.ClientSecret,
+		redirectURI:    cfg.RedirectURI,
+		scopes:         scopes,
+		authParams:     cfg.AuthParams,
+		authURL:        provider.Endpoint().AuthURL,
+		tokenURL:       provider.Endpoint().TokenURL,
+		revocationURL:  metadata.RevocationEndpoint,
+		endSessionURL:  endSessionURL,
+		basicAuth:      prefersBasicAuth(metadata.AuthMethods),
+		graph:          entra,
 	}, nil
 }
 
-// ValidateToken validates an OIDC ID token and returns claims
-func (s *AuthService) ValidateToken(token string) *TokenValidationResult {
+// NormalizeIssuer turns a Microsoft Entra tenant ID or tenant URL into the tenant's v2.0 issuer.
+// Other issuers are used as they are.
+func NormalizeIssuer(issuer string) string {
+	issuer = strings.TrimSuffix(issuer, "/")
+	if !strings.Contains(issuer, "://") {
+		return fmt.Sprintf("https://login.microsoftonline.com/%s/v2.0", issuer)
+	}
+	if IsEntraIssuer(issuer) && !strings.HasSuffix(issuer, "/v2.0") {
+		return issuer + "/v2.0"
+	}
+	return issuer
+}
+
+// IsEntraIssuer reports whether issuer is a Microsoft Entra ID tenant
+func IsEntraIssuer(issuer string) bool {
+	return strings.Contains(issuer, "login.microsoftonline.com")
+}
+
+// prefersBasicAuth reports whether the token endpoint accepts client credentials only with HTTP
+// Basic, as Okta does by default. Credentials are sent in the form otherwise.
+func prefersBasicAuth(methods []string) bool {
+	return !slices.Contains(methods, "client_secret_post") && slices.Contains(methods, "client_secret_basic")
+}
+
+// NewDevAuthService signs users in with the development identity provider instead of Microsoft
+func NewDevAuthService(dev *DevIdentityProvider) *AuthService {
+	return &AuthService{
+		verifier:       dev.Verifier(),
+		issuedVerifier: dev.verifier(&oidc.Config{ClientID: dev.ClientID, SkipExpiryCheck: true}),
+		clientID:       dev.ClientID,
+		redirectURI:    dev.RedirectURI,
+		dev:            dev,
+	}
+}
+
+// Dev returns the development identity provider, or nil when signing in with an OIDC provider
+func (s *AuthService) Dev() *DevIdentityProvider {
+	return s.dev
+}
+
+// AuthorizeURL returns the URL of the login page that starts the authorization code flow,
+// carrying the flow's state, nonce and PKCE challenge
+func (s *AuthService) AuthorizeURL(flow *LoginFlow) string {
+	query := url.Values{}
+	query.Set("client_id", s.clientID)
+	query.Set("response_type", "code")
+	query.Set("redirect_uri", s.redirectURI)
+	query.Set("scope", strings.Join(s.scopes, " "))
+	query.Set("state", flow.State)
+	query.Set("nonce", flow.Nonce)
+	query.Set("code_challenge", flow.CodeChallenge())
+	query.Set("code_challenge_method", "S256")
+
+	for key, values := range s.authParams {
+		if query.Has(key) {
+			continue // Never let configuration replace the flow's own parameters
+		}
+		query[key] = values
+	}
+
+	if s.dev != nil {
+		return s.dev.AuthorizeURL(query.Encode())
+	}
+	separator := "?"
+	if strings.Contains(s.authURL, "?") {
+		separator = "&"
+	}
+	return s.authURL + separator + query.Encode()
+}
+
+// SupportsGraph reports whether GetGraphToken can exchange tokens for Microsoft Graph
+func (s *AuthService) SupportsGraph() bool {
+	return s.graph
+}
+
+// EndSessionURL returns the provider's logout page, which signs the user out of the provider
+// and then sends them to postLogoutRedirectURI. It returns "" unless ProviderConfig.EndSession
+// is set. Entra ID only redirects to URIs registered on the app as front-channel logout URLs.
+func (s *AuthService) EndSessionURL(idTokenHint, postLogoutRedirectURI string) string {
+	if s.endSessionURL == "" {
+		return ""
+	}
+	query := url.Values{}
+	query.Set("client_id", s.clientID)
+	query.Set("post_logout_redirect_uri", postLogoutRedirectURI)
+	if idTokenHint != "" {
+		query.Set("id_token_hint", idTokenHint)
+	}
+
+	separator := "?"
+	if strings.Contains(s.endSessionURL, "?") {
+		separator = "&"
+	}
+	return s.endSessionURL + separator + query.Encode()
+}
+
+// tokenEndpointError is a token or revocation endpoint answer other than 200 OK
+type tokenEndpointError struct {
+	status int
+	body   string
+}
+
+func (e *tokenEndpointError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.status, e.body)
+}
+
+// postToken sends a request to the provider's token endpoint and decodes the JSON response
+func (s *AuthService) postToken(data url.Values) (map[string]interface{}, error) {
+	bodyBytes, err := s.postClientRequest(s.tokenURL, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokenResponse map[string]interface{}
+	if err := json.Unmarshal(bodyBytes, &tokenResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	return tokenResponse, nil
+}
+
+// postClientRequest posts a form to one of the provider's endpoints, authenticated with the
+// client credentials, and returns the body of a 200 OK response
+func (s *AuthService) postClientRequest(endpoint string, data url.Values) ([]byte, error) {
+	data.Set("client_id", s.clientID)
+	if !s.basicAuth {
+		data.Set("client_secret", s.clientSecret)
+	}
+
+	req, err := http.NewRequest("POST", endpoint, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	if s.basicAuth {
+		req.SetBasicAuth(url.QueryEscape(s.clientID), url.QueryEscape(s.clientSecret))
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, &tokenEndpointError{status: resp.StatusCode, body: string(bodyBytes)}
+	}
+	return bodyBytes, nil
+}
+
+// ExchangeCode redeems the authorization code from the login callback for tokens, proving
+// with codeVerifier that this service started the flow
+func (s *AuthService) ExchangeCode(code, codeVerifier string) *TokenRefreshResult {
+	if s.dev != nil {
+		return s.dev.ExchangeCode(code, s.redirectURI, codeVerifier)
+	}
+
+	data := url.Values{}
+	data.Set("grant_type", "authorization_code")
+	data.Set("code", code)
+	data.Set("redirect_uri", s.redirectURI)
+	data.Set("scope", strings.Join(s.scopes, " "))
+	data.Set("code_verifier", codeVerifier)
+
+	tokenResponse, err := s.postToken(data)
+	if err != nil {
+		return &TokenRefreshResult{
+			Success: false,
+			Error:   fmt.Sprintf("Token exchange failed: %v", err),
+		}
+	}
+
+	accessToken, _ := tokenResponse["access_token"].(string)
+	idToken, _ := tokenResponse["id_token"].(string)
+	refreshToken, _ := tokenResponse["refresh_token"].(string)
+	expiresIn, _ := tokenResponse["expires_in"].(float64)
+
+	// Providers only issue refresh tokens when asked to (offline_access, or access_type=offline
+	// on Google); without one, users sign in again when the ID token expires
+	if accessToken == "" || idToken == "" {
+		return &TokenRefreshResult{
+			Success: false,
+			Error:   "Missing tokens in token response",
+		}
+	}
+
+	return &TokenRefreshResult{
+		Success:      true,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		IDToken:      idToken,
+		ExpiresIn:    int(expiresIn),
+	}
+}
+
+// ValidateToken validates an OIDC ID token and returns claims. When expectedNonce is set, the
+// token's nonce claim must match it, so a token from another login cannot be replayed.
+func (s *AuthService) ValidateToken(token, expectedNonce string) *TokenValidationResult {
 	ctx := context.Background()
 
 	idToken, err := s.verifier.Verify(ctx, token)
@@ -86,6 +342,13 @@ func (s *AuthService) ValidateToken(token string) *TokenValidationResult {
 		}
 	}
 
+	if expectedNonce != "" && subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(expectedNonce)) != 1 {
+		return &TokenValidationResult{
+			Valid: false,
+			Error: "Token nonce does not match the login request",
+		}
+	}
+
 	// Extract claims
 	claims := make(map[string]interface{})
 	if err := idToken.Claims(&claims); err != nil {
@@ -95,8 +358,11 @@ func (s *AuthService) ValidateToken(token string) *TokenValidationResult {
 		}
 	}
 
-	// Extract standard fields
+	// Extract standard fields. Entra ID identifies users by oid; other providers only by sub.
 	entraID, _ := claims["oid"].(string)
+	if entraID == "" {
+		entraID = idToken.Subject
+	}
 	email, _ := claims["email"].(string)
 	name, _ := claims["name"].(string)
 
@@ -108,7 +374,7 @@ func (s *AuthService) ValidateToken(token string) *TokenValidationResult {
 	if entraID == "" {
 		return &TokenValidationResult{
 			Valid: false,
-			Error: "Missing oid claim in token",
+			Error: "Missing oid and sub claims in token",
 		}
 	}
 
@@ -121,50 +387,52 @@ func (s *AuthService) ValidateToken(token string) *TokenValidationResult {
 	}
 }
 
-// RefreshToken exchanges a refresh token for new access and ID tokens
-func (s *AuthService) RefreshToken(refreshToken string) *TokenRefreshResult {
-	tokenURL := fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", s.tenantID)
-
-	data := url.Values{}
-	data.Set("grant_type", "refresh_token")
-	data.Set("refresh_token", refreshToken)
-	data.Set("client_id", s.clientID)
-	data.Set("client_secret", s.clientSecret)
-	data.Set("scope", OAuthScope)
-
-	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(data.Encode()))
-	if err != nil {
-		return &TokenRefreshResult{
-			Success: false,
-			Error:   fmt.Sprintf("Failed to create refresh request: %v", err),
-		}
+// TokenIssued returns the user an ID token was issued to and when, also after it expired. ok is
+// false for tokens this provider did not sign.
+func (s *AuthService) TokenIssued(token string) (userID string, issuedAt time.Time, ok bool) {
+	if token == "" {
+		return "", time.Time{}, false
 	}
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	idToken, err := s.issuedVerifier.Verify(context.Background(), token)
 	if err != nil {
-		return &TokenRefreshResult{
-			Success: false,
-			Error:   fmt.Sprintf("Failed to call refresh endpoint: %v", err),
-		}
+		return "", time.Time{}, false
 	}
-	defer resp.Body.Close()
+	var claims struct {
+		OID string `json:"oid"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return "", time.Time{}, false
+	}
+	userID = claims.OID
+	if userID == "" {
+		userID = idToken.Subject
+	}
+	return userID, idToken.IssuedAt, userID != ""
+}
 
-	bodyBytes, _ := io.ReadAll(resp.Body)
+// RefreshToken exchanges a refresh token for new access and ID tokens
+func (s *AuthService) RefreshToken(refreshToken string) *TokenRefreshResult {
+	if s.dev != nil {
+		return s.dev.Refresh(refreshToken)
+	}
 
-	if resp.StatusCode != http.StatusOK {
+	if refreshToken == "" {
 		return &TokenRefreshResult{
 			Success: false,
-			Error:   fmt.Sprintf("Token refresh failed with status %d: %s", resp.StatusCode, string(bodyBytes)),
+			Error:   "No refresh token was issued at login",
 		}
 	}
 
-	var tokenResponse map[string]interface{}
-	if err := json.Unmarshal(bodyBytes, &tokenResponse); err != nil {
+	data := url.Values{}
+	data.Set("grant_type", "refresh_token")
+	data.Set("refresh_token", refreshToken)
+	data.Set("scope", strings.Join(s.scopes, " "))
+
+	tokenResponse, err := s.postToken(data)
+	if err != nil {
 		return &TokenRefreshResult{
 			Success: false,
-			Error:   fmt.Sprintf("Failed to decode token response: %v", err),
+			Error:   fmt.Sprintf("Token refresh failed: %v", err),
 		}
 	}
 
@@ -194,39 +462,42 @@ func (s *AuthService) RefreshToken(refreshToken string) *TokenRefreshResult {
 	}
 }
 
-// GetGraphToken exchanges a refresh token for a Graph API-specific access token
-func (s *AuthService) GetGraphToken(refreshToken string) (string, error) {
-	tokenURL := fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", s.tenantID)
+// RevokeRefreshToken revokes a refresh token at the provider's revocation endpoint, so it cannot
+// be used again. Providers without one (Entra ID) keep the token valid until it expires; nil is
+// returned for them.
+func (s *AuthService) RevokeRefreshToken(refreshToken string) error {
+	if s.dev != nil {
+		s.dev.RevokeRefreshToken(refreshToken)
+		return nil
+	}
+	if s.revocationURL == "" || refreshToken == "" {
+		return nil
+	}
 
 	data := url.Values{}
-	data.Set("grant_type", "refresh_token")
-	data.Set("refresh_token", refreshToken)
-	data.Set("client_id", s.clientID)
-	data.Set("client_secret", s.clientSecret)
-	data.Set("scope", "https://graph.microsoft.com/.default")
-
-	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(data.Encode()))
-	if err != nil {
-		return "", fmt.Errorf("failed to create graph token request: %w", err)
+	data.Set("token", refreshToken)
+	data.Set("token_type_hint", "refresh_token")
+	if _, err := s.postClientRequest(s.revocationURL, data); err != nil {
+		return fmt.Errorf("token revocation failed: %w", err)
 	}
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	return nil
+}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to call graph token endpoint: %w", err)
+// GetGraphToken exchanges a refresh token for a Graph API-specific access token. Only Microsoft
+// Entra ID issues them; other providers return ErrGraphNotSupported.
+func (s *AuthService) GetGraphToken(refreshToken string) (string, error) {
+	if !s.graph {
+		return "", ErrGraphNotSupported
 	}
-	defer resp.Body.Close()
-
-	bodyBytes, _ := io.ReadAll(resp.Body)
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("graph token request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
+	data := url.Values{}
+	data.Set("grant_type", "refresh_token")
+	data.Set("refresh_token", refreshToken)
+	data.Set("scope", GraphScope)
 
-	var tokenResponse map[string]interface{}
-	if err := json.Unmarshal(bodyBytes, &tokenResponse); err != nil {
-		return "", fmt.Errorf("failed to decode graph token response: %w", err)
+	tokenResponse, err := s.postToken(data)
+	if err != nil {
+		return "", fmt.Errorf("graph token request failed: %w", err)
 	}
 
 	accessToken, ok := tokenResponse["access_token"].(string)
@@ -236,22 +507,3 @@ func (s *AuthService) GetGraphToken(refreshToken string) (string, error) {
 
 	return accessToken, nil
 }
-
-// ExtractTenantID extracts tenant ID from various issuer formats.
-// Supports plain tenant ID or full URL like "https://login.microsoftonline.com/TENANT_ID/v2.0"
-func ExtractTenantID(issuer string) string {
-	// If it's already just the tenant ID
-	if !strings.Contains(issuer, "http") && !strings.Contains(issuer, "/") {
-		return issuer
-	}
-
-	// Extract from URL like https://login.microsoftonline.com/{tenant}/v2.0
-	parts := strings.Split(issuer, "/")
-	for i, part := range parts {
-		if part == "login.microsoftonline.com" && i+1 < len(parts) {
-			return parts[i+1]
-		}
-	}
-
-	return issuer
-}
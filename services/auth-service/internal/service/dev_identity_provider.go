@@ -0,0 +1,390 @@
+package service
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+)
+
+// DevIdentityProviderPath is where auth-service serves the development identity provider.
+// It lives under /auth so nginx routes it like the rest of the OAuth flow.
+const DevIdentityProviderPath = "/auth/dev"
+
+const (
+	devCodeLifetime    = time.Minute
+	devRefreshLifetime = 24 * time.Hour
+)
+
+// DevUser is a fake account offered on the development login form
+type DevUser struct {
+	ID     string   `json:"id"` // used as oid and sub
+	Name   string   `json:"name"`
+	Email  string   `json:"email"`
+	Groups []string `json:"groups,omitempty"`
+	Roles  []string `json:"roles,omitempty"`
+}
+
+// ParseDevUsers reads users from "email:Name:group1|group2:Role1|Role2" entries separated by
+// commas. Name, groups and roles are optional. Each user's ID is derived from the email, so it
+// stays the same across restarts.
+func ParseDevUsers(raw string) ([]DevUser, error) {
+	var users []DevUser
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) > 4 {
+			return nil, fmt.Errorf("invalid dev user %q: expected email:Name:groups:roles", entry)
+		}
+		email := strings.ToLower(strings.TrimSpace(parts[0]))
+		if !strings.Contains(email, "@") {
+			return nil, fmt.Errorf("invalid dev user %q: %q is not an email address", entry, email)
+		}
+
+		user := DevUser{ID: devUserID(email), Email: email, Name: email}
+		if len(parts) > 1 && strings.TrimSpace(parts[1]) != "" {
+			user.Name = strings.TrimSpace(parts[1])
+		}
+		if len(parts) > 2 {
+			user.Groups = splitDevList(parts[2])
+		}
+		if len(parts) > 3 {
+			user.Roles = splitDevList(parts[3])
+		}
+		users = append(users, user)
+	}
+
+	if len(users) == 0 {
+		return nil, errors.New("no dev users configured")
+	}
+	return users, nil
+}
+
+func splitDevList(raw string) []string {
+	var values []string
+	for _, value := range strings.Split(raw, "|") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// devUserID formats a hash of the email like an Entra object ID
+func devUserID(email string) string {
+	sum := sha256.Sum256([]byte(email))
+	h := hex.EncodeToString(sum[:16])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+// DevIdentityProvider is a minimal OpenID Connect provider for local development. It signs
+// tokens for the configured fake users with its own RSA key and publishes discovery and JWKS
+// documents, so the backend and editor can verify those tokens without an Entra tenant.
+type DevIdentityProvider struct {
+	Issuer      string
+	ClientID    string
+	RedirectURI string
+	Users       []DevUser
+	// TokenLifetime is how long ID and access tokens are valid
+	TokenLifetime time.Duration
+
+	key   *rsa.PrivateKey
+	keyID string
+
+	mu            sync.Mutex
+	codes         map[string]devGrant
+	refreshTokens map[string]devGrant
+}
+
+type devGrant struct {
+	userID        string
+	nonce         string
+	codeChallenge string
+	expiresAt     time.Time
+}
+
+// NewDevIdentityProvider creates a provider signing with the RSA key in keyPEM (PKCS#1 or
+// PKCS#8). Without a key, one is generated and tokens stop verifying after a restart.
+func NewDevIdentityProvider(issuer, clientID, redirectURI string, users []DevUser, keyPEM []byte) (*DevIdentityProvider, error) {
+	key, err := loadOrGenerateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode dev public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+
+	return &DevIdentityProvider{
+		Issuer:        strings.TrimSuffix(issuer, "/"),
+		ClientID:      clientID,
+		RedirectURI:   redirectURI,
+		Users:         users,
+		TokenLifetime: time.Hour,
+		key:           key,
+		keyID:         hex.EncodeToString(sum[:8]),
+		codes:         make(map[string]devGrant),
+		refreshTokens: make(map[string]devGrant),
+	}, nil
+}
+
+func loadOrGenerateKey(keyPEM []byte) (*rsa.PrivateKey, error) {
+	if len(keyPEM) == 0 {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate dev signing key: %w", err)
+		}
+		return key, nil
+	}
+
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("dev signing key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dev signing key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("dev signing key must be an RSA key")
+	}
+	return key, nil
+}
+
+// User returns the configured user with the given ID
+func (p *DevIdentityProvider) User(id string) (DevUser, bool) {
+	for _, user := range p.Users {
+		if user.ID == id {
+			return user, true
+		}
+	}
+	return DevUser{}, false
+}
+
+// Discovery returns the OpenID Connect discovery document
+func (p *DevIdentityProvider) Discovery() map[string]interface{} {
+	return map[string]interface{}{
+		"issuer":                                p.Issuer,
+		"authorization_endpoint":                p.Issuer + "/authorize",
+		"token_endpoint":                        p.Issuer + "/token",
+		"jwks_uri":                              p.Issuer + "/keys",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{oidc.RS256},
+		"scopes_supported":                      []string{oidc.ScopeOpenID, "profile", "email", oidc.ScopeOfflineAccess},
+		"grant_types_supported":                 []string{"authorization_code", "refresh_token"},
+		"claims_supported":                      []string{"sub", "oid", "jti", "name", "email", "preferred_username", "groups", "roles", "nonce"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	}
+}
+
+// JWKS returns the public signing key as a JSON Web Key Set
+func (p *DevIdentityProvider) JWKS() map[string]interface{} {
+	pub := p.key.PublicKey
+	return map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": oidc.RS256,
+			"kid": p.keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	}
+}
+
+// Verifier returns a verifier for the ID tokens this provider issues
+func (p *DevIdentityProvider) Verifier() *oidc.IDTokenVerifier {
+	return p.verifier(&oidc.Config{ClientID: p.ClientID})
+}
+
+func (p *DevIdentityProvider) verifier(config *oidc.Config) *oidc.IDTokenVerifier {
+	keySet := &oidc.StaticKeySet{PublicKeys: []crypto.PublicKey{&p.key.PublicKey}}
+	return oidc.NewVerifier(p.Issuer, keySet, config)
+}
+
+// AuthorizeURL returns the login form URL relative to auth-service
+func (p *DevIdentityProvider) AuthorizeURL(query string) string {
+	return DevIdentityProviderPath + "/authorize?" + query
+}
+
+// IssueCode signs userID in and returns a one-time authorization code for redirectURI. With a
+// PKCE codeChallenge (S256), the code is only redeemed together with the matching verifier.
+func (p *DevIdentityProvider) IssueCode(userID, redirectURI, nonce, codeChallenge string) (string, error) {
+	if _, ok := p.User(userID); !ok {
+		return "", fmt.Errorf("unknown dev user %q", userID)
+	}
+	if redirectURI != p.RedirectURI {
+		return "", fmt.Errorf("redirect_uri %q is not registered", redirectURI)
+	}
+
+	code, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.codes[code] = devGrant{userID: userID, nonce: nonce, codeChallenge: codeChallenge, expiresAt: time.Now().Add(devCodeLifetime)}
+	return code, nil
+}
+
+// ExchangeCode redeems an authorization code for tokens
+func (p *DevIdentityProvider) ExchangeCode(code, redirectURI, codeVerifier string) *TokenRefreshResult {
+	if redirectURI != p.RedirectURI {
+		return &TokenRefreshResult{Success: false, Error: "redirect_uri does not match"}
+	}
+
+	p.mu.Lock()
+	grant, ok := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+
+	if !ok || time.Now().After(grant.expiresAt) {
+		return &TokenRefreshResult{Success: false, Error: "Invalid or expired authorization code"}
+	}
+	if grant.codeChallenge != "" && PKCEChallenge(codeVerifier) != grant.codeChallenge {
+		return &TokenRefreshResult{Success: false, Error: "code_verifier does not match the code challenge"}
+	}
+	return p.issueTokens(grant)
+}
+
+// RevokeRefreshToken makes a refresh token unusable
+func (p *DevIdentityProvider) RevokeRefreshToken(refreshToken string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.refreshTokens, refreshToken)
+}
+
+// Refresh redeems a refresh token for new tokens. Refresh tokens are single use.
+func (p *DevIdentityProvider) Refresh(refreshToken string) *TokenRefreshResult {
+	p.mu.Lock()
+	grant, ok := p.refreshTokens[refreshToken]
+	delete(p.refreshTokens, refreshToken)
+	p.mu.Unlock()
+
+	if !ok || time.Now().After(grant.expiresAt) {
+		return &TokenRefreshResult{Success: false, Error: "Invalid or expired refresh token"}
+	}
+	grant.nonce = "" // Refreshed ID tokens carry no nonce
+	return p.issueTokens(grant)
+}
+
+func (p *DevIdentityProvider) issueTokens(grant devGrant) *TokenRefreshResult {
+	user, ok := p.User(grant.userID)
+	if !ok {
+		return &TokenRefreshResult{Success: false, Error: "User no longer exists"}
+	}
+
+	tokenID, err := randomToken()
+	if err != nil {
+		return &TokenRefreshResult{Success: false, Error: err.Error()}
+	}
+
+	now := time.Now()
+	claims := map[string]interface{}{
+		"jti":                tokenID,
+		"iss":                p.Issuer,
+		"sub":                user.ID,
+		"aud":                p.ClientID,
+		"iat":                now.Unix(),
+		"exp":                now.Add(p.TokenLifetime).Unix(),
+		"oid":                user.ID,
+		"name":               user.Name,
+		"email":              user.Email,
+		"preferred_username": user.Email,
+	}
+	if user.Groups != nil {
+		claims["groups"] = user.Groups
+	}
+	if user.Roles != nil {
+		claims["roles"] = user.Roles
+	}
+	if grant.nonce != "" {
+		claims["nonce"] = grant.nonce
+	}
+
+	idToken, err := p.sign(claims)
+	if err != nil {
+		return &TokenRefreshResult{Success: false, Error: err.Error()}
+	}
+	accessToken, err := p.sign(map[string]interface{}{
+		"iss": p.Issuer,
+		"sub": user.ID,
+		"aud": p.ClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(p.TokenLifetime).Unix(),
+		"oid": user.ID,
+		"scp": "GeneralAccess",
+	})
+	if err != nil {
+		return &TokenRefreshResult{Success: false, Error: err.Error()}
+	}
+	refreshToken, err := randomToken()
+	if err != nil {
+		return &TokenRefreshResult{Success: false, Error: err.Error()}
+	}
+
+	p.mu.Lock()
+	p.refreshTokens[refreshToken] = devGrant{userID: user.ID, expiresAt: now.Add(devRefreshLifetime)}
+	p.mu.Unlock()
+
+	return &TokenRefreshResult{
+		Success:      true,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		IDToken:      idToken,
+		ExpiresIn:    int(p.TokenLifetime.Seconds()),
+	}
+}
+
+// sign encodes claims as an RS256 JWT
+func (p *DevIdentityProvider) sign(claims map[string]interface{}) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": oidc.RS256, "typ": "JWT", "kid": p.keyID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode claims: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LoginFlowLifetime bounds how long a user may take between /auth/login and /auth/callback
+const LoginFlowLifetime = 10 * time.Minute
+
+// LoginFlow is what Login remembers for Callback: the state that ties the callback to this
+// browser, the nonce expected in the ID token, the PKCE code verifier and where to go afterwards
+type LoginFlow struct {
+	State        string    `json:"s"`
+	Nonce        string    `json:"n"`
+	CodeVerifier string    `json:"v"`
+	ReturnTo     string    `json:"r,omitempty"`
+	ExpiresAt    time.Time `json:"e"`
+}
+
+// NewLoginFlow generates fresh random state, nonce and code verifier
+func NewLoginFlow(returnTo string) (*LoginFlow, error) {
+	state, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	return &LoginFlow{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ReturnTo:     returnTo,
+		ExpiresAt:    time.Now().Add(LoginFlowLifetime),
+	}, nil
+}
+
+// CodeChallenge returns the S256 PKCE challenge for the flow's code verifier
+func (f *LoginFlow) CodeChallenge() string {
+	return PKCEChallenge(f.CodeVerifier)
+}
+
+// PKCEChallenge derives the S256 code challenge from a code verifier
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// LoginFlowCodec signs login flows so they can be kept in a cookie without the browser being
+// able to change them. Every instance of auth-service must share the secret.
+type LoginFlowCodec struct {
+	secret []byte
+}
+
+// NewLoginFlowCodec signs with secret, or with a random secret when it is empty
+func NewLoginFlowCodec(secret []byte) (*LoginFlowCodec, error) {
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate login flow secret: %w", err)
+		}
+	}
+	return &LoginFlowCodec{secret: secret}, nil
+}
+
+// Encode serializes and signs flow
+func (c *LoginFlowCodec) Encode(flow *LoginFlow) (string, error) {
+	payload, err := json.Marshal(flow)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode login flow: %w", err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + c.sign(encoded), nil
+}
+
+// Decode checks the signature and expiry of a value produced by Encode
+func (c *LoginFlowCodec) Decode(value string) (*LoginFlow, error) {
+	encoded, signature, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(c.sign(encoded))) {
+		return nil, errors.New("login flow signature is invalid")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode login flow: %w", err)
+	}
+	var flow LoginFlow
+	if err := json.Unmarshal(payload, &flow); err != nil {
+		return nil, fmt.Errorf("failed to decode login flow: %w", err)
+	}
+	if time.Now().After(flow.ExpiresAt) {
+		return nil, errors.New("login flow has expired")
+	}
+	return &flow, nil
+}
+
+func (c *LoginFlowCodec) sign(encoded string) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
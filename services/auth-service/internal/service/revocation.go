@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Revocation kinds
+const (
+	RevokedSession = "session" // Value is a session ID
+	RevokedToken   = "token"   // Value is an ID token's jti (uti on Entra ID)
+	RevokedUser    = "user"    // Value is a user ID; every token issued before RevokedAt is revoked
+
+	// RevokedRefreshToken entries are checked by auth-service alone and not handed to the backend.
+	// Value is RefreshTokenID of a refresh token kept in cookies.
+	RevokedRefreshToken = "refresh_token"
+)
+
+// userRevocationLifetime is how long signing a user out everywhere is remembered. It outlives
+// every ID token issued before it, and sessions are ended along with it.
+const userRevocationLifetime = 24 * time.Hour
+
+// refreshTokenRevocationLifetime is how long a revoked refresh token is remembered: Entra ID's
+// refresh tokens stay usable for up to 90 days, and their expiry is not known to auth-service
+const refreshTokenRevocationLifetime = 90 * 24 * time.Hour
+
+// Revocation is an entry of the revocation list the backend checks tokens against. Entries
+// are dropped once nothing they revoke can still be used.
+type Revocation struct {
+	Kind      string    `json:"kind"`
+	Value     string    `json:"value"`
+	RevokedAt time.Time `json:"revokedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// RevocationStore keeps the revocation list. Adding an entry with the kind and value of an
+// existing one replaces it. Get and List never return expired entries; Get returns nil for
+// values that are not revoked.
+type RevocationStore interface {
+	Add(ctx context.Context, revocation *Revocation) error
+	Get(ctx context.Context, kind, value string) (*Revocation, error)
+	List(ctx context.Context) ([]*Revocation, error)
+}
+
+// TokenID returns the identifier a token is revoked by: jti, or uti on Entra ID tokens
+func TokenID(claims map[string]interface{}) string {
+	if jti, _ := claims["jti"].(string); jti != "" {
+		return jti
+	}
+	uti, _ := claims["uti"].(string)
+	return uti
+}
+
+// RefreshTokenID derives the value a refresh token is revoked by, so the token itself is not stored
+func RefreshTokenID(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// RevocationService records signed-out sessions, tokens and users, and revokes refresh tokens
+// at the identity provider where it supports that
+type RevocationService struct {
+	Store    RevocationStore
+	Auth     *AuthService
+	Sessions *SessionService // nil without a session store
+}
+
+func NewRevocationService(store RevocationStore, authService *AuthService, sessions *SessionService) *RevocationService {
+	return &RevocationService{
+		Store:    store,
+		Auth:     authService,
+		Sessions: sessions,
+	}
+}
+
+// RevokeTokens revokes an ID token until it expires and a refresh token, both at the identity
+// provider where it supports that and in the revocation list, which RefreshTokenRevoked checks.
+// Either may be empty; an ID token that no longer validates needs no revoking.
+func (s *RevocationService) RevokeTokens(ctx context.Context, idToken, refreshToken string) error {
+	if refreshToken != "" {
+		if err := s.Auth.RevokeRefreshToken(refreshToken); err != nil {
+			log.Printf("Warning: Failed to revoke refresh token at the identity provider: %v", err)
+		}
+		now := time.Now()
+		if err := s.Store.Add(ctx, &Revocation{
+			Kind:      RevokedRefreshToken,
+			Value:     RefreshTokenID(refreshToken),
+			RevokedAt: now,
+			ExpiresAt: now.Add(refreshTokenRevocationLifetime),
+		}); err != nil {
+			return fmt.Errorf("failed to revoke refresh token: %w", err)
+		}
+	}
+	if idToken == "" {
+		return nil
+	}
+
+	validation := s.Auth.ValidateToken(idToken, "")
+	if !validation.Valid {
+		return nil
+	}
+	id := TokenID(validation.Claims)
+	if id == "" {
+		return nil // Only the user or session can be revoked
+	}
+	return s.Store.Add(ctx, &Revocation{
+		Kind:      RevokedToken,
+		Value:     id,
+		RevokedAt: time.Now(),
+		ExpiresAt: tokenExpiry(validation.Claims, 0, time.Now()),
+	})
+}
+
+// RevokeSession records an ended session along with its tokens. It is called for every session
+// that is signed out or revoked, so failures are logged rather than returned.
+func (s *RevocationService) RevokeSession(ctx context.Context, session *Session) {
+	err := s.Store.Add(ctx, &Revocation{
+		Kind:      RevokedSession,
+		Value:     session.ID,
+		RevokedAt: time.Now(),
+		ExpiresAt: session.ExpiresAt,
+	})
+	if err == nil {
+		err = s.RevokeTokens(ctx, session.IDToken, session.RefreshToken)
+	}
+	if err != nil {
+		log.Printf("Warning: Failed to record revocation of session of user %s: %v", session.UserID, err)
+	}
+}
+
+// SignOutUser ends all of the user's sessions and revokes every token issued to the user so
+// far. It returns how many sessions were ended.
+func (s *RevocationService) SignOutUser(ctx context.Context, userID string) (int, error) {
+	now := time.Now()
+	if err := s.Store.Add(ctx, &Revocation{
+		Kind:      RevokedUser,
+		Value:     userID,
+		RevokedAt: now,
+		ExpiresAt: now.Add(userRevocationLifetime),
+	}); err != nil {
+		return 0, fmt.Errorf("failed to revoke tokens of user: %w", err)
+	}
+
+	if s.Sessions == nil {
+		return 0, nil
+	}
+	return s.Sessions.DeleteAll(ctx, userID)
+}
+
+// RefreshTokenRevoked reports whether a refresh token kept in cookies was signed out. Its holder
+// may not use it even where the identity provider still accepts it.
+func (s *RevocationService) RefreshTokenRevoked(ctx context.Context, refreshToken string) (bool, error) {
+	revocation, err := s.Store.Get(ctx, RevokedRefreshToken, RefreshTokenID(refreshToken))
+	return revocation != nil, err
+}
+
+// UserSignedOut reports whether the user was signed out everywhere since issuedWith was issued.
+// Refresh tokens kept in cookies are unknown to auth-service, so the ID token issued along with
+// one tells when it was issued; a missing, invalid or another user's ID token counts as older.
+func (s *RevocationService) UserSignedOut(ctx context.Context, userID, issuedWith string) (bool, error) {
+	revocation, err := s.Store.Get(ctx, RevokedUser, userID)
+	if err != nil || revocation == nil {
+		return false, err
+	}
+	tokenUserID, issuedAt, ok := s.Auth.TokenIssued(issuedWith)
+	if !ok || tokenUserID != userID {
+		return true, nil
+	}
+	return !issuedAt.After(revocation.RevokedAt), nil
+}
+
+// List returns the revocations the backend checks tokens against, which are those still in
+// effect apart from refresh tokens
+func (s *RevocationService) List(ctx context.Context) ([]*Revocation, error) {
+	revocations, err := s.Store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	listed := revocations[:0]
+	for _, revocation := range revocations {
+		if revocation.Kind != RevokedRefreshToken {
+			listed = append(listed, revocation)
+		}
+	}
+	return listed, nil
+}
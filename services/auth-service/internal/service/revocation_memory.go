@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryRevocationStore keeps the revocation list in process memory. Like MemorySessionStore, it
+// is lost on restart and not shared between instances.
+type MemoryRevocationStore struct {
+	mu          sync.Mutex
+	revocations map[string]Revocation // keyed by kind and value
+}
+
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{revocations: make(map[string]Revocation)}
+}
+
+func (m *MemoryRevocationStore) Add(_ context.Context, revocation *Revocation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.revocations[revocation.Kind+":"+revocation.Value] = *revocation
+	return nil
+}
+
+func (m *MemoryRevocationStore) Get(_ context.Context, kind, value string) (*Revocation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	revocation, ok := m.revocations[kind+":"+value]
+	if !ok || !time.Now().Before(revocation.ExpiresAt) {
+		return nil, nil
+	}
+	return &revocation, nil
+}
+
+func (m *MemoryRevocationStore) List(_ context.Context) ([]*Revocation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	revocations := make([]*Revocation, 0, len(m.revocations))
+	for key, revocation := range m.revocations {
+		if !now.Before(revocation.ExpiresAt) {
+			delete(m.revocations, key)
+			continue
+		}
+		revocation := revocation
+		revocations = append(revocations, &revocation)
+	}
+	return revocations, nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PostgresRevocationStore keeps the revocation list in the auth_revocations table, next to the
+// sessions of PostgresSessionStore
+type PostgresRevocationStore struct {
+	DB *sql.DB
+}
+
+// NewPostgresRevocationStore creates the auth_revocations table if it does not exist yet
+func NewPostgresRevocationStore(ctx context.Context, db *sql.DB) (*PostgresRevocationStore, error) {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS auth_revocations (
+		kind TEXT NOT NULL,
+		value TEXT NOT NULL,
+		revoked_at TIMESTAMPTZ NOT NULL,
+		expires_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (kind, value)
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create auth_revocations table: %w", err)
+	}
+	return &PostgresRevocationStore{DB: db}, nil
+}
+
+func (p *PostgresRevocationStore) Add(ctx context.Context, revocation *Revocation) error {
+	// Revocations are rare enough to purge expired ones along the way
+	if _, err := p.DB.ExecContext(ctx, `DELETE FROM auth_revocations WHERE expires_at < $1`, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to purge expired revocations: %w", err)
+	}
+	_, err := p.DB.ExecContext(ctx,
+		`INSERT INTO auth_revocations (kind, value, revoked_at, expires_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (kind, value) DO UPDATE SET revoked_at = EXCLUDED.revoked_at, expires_at = EXCLUDED.expires_at`,
+		revocation.Kind, revocation.Value, revocation.RevokedAt.UTC(), revocation.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to insert revocation: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresRevocationStore) Get(ctx context.Context, kind, value string) (*Revocation, error) {
+	revocation := Revocation{Kind: kind, Value: value}
+	err := p.DB.QueryRowContext(ctx,
+		`SELECT revoked_at, expires_at FROM auth_revocations WHERE kind = $1 AND value = $2 AND expires_at > $3`,
+		kind, value, time.Now().UTC()).Scan(&revocation.RevokedAt, &revocation.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load revocation: %w", err)
+	}
+	return &revocation, nil
+}
+
+func (p *PostgresRevocationStore) List(ctx context.Context) ([]*Revocation, error) {
+	rows, err := p.DB.QueryContext(ctx,
+		`SELECT kind, value, revoked_at, expires_at FROM auth_revocations WHERE expires_at > $1`, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revocations: %w", err)
+	}
+	defer rows.Close()
+
+	var revocations []*Revocation
+	for rows.Next() {
+		var revocation Revocation
+		if err := rows.Scan(&revocation.Kind, &revocation.Value, &revocation.RevokedAt, &revocation.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to read revocation: %w", err)
+		}
+		revocations = append(revocations, &revocation)
+	}
+	return revocations, rows.Err()
+}
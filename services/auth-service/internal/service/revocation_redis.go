@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RedisRevocationStore keeps the revocation list in the Redis server of a RedisSessionStore.
+// Each revocation is a JSON string that Redis expires by itself; a set indexes their keys.
+type RedisRevocationStore struct {
+	client *redisClient
+	prefix string
+}
+
+// Revocations returns a revocation store sharing the session store's connection
+func (r *RedisSessionStore) Revocations() *RedisRevocationStore {
+	return &RedisRevocationStore{client: r.client, prefix: r.prefix}
+}
+
+func (r *RedisRevocationStore) revocationKey(kind, value string) string {
+	return r.prefix + "revocation:" + kind + ":" + value
+}
+
+func (r *RedisRevocationStore) indexKey() string {
+	return r.prefix + "revocations"
+}
+
+func (r *RedisRevocationStore) Add(ctx context.Context, revocation *Revocation) error {
+	ttl := int(time.Until(revocation.ExpiresAt).Seconds())
+	if ttl <= 0 {
+		return nil // Nothing left to revoke
+	}
+	data, err := json.Marshal(revocation)
+	if err != nil {
+		return fmt.Errorf("failed to encode revocation: %w", err)
+	}
+
+	key := r.revocationKey(revocation.Kind, revocation.Value)
+	if _, err := r.client.do(ctx, "SET", key, string(data), "EX", strconv.Itoa(ttl)); err != nil {
+		return fmt.Errorf("failed to store revocation: %w", err)
+	}
+	if _, err := r.client.do(ctx, "SADD", r.indexKey(), key); err != nil {
+		return fmt.Errorf("failed to index revocation: %w", err)
+	}
+	return nil
+}
+
+func (r *RedisRevocationStore) Get(ctx context.Context, kind, value string) (*Revocation, error) {
+	reply, err := r.client.do(ctx, "GET", r.revocationKey(kind, value))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load revocation: %w", err)
+	}
+	data, ok := reply.(string)
+	if !ok {
+		return nil, nil
+	}
+	var revocation Revocation
+	if err := json.Unmarshal([]byte(data), &revocation); err != nil {
+		return nil, fmt.Errorf("failed to decode revocation: %w", err)
+	}
+	return &revocation, nil
+}
+
+func (r *RedisRevocationStore) List(ctx context.Context) ([]*Revocation, error) {
+	reply, err := r.client.do(ctx, "SMEMBERS", r.indexKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revocations: %w", err)
+	}
+	keys, _ := reply.([]interface{})
+
+	var revocations []*Revocation
+	for _, rawKey := range keys {
+		key, _ := rawKey.(string)
+		reply, err := r.client.do(ctx, "GET", key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load revocation: %w", err)
+		}
+		data, ok := reply.(string)
+		if !ok {
+			// Expired on its own; drop it from the index
+			if _, err := r.client.do(ctx, "SREM", r.indexKey(), key); err != nil {
+				return nil, fmt.Errorf("failed to unindex revocation: %w", err)
+			}
+			continue
+		}
+		var revocation Revocation
+		if err := json.Unmarshal([]byte(data), &revocation); err != nil {
+			return nil, fmt.Errorf("failed to decode revocation: %w", err)
+		}
+		revocations = append(revocations, &revocation)
+	}
+	return revocations, nil
+}
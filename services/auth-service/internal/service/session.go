@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrSessionNotFound is returned for sessions that do not exist, expired or were revoked
+var ErrSessionNotFound = errors.New("session not found")
+
+// tokenRefreshMargin is how long before the ID token expires a session's tokens are refreshed
+const tokenRefreshMargin = 5 * time.Minute
+
+// lastSeenInterval limits how often resolving a session writes its LastSeenAt
+const lastSeenInterval = time.Minute
+
+// Session holds a signed-in user's tokens on the server. The browser only gets an opaque
+// secret; ID is the SHA-256 of that secret, so listing sessions never reveals a usable cookie.
+type Session struct {
+	ID               string    `json:"id"`
+	UserID           string    `json:"userId"` // Entra object ID (oid)
+	Email            string    `json:"email"`
+	Name             string    `json:"name"`
+	IDToken          string    `json:"idToken"`
+	AccessToken      string    `json:"accessToken"`
+	RefreshToken     string    `json:"refreshToken"`
+	GraphAccessToken string    `json:"graphAccessToken,omitempty"`
+	TokenExpiresAt   time.Time `json:"tokenExpiresAt"`
+	UserAgent        string    `json:"userAgent"`
+	IPAddress        string    `json:"ipAddress"`
+	CreatedAt        time.Time `json:"createdAt"`
+	LastSeenAt       time.Time `json:"lastSeenAt"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+}
+
+// SessionStore keeps sessions. Get and ListByUser never return expired sessions.
+type SessionStore interface {
+	Create(ctx context.Context, session *Session) error
+	Get(ctx context.Context, id string) (*Session, error)
+	Update(ctx context.Context, session *Session) error
+	Delete(ctx context.Context, id string) error
+	ListByUser(ctx context.Context, userID string) ([]*Session, error)
+}
+
+// SessionID derives the stored session ID from the secret in the session cookie
+func SessionID(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// SessionService creates sessions at login and hands their tokens to the services behind
+// auth-service, refreshing them with the stored refresh token when they are about to expire
+type SessionService struct {
+	Store    SessionStore
+	Auth     *AuthService
+	Lifetime time.Duration
+
+	// OnSessionEnded, when set, is called for every session that is signed out or revoked
+	OnSessionEnded func(ctx context.Context, session *Session)
+
+	refreshMu sync.Mutex
+}
+
+func NewSessionService(store SessionStore, authService *AuthService, lifetime time.Duration) *SessionService {
+	return &SessionService{
+		Store:    store,
+		Auth:     authService,
+		Lifetime: lifetime,
+	}
+}
+
+// Create stores a new session for a completed login and returns the secret for the cookie
+func (s *SessionService) Create(ctx context.Context, tokens *TokenRefreshResult, graphAccessToken string, identity *TokenValidationResult, userAgent, ipAddress string) (string, *Session, error) {
+	secret, err := randomToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	session := &Session{
+		ID:               SessionID(secret),
+		UserID:           identity.EntraID,
+		Email:            identity.Email,
+		Name:             identity.Name,
+		IDToken:          tokens.IDToken,
+		AccessToken:      tokens.AccessToken,
+		RefreshToken:     tokens.RefreshToken,
+		GraphAccessToken: graphAccessToken,
+		TokenExpiresAt:   tokenExpiry(identity.Claims, tokens.ExpiresIn, now),
+		UserAgent:        userAgent,
+		IPAddress:        ipAddress,
+		CreatedAt:        now,
+		LastSeenAt:       now,
+		ExpiresAt:        now.Add(s.Lifetime),
+	}
+	if err := s.Store.Create(ctx, session); err != nil {
+		return "", nil, fmt.Errorf("failed to store session: %w", err)
+	}
+	return secret, session, nil
+}
+
+// tokenExpiry reads the ID token's exp claim, falling back to the token response's expires_in
+func tokenExpiry(claims map[string]interface{}, expiresIn int, now time.Time) time.Time {
+	if exp, ok := claims["exp"].(float64); ok {
+		return time.Unix(int64(exp), 0)
+	}
+	return now.Add(time.Duration(expiresIn) * time.Second)
+}
+
+// Get returns the session behind a cookie secret
+func (s *SessionService) Get(ctx context.Context, secret string) (*Session, error) {
+	if secret == "" {
+		return nil, ErrSessionNotFound
+	}
+	return s.Store.Get(ctx, SessionID(secret))
+}
+
+// Resolve returns the session behind a cookie secret with tokens that are valid for at least a
+// few more minutes, refreshing them first if needed
+func (s *SessionService) Resolve(ctx context.Context, secret string) (*Session, error) {
+	session, err := s.Get(ctx, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Until(session.TokenExpiresAt) < tokenRefreshMargin {
+		if session, err = s.refresh(ctx, session.ID, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if time.Since(session.LastSeenAt) > lastSeenInterval {
+		session.LastSeenAt = time.Now()
+		if err := s.Store.Update(ctx, session); err != nil {
+			log.Printf("Warning: Failed to update last seen time of session: %v", err)
+		}
+	}
+	return session, nil
+}
+
+// Refresh renews the tokens of the session behind a cookie secret now
+func (s *SessionService) Refresh(ctx context.Context, secret string) (*Session, error) {
+	return s.refresh(ctx, SessionID(secret), true)
+}
+
+// refresh exchanges the session's refresh token for new tokens. Refreshes are serialized and
+// the session re-read, so concurrent requests do not spend the same refresh token twice.
+func (s *SessionService) refresh(ctx context.Context, id string, force bool) (*Session, error) {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	session, err := s.Store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !force && time.Until(session.TokenExpiresAt) >= tokenRefreshMargin {
+		return session, nil // Refreshed by a concurrent request
+	}
+
+	result := s.Auth.RefreshToken(session.RefreshToken)
+	if !result.Success {
+		if time.Now().Before(session.TokenExpiresAt) {
+			log.Printf("Warning: Session token refresh failed, using current tokens: %s", result.Error)
+			return session, nil
+		}
+		return nil, fmt.Errorf("%w: tokens expired and could not be refreshed: %s", ErrSessionNotFound, result.Error)
+	}
+
+	now := time.Now()
+	session.IDToken = result.IDToken
+	session.AccessToken = result.AccessToken
+	session.RefreshToken = result.RefreshToken
+	session.TokenExpiresAt = now.Add(time.Duration(result.ExpiresIn) * time.Second)
+	if validation := s.Auth.ValidateToken(result.IDToken, ""); validation.Valid {
+		session.TokenExpiresAt = tokenExpiry(validation.Claims, result.ExpiresIn, now)
+	}
+	if s.Auth.SupportsGraph() {
+		if graphAccessToken, err := s.Auth.GetGraphToken(result.RefreshToken); err == nil {
+			session.GraphAccessToken = graphAccessToken
+		}
+	}
+	session.LastSeenAt = now
+
+	if err := s.Store.Update(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to store refreshed session: %w", err)
+	}
+	return session, nil
+}
+
+// List returns the user's sessions, most recently used first
+func (s *SessionService) List(ctx context.Context, userID string) ([]*Session, error) {
+	sessions, err := s.Store.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt) })
+	return sessions, nil
+}
+
+// Revoke ends one of the user's sessions. Sessions of other users are reported as not found.
+func (s *SessionService) Revoke(ctx context.Context, userID, id string) error {
+	session, err := s.Store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return ErrSessionNotFound
+	}
+	return s.end(ctx, session)
+}
+
+// Delete ends the session behind a cookie secret
+func (s *SessionService) Delete(ctx context.Context, secret string) error {
+	session, err := s.Get(ctx, secret)
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.end(ctx, session)
+}
+
+// DeleteAll ends every session of the user and returns how many there were
+func (s *SessionService) DeleteAll(ctx context.Context, userID string) (int, error) {
+	sessions, err := s.Store.ListByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	for i, session := range sessions {
+		if err := s.end(ctx, session); err != nil {
+			return i, err
+		}
+	}
+	return len(sessions), nil
+}
+
+func (s *SessionService) end(ctx context.Context, session *Session) error {
+	if err := s.Store.Delete(ctx, session.ID); err != nil {
+		return err
+	}
+	if s.OnSessionEnded != nil {
+		s.OnSessionEnded(ctx, session)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemorySessionStore keeps sessions in process memory. Sessions are lost on restart and not
+// shared between instances, so it suits development and single-instance deployments.
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]Session
+}
+
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]Session)}
+}
+
+func (m *MemorySessionStore) Create(_ context.Context, session *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.purgeExpiredLocked()
+	m.sessions[session.ID] = *session
+	return nil
+}
+
+func (m *MemorySessionStore) Get(_ context.Context, id string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, ok := m.sessions[id]
+	if !ok || !time.Now().Before(session.ExpiresAt) {
+		delete(m.sessions, id)
+		return nil, ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (m *MemorySessionStore) Update(_ context.Context, session *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.sessions[session.ID]; !ok {
+		return ErrSessionNotFound
+	}
+	m.sessions[session.ID] = *session
+	return nil
+}
+
+func (m *MemorySessionStore) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *MemorySessionStore) ListByUser(_ context.Context, userID string) ([]*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.purgeExpiredLocked()
+
+	var sessions []*Session
+	for _, session := range m.sessions {
+		if session.UserID == userID {
+			session := session
+			sessions = append(sessions, &session)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *MemorySessionStore) purgeExpiredLocked() {
+	now := time.Now()
+	for id, session := range m.sessions {
+		if !now.Before(session.ExpiresAt) {
+			delete(m.sessions, id)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PostgresSessionStore keeps sessions in the auth_sessions table, shared by every instance.
+// The session is stored as JSON next to the columns it is looked up by.
+type PostgresSessionStore struct {
+	DB *sql.DB
+}
+
+// NewPostgresSessionStore creates the auth_sessions table if it does not exist yet
+func NewPostgresSessionStore(ctx context.Context, db *sql.DB) (*PostgresSessionStore, error) {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS auth_sessions (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			expires_at TIMESTAMPTZ NOT NULL,
+			data TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_auth_sessions_user_id ON auth_sessions (user_id)`,
+	}
+	for _, statement := range statements {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return nil, fmt.Errorf("failed to create auth_sessions table: %w", err)
+		}
+	}
+	return &PostgresSessionStore{DB: db}, nil
+}
+
+func (p *PostgresSessionStore) Create(ctx context.Context, session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	// Creating sessions is rare enough to purge expired ones along the way
+	if _, err := p.DB.ExecContext(ctx, `DELETE FROM auth_sessions WHERE expires_at < $1`, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to purge expired sessions: %w", err)
+	}
+	_, err = p.DB.ExecContext(ctx,
+		`INSERT INTO auth_sessions (id, user_id, expires_at, data) VALUES ($1, $2, $3, $4)`,
+		session.ID, session.UserID, session.ExpiresAt.UTC(), string(data))
+	if err != nil {
+		return fmt.Errorf("failed to insert session: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresSessionStore) Get(ctx context.Context, id string) (*Session, error) {
+	var data string
+	err := p.DB.QueryRowContext(ctx, `SELECT data FROM auth_sessions WHERE id = $1`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+
+	session, err := decodeSession(data)
+	if err != nil {
+		return nil, err
+	}
+	if !time.Now().Before(session.ExpiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func (p *PostgresSessionStore) Update(ctx context.Context, session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	result, err := p.DB.ExecContext(ctx,
+		`UPDATE auth_sessions SET expires_at = $2, data = $3 WHERE id = $1`,
+		session.ID, session.ExpiresAt.UTC(), string(data))
+	if err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+func (p *PostgresSessionStore) Delete(ctx context.Context, id string) error {
+	if _, err := p.DB.ExecContext(ctx, `DELETE FROM auth_sessions WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresSessionStore) ListByUser(ctx context.Context, userID string) ([]*Session, error) {
+	rows, err := p.DB.QueryContext(ctx,
+		`SELECT data FROM auth_sessions WHERE user_id = $1 AND expires_at > $2`, userID, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*Session
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read session: %w", err)
+		}
+		session, err := decodeSession(data)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+func decodeSession(data string) (*Session, error) {
+	var session Session
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	return &session, nil
+}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RedisSessionStore keeps sessions in Redis or a Redis-compatible server (Azure Cache for
+// Redis, Valkey, KeyDB). Each session is a JSON string that Redis expires by itself; a set per
+// user indexes the user's session IDs.
+type RedisSessionStore struct {
+	client *redisClient
+	prefix string
+}
+
+// NewRedisSessionStore connects to a redis:// or rediss:// (TLS) URL such as
+// rediss://:password@host:6380/0
+func NewRedisSessionStore(ctx context.Context, rawURL string) (*RedisSessionStore, error) {
+	client, err := newRedisClient(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := client.do(ctx, "PING"); err != nil {
+		return nil, fmt.Errorf("failed to reach redis: %w", err)
+	}
+	return &RedisSessionStore{client: client, prefix: "rosetta:"}, nil
+}
+
+func (r *RedisSessionStore) sessionKey(id string) string {
+	return r.prefix + "session:" + id
+}
+
+func (r *RedisSessionStore) userKey(userID string) string {
+	return r.prefix + "user-sessions:" + userID
+}
+
+func (r *RedisSessionStore) Create(ctx context.Context, session *Session) error {
+	if err := r.write(ctx, session, false); err != nil {
+		return err
+	}
+	userKey := r.userKey(session.UserID)
+	if _, err := r.client.do(ctx, "SADD", userKey, session.ID); err != nil {
+		return fmt.Errorf("failed to index session: %w", err)
+	}
+	// The index lives as long as the user's newest session
+	ttl := strconv.Itoa(int(time.Until(session.ExpiresAt).Seconds()) + 1)
+	if _, err := r.client.do(ctx, "EXPIRE", userKey, ttl); err != nil {
+		return fmt.Errorf("failed to index session: %w", err)
+	}
+	return nil
+}
+
+// write stores the session until it expires. With onlyExisting, a session that is gone
+// (revoked or expired) is not brought back.
+func (r *RedisSessionStore) write(ctx context.Context, session *Session, onlyExisting bool) error {
+	ttl := int(time.Until(session.ExpiresAt).Seconds())
+	if ttl <= 0 {
+		return ErrSessionNotFound
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	args := []string{"SET", r.sessionKey(session.ID), string(data), "EX", strconv.Itoa(ttl)}
+	if onlyExisting {
+		args = append(args, "XX")
+	}
+	reply, err := r.client.do(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("failed to store session: %w", err)
+	}
+	if reply == nil {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+func (r *RedisSessionStore) Get(ctx context.Context, id string) (*Session, error) {
+	reply, err := r.client.do(ctx, "GET", r.sessionKey(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+	data, ok := reply.(string)
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return decodeSession(data)
+}
+
+func (r *RedisSessionStore) Update(ctx context.Context, session *Session) error {
+	return r.write(ctx, session, true)
+}
+
+func (r *RedisSessionStore) Delete(ctx context.Context, id string) error {
+	session, err := r.Get(ctx, id)
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := r.client.do(ctx, "DEL", r.sessionKey(id)); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	if _, err := r.client.do(ctx, "SREM", r.userKey(session.UserID), id); err != nil {
+		return fmt.Errorf("failed to unindex session: %w", err)
+	}
+	return nil
+}
+
+func (r *RedisSessionStore) ListByUser(ctx context.Context, userID string) ([]*Session, error) {
+	reply, err := r.client.do(ctx, "SMEMBERS", r.userKey(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	ids, _ := reply.([]interface{})
+
+	var sessions []*Session
+	for _, rawID := range ids {
+		id, _ := rawID.(string)
+		session, err := r.Get(ctx, id)
+		if errors.Is(err, ErrSessionNotFound) {
+			// Expired on its own; drop it from the index
+			if _, err := r.client.do(ctx, "SREM", r.userKey(userID), id); err != nil {
+				return nil, fmt.Errorf("failed to unindex session: %w", err)
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// redisClient speaks just enough of the Redis protocol (RESP2) for the session store, over a
+// single connection that is re-established after errors
+type redisClient struct {
+	addr     string
+	useTLS   bool
+	password string
+	username string
+	db       string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func newRedisClient(rawURL string) (*redisClient, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+	if parsed.Scheme != "redis" && parsed.Scheme != "rediss" {
+		return nil, fmt.Errorf("invalid redis URL scheme %q", parsed.Scheme)
+	}
+
+	client := &redisClient{addr: parsed.Host, useTLS: parsed.Scheme == "rediss"}
+	if parsed.Port() == "" {
+		client.addr = net.JoinHostPort(parsed.Hostname(), "6379")
+	}
+	if parsed.User != nil {
+		client.username = parsed.User.Username()
+		client.password, _ = parsed.User.Password()
+	}
+	client.db = strings.TrimPrefix(parsed.Path, "/")
+	return client, nil
+}
+
+// do sends one command and returns its reply: string, int64, []interface{} or nil
+func (c *redisClient) do(ctx context.Context, args ...string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	reply, err := c.doLocked(ctx, args)
+	var redisErr redisError
+	if err != nil && !errors.As(err, &redisErr) {
+		// The connection may be broken; retry once on a new one
+		c.closeLocked()
+		reply, err = c.doLocked(ctx, args)
+	}
+	return reply, err
+}
+
+func (c *redisClient) doLocked(ctx context.Context, args []string) (interface{}, error) {
+	if c.conn == nil {
+		if err := c.connectLocked(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		c.conn.SetDeadline(deadline)
+	} else {
+		c.conn.SetDeadline(time.Now().Add(5 * time.Second))
+	}
+
+	if _, err := c.conn.Write(encodeRedisCommand(args)); err != nil {
+		c.closeLocked()
+		return nil, err
+	}
+	reply, err := readRedisReply(c.reader)
+	var redisErr redisError
+	if err != nil && !errors.As(err, &redisErr) {
+		c.closeLocked()
+	}
+	return reply, err
+}
+
+func (c *redisClient) connectLocked(ctx context.Context) error {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	var conn net.Conn
+	var err error
+	if c.useTLS {
+		host, _, _ := net.SplitHostPort(c.addr)
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}}).DialContext(ctx, "tcp", c.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", c.addr)
+	}
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.reader = bufio.NewReader(conn)
+
+	var setup [][]string
+	if c.password != "" {
+		if c.username != "" {
+			setup = append(setup, []string{"AUTH", c.username, c.password})
+		} else {
+			setup = append(setup, []string{"AUTH", c.password})
+		}
+	}
+	if c.db != "" && c.db != "0" {
+		setup = append(setup, []string{"SELECT", c.db})
+	}
+	for _, args := range setup {
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if _, err := conn.Write(encodeRedisCommand(args)); err != nil {
+			c.closeLocked()
+			return err
+		}
+		if _, err := readRedisReply(c.reader); err != nil {
+			c.closeLocked()
+			return fmt.Errorf("redis %s failed: %w", args[0], err)
+		}
+	}
+	return nil
+}
+
+func (c *redisClient) closeLocked() {
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+		c.reader = nil
+	}
+}
+
+// redisError is an error reply from the server, as opposed to a connection problem
+type redisError string
+
+func (e redisError) Error() string { return string(e) }
+
+func encodeRedisCommand(args []string) []byte {
+	var b strings.Builder
+	b.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		b.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+	}
+	return []byte(b.String())
+}
+
+func readRedisReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = readRedisReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected redis reply %q", line)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/authcookie"
 	"github.com/gin-gonic/gin"
 )
 
@@ -28,29 +29,100 @@ func GetRedirectURL() string {
 	return "https://" + domain
 }
 
-// SetCookiesFromTokens sets authentication cookies with the provided tokens
-func SetCookiesFromTokens(c *gin.Context, accessToken, refreshToken, idToken, graphAccessToken string) {
-	cookieDomain := GetCookieDomain()
-	isSecure := !IsDevelopment()
+// tokenCookieMaxAge is how long each token cookie lives (seconds)
+var tokenCookieMaxAge = map[string]int{
+	"id_token":           3600,
+	"access_token":       3600,
+	"refresh_token":      3600 * 24,
+	"graph_access_token": 3600,
+}
+
+// tokenCookie returns the attributes of the token cookie called name
+func tokenCookie(name string) http.Cookie {
+	cookie := http.Cookie{
+		Name:     name,
+		MaxAge:   tokenCookieMaxAge[name],
+		Path:     "/",
+		Domain:   GetCookieDomain(),
+		Secure:   !IsDevelopment(),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if !IsDevelopment() {
+		cookie.SameSite = http.SameSiteNoneMode
+	}
+	return cookie
+}
+
+// SetCookiesFromTokens sets authentication cookies with the provided tokens, encrypted and
+// chunked by cookies
+func SetCookiesFromTokens(c *gin.Context, cookies *authcookie.Codec, accessToken, refreshToken, idToken, graphAccessToken string) {
+	tokens := [][2]string{
+		{"id_token", idToken},
+		{"access_token", accessToken},
+		{"refresh_token", refreshToken},
+	}
+	// Set Graph API access token if provided
+	if graphAccessToken != "" {
+		tokens = append(tokens, [2]string{"graph_access_token", graphAccessToken})
+	}
+
+	for _, token := range tokens {
+		name := token[0]
+		cookie := tokenCookie(name)
+		cookie.Value = token[1]
+		if err := cookies.Write(c.Writer, c.Request, cookie); err != nil {
+			log.Printf("Error: Failed to set %s cookie: %v", name, err)
+		}
+	}
+
+	log.Printf("Cookies set for domain: %s (Secure: %v, Encrypted: %v)", GetCookieDomain(), !IsDevelopment(), cookies.Encrypts())
+}
 
+// ReadTokenCookie returns the decrypted value of the token cookie called name
+func ReadTokenCookie(c *gin.Context, cookies *authcookie.Codec, name string) (string, error) {
+	return cookies.Read(c.Request, name)
+}
+
+// ClearTokenCookies removes the token cookies and their chunks
+func ClearTokenCookies(c *gin.Context, cookies *authcookie.Codec) {
+	for name := range tokenCookieMaxAge {
+		cookies.Clear(c.Writer, c.Request, tokenCookie(name))
+	}
+}
+
+// SessionCookie holds the opaque secret of a server-side session (SESSION_STORE)
+const SessionCookie = "rosetta_session"
+
+// SetSessionCookie stores a session secret with the same domain and SameSite policy as the token
+// cookies, since the backend and backend-editor read it too
+func SetSessionCookie(c *gin.Context, secret string, maxAge int) {
 	if IsDevelopment() {
 		c.SetSameSite(http.SameSiteLaxMode)
 	} else {
 		c.SetSameSite(http.SameSiteNoneMode)
-		isSecure = true
 	}
+	c.SetCookie(SessionCookie, secret, maxAge, "/", GetCookieDomain(), !IsDevelopment(), true)
+}
 
-	c.SetCookie("id_token", idToken, 3600, "/", cookieDomain, isSecure, true)
-	c.SetCookie("access_token", accessToken, 3600, "/", cookieDomain, isSecure, true)
-	c.SetCookie("refresh_token", refreshToken, 3600*24, "/", cookieDomain, isSecure, true)
+// ClearSessionCookie removes the session cookie
+func ClearSessionCookie(c *gin.Context) {
+	SetSessionCookie(c, "", -1)
+}
 
-	// Set Graph API access token if provided
-	if graphAccessToken != "" {
-		c.SetCookie("graph_access_token", graphAccessToken, 3600, "/", cookieDomain, isSecure, true)
-		log.Printf("Graph access token cookie set")
-	}
+// LoginFlowCookie holds the signed state, nonce and PKCE verifier between login and callback
+const LoginFlowCookie = "oauth_flow"
+
+// SetLoginFlowCookie stores the signed login flow for the callback. It is scoped to /auth and
+// uses SameSite=Lax, which is still sent on the identity provider's top-level redirect back.
+func SetLoginFlowCookie(c *gin.Context, value string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(LoginFlowCookie, value, maxAge, "/auth", GetCookieDomain(), !IsDevelopment(), true)
+}
 
-	log.Printf("Cookies set for domain: %s (Secure: %v)", cookieDomain, isSecure)
+// ClearLoginFlowCookie removes the login flow once the callback used it
+func ClearLoginFlowCookie(c *gin.Context) {
+	SetLoginFlowCookie(c, "", -1)
 }
 
 // GetCookieDomain determines the appropriate cookie domain based on environment
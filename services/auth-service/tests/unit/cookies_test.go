@@ -1,9 +1,11 @@
 package unit_test
 
 import (
+	"bytes"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -11,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/auth-service/internal/util"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/authcookie"
 )
 
 func TestMain(m *testing.M) {
@@ -147,7 +150,7 @@ func TestSetCookiesFromTokens_Development_HttpOnly(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
 
-	util.SetCookiesFromTokens(c, "access", "refresh", "id-token", "")
+	util.SetCookiesFromTokens(c, authcookie.NewCodec(nil), "access", "refresh", "id-token", "")
 
 	// Check cookies in response
 	cookies := w.Result().Cookies()
@@ -166,7 +169,7 @@ func TestSetCookiesFromTokens_Production_Secure(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
 
-	util.SetCookiesFromTokens(c, "access", "refresh", "id-token", "")
+	util.SetCookiesFromTokens(c, authcookie.NewCodec(nil), "access", "refresh", "id-token", "")
 
 	cookies := w.Result().Cookies()
 	require.GreaterOrEqual(t, len(cookies), 3, "Should set at least 3 cookies")
@@ -184,7 +187,7 @@ func TestSetCookiesFromTokens_Development_NotSecure(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
 
-	util.SetCookiesFromTokens(c, "access", "refresh", "id-token", "")
+	util.SetCookiesFromTokens(c, authcookie.NewCodec(nil), "access", "refresh", "id-token", "")
 
 	cookies := w.Result().Cookies()
 	require.GreaterOrEqual(t, len(cookies), 3, "Should set at least 3 cookies")
@@ -202,7 +205,7 @@ func TestSetCookiesFromTokens_SetsAllCookies(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
 
-	util.SetCookiesFromTokens(c, "my-access", "my-refresh", "my-id-token", "")
+	util.SetCookiesFromTokens(c, authcookie.NewCodec(nil), "my-access", "my-refresh", "my-id-token", "")
 
 	cookies := w.Result().Cookies()
 	cookieMap := make(map[string]*http.Cookie)
@@ -229,7 +232,7 @@ func TestSetCookiesFromTokens_GraphToken(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
 
-	util.SetCookiesFromTokens(c, "access", "refresh", "id-token", "graph-token")
+	util.SetCookiesFromTokens(c, authcookie.NewCodec(nil), "access", "refresh", "id-token", "graph-token")
 
 	cookies := w.Result().Cookies()
 	cookieMap := make(map[string]*http.Cookie)
@@ -249,7 +252,7 @@ func TestSetCookiesFromTokens_NoGraphTokenWhenEmpty(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
 
-	util.SetCookiesFromTokens(c, "access", "refresh", "id-token", "")
+	util.SetCookiesFromTokens(c, authcookie.NewCodec(nil), "access", "refresh", "id-token", "")
 
 	cookies := w.Result().Cookies()
 	for _, cookie := range cookies {
@@ -265,7 +268,7 @@ func TestSetCookiesFromTokens_PathIsRoot(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
 
-	util.SetCookiesFromTokens(c, "access", "refresh", "id-token", "")
+	util.SetCookiesFromTokens(c, authcookie.NewCodec(nil), "access", "refresh", "id-token", "")
 
 	cookies := w.Result().Cookies()
 	for _, cookie := range cookies {
@@ -281,7 +284,7 @@ func TestSetCookiesFromTokens_RefreshTokenLongerExpiry(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
 
-	util.SetCookiesFromTokens(c, "access", "refresh", "id-token", "")
+	util.SetCookiesFromTokens(c, authcookie.NewCodec(nil), "access", "refresh", "id-token", "")
 
 	cookies := w.Result().Cookies()
 	cookieMap := make(map[string]*http.Cookie)
@@ -310,7 +313,7 @@ func TestSetCookiesFromTokens_Production_SameSiteNone(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
 
-	util.SetCookiesFromTokens(c, "access", "refresh", "id-token", "")
+	util.SetCookiesFromTokens(c, authcookie.NewCodec(nil), "access", "refresh", "id-token", "")
 
 	cookies := w.Result().Cookies()
 	require.GreaterOrEqual(t, len(cookies), 3, "Should set at least 3 cookies")
@@ -331,7 +334,7 @@ func TestSetCookiesFromTokens_Development_SameSite(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
 
-	util.SetCookiesFromTokens(c, "access", "refresh", "id-token", "")
+	util.SetCookiesFromTokens(c, authcookie.NewCodec(nil), "access", "refresh", "id-token", "")
 
 	cookies := w.Result().Cookies()
 	require.GreaterOrEqual(t, len(cookies), 3, "Should set at least 3 cookies")
@@ -344,6 +347,59 @@ func TestSetCookiesFromTokens_Development_SameSite(t *testing.T) {
 	}
 }
 
+// ============================================================================
+// Encrypted Token Cookie Tests
+// ============================================================================
+
+func newEncryptingCodec(t *testing.T) *authcookie.Codec {
+	keys, err := authcookie.NewKeyRing(authcookie.Key{ID: "k1", Secret: bytes.Repeat([]byte{7}, 32)})
+	require.NoError(t, err)
+	return authcookie.NewCodec(keys)
+}
+
+func TestSetCookiesFromTokens_Encrypted(t *testing.T) {
+	os.Setenv("ROSETTA_DOMAIN", "localhost")
+	defer os.Unsetenv("ROSETTA_DOMAIN")
+	codec := newEncryptingCodec(t)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	largeIDToken := strings.Repeat("i", 6000) // Tokens with many group claims
+	util.SetCookiesFromTokens(c, codec, "secret-access", "secret-refresh", largeIDToken, "")
+
+	next := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		assert.NotContains(t, cookie.Value, "secret", "Cookie %s must not carry the token in clear", cookie.Name)
+		assert.LessOrEqual(t, len(cookie.String()), 4096, "Cookie %s must fit in a browser cookie", cookie.Name)
+		next.AddCookie(cookie)
+	}
+
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = next
+	refreshToken, err := util.ReadTokenCookie(c, codec, "refresh_token")
+	require.NoError(t, err)
+	assert.Equal(t, "secret-refresh", refreshToken)
+	idToken, err := util.ReadTokenCookie(c, codec, "id_token")
+	require.NoError(t, err)
+	assert.Equal(t, largeIDToken, idToken)
+
+	// Logout clears the chunks as well
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = next
+	util.ClearTokenCookies(c, codec)
+	cleared := map[string]bool{}
+	for _, cookie := range w.Result().Cookies() {
+		assert.Negative(t, cookie.MaxAge)
+		cleared[cookie.Name] = true
+	}
+	for _, name := range []string{"id_token", "id_token_1", "id_token_2", "access_token", "refresh_token", "graph_access_token"} {
+		assert.True(t, cleared[name], "%s should be cleared", name)
+	}
+}
+
 // ============================================================================
 // GetRosettaDomain Tests
 // ============================================================================
@@ -399,7 +455,7 @@ func TestCookies_NeverExposeSecretInDevelopment(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
 
-	util.SetCookiesFromTokens(c, "secret-access", "secret-refresh", "secret-id", "")
+	util.SetCookiesFromTokens(c, authcookie.NewCodec(nil), "secret-access", "secret-refresh", "secret-id", "")
 
 	cookies := w.Result().Cookies()
 	for _, cookie := range cookies {
@@ -0,0 +1,227 @@
+package unit_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/auth-service/internal/controller"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/auth-service/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/authcookie"
+)
+
+const devRedirectURI = "http://localhost:3002/auth/callback"
+
+func newDevProvider(t *testing.T) *service.DevIdentityProvider {
+	users, err := service.ParseDevUsers("ada@example.com:Ada Admin:group-cloud|group-security:Rosetta.Admin, bob@example.com")
+	require.NoError(t, err)
+	provider, err := service.NewDevIdentityProvider("http://localhost:3002/auth/dev", "rosetta-dev", devRedirectURI, users, nil)
+	require.NoError(t, err)
+	return provider
+}
+
+func newDevRouter(provider *service.DevIdentityProvider) *gin.Engine {
+	return newDevRouterWithRevocations(provider, service.NewMemoryRevocationStore())
+}
+
+// newDevRouterWithRevocations serves cookie mode, keeping the revocation list in store
+func newDevRouterWithRevocations(provider *service.DevIdentityProvider, store service.RevocationStore) *gin.Engine {
+	flowCodec, _ := service.NewLoginFlowCodec([]byte("test-secret"))
+	authService := service.NewDevAuthService(provider)
+	revocations := service.NewRevocationService(store, authService, nil)
+	authController := controller.NewAuthController(authService, flowCodec, nil, revocations, authcookie.NewCodec(nil))
+	revocationController := controller.NewRevocationController(revocations, testInternalSecret)
+	devController := controller.NewDevIdentityController(provider)
+
+	r := gin.New()
+	r.GET("/auth/login", authController.Login)
+	r.GET("/auth/callback", authController.Callback)
+	r.GET("/auth/logout", authController.Logout)
+	r.POST("/auth/refresh", authController.RefreshToken)
+	r.GET("/auth/internal/revocations", revocationController.List)
+	r.POST("/auth/internal/users/:userId/sign-out", revocationController.SignOutUser)
+	r.GET("/auth/dev/.well-known/openid-configuration", devController.Discovery)
+	r.GET("/auth/dev/keys", devController.Keys)
+	r.GET("/auth/dev/authorize", devController.LoginForm)
+	r.POST("/auth/dev/authorize", devController.Authorize)
+	r.POST("/auth/dev/token", devController.Token)
+	return r
+}
+
+func postForm(r *gin.Engine, path string, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func get(r *gin.Engine, path string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func findCookie(w *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// devSignIn walks /auth/login and the dev login form as ada, returning the callback query and
+// the login flow cookie
+func devSignIn(t *testing.T, r *gin.Engine, provider *service.DevIdentityProvider, loginPath string) (string, *http.Cookie) {
+	w := get(r, loginPath)
+	require.Equal(t, http.StatusFound, w.Code)
+	flowCookie := findCookie(w, "oauth_flow")
+	require.NotNil(t, flowCookie)
+	authorize, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	query := authorize.Query()
+
+	w = postForm(r, "/auth/dev/authorize", url.Values{
+		"user":           {provider.Users[0].ID},
+		"client_id":      {query.Get("client_id")},
+		"redirect_uri":   {query.Get("redirect_uri")},
+		"state":          {query.Get("state")},
+		"nonce":          {query.Get("nonce")},
+		"code_challenge": {query.Get("code_challenge")},
+	})
+	require.Equal(t, http.StatusFound, w.Code)
+	callback, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	return callback.RawQuery, flowCookie
+}
+
+// ============================================================================
+// ParseDevUsers Tests
+// ============================================================================
+
+func TestParseDevUsers(t *testing.T) {
+	users, err := service.ParseDevUsers("Ada@Example.com:Ada Admin:group-cloud|group-security:Rosetta.Admin, bob@example.com")
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+
+	assert.Equal(t, "ada@example.com", users[0].Email)
+	assert.Equal(t, "Ada Admin", users[0].Name)
+	assert.Equal(t, []string{"group-cloud", "group-security"}, users[0].Groups)
+	assert.Equal(t, []string{"Rosetta.Admin"}, users[0].Roles)
+	assert.Equal(t, "bob@example.com", users[1].Name, "the name defaults to the email")
+	assert.Len(t, users[0].ID, 36)
+
+	again, _ := service.ParseDevUsers("ada@example.com")
+	assert.Equal(t, users[0].ID, again[0].ID, "IDs stay the same across restarts")
+
+	_, err = service.ParseDevUsers("not-an-email")
+	assert.Error(t, err)
+	_, err = service.ParseDevUsers(" , ")
+	assert.Error(t, err)
+}
+
+// ============================================================================
+// Dev Login Flow Tests
+// ============================================================================
+
+func TestDevIdentityProvider_LoginFlow(t *testing.T) {
+	provider := newDevProvider(t)
+	r := newDevRouter(provider)
+	ada := provider.Users[0]
+
+	w := get(r, "/auth/login")
+	require.Equal(t, http.StatusFound, w.Code)
+	location := w.Header().Get("Location")
+	assert.True(t, strings.HasPrefix(location, "/auth/dev/authorize?"))
+
+	w = get(r, location)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Ada Admin")
+
+	callbackQuery, flowCookie := devSignIn(t, r, provider, "/auth/login")
+	w = get(r, "/auth/callback?"+callbackQuery, flowCookie)
+	require.Equal(t, http.StatusFound, w.Code)
+	cookies := map[string]string{}
+	for _, cookie := range w.Result().Cookies() {
+		cookies[cookie.Name] = cookie.Value
+	}
+	require.NotEmpty(t, cookies["id_token"])
+	assert.NotEmpty(t, cookies["refresh_token"])
+	assert.Empty(t, cookies["oauth_flow"], "the login flow is cleared")
+
+	result := service.NewDevAuthService(provider).ValidateToken(cookies["id_token"], "")
+	require.True(t, result.Valid, result.Error)
+	assert.Equal(t, ada.ID, result.EntraID)
+	assert.Equal(t, "ada@example.com", result.Email)
+	assert.Equal(t, []interface{}{"group-cloud", "group-security"}, result.Claims["groups"])
+
+	w = get(r, "/auth/callback?"+callbackQuery, flowCookie)
+	assert.Equal(t, http.StatusBadGateway, w.Code, "codes are single use")
+}
+
+func TestDevIdentityProvider_RejectsUnknownUsersAndRedirects(t *testing.T) {
+	provider := newDevProvider(t)
+	r := newDevRouter(provider)
+
+	w := postForm(r, "/auth/dev/authorize", url.Values{"user": {"nobody"}, "client_id": {"rosetta-dev"}, "redirect_uri": {devRedirectURI}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = postForm(r, "/auth/dev/authorize", url.Values{"user": {provider.Users[0].ID}, "client_id": {"rosetta-dev"}, "redirect_uri": {"https://evil.example.com/cb"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = get(r, "/auth/dev/authorize?client_id=other&redirect_uri="+url.QueryEscape(devRedirectURI))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDevIdentityProvider_TokenEndpointAndRefresh(t *testing.T) {
+	provider := newDevProvider(t)
+	r := newDevRouter(provider)
+	code, err := provider.IssueCode(provider.Users[1].ID, devRedirectURI, "n-1", "")
+	require.NoError(t, err)
+
+	w := postForm(r, "/auth/dev/token", url.Values{"grant_type": {"authorization_code"}, "code": {code}, "redirect_uri": {devRedirectURI}})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id_token"`)
+
+	tokens := provider.ExchangeCode(code, devRedirectURI, "")
+	assert.False(t, tokens.Success, "the code was redeemed already")
+
+	code, _ = provider.IssueCode(provider.Users[1].ID, devRedirectURI, "", service.PKCEChallenge("verifier"))
+	assert.False(t, provider.ExchangeCode(code, devRedirectURI, "other-verifier").Success, "PKCE verifier must match")
+	code, _ = provider.IssueCode(provider.Users[1].ID, devRedirectURI, "", service.PKCEChallenge("verifier"))
+	tokens = provider.ExchangeCode(code, devRedirectURI, "verifier")
+	require.True(t, tokens.Success)
+
+	refreshed := service.NewDevAuthService(provider).RefreshToken(tokens.RefreshToken)
+	require.True(t, refreshed.Success, refreshed.Error)
+	assert.NotEqual(t, tokens.RefreshToken, refreshed.RefreshToken)
+	assert.False(t, provider.Refresh(tokens.RefreshToken).Success, "refresh tokens are single use")
+
+	_, err = service.NewDevAuthService(provider).GetGraphToken(refreshed.RefreshToken)
+	assert.Error(t, err)
+}
+
+func TestDevIdentityProvider_DiscoveryAndKeys(t *testing.T) {
+	r := newDevRouter(newDevProvider(t))
+
+	w := get(r, "/auth/dev/.well-known/openid-configuration")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"issuer":"http://localhost:3002/auth/dev"`)
+	assert.Contains(t, w.Body.String(), `"jwks_uri":"http://localhost:3002/auth/dev/keys"`)
+
+	w = get(r, "/auth/dev/keys")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"kty":"RSA"`)
+	assert.Contains(t, w.Body.String(), `"alg":"RS256"`)
+}
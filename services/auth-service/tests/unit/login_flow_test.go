@@ -0,0 +1,133 @@
+package unit_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/auth-service/internal/service"
+)
+
+// ============================================================================
+// LoginFlowCodec Tests
+// ============================================================================
+
+func TestLoginFlowCodec_RoundTrip(t *testing.T) {
+	codec, err := service.NewLoginFlowCodec([]byte("secret"))
+	require.NoError(t, err)
+	flow, err := service.NewLoginFlow("/paths/42")
+	require.NoError(t, err)
+
+	value, err := codec.Encode(flow)
+	require.NoError(t, err)
+	decoded, err := codec.Decode(value)
+	require.NoError(t, err)
+
+	assert.Equal(t, flow.State, decoded.State)
+	assert.Equal(t, flow.Nonce, decoded.Nonce)
+	assert.Equal(t, flow.CodeVerifier, decoded.CodeVerifier)
+	assert.Equal(t, "/paths/42", decoded.ReturnTo)
+	assert.NotEqual(t, flow.State, flow.Nonce)
+}
+
+func TestLoginFlowCodec_RejectsTamperingAndExpiry(t *testing.T) {
+	codec, _ := service.NewLoginFlowCodec([]byte("secret"))
+	other, _ := service.NewLoginFlowCodec([]byte("other-secret"))
+	flow, _ := service.NewLoginFlow("")
+
+	value, _ := codec.Encode(flow)
+	_, err := other.Decode(value)
+	assert.Error(t, err, "signed with another secret")
+
+	encoded, signature, _ := strings.Cut(value, ".")
+	_, err = codec.Decode(encoded + "x." + signature)
+	assert.Error(t, err, "payload changed")
+	_, err = codec.Decode("garbage")
+	assert.Error(t, err)
+
+	flow.ExpiresAt = time.Now().Add(-time.Second)
+	value, _ = codec.Encode(flow)
+	_, err = codec.Decode(value)
+	assert.Error(t, err, "expired")
+}
+
+// ============================================================================
+// Login / Callback Tests
+// ============================================================================
+
+func TestLogin_SendsStateNonceAndPKCE(t *testing.T) {
+	r := newDevRouter(newDevProvider(t))
+
+	w := get(r, "/auth/login")
+	require.Equal(t, http.StatusFound, w.Code)
+	authorize, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	query := authorize.Query()
+
+	assert.NotEmpty(t, query.Get("state"))
+	assert.NotEmpty(t, query.Get("nonce"))
+	assert.NotEmpty(t, query.Get("code_challenge"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+
+	cookie := findCookie(w, "oauth_flow")
+	require.NotNil(t, cookie)
+	assert.True(t, cookie.HttpOnly)
+	assert.Equal(t, "/auth", cookie.Path)
+	assert.NotContains(t, cookie.Value, query.Get("state"), "the cookie is encoded, not a copy of the query")
+}
+
+func TestCallback_RequiresMatchingState(t *testing.T) {
+	provider := newDevProvider(t)
+	r := newDevRouter(provider)
+	callbackQuery, flowCookie := devSignIn(t, r, provider, "/auth/login")
+
+	w := get(r, "/auth/callback?"+callbackQuery)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "no login flow cookie")
+
+	_, otherFlow := devSignIn(t, r, provider, "/auth/login")
+	w = get(r, "/auth/callback?"+callbackQuery, otherFlow)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "state from another login")
+
+	query, _ := url.ParseQuery(callbackQuery)
+	w = get(r, "/auth/callback?code="+url.QueryEscape(query.Get("code")), flowCookie)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "missing state")
+
+	w = get(r, "/auth/callback?error=access_denied&error_description=cancelled", flowCookie)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCallback_RedirectsToReturnTo(t *testing.T) {
+	provider := newDevProvider(t)
+	r := newDevRouter(provider)
+
+	callbackQuery, flowCookie := devSignIn(t, r, provider, "/auth/login?returnTo="+url.QueryEscape("/paths/42"))
+	w := get(r, "/auth/callback?"+callbackQuery, flowCookie)
+	require.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "http://localhost/paths/42", w.Header().Get("Location"))
+
+	for _, returnTo := range []string{"https://evil.example.com/x", "//evil.example.com", "/\\evil.example.com", "javascript:alert(1)"} {
+		callbackQuery, flowCookie = devSignIn(t, r, provider, "/auth/login?returnTo="+url.QueryEscape(returnTo))
+		w = get(r, "/auth/callback?"+callbackQuery, flowCookie)
+		require.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "http://localhost", w.Header().Get("Location"), returnTo)
+	}
+}
+
+func TestValidateToken_ChecksNonce(t *testing.T) {
+	provider := newDevProvider(t)
+	code, err := provider.IssueCode(provider.Users[0].ID, devRedirectURI, "expected-nonce", "")
+	require.NoError(t, err)
+	tokens := provider.ExchangeCode(code, devRedirectURI, "")
+	require.True(t, tokens.Success)
+
+	authService := service.NewDevAuthService(provider)
+	assert.True(t, authService.ValidateToken(tokens.IDToken, "expected-nonce").Valid)
+	result := authService.ValidateToken(tokens.IDToken, "other-nonce")
+	assert.False(t, result.Valid)
+	assert.Contains(t, result.Error, "nonce")
+}
@@ -0,0 +1,240 @@
+package unit_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/auth-service/internal/controller"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/auth-service/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/authcookie"
+)
+
+// fakeOIDCProvider serves discovery, keys, and token and revocation endpoints laid out like a
+// Keycloak realm
+type fakeOIDCProvider struct {
+	server      *httptest.Server
+	key         *rsa.PrivateKey
+	authMethods []string
+
+	mu          sync.Mutex
+	lastForm    url.Values
+	lastBasicID string
+}
+
+func newFakeOIDCProvider(t *testing.T, authMethods ...string) *fakeOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	p := &fakeOIDCProvider{key: key, authMethods: authMethods}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/realms/rosetta/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		issuer := p.issuer()
+		discovery := map[string]interface{}{
+			"issuer":                                issuer,
+			"authorization_endpoint":                issuer + "/protocol/openid-connect/auth",
+			"token_endpoint":                        issuer + "/protocol/openid-connect/token",
+			"revocation_endpoint":                   issuer + "/protocol/openid-connect/revoke",
+			"end_session_endpoint":                  issuer + "/protocol/openid-connect/logout",
+			"jwks_uri":                              issuer + "/protocol/openid-connect/certs",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		}
+		if len(p.authMethods) > 0 {
+			discovery["token_endpoint_auth_methods_supported"] = p.authMethods
+		}
+		_ = json.NewEncoder(w).Encode(discovery)
+	})
+	mux.HandleFunc("/realms/rosetta/protocol/openid-connect/certs", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/realms/rosetta/protocol/openid-connect/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		basicID, _, _ := r.BasicAuth()
+		p.mu.Lock()
+		p.lastForm, p.lastBasicID = r.PostForm, basicID
+		p.mu.Unlock()
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "kc-access",
+			"refresh_token": "kc-refresh",
+			"id_token":      p.signIDToken(t, "kc-user-1"),
+			"expires_in":    300,
+		})
+	})
+	mux.HandleFunc("/realms/rosetta/protocol/openid-connect/revoke", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		basicID, _, _ := r.BasicAuth()
+		p.mu.Lock()
+		p.lastForm, p.lastBasicID = r.PostForm, basicID
+		p.mu.Unlock()
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *fakeOIDCProvider) issuer() string {
+	return p.server.URL + "/realms/rosetta"
+}
+
+func (p *fakeOIDCProvider) lastRequest() (url.Values, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lastForm, p.lastBasicID
+}
+
+// signIDToken issues an ID token with sub but, unlike Entra ID, no oid
+func (p *fakeOIDCProvider) signIDToken(t *testing.T, subject string) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "test", "typ": "JWT"})
+	payload, _ := json.Marshal(map[string]interface{}{
+		"iss":   p.issuer(),
+		"sub":   subject,
+		"aud":   "rosetta",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+		"email": "kim@example.com",
+		"name":  "Kim Keycloak",
+	})
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func newGenericAuthService(t *testing.T, p *fakeOIDCProvider, cfg service.ProviderConfig) *service.AuthService {
+	cfg.Issuer = p.issuer()
+	cfg.ClientID = "rosetta"
+	cfg.ClientSecret = "kc-secret"
+	cfg.RedirectURI = devRedirectURI
+	authService, err := service.NewAuthService(cfg)
+	require.NoError(t, err)
+	return authService
+}
+
+// ============================================================================
+// Generic OIDC Provider Tests
+// ============================================================================
+
+func TestNormalizeIssuer(t *testing.T) {
+	tests := map[string]string{
+		"00000000-0000-0000-0000-000000000001":                                   "https://login.microsoftonline.com/00000000-0000-0000-0000-000000000001/v2.0",
+		"https://login.microsoftonline.com/00000000-0000-0000-0000-000000000001": "https://login.microsoftonline.com/00000000-0000-0000-0000-000000000001/v2.0",
+		"https://login.microsoftonline.com/tenant/v2.0/":                         "https://login.microsoftonline.com/tenant/v2.0",
+		"https://keycloak.example.com/realms/rosetta":                            "https://keycloak.example.com/realms/rosetta",
+		"https://accounts.google.com":                                            "https://accounts.google.com",
+		"https://example.okta.com/oauth2/default/":                               "https://example.okta.com/oauth2/default",
+	}
+	for issuer, expected := range tests {
+		assert.Equal(t, expected, service.NormalizeIssuer(issuer), issuer)
+	}
+}
+
+func TestAuthService_GenericProvider_UsesDiscoveredEndpoints(t *testing.T) {
+	p := newFakeOIDCProvider(t, "client_secret_post", "client_secret_basic")
+	authService := newGenericAuthService(t, p, service.ProviderConfig{
+		AuthParams: url.Values{"access_type": {"offline"}, "state": {"overridden"}},
+	})
+
+	flow, err := service.NewLoginFlow("")
+	require.NoError(t, err)
+	authorize, err := url.Parse(authService.AuthorizeURL(flow))
+	require.NoError(t, err)
+	assert.Equal(t, p.issuer()+"/protocol/openid-connect/auth", authorize.Scheme+"://"+authorize.Host+authorize.Path)
+	assert.Equal(t, "openid profile email offline_access", authorize.Query().Get("scope"), "no Entra API scope")
+	assert.Equal(t, "offline", authorize.Query().Get("access_type"))
+	assert.Equal(t, flow.State, authorize.Query().Get("state"), "configuration cannot replace the flow's parameters")
+
+	assert.False(t, authService.SupportsGraph())
+	_, err = authService.GetGraphToken("kc-refresh")
+	assert.ErrorIs(t, err, service.ErrGraphNotSupported)
+}
+
+func TestAuthService_GenericProvider_ExchangeAndValidate(t *testing.T) {
+	p := newFakeOIDCProvider(t, "client_secret_post", "client_secret_basic")
+	authService := newGenericAuthService(t, p, service.ProviderConfig{Scopes: []string{"openid", "email"}})
+
+	tokens := authService.ExchangeCode("code-1", "verifier-1")
+	require.True(t, tokens.Success, tokens.Error)
+	assert.Equal(t, "kc-refresh", tokens.RefreshToken)
+
+	form, basicID := p.lastRequest()
+	assert.Equal(t, "authorization_code", form.Get("grant_type"))
+	assert.Equal(t, "verifier-1", form.Get("code_verifier"))
+	assert.Equal(t, "openid email", form.Get("scope"))
+	assert.Equal(t, "kc-secret", form.Get("client_secret"))
+	assert.Empty(t, basicID)
+
+	result := authService.ValidateToken(tokens.IDToken, "")
+	require.True(t, result.Valid, result.Error)
+	assert.Equal(t, "kc-user-1", result.EntraID, "sub identifies users without oid")
+	assert.Equal(t, "kim@example.com", result.Email)
+}
+
+func TestAuthService_GenericProvider_BasicAuthOnly(t *testing.T) {
+	p := newFakeOIDCProvider(t, "client_secret_basic", "private_key_jwt")
+	authService := newGenericAuthService(t, p, service.ProviderConfig{})
+
+	refreshed := authService.RefreshToken("kc-refresh")
+	require.True(t, refreshed.Success, refreshed.Error)
+
+	form, basicID := p.lastRequest()
+	assert.Equal(t, "refresh_token", form.Get("grant_type"))
+	assert.Equal(t, "rosetta", basicID)
+	assert.Empty(t, form.Get("client_secret"), "the secret is not sent in the form")
+
+	refreshed = authService.RefreshToken("")
+	assert.False(t, refreshed.Success, "no refresh token was issued")
+	assert.True(t, strings.Contains(refreshed.Error, "refresh token"))
+}
+
+func TestAuthService_GenericProvider_RevocationAndEndSession(t *testing.T) {
+	p := newFakeOIDCProvider(t, "client_secret_post")
+	authService := newGenericAuthService(t, p, service.ProviderConfig{EndSession: true})
+
+	require.NoError(t, authService.RevokeRefreshToken("kc-refresh"))
+	form, _ := p.lastRequest()
+	assert.Equal(t, "kc-refresh", form.Get("token"))
+	assert.Equal(t, "refresh_token", form.Get("token_type_hint"))
+	assert.Equal(t, "kc-secret", form.Get("client_secret"))
+
+	logout, err := url.Parse(authService.EndSessionURL("id-token", "http://localhost/goodbye"))
+	require.NoError(t, err)
+	assert.Equal(t, p.issuer()+"/protocol/openid-connect/logout", logout.Scheme+"://"+logout.Host+logout.Path)
+	assert.Equal(t, "http://localhost/goodbye", logout.Query().Get("post_logout_redirect_uri"))
+	assert.Equal(t, "id-token", logout.Query().Get("id_token_hint"))
+	assert.Equal(t, "rosetta", logout.Query().Get("client_id"))
+
+	flowCodec, _ := service.NewLoginFlowCodec([]byte("test-secret"))
+	revocations := service.NewRevocationService(service.NewMemoryRevocationStore(), authService, nil)
+	r := gin.New()
+	r.GET("/auth/logout", controller.NewAuthController(authService, flowCodec, nil, revocations, authcookie.NewCodec(nil)).Logout)
+	w := get(r, "/auth/logout?redirect=/goodbye")
+	require.Equal(t, http.StatusFound, w.Code)
+	assert.True(t, strings.HasPrefix(w.Header().Get("Location"), p.issuer()+"/protocol/openid-connect/logout?"), "logout continues at the provider")
+
+	withoutEndSession := newGenericAuthService(t, p, service.ProviderConfig{})
+	assert.Empty(t, withoutEndSession.EndSessionURL("id-token", "http://localhost/"), "provider logout is opt-in")
+}
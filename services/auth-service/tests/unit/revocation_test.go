@@ -0,0 +1,211 @@
+package unit_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/auth-service/internal/service"
+)
+
+// listRevocations reads the revocation list the way the backend does
+func listRevocations(t *testing.T, r *gin.Engine) []service.Revocation {
+	w := doSessionRequest(r, http.MethodGet, "/auth/internal/revocations", "", testInternalSecret)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var body struct {
+		Revocations []service.Revocation `json:"revocations"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body.Revocations
+}
+
+func findRevocation(revocations []service.Revocation, kind, value string) *service.Revocation {
+	for _, revocation := range revocations {
+		if revocation.Kind == kind && revocation.Value == value {
+			return &revocation
+		}
+	}
+	return nil
+}
+
+// ============================================================================
+// Revocation Tests
+// ============================================================================
+
+func TestLogout_RevokesCookieTokens(t *testing.T) {
+	provider := newDevProvider(t)
+	store := service.NewMemoryRevocationStore()
+	r := newDevRouterWithRevocations(provider, store)
+	callbackQuery, flowCookie := devSignIn(t, r, provider, "/auth/login")
+	w := get(r, "/auth/callback?"+callbackQuery, flowCookie)
+	require.Equal(t, http.StatusFound, w.Code, w.Body.String())
+	idToken, refreshToken := findCookie(w, "id_token"), findCookie(w, "refresh_token")
+	require.NotNil(t, idToken)
+	require.NotNil(t, refreshToken)
+
+	assert.Empty(t, listRevocations(t, r))
+	w = doSessionRequest(r, http.MethodGet, "/auth/internal/revocations", "", "wrong")
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "internal secret required")
+
+	w = get(r, "/auth/logout", idToken, refreshToken)
+	require.Equal(t, http.StatusFound, w.Code)
+
+	validation := service.NewDevAuthService(provider).ValidateToken(idToken.Value, "")
+	require.True(t, validation.Valid, validation.Error)
+	jti := service.TokenID(validation.Claims)
+	require.NotEmpty(t, jti)
+	revocation := findRevocation(listRevocations(t, r), service.RevokedToken, jti)
+	require.NotNil(t, revocation, "the ID token is revoked")
+	assert.WithinDuration(t, time.Unix(int64(validation.Claims["exp"].(float64)), 0), revocation.ExpiresAt, time.Second, "until it expires")
+
+	stored, err := store.Get(context.Background(), service.RevokedRefreshToken, service.RefreshTokenID(refreshToken.Value))
+	require.NoError(t, err)
+	assert.NotNil(t, stored, "the refresh token is revoked for providers that cannot revoke it")
+	assert.Nil(t, findRevocation(listRevocations(t, r), service.RevokedRefreshToken, service.RefreshTokenID(refreshToken.Value)), "refresh tokens are not handed to the backend")
+
+	w = doSessionRequest(r, http.MethodPost, "/auth/refresh", "", "", refreshToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "the refresh token is revoked at the provider")
+}
+
+// cookieSignIn signs ada in without a session store and returns the ID and refresh token cookies
+func cookieSignIn(t *testing.T, r *gin.Engine, provider *service.DevIdentityProvider) (*http.Cookie, *http.Cookie) {
+	callbackQuery, flowCookie := devSignIn(t, r, provider, "/auth/login")
+	w := get(r, "/auth/callback?"+callbackQuery, flowCookie)
+	require.Equal(t, http.StatusFound, w.Code, w.Body.String())
+	idToken, refreshToken := findCookie(w, "id_token"), findCookie(w, "refresh_token")
+	require.NotNil(t, idToken)
+	require.NotNil(t, refreshToken)
+	return idToken, refreshToken
+}
+
+func TestRefresh_RejectsRevokedRefreshToken(t *testing.T) {
+	provider := newDevProvider(t)
+	store := service.NewMemoryRevocationStore()
+	r := newDevRouterWithRevocations(provider, store)
+	idToken, refreshToken := cookieSignIn(t, r, provider)
+
+	// Entra ID cannot revoke refresh tokens, so the provider still accepts this one
+	now := time.Now()
+	require.NoError(t, store.Add(context.Background(), &service.Revocation{
+		Kind: service.RevokedRefreshToken, Value: service.RefreshTokenID(refreshToken.Value), RevokedAt: now, ExpiresAt: now.Add(time.Hour),
+	}))
+
+	w := doSessionRequest(r, http.MethodPost, "/auth/refresh", "", "", idToken, refreshToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = doSessionRequest(r, http.MethodPost, "/auth/refresh", `{"refresh_token":"`+refreshToken.Value+`"}`, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "also when sent in the body")
+	assert.True(t, provider.Refresh(refreshToken.Value).Success, "rejected without redeeming it at the provider")
+}
+
+func TestRefresh_RejectsTokensIssuedBeforeSignOutEverywhere(t *testing.T) {
+	provider := newDevProvider(t)
+	store := service.NewMemoryRevocationStore()
+	r := newDevRouterWithRevocations(provider, store)
+	userID := provider.Users[0].ID
+	idToken, refreshToken := cookieSignIn(t, r, provider)
+	_, stolenRefreshToken := cookieSignIn(t, r, provider)
+
+	w := doSessionRequest(r, http.MethodPost, "/auth/internal/users/"+userID+"/sign-out", "", testInternalSecret)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doSessionRequest(r, http.MethodPost, "/auth/refresh", "", "", idToken, refreshToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "issued before the sign-out")
+	assert.Nil(t, findCookie(w, "refresh_token"), "no new tokens are handed out")
+	w = doSessionRequest(r, http.MethodPost, "/auth/refresh", `{"refresh_token":"`+stolenRefreshToken.Value+`"}`, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "without the ID token it was issued with")
+
+	// Signing in again after the sign-out works; iat has a precision of one second
+	now := time.Now()
+	require.NoError(t, store.Add(context.Background(), &service.Revocation{
+		Kind: service.RevokedUser, Value: userID, RevokedAt: now.Add(-time.Minute), ExpiresAt: now.Add(time.Hour),
+	}))
+	idToken, refreshToken = cookieSignIn(t, r, provider)
+	w = doSessionRequest(r, http.MethodPost, "/auth/refresh", "", "", idToken, refreshToken)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestSession_LogoutRevokesSession(t *testing.T) {
+	provider := newDevProvider(t)
+	r := newSessionRouter(provider, service.NewMemorySessionStore())
+	cookie := sessionSignIn(t, r, provider)
+
+	w := get(r, "/auth/logout", cookie)
+	require.Equal(t, http.StatusFound, w.Code)
+
+	revocations := listRevocations(t, r)
+	assert.NotNil(t, findRevocation(revocations, service.RevokedSession, service.SessionID(cookie.Value)))
+	assert.Len(t, revocations, 2, "the session and its ID token")
+}
+
+func TestSession_SignOutUserEverywhere(t *testing.T) {
+	provider := newDevProvider(t)
+	r := newSessionRouter(provider, service.NewMemorySessionStore())
+	laptop := sessionSignIn(t, r, provider)
+	phone := sessionSignIn(t, r, provider)
+	userID := provider.Users[0].ID
+
+	w := doSessionRequest(r, http.MethodPost, "/auth/internal/users/"+userID+"/sign-out", "", "wrong")
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "internal secret required")
+
+	before := time.Now()
+	w = doSessionRequest(r, http.MethodPost, "/auth/internal/users/"+userID+"/sign-out", "", testInternalSecret)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"sessionsEnded":2}`, w.Body.String())
+
+	assert.Equal(t, http.StatusNotFound, resolveSession(r, laptop.Value).Code)
+	assert.Equal(t, http.StatusNotFound, resolveSession(r, phone.Value).Code)
+
+	revocations := listRevocations(t, r)
+	revocation := findRevocation(revocations, service.RevokedUser, userID)
+	require.NotNil(t, revocation)
+	assert.False(t, revocation.RevokedAt.Before(before.Truncate(time.Second)))
+	assert.NotNil(t, findRevocation(revocations, service.RevokedSession, service.SessionID(laptop.Value)))
+	assert.NotNil(t, findRevocation(revocations, service.RevokedSession, service.SessionID(phone.Value)))
+}
+
+func testRevocationStore(t *testing.T, store service.RevocationStore) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	require.NoError(t, store.Add(ctx, &service.Revocation{Kind: service.RevokedUser, Value: "user-1", RevokedAt: now.Add(-time.Minute), ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, store.Add(ctx, &service.Revocation{Kind: service.RevokedUser, Value: "user-1", RevokedAt: now, ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, store.Add(ctx, &service.Revocation{Kind: service.RevokedToken, Value: "jti-1", RevokedAt: now, ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, store.Add(ctx, &service.Revocation{Kind: service.RevokedToken, Value: "jti-expired", RevokedAt: now, ExpiresAt: now.Add(-time.Second)}))
+
+	revocations, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, revocations, 2, "replaced and expired entries are not listed")
+
+	revocation, err := store.Get(ctx, service.RevokedUser, "user-1")
+	require.NoError(t, err)
+	require.NotNil(t, revocation)
+	assert.True(t, revocation.RevokedAt.Equal(now))
+	revocation, err = store.Get(ctx, service.RevokedToken, "jti-expired")
+	require.NoError(t, err)
+	assert.Nil(t, revocation, "expired")
+	revocation, err = store.Get(ctx, service.RevokedToken, "jti-unknown")
+	require.NoError(t, err)
+	assert.Nil(t, revocation)
+	for _, revocation := range revocations {
+		if revocation.Kind == service.RevokedUser {
+			assert.True(t, revocation.RevokedAt.Equal(now), "signing out again moves the revocation time")
+		}
+	}
+}
+
+func TestMemoryRevocationStore(t *testing.T) {
+	testRevocationStore(t, service.NewMemoryRevocationStore())
+}
+
+func TestRedisRevocationStore(t *testing.T) {
+	addr := startFakeRedis(t, "")
+	store, err := service.NewRedisSessionStore(context.Background(), "redis://"+addr)
+	require.NoError(t, err)
+	testRevocationStore(t, store.Revocations())
+}
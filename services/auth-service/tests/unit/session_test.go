@@ -0,0 +1,402 @@
+package unit_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/auth-service/internal/controller"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/auth-service/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/authcookie"
+)
+
+const testInternalSecret = "internal-secret"
+
+func newSessionRouter(provider *service.DevIdentityProvider, store service.SessionStore) *gin.Engine {
+	flowCodec, _ := service.NewLoginFlowCodec([]byte("test-secret"))
+	authService := service.NewDevAuthService(provider)
+	sessions := service.NewSessionService(store, authService, 24*time.Hour)
+	revocations := service.NewRevocationService(service.NewMemoryRevocationStore(), authService, sessions)
+	sessions.OnSessionEnded = revocations.RevokeSession
+	authController := controller.NewAuthController(authService, flowCodec, sessions, revocations, authcookie.NewCodec(nil))
+	sessionController := controller.NewSessionController(sessions, testInternalSecret)
+	revocationController := controller.NewRevocationController(revocations, testInternalSecret)
+	devController := controller.NewDevIdentityController(provider)
+
+	r := gin.New()
+	r.GET("/auth/login", authController.Login)
+	r.GET("/auth/callback", authController.Callback)
+	r.GET("/auth/logout", authController.Logout)
+	r.POST("/auth/refresh", authController.RefreshToken)
+	r.GET("/auth/sessions", sessionController.List)
+	r.DELETE("/auth/sessions/:id", sessionController.Revoke)
+	r.POST("/auth/internal/sessions/resolve", sessionController.Resolve)
+	r.GET("/auth/internal/revocations", revocationController.List)
+	r.POST("/auth/internal/users/:userId/sign-out", revocationController.SignOutUser)
+	r.POST("/auth/dev/authorize", devController.Authorize)
+	return r
+}
+
+// sessionSignIn signs ada in and returns the session cookie
+func sessionSignIn(t *testing.T, r *gin.Engine, provider *service.DevIdentityProvider) *http.Cookie {
+	callbackQuery, flowCookie := devSignIn(t, r, provider, "/auth/login")
+	w := get(r, "/auth/callback?"+callbackQuery, flowCookie)
+	require.Equal(t, http.StatusFound, w.Code, w.Body.String())
+	assert.Nil(t, findCookie(w, "id_token"), "tokens stay on the server")
+	assert.Nil(t, findCookie(w, "refresh_token"), "tokens stay on the server")
+	cookie := findCookie(w, "rosetta_session")
+	require.NotNil(t, cookie)
+	assert.True(t, cookie.HttpOnly)
+	return cookie
+}
+
+func doSessionRequest(r *gin.Engine, method, path, body, bearer string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func resolveSession(r *gin.Engine, secret string) *httptest.ResponseRecorder {
+	return doSessionRequest(r, http.MethodPost, "/auth/internal/sessions/resolve", `{"session":"`+secret+`"}`, testInternalSecret)
+}
+
+// ============================================================================
+// Session Endpoint Tests
+// ============================================================================
+
+func TestSession_LoginResolveAndLogout(t *testing.T) {
+	provider := newDevProvider(t)
+	r := newSessionRouter(provider, service.NewMemorySessionStore())
+	cookie := sessionSignIn(t, r, provider)
+
+	w := resolveSession(r, cookie.Value)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resolved controller.ResolvedSession
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resolved))
+	assert.Equal(t, service.SessionID(cookie.Value), resolved.ID)
+	assert.Equal(t, provider.Users[0].ID, resolved.UserID)
+	validation := service.NewDevAuthService(provider).ValidateToken(resolved.IDToken, "")
+	require.True(t, validation.Valid, validation.Error)
+	assert.Equal(t, "ada@example.com", validation.Email)
+
+	w = doSessionRequest(r, http.MethodPost, "/auth/internal/sessions/resolve", `{"session":"`+cookie.Value+`"}`, "wrong")
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "internal secret required")
+
+	w = resolveSession(r, "unknown")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = get(r, "/auth/logout", cookie)
+	require.Equal(t, http.StatusFound, w.Code)
+	cleared := findCookie(w, "rosetta_session")
+	require.NotNil(t, cleared)
+	assert.True(t, cleared.MaxAge < 0)
+
+	w = resolveSession(r, cookie.Value)
+	assert.Equal(t, http.StatusNotFound, w.Code, "logout ends the session")
+}
+
+func TestSession_RefreshKeepsTokensOnServer(t *testing.T) {
+	provider := newDevProvider(t)
+	r := newSessionRouter(provider, service.NewMemorySessionStore())
+	cookie := sessionSignIn(t, r, provider)
+
+	w := resolveSession(r, cookie.Value)
+	var before controller.ResolvedSession
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &before))
+
+	time.Sleep(1100 * time.Millisecond) // New tokens get a later iat
+	w = doSessionRequest(r, http.MethodPost, "/auth/refresh", "", "", cookie)
+	require.Equal(t, http.StatusOK, w.Code)
+	var result service.TokenRefreshResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.True(t, result.Success)
+	assert.Empty(t, result.IDToken)
+	assert.Empty(t, result.RefreshToken)
+	assert.Positive(t, result.ExpiresIn)
+
+	w = resolveSession(r, cookie.Value)
+	var after controller.ResolvedSession
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &after))
+	assert.NotEqual(t, before.IDToken, after.IDToken)
+}
+
+func TestSession_ResolveRefreshesExpiringTokens(t *testing.T) {
+	provider := newDevProvider(t)
+	provider.TokenLifetime = 2 * time.Minute // Always inside the refresh margin
+	r := newSessionRouter(provider, service.NewMemorySessionStore())
+	cookie := sessionSignIn(t, r, provider)
+
+	time.Sleep(1100 * time.Millisecond)
+	w := resolveSession(r, cookie.Value)
+	require.Equal(t, http.StatusOK, w.Code)
+	var first controller.ResolvedSession
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &first))
+
+	time.Sleep(1100 * time.Millisecond)
+	w = resolveSession(r, cookie.Value)
+	require.Equal(t, http.StatusOK, w.Code)
+	var second controller.ResolvedSession
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &second))
+	assert.NotEqual(t, first.IDToken, second.IDToken, "the stored refresh token was rotated and reused")
+}
+
+func TestSession_ListAndRevoke(t *testing.T) {
+	provider := newDevProvider(t)
+	r := newSessionRouter(provider, service.NewMemorySessionStore())
+	laptop := sessionSignIn(t, r, provider)
+	phone := sessionSignIn(t, r, provider)
+
+	w := get(r, "/auth/sessions", laptop)
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed struct {
+		Sessions []controller.SessionView `json:"sessions"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Sessions, 2)
+	assert.NotContains(t, w.Body.String(), "idToken", "no tokens in the list")
+	current := 0
+	for _, session := range listed.Sessions {
+		if session.Current {
+			current++
+			assert.Equal(t, service.SessionID(laptop.Value), session.ID)
+		}
+	}
+	assert.Equal(t, 1, current)
+
+	w = doSessionRequest(r, http.MethodDelete, "/auth/sessions/"+service.SessionID(phone.Value), "", "", laptop)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, http.StatusNotFound, resolveSession(r, phone.Value).Code)
+	assert.Equal(t, http.StatusOK, resolveSession(r, laptop.Value).Code)
+
+	w = doSessionRequest(r, http.MethodDelete, "/auth/sessions/"+service.SessionID(phone.Value), "", "", laptop)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = get(r, "/auth/sessions")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = get(r, "/auth/sessions", phone)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "revoked session")
+}
+
+func TestSession_RevokeOtherUsersSession(t *testing.T) {
+	store := service.NewMemorySessionStore()
+	provider := newDevProvider(t)
+	r := newSessionRouter(provider, store)
+	cookie := sessionSignIn(t, r, provider)
+
+	other := &service.Session{ID: "other-session", UserID: "someone-else", ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, store.Create(context.Background(), other))
+
+	w := doSessionRequest(r, http.MethodDelete, "/auth/sessions/other-session", "", "", cookie)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	_, err := store.Get(context.Background(), "other-session")
+	assert.NoError(t, err, "still exists")
+}
+
+// ============================================================================
+// Session Store Tests
+// ============================================================================
+
+// testSessionStore runs the behaviour every SessionStore shares
+func testSessionStore(t *testing.T, store service.SessionStore) {
+	ctx := context.Background()
+	session := &service.Session{
+		ID:        "session-1",
+		UserID:    "user-1",
+		IDToken:   "id-token",
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	require.NoError(t, store.Create(ctx, session))
+	require.NoError(t, store.Create(ctx, &service.Session{ID: "session-2", UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)}))
+	require.NoError(t, store.Create(ctx, &service.Session{ID: "session-3", UserID: "user-2", ExpiresAt: time.Now().Add(time.Hour)}))
+
+	loaded, err := store.Get(ctx, "session-1")
+	require.NoError(t, err)
+	assert.Equal(t, "id-token", loaded.IDToken)
+
+	loaded.IDToken = "refreshed"
+	require.NoError(t, store.Update(ctx, loaded))
+	loaded, err = store.Get(ctx, "session-1")
+	require.NoError(t, err)
+	assert.Equal(t, "refreshed", loaded.IDToken)
+
+	sessions, err := store.ListByUser(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Len(t, sessions, 2)
+
+	require.NoError(t, store.Delete(ctx, "session-1"))
+	_, err = store.Get(ctx, "session-1")
+	assert.ErrorIs(t, err, service.ErrSessionNotFound)
+	assert.ErrorIs(t, store.Update(ctx, loaded), service.ErrSessionNotFound, "deleted sessions are not brought back")
+	require.NoError(t, store.Delete(ctx, "session-1"), "deleting twice is fine")
+
+	sessions, err = store.ListByUser(ctx, "user-1")
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "session-2", sessions[0].ID)
+}
+
+func TestMemorySessionStore(t *testing.T) {
+	testSessionStore(t, service.NewMemorySessionStore())
+}
+
+func TestMemorySessionStore_Expiry(t *testing.T) {
+	ctx := context.Background()
+	store := service.NewMemorySessionStore()
+	require.NoError(t, store.Create(ctx, &service.Session{ID: "expired", UserID: "user-1", ExpiresAt: time.Now().Add(-time.Second)}))
+
+	_, err := store.Get(ctx, "expired")
+	assert.ErrorIs(t, err, service.ErrSessionNotFound)
+	sessions, err := store.ListByUser(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func TestRedisSessionStore(t *testing.T) {
+	addr := startFakeRedis(t, "hunter2")
+	store, err := service.NewRedisSessionStore(context.Background(), "redis://:hunter2@"+addr+"/0")
+	require.NoError(t, err)
+	testSessionStore(t, store)
+
+	_, err = service.NewRedisSessionStore(context.Background(), "redis://:wrong@"+addr)
+	assert.Error(t, err)
+	_, err = service.NewRedisSessionStore(context.Background(), "http://"+addr)
+	assert.Error(t, err)
+}
+
+// startFakeRedis serves the handful of Redis commands the session store uses, without expiry
+func startFakeRedis(t *testing.T, password string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	var mu sync.Mutex
+	strs := map[string]string{}
+	sets := map[string]map[string]bool{}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				authenticated := password == ""
+				for {
+					args, err := readFakeRedisCommand(reader)
+					if err != nil {
+						return
+					}
+					mu.Lock()
+					reply := "-ERR unknown command\r\n"
+					switch strings.ToUpper(args[0]) {
+					case "AUTH":
+						if args[len(args)-1] == password {
+							authenticated = true
+							reply = "+OK\r\n"
+						} else {
+							reply = "-WRONGPASS invalid password\r\n"
+						}
+					case "PING":
+						reply = "+PONG\r\n"
+					case "SET":
+						_, exists := strs[args[1]]
+						if len(args) > 5 && args[5] == "XX" && !exists {
+							reply = "$-1\r\n"
+						} else {
+							strs[args[1]] = args[2]
+							reply = "+OK\r\n"
+						}
+					case "GET":
+						if value, ok := strs[args[1]]; ok {
+							reply = "$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n"
+						} else {
+							reply = "$-1\r\n"
+						}
+					case "DEL":
+						delete(strs, args[1])
+						reply = ":1\r\n"
+					case "SADD":
+						if sets[args[1]] == nil {
+							sets[args[1]] = map[string]bool{}
+						}
+						sets[args[1]][args[2]] = true
+						reply = ":1\r\n"
+					case "SREM":
+						delete(sets[args[1]], args[2])
+						reply = ":1\r\n"
+					case "SMEMBERS":
+						reply = "*" + strconv.Itoa(len(sets[args[1]])) + "\r\n"
+						for member := range sets[args[1]] {
+							reply += "$" + strconv.Itoa(len(member)) + "\r\n" + member + "\r\n"
+						}
+					case "EXPIRE":
+						reply = ":1\r\n"
+					}
+					if !authenticated && strings.ToUpper(args[0]) != "AUTH" {
+						reply = "-NOAUTH Authentication required\r\n"
+					}
+					mu.Unlock()
+					if _, err := io.WriteString(conn, reply); err != nil {
+						return
+					}
+				}
+			}(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func readFakeRedisCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		if line, err = reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	return args, nil
+}
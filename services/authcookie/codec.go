@@ -0,0 +1,240 @@
+// Package authcookie keeps auth tokens in cookies that the browser can neither read nor forge:
+// values are encrypted and authenticated with AES-GCM under a rotating key ring, and split
+// across several cookies when they do not fit in one. Auth-service writes these cookies and
+// the backend reads them, so both must be given the same keys (AUTH_COOKIE_KEYS).
+package authcookie
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMaxChunkSize keeps each cookie, with its name and attributes, under the 4096 bytes
+// browsers store per cookie
+const DefaultMaxChunkSize = 3800
+
+// sealedPrefix starts every sealed value, followed by the key ID and the ciphertext
+const sealedPrefix = "v1."
+
+// chunkedPrefix marks a cookie whose value is split over <name>_1 ... <name>_N
+const chunkedPrefix = "chunks-"
+
+var (
+	// ErrInvalid is returned for values that were tampered with, sealed for another cookie or
+	// sealed with a key that is no longer in the key ring
+	ErrInvalid = errors.New("invalid auth cookie")
+	// ErrExpired is returned for sealed values past the expiry they were sealed with
+	ErrExpired = errors.New("auth cookie expired")
+)
+
+// Codec writes and reads auth cookies. Without keys, values are stored as they are and only
+// chunked, which keeps development setups without AUTH_COOKIE_KEYS working.
+type Codec struct {
+	Keys         *KeyRing
+	MaxChunkSize int
+}
+
+func NewCodec(keys *KeyRing) *Codec {
+	return &Codec{Keys: keys, MaxChunkSize: DefaultMaxChunkSize}
+}
+
+// NewCodecFromEnv builds a codec with the keys in AUTH_COOKIE_KEYS (see ParseKeyRing), or one
+// that does not encrypt when it is unset
+func NewCodecFromEnv() (*Codec, error) {
+	spec := os.Getenv("AUTH_COOKIE_KEYS")
+	if strings.TrimSpace(spec) == "" {
+		return NewCodec(nil), nil
+	}
+	keys, err := ParseKeyRing(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AUTH_COOKIE_KEYS: %w", err)
+	}
+	return NewCodec(keys), nil
+}
+
+// Encrypts reports whether values are sealed, rather than stored as they are
+func (c *Codec) Encrypts() bool {
+	return c.Keys != nil
+}
+
+// Seal encrypts value for the cookie called name. The name and expiry are authenticated too, so
+// a value cannot be moved to another cookie or used after expiresAt.
+func (c *Codec) Seal(name, value string, expiresAt time.Time) (string, error) {
+	if c.Keys == nil {
+		return value, nil
+	}
+
+	aead := c.Keys.aeads[c.Keys.primary]
+	plaintext := make([]byte, 8, 8+len(value))
+	binary.BigEndian.PutUint64(plaintext, uint64(expiresAt.Unix()))
+	plaintext = append(plaintext, value...)
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, additionalData(name))
+	return sealedPrefix + c.Keys.primary + "." + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value Seal produced for the cookie called name
+func (c *Codec) Open(name, sealed string) (string, error) {
+	if c.Keys == nil {
+		return sealed, nil
+	}
+
+	rest, ok := strings.CutPrefix(sealed, sealedPrefix)
+	if !ok {
+		return "", ErrInvalid
+	}
+	keyID, encoded, ok := strings.Cut(rest, ".")
+	if !ok {
+		return "", ErrInvalid
+	}
+	aead, ok := c.Keys.aeads[keyID]
+	if !ok {
+		return "", ErrInvalid
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(data) < aead.NonceSize() {
+		return "", ErrInvalid
+	}
+
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], additionalData(name))
+	if err != nil || len(plaintext) < 8 {
+		return "", ErrInvalid
+	}
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(plaintext[:8])), 0)
+	if !time.Now().Before(expiresAt) {
+		return "", ErrExpired
+	}
+	return string(plaintext[8:]), nil
+}
+
+func additionalData(name string) []byte {
+	return []byte("authcookie:v1:" + name)
+}
+
+// Write sets cookie, with cookie.Value sealed, as one cookie or as a header cookie plus chunks.
+// cookie.MaxAge must be positive. Chunks left over from a larger earlier value are removed.
+func (c *Codec) Write(w http.ResponseWriter, r *http.Request, cookie http.Cookie) error {
+	if cookie.MaxAge <= 0 {
+		return fmt.Errorf("cookie %s needs a positive MaxAge", cookie.Name)
+	}
+
+	sealed, err := c.Seal(cookie.Name, cookie.Value, time.Now().Add(time.Duration(cookie.MaxAge)*time.Second))
+	if err != nil {
+		return err
+	}
+	// Escaped like gin's SetCookie, so cookies written before the codec still read the same
+	value := url.QueryEscape(sealed)
+
+	maxChunkSize := c.MaxChunkSize
+	if maxChunkSize <= 0 {
+		maxChunkSize = DefaultMaxChunkSize
+	}
+
+	var chunks []string
+	if len(value) > maxChunkSize {
+		for len(value) > 0 {
+			size := min(maxChunkSize, len(value))
+			// Never cut an escape sequence, so each chunk can be unescaped on its own
+			if i := strings.LastIndexByte(value[:size], '%'); i >= 0 && i > size-3 && size < len(value) {
+				size = i
+			}
+			chunks = append(chunks, value[:size])
+			value = value[size:]
+		}
+		value = chunkedPrefix + strconv.Itoa(len(chunks))
+	}
+
+	header := cookie
+	header.Value = value
+	http.SetCookie(w, &header)
+	for i, chunk := range chunks {
+		part := cookie
+		part.Name = chunkName(cookie.Name, i+1)
+		part.Value = chunk
+		http.SetCookie(w, &part)
+	}
+
+	c.clearChunks(w, r, cookie, len(chunks))
+	return nil
+}
+
+// Read returns the value of the cookie called name, joining its chunks and opening it.
+// Returns http.ErrNoCookie when it is not set.
+func (c *Codec) Read(r *http.Request, name string) (string, error) {
+	header, err := r.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+
+	value := header.Value
+	if count, ok := strings.CutPrefix(value, chunkedPrefix); ok {
+		n, err := strconv.Atoi(count)
+		if err != nil || n <= 0 || n > 50 {
+			return "", ErrInvalid
+		}
+		var joined strings.Builder
+		for i := 1; i <= n; i++ {
+			chunk, err := r.Cookie(chunkName(name, i))
+			if err != nil {
+				return "", ErrInvalid
+			}
+			joined.WriteString(chunk.Value)
+		}
+		value = joined.String()
+	}
+
+	sealed, err := url.QueryUnescape(value)
+	if err != nil {
+		return "", ErrInvalid
+	}
+	return c.Open(name, sealed)
+}
+
+// Clear removes cookie and its chunks. Only Name, Path, Domain, Secure, HttpOnly and SameSite
+// of cookie are used.
+func (c *Codec) Clear(w http.ResponseWriter, r *http.Request, cookie http.Cookie) {
+	header := cookie
+	header.Value = ""
+	header.MaxAge = -1
+	http.SetCookie(w, &header)
+	c.clearChunks(w, r, cookie, 0)
+}
+
+// clearChunks removes the request's chunks of cookie numbered above keep
+func (c *Codec) clearChunks(w http.ResponseWriter, r *http.Request, cookie http.Cookie, keep int) {
+	if r == nil {
+		return
+	}
+	prefix := cookie.Name + "_"
+	for _, existing := range r.Cookies() {
+		index, ok := strings.CutPrefix(existing.Name, prefix)
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(index); err != nil || n <= keep {
+			continue
+		}
+		stale := cookie
+		stale.Name = existing.Name
+		stale.Value = ""
+		stale.MaxAge = -1
+		http.SetCookie(w, &stale)
+	}
+}
+
+func chunkName(name string, index int) string {
+	return name + "_" + strconv.Itoa(index)
+}
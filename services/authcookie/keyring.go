@@ -0,0 +1,85 @@
+package authcookie
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Key is an AES key with the ID sealed values refer to it by
+type Key struct {
+	ID     string
+	Secret []byte // 16, 24 or 32 bytes (AES-128, -192 or -256)
+}
+
+// KeyRing holds the keys cookies are sealed with. The first key seals new values; every key
+// opens values sealed with it, so a key can be replaced without signing everyone out.
+type KeyRing struct {
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+// NewKeyRing builds a key ring whose first key is used for new values
+func NewKeyRing(keys ...Key) (*KeyRing, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("key ring needs at least one key")
+	}
+
+	ring := &KeyRing{primary: keys[0].ID, aeads: make(map[string]cipher.AEAD, len(keys))}
+	for _, key := range keys {
+		if key.ID == "" || strings.ContainsAny(key.ID, ".:,") {
+			return nil, fmt.Errorf("invalid key ID %q", key.ID)
+		}
+		if _, exists := ring.aeads[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key ID %q", key.ID)
+		}
+		block, err := aes.NewCipher(key.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key.ID, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key.ID, err)
+		}
+		ring.aeads[key.ID] = aead
+	}
+	return ring, nil
+}
+
+// ParseKeyRing reads keys written as "id:base64secret", comma-separated, newest first.
+// Secrets are standard or URL-safe base64, e.g. from `openssl rand -base64 32`.
+func ParseKeyRing(spec string) (*KeyRing, error) {
+	var keys []Key
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid key %q, expected id:base64secret", entry)
+		}
+		secret, err := decodeBase64(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: secret is not base64: %w", id, err)
+		}
+		keys = append(keys, Key{ID: id, Secret: secret})
+	}
+	return NewKeyRing(keys...)
+}
+
+// PrimaryKeyID returns the ID of the key new values are sealed with
+func (k *KeyRing) PrimaryKeyID() string {
+	return k.primary
+}
+
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(strings.TrimSpace(s), "=")
+	if strings.ContainsAny(s, "-_") {
+		return base64.RawURLEncoding.DecodeString(s)
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
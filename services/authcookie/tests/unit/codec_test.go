@@ -0,0 +1,236 @@
+package unit_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/authcookie"
+)
+
+func newKeyRing(t *testing.T, keys ...authcookie.Key) *authcookie.KeyRing {
+	ring, err := authcookie.NewKeyRing(keys...)
+	require.NoError(t, err)
+	return ring
+}
+
+func key(id string, fill byte) authcookie.Key {
+	return authcookie.Key{ID: id, Secret: bytes.Repeat([]byte{fill}, 32)}
+}
+
+// roundTrip writes cookie with codec and returns a request carrying the cookies it set
+func roundTrip(t *testing.T, codec *authcookie.Codec, cookie http.Cookie) (*http.Request, []*http.Cookie) {
+	w := httptest.NewRecorder()
+	require.NoError(t, codec.Write(w, httptest.NewRequest(http.MethodGet, "/", nil), cookie))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	set := w.Result().Cookies()
+	for _, c := range set {
+		req.AddCookie(c)
+	}
+	return req, set
+}
+
+// ============================================================================
+// Key Ring Tests
+// ============================================================================
+
+func TestParseKeyRing(t *testing.T) {
+	ring, err := authcookie.ParseKeyRing("2025-06:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=, 2025-01:__________________________________________8")
+	require.NoError(t, err)
+	assert.Equal(t, "2025-06", ring.PrimaryKeyID())
+
+	for _, spec := range []string{
+		"",
+		"no-secret",
+		"short:AAAA",
+		"bad:not base64!",
+		"dup:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=,dup:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+		"a.b:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+	} {
+		_, err := authcookie.ParseKeyRing(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestNewCodecFromEnv(t *testing.T) {
+	t.Setenv("AUTH_COOKIE_KEYS", "")
+	codec, err := authcookie.NewCodecFromEnv()
+	require.NoError(t, err)
+	assert.False(t, codec.Encrypts())
+
+	t.Setenv("AUTH_COOKIE_KEYS", "k1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")
+	codec, err = authcookie.NewCodecFromEnv()
+	require.NoError(t, err)
+	assert.True(t, codec.Encrypts())
+
+	t.Setenv("AUTH_COOKIE_KEYS", "k1:short")
+	_, err = authcookie.NewCodecFromEnv()
+	assert.Error(t, err)
+}
+
+// ============================================================================
+// Seal / Open Tests
+// ============================================================================
+
+func TestSealAndOpen(t *testing.T) {
+	codec := authcookie.NewCodec(newKeyRing(t, key("k1", 1)))
+
+	sealed, err := codec.Seal("id_token", "eyJ.token", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "eyJ.token")
+
+	opened, err := codec.Open("id_token", sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "eyJ.token", opened)
+
+	_, err = codec.Open("graph_access_token", sealed)
+	assert.ErrorIs(t, err, authcookie.ErrInvalid, "bound to the cookie name")
+
+	tampered := []byte(sealed)
+	tampered[len(tampered)-5] ^= 1
+	_, err = codec.Open("id_token", string(tampered))
+	assert.ErrorIs(t, err, authcookie.ErrInvalid)
+
+	_, err = codec.Open("id_token", "eyJ.plain.jwt")
+	assert.ErrorIs(t, err, authcookie.ErrInvalid, "unsealed values are rejected")
+
+	expired, err := codec.Seal("id_token", "eyJ.token", time.Now().Add(-time.Second))
+	require.NoError(t, err)
+	_, err = codec.Open("id_token", expired)
+	assert.ErrorIs(t, err, authcookie.ErrExpired)
+}
+
+func TestKeyRotation(t *testing.T) {
+	old := authcookie.NewCodec(newKeyRing(t, key("old", 1)))
+	sealed, err := old.Seal("id_token", "token", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	rotated := authcookie.NewCodec(newKeyRing(t, key("new", 2), key("old", 1)))
+	opened, err := rotated.Open("id_token", sealed)
+	require.NoError(t, err, "old key still opens")
+	assert.Equal(t, "token", opened)
+
+	resealed, err := rotated.Seal("id_token", "token", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(resealed, "v1.new."), "new values use the first key")
+
+	retired := authcookie.NewCodec(newKeyRing(t, key("new", 2)))
+	_, err = retired.Open("id_token", sealed)
+	assert.ErrorIs(t, err, authcookie.ErrInvalid, "retired key no longer opens")
+}
+
+// ============================================================================
+// Write / Read / Clear Tests
+// ============================================================================
+
+func TestWriteAndRead(t *testing.T) {
+	codec := authcookie.NewCodec(newKeyRing(t, key("k1", 1)))
+	req, set := roundTrip(t, codec, http.Cookie{Name: "id_token", Value: "eyJ.token", MaxAge: 3600, Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode})
+
+	require.Len(t, set, 1)
+	assert.True(t, set[0].HttpOnly)
+	assert.Equal(t, 3600, set[0].MaxAge)
+
+	value, err := codec.Read(req, "id_token")
+	require.NoError(t, err)
+	assert.Equal(t, "eyJ.token", value)
+
+	_, err = codec.Read(req, "access_token")
+	assert.ErrorIs(t, err, http.ErrNoCookie)
+}
+
+func TestWrite_ChunksLargeValues(t *testing.T) {
+	codec := authcookie.NewCodec(newKeyRing(t, key("k1", 1)))
+	large := strings.Repeat("x", 9000) // A token with hundreds of group claims
+
+	req, set := roundTrip(t, codec, http.Cookie{Name: "id_token", Value: large, MaxAge: 3600, Path: "/"})
+	chunks := len(set) - 1
+	require.Greater(t, chunks, 1)
+	assert.Equal(t, "chunks-"+strconv.Itoa(chunks), set[0].Value)
+	for _, cookie := range set {
+		assert.LessOrEqual(t, len(cookie.String()), 4096)
+	}
+
+	value, err := codec.Read(req, "id_token")
+	require.NoError(t, err)
+	assert.Equal(t, large, value)
+
+	// A smaller value replaces the chunked one and removes its chunks
+	w := httptest.NewRecorder()
+	require.NoError(t, codec.Write(w, req, http.Cookie{Name: "id_token", Value: "small", MaxAge: 3600, Path: "/"}))
+	removed := 0
+	for _, cookie := range w.Result().Cookies() {
+		if strings.HasPrefix(cookie.Name, "id_token_") {
+			assert.Negative(t, cookie.MaxAge)
+			removed++
+		}
+	}
+	assert.Equal(t, chunks, removed)
+}
+
+func TestWrite_ChunksKeepEscapesWhole(t *testing.T) {
+	codec := &authcookie.Codec{MaxChunkSize: 10}
+	value := strings.Repeat("a/b+", 20)
+
+	req, set := roundTrip(t, codec, http.Cookie{Name: "refresh_token", Value: value, MaxAge: 3600})
+	for _, cookie := range set[1:] {
+		_, err := url.QueryUnescape(cookie.Value)
+		assert.NoError(t, err, "chunk %s unescapes on its own", cookie.Name)
+	}
+
+	read, err := codec.Read(req, "refresh_token")
+	require.NoError(t, err)
+	assert.Equal(t, value, read)
+}
+
+func TestRead_MissingChunk(t *testing.T) {
+	codec := authcookie.NewCodec(newKeyRing(t, key("k1", 1)))
+	_, set := roundTrip(t, codec, http.Cookie{Name: "id_token", Value: strings.Repeat("x", 9000), MaxAge: 3600})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range set[:len(set)-1] {
+		req.AddCookie(cookie)
+	}
+	_, err := codec.Read(req, "id_token")
+	assert.ErrorIs(t, err, authcookie.ErrInvalid)
+}
+
+func TestWithoutKeys_StoresValuesAsTheyAre(t *testing.T) {
+	codec := authcookie.NewCodec(nil)
+	req, set := roundTrip(t, codec, http.Cookie{Name: "refresh_token", Value: "0.AXo+abc/def=", MaxAge: 3600})
+	require.Len(t, set, 1)
+
+	value, err := codec.Read(req, "refresh_token")
+	require.NoError(t, err)
+	assert.Equal(t, "0.AXo+abc/def=", value)
+
+	// Cookies written by gin's SetCookie before the codec read the same
+	legacy := httptest.NewRequest(http.MethodGet, "/", nil)
+	legacy.Header.Set("Cookie", "refresh_token=0.AXo%2Babc%2Fdef%3D")
+	value, err = codec.Read(legacy, "refresh_token")
+	require.NoError(t, err)
+	assert.Equal(t, "0.AXo+abc/def=", value)
+}
+
+func TestClear(t *testing.T) {
+	codec := authcookie.NewCodec(newKeyRing(t, key("k1", 1)))
+	req, _ := roundTrip(t, codec, http.Cookie{Name: "id_token", Value: strings.Repeat("x", 5000), MaxAge: 3600})
+
+	w := httptest.NewRecorder()
+	codec.Clear(w, req, http.Cookie{Name: "id_token", Path: "/"})
+	cleared := map[string]bool{}
+	for _, cookie := range w.Result().Cookies() {
+		assert.Negative(t, cookie.MaxAge)
+		cleared[cookie.Name] = true
+	}
+	assert.Equal(t, map[string]bool{"id_token": true, "id_token_1": true, "id_token_2": true}, cleared)
+}
@@ -4,9 +4,11 @@ import { DiagramBody, DiagramParams } from '../types/diagramTypes.js';
 import defaultDiagramTemplate from '../templates/defaultDiagram.json' with { type: 'json' };
 import { errors, sendError } from '../utils/errorResponse.js';
 
-/** Retrieves all diagrams with basic metadata (name, createdAt, updatedAt) */
+/** Retrieves all diagrams with basic metadata (name, learningPathId, createdAt, updatedAt) */
 export const getDiagrams = async (_req: Request, res: Response) => {
-  const diagrams = await DiagramModel.find().select('name createdAt updatedAt');
+  const diagrams = await DiagramModel.find().select(
+    'name learningPathId createdAt updatedAt',
+  );
   res.json(diagrams);
 };
 
@@ -1,10 +1,13 @@
 /** Express authentication middleware with local OIDC validation and CBAC enrichment */
 
+import { timingSafeEqual } from 'node:crypto';
 import { Request, Response, NextFunction } from 'express';
 import authService, {
   type AuthenticatedUser,
 } from '../services/authService.js';
+import sessionService, { SESSION_COOKIE } from '../services/sessionService.js';
 import { parseCookies } from '../utils/cookieParser.js';
+import { readAuthCookie } from '../utils/authCookie.js';
 
 /** Checks if test mode is enabled (NODE_ENV === 'development') */
 const isTestModeEnabled = (): boolean => {
@@ -35,25 +38,52 @@ function parseTestUser(req: Request): AuthenticatedUser | null {
   };
 }
 
+/** Identity of the backend's background work (outbox delivery, saga recovery, reconciliation) */
+export const BACKEND_SERVICE_USER: AuthenticatedUser = {
+  entraId: 'service:backend',
+  email: '',
+  name: 'Rosetta backend',
+  community: null,
+  isAdmin: true,
+};
+
+/** Checks whether the Bearer token is INTERNAL_API_SECRET, which the backend sends when no user token is available */
+function isInternalServiceRequest(req: Request): boolean {
+  const secret = process.env.INTERNAL_API_SECRET;
+  const authHeader = req.headers.authorization;
+  if (!secret || !authHeader || !authHeader.startsWith('Bearer ')) {
+    return false;
+  }
+
+  const presented = Buffer.from(authHeader.substring(7));
+  const expected = Buffer.from(secret);
+  return presented.length === expected.length && timingSafeEqual(presented, expected);
+}
+
 /** Express Request extended with authenticated user information */
 export interface AuthenticatedRequest extends Request {
   user?: AuthenticatedUser;
 }
 
-/** Extracts id_token from Authorization header (Bearer) or id_token cookie */
-function extractToken(req: Request): string | null {
+/** Extracts id_token from Authorization header (Bearer), the auth-service session cookie or id_token cookie */
+async function extractToken(req: Request): Promise<string | null> {
   // Try Authorization header first
   const authHeader = req.headers.authorization;
   if (authHeader && authHeader.startsWith('Bearer ')) {
     return authHeader.substring(7);
   }
 
-  // Fall back to id_token cookie
+  // Then the session cookie, whose tokens are kept by auth-service
   const cookies = parseCookies(req.headers.cookie);
-  return cookies['id_token'] || null;
+  if (cookies[SESSION_COOKIE] && sessionService.isEnabled()) {
+    return sessionService.resolveIdToken(cookies[SESSION_COOKIE]);
+  }
+
+  // Fall back to id_token cookie, which auth-service may encrypt and split into chunks
+  return readAuthCookie(cookies, 'id_token');
 }
 
-/** Validates token and attaches authenticated user with CBAC info to request (supports test mode in development) */
+/** Validates token and attaches authenticated user with CBAC info to request (accepts INTERNAL_API_SECRET from the backend; supports test mode in development) */
 export async function authenticateRequest(
   req: Request,
   res: Response,
@@ -70,7 +100,14 @@ export async function authenticateRequest(
     }
   }
 
-  const token = extractToken(req);
+  // Service-to-service: the backend's background work has no user token
+  if (isInternalServiceRequest(req)) {
+    (req as AuthenticatedRequest).user = BACKEND_SERVICE_USER;
+    next();
+    return;
+  }
+
+  const token = await extractToken(req);
 
   if (!token) {
     res.status(401).json({
@@ -3,7 +3,9 @@
 import { IncomingMessage } from 'http';
 import type { WebSocket } from 'ws';
 import authService, { type AuthenticatedUser } from '../services/authService.js';
+import sessionService, { SESSION_COOKIE } from '../services/sessionService.js';
 import { parseCookies } from '../utils/cookieParser.js';
+import { readAuthCookie } from '../utils/authCookie.js';
 
 /** Checks if test mode is enabled (NODE_ENV === 'development') */
 const isTestModeEnabled = (): boolean => {
@@ -58,7 +60,7 @@ function parseTestUserFromUrl(url: string | undefined): AuthenticatedUser | null
   }
 }
 
-/** Authenticates WebSocket upgrade request from id_token cookie (supports test mode in development) */
+/** Authenticates WebSocket upgrade request from the session or id_token cookie (supports test mode in development) */
 export async function authenticateUpgradeRequest(
   req: IncomingMessage,
 ): Promise<AuthenticatedUser | null> {
@@ -72,7 +74,10 @@ export async function authenticateUpgradeRequest(
   }
 
   const cookies = parseCookies(req.headers.cookie);
-  const idToken = cookies['id_token'];
+  const idToken =
+    cookies[SESSION_COOKIE] && sessionService.isEnabled()
+      ? await sessionService.resolveIdToken(cookies[SESSION_COOKIE])
+      : readAuthCookie(cookies, 'id_token');
 
   if (!idToken) {
     console.log('WebSocket auth failed: No id_token provided');
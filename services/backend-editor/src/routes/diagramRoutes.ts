@@ -16,10 +16,11 @@ import {
 const router = Router();
 
 // Apply authentication middleware to ALL routes (Zero Trust)
-// Service-to-service calls must include valid user token for audit trail
+// Service-to-service calls carry the user's token, or INTERNAL_API_SECRET for the backend's
+// background work (outbox delivery, saga recovery, reconciliation)
 router.use(catchAsync(authenticateRequest));
 
-// Service-to-service routes (Zero Trust: authenticated via user token)
+// Service-to-service routes (Zero Trust: authenticated via user token or INTERNAL_API_SECRET)
 // These enforce SAGA patterns - diagrams can only be created/updated/deleted through backend
 // User token provides audit trail of who initiated the operation
 router.post<object, unknown, { learningPathId: string; name?: string }>(
@@ -31,8 +31,8 @@ class AuthService {
 
     const claims = validationResult.claims;
 
-    // Extract user identity
-    const entraId = claims.oid;
+    // Extract user identity (Entra's per-app sub is only used by providers without oid)
+    const entraId = claims.oid || claims.sub;
     const email = claims.email || claims.preferred_username || '';
     const name = claims.name || 'Unknown User';
 
@@ -1,10 +1,10 @@
-/** Local OIDC token validation using Microsoft Entra ID JWKS */
+/** Local OIDC token validation using Microsoft Entra ID JWKS, or another issuer's (OIDC_ISSUER_URL) */
 
 import * as jose from 'jose';
 
 export interface TokenClaims {
   sub: string;
-  oid: string; // Entra ID (Object ID)
+  oid?: string; // Entra ID (Object ID); other providers only have sub
   email?: string;
   preferred_username?: string;
   name?: string;
@@ -26,24 +26,38 @@ class OIDCService {
   private tenantId: string;
   private clientId: string;
   private issuer: string;
+  private jwksUrl: string;
 
   constructor() {
     this.tenantId = process.env.TENANT_ID || '';
     this.clientId = process.env.CLIENT_ID || '';
-    this.issuer = `https://login.microsoftonline.com/${this.tenantId}/v2.0`;
 
-    if (!this.tenantId || !this.clientId) {
+    // OIDC_ISSUER_URL points at another issuer, such as auth-service's development provider
+    const issuerOverride = process.env.OIDC_ISSUER_URL?.replace(/\/$/, '');
+    if (issuerOverride) {
+      this.issuer = issuerOverride;
+      this.jwksUrl = process.env.OIDC_JWKS_URL || `${issuerOverride}/keys`;
+    } else {
+      this.issuer = `https://login.microsoftonline.com/${this.tenantId}/v2.0`;
+      this.jwksUrl = `https://login.microsoftonline.com/${this.tenantId}/discovery/v2.0/keys`;
+    }
+
+    if (!this.isConfigured()) {
       console.warn(
         'OIDC Service: TENANT_ID or CLIENT_ID not configured. Token validation will fail.',
       );
     }
   }
 
+  /** An issuer override stands in for TENANT_ID */
+  private isConfigured(): boolean {
+    return Boolean(this.clientId && (this.tenantId || process.env.OIDC_ISSUER_URL));
+  }
+
   /** Lazily initializes JWKS for token verification */
   private async getJWKS(): Promise<jose.JWTVerifyGetKey> {
     if (!this.jwks) {
-      const jwksUrl = `https://login.microsoftonline.com/${this.tenantId}/discovery/v2.0/keys`;
-      this.jwks = jose.createRemoteJWKSet(new URL(jwksUrl));
+      this.jwks = jose.createRemoteJWKSet(new URL(this.jwksUrl));
     }
     return this.jwks;
   }
@@ -54,7 +68,7 @@ class OIDCService {
       return { valid: false, error: 'No token provided' };
     }
 
-    if (!this.tenantId || !this.clientId) {
+    if (!this.isConfigured()) {
       return {
         valid: false,
         error: 'OIDC not configured: missing TENANT_ID or CLIENT_ID',
@@ -71,9 +85,9 @@ class OIDCService {
 
       const claims = payload as unknown as TokenClaims;
 
-      // Validate required claims
-      if (!claims.oid) {
-        return { valid: false, error: 'Token missing required claim: oid' };
+      // Validate required claims: Entra ID identifies users by oid, other providers by sub
+      if (!claims.oid && !claims.sub) {
+        return { valid: false, error: 'Token missing required claim: oid or sub' };
       }
 
       return {
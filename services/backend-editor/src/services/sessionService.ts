@@ -0,0 +1,74 @@
+/** Resolves auth-service session cookies (rosetta_session) into the session's ID token */
+
+export const SESSION_COOKIE = 'rosetta_session';
+
+/** How long a resolved session is reused, so a burst of requests costs one round trip */
+const CACHE_TTL_MS = 15_000;
+
+interface ResolvedSession {
+  id: string;
+  userId: string;
+  idToken: string;
+  expiresAt: string;
+}
+
+class SessionService {
+  private cache = new Map<string, { idToken: string; expiresAt: number }>();
+
+  /** Sessions are enabled when auth-service shares INTERNAL_API_SECRET with this service */
+  isEnabled(): boolean {
+    return Boolean(process.env.AUTH_SERVICE_URL && process.env.INTERNAL_API_SECRET);
+  }
+
+  /** Returns the ID token of the session, or null if it is unknown, expired or revoked */
+  async resolveIdToken(secret: string): Promise<string | null> {
+    if (!secret || !this.isEnabled()) {
+      return null;
+    }
+
+    const now = Date.now();
+    const cached = this.cache.get(secret);
+    if (cached && now < cached.expiresAt) {
+      return cached.idToken;
+    }
+
+    const baseUrl = process.env.AUTH_SERVICE_URL!.replace(/\/$/, '');
+    try {
+      const response = await fetch(`${baseUrl}/auth/internal/sessions/resolve`, {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+          Authorization: `Bearer ${process.env.INTERNAL_API_SECRET}`,
+        },
+        body: JSON.stringify({ session: secret }),
+        signal: AbortSignal.timeout(5000),
+      });
+      if (!response.ok) {
+        if (response.status !== 404) {
+          console.error(`Session resolve failed with status ${response.status}`);
+        }
+        return null;
+      }
+
+      const session = (await response.json()) as ResolvedSession;
+      for (const [key, entry] of this.cache) {
+        if (now >= entry.expiresAt) this.cache.delete(key);
+      }
+      this.cache.set(secret, {
+        idToken: session.idToken,
+        expiresAt: Math.min(now + CACHE_TTL_MS, Date.parse(session.expiresAt)),
+      });
+      return session.idToken;
+    } catch (error) {
+      console.error('Session resolve failed:', error);
+      return null;
+    }
+  }
+
+  /** Forgets cached sessions (for tests) */
+  clearCache(): void {
+    this.cache.clear();
+  }
+}
+
+export default new SessionService();
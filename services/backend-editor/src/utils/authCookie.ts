@@ -0,0 +1,113 @@
+/**
+ * Reads the token cookies auth-service writes: joined when split into chunks and decrypted with
+ * AUTH_COOKIE_KEYS when set. Mirrors services/authcookie, which writes them.
+ */
+
+import { createDecipheriv } from 'node:crypto';
+
+const SEALED_PREFIX = 'v1.';
+const CHUNKED_PREFIX = 'chunks-';
+const MAX_CHUNKS = 50;
+const NONCE_SIZE = 12;
+const TAG_SIZE = 16;
+
+let parsedSpec: string | undefined;
+let parsedKeys = new Map<string, Buffer>();
+
+/** Parses AUTH_COOKIE_KEYS ("id:base64secret", comma-separated), caching it per value */
+function cookieKeys(): Map<string, Buffer> {
+  const spec = process.env.AUTH_COOKIE_KEYS?.trim() ?? '';
+  if (spec === parsedSpec) {
+    return parsedKeys;
+  }
+
+  const keys = new Map<string, Buffer>();
+  for (const entry of spec.split(',')) {
+    const [id, encoded] = entry.trim().split(':');
+    if (!id || !encoded) {
+      continue;
+    }
+    const secret = Buffer.from(encoded, 'base64');
+    if ([16, 24, 32].includes(secret.length)) {
+      keys.set(id, secret);
+    } else {
+      console.error(`AUTH_COOKIE_KEYS: key ${id} is not 16, 24 or 32 bytes, ignoring it`);
+    }
+  }
+  parsedSpec = spec;
+  parsedKeys = keys;
+  return keys;
+}
+
+/** Whether token cookies are expected to be encrypted */
+export function encryptsAuthCookies(): boolean {
+  return Boolean(process.env.AUTH_COOKIE_KEYS?.trim());
+}
+
+/** Decrypts a value sealed for the cookie called name, or returns null if it is invalid or expired */
+function open(name: string, sealed: string): string | null {
+  if (!sealed.startsWith(SEALED_PREFIX)) {
+    return null;
+  }
+  const rest = sealed.slice(SEALED_PREFIX.length);
+  const dot = rest.indexOf('.');
+  const secret = dot > 0 ? cookieKeys().get(rest.slice(0, dot)) : undefined;
+  if (!secret) {
+    return null;
+  }
+
+  const data = Buffer.from(rest.slice(dot + 1), 'base64url');
+  if (data.length < NONCE_SIZE + TAG_SIZE + 8) {
+    return null;
+  }
+  try {
+    const decipher = createDecipheriv(
+      `aes-${secret.length * 8}-gcm` as 'aes-256-gcm',
+      secret,
+      data.subarray(0, NONCE_SIZE),
+    );
+    decipher.setAAD(Buffer.from(`authcookie:v1:${name}`));
+    decipher.setAuthTag(data.subarray(data.length - TAG_SIZE));
+    const plaintext = Buffer.concat([
+      decipher.update(data.subarray(NONCE_SIZE, data.length - TAG_SIZE)),
+      decipher.final(),
+    ]);
+
+    const expiresAt = Number(plaintext.readBigUInt64BE(0)) * 1000;
+    if (Date.now() >= expiresAt) {
+      return null;
+    }
+    return plaintext.subarray(8).toString('utf8');
+  } catch {
+    return null;
+  }
+}
+
+/** Returns the value of the token cookie called name from parsed cookies, or null if it is missing or invalid */
+export function readAuthCookie(
+  cookies: Record<string, string>,
+  name: string,
+): string | null {
+  let value = cookies[name];
+  if (!value) {
+    return null;
+  }
+
+  if (value.startsWith(CHUNKED_PREFIX)) {
+    const count = Number(value.slice(CHUNKED_PREFIX.length));
+    if (!Number.isInteger(count) || count <= 0 || count > MAX_CHUNKS) {
+      return null;
+    }
+    const chunks: string[] = [];
+    for (let i = 1; i <= count; i++) {
+      const chunk = cookies[`${name}_${i}`];
+      if (chunk === undefined) {
+        return null;
+      }
+      chunks.push(chunk);
+    }
+    value = chunks.join('');
+  }
+
+  return encryptsAuthCookies() ? open(name, value) : value;
+}
@@ -176,6 +176,11 @@ export function clearAuthEnv(): void {
   delete process.env.ADMIN_EMAILS;
   delete process.env.TENANT_ID;
   delete process.env.CLIENT_ID;
+  delete process.env.OIDC_ISSUER_URL;
+  delete process.env.OIDC_JWKS_URL;
+  delete process.env.AUTH_SERVICE_URL;
+  delete process.env.INTERNAL_API_SECRET;
+  delete process.env.AUTH_COOKIE_KEYS;
 }
 
 /**
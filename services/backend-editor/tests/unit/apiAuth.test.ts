@@ -15,8 +15,18 @@ vi.mock('../../src/services/authService.js', () => ({
   },
 }));
 
+vi.mock('../../src/services/sessionService.js', () => ({
+  SESSION_COOKIE: 'rosetta_session',
+  default: {
+    isEnabled: vi.fn(() => false),
+    resolveIdToken: vi.fn(),
+  },
+}));
+
 import authService from '../../src/services/authService.js';
+import sessionService from '../../src/services/sessionService.js';
 import {
+  BACKEND_SERVICE_USER,
   authenticateRequest,
   requireCommunityAccess,
   requireDiagramAccess,
@@ -27,6 +37,7 @@ describe('API Auth Middleware', () => {
   beforeEach(() => {
     vi.clearAllMocks();
     clearAuthEnv();
+    vi.mocked(sessionService.isEnabled).mockReturnValue(false);
   });
 
   afterEach(() => {
@@ -62,6 +73,66 @@ describe('API Auth Middleware', () => {
       expect(next).toHaveBeenCalled();
     });
 
+    it('should authenticate with the ID token of a session cookie', async () => {
+      const mockUser = createMockUser();
+      vi.mocked(sessionService.isEnabled).mockReturnValue(true);
+      vi.mocked(sessionService.resolveIdToken).mockResolvedValue('session-id-token');
+      vi.mocked(authService.authenticateToken).mockResolvedValue({ valid: true, user: mockUser });
+
+      const req = createMockRequest({ cookies: { rosetta_session: 'secret' } }) as Request;
+      const { res } = createMockResponse();
+      const next = createMockNext();
+
+      await authenticateRequest(req, res as Response, next as NextFunction);
+
+      expect(sessionService.resolveIdToken).toHaveBeenCalledWith('secret');
+      expect(authService.authenticateToken).toHaveBeenCalledWith('session-id-token');
+      expect(next).toHaveBeenCalled();
+    });
+
+    it('should return 401 when the session is unknown or revoked', async () => {
+      vi.mocked(sessionService.isEnabled).mockReturnValue(true);
+      vi.mocked(sessionService.resolveIdToken).mockResolvedValue(null);
+
+      const req = createMockRequest({ cookies: { rosetta_session: 'revoked' } }) as Request;
+      const { res, statusSpy } = createMockResponse();
+      const next = createMockNext();
+
+      await authenticateRequest(req, res as Response, next as NextFunction);
+
+      expect(statusSpy).toHaveBeenCalledWith(401);
+      expect(next).not.toHaveBeenCalled();
+    });
+
+    it('should authenticate the backend with INTERNAL_API_SECRET', async () => {
+      process.env.INTERNAL_API_SECRET = 'internal-secret';
+
+      const req = createMockRequest({ bearerToken: 'internal-secret' }) as Request;
+      const { res } = createMockResponse();
+      const next = createMockNext();
+
+      await authenticateRequest(req, res as Response, next as NextFunction);
+
+      expect((req as AuthenticatedRequest).user).toEqual(BACKEND_SERVICE_USER);
+      expect(authService.authenticateToken).not.toHaveBeenCalled();
+      expect(next).toHaveBeenCalled();
+    });
+
+    it('should validate other Bearer tokens as ID tokens when INTERNAL_API_SECRET is set', async () => {
+      process.env.INTERNAL_API_SECRET = 'internal-secret';
+      vi.mocked(authService.authenticateToken).mockResolvedValue({ valid: false, error: 'Invalid token' });
+
+      const req = createMockRequest({ bearerToken: 'internal-secret-guess' }) as Request;
+      const { res, statusSpy } = createMockResponse();
+      const next = createMockNext();
+
+      await authenticateRequest(req, res as Response, next as NextFunction);
+
+      expect(authService.authenticateToken).toHaveBeenCalledWith('internal-secret-guess');
+      expect(statusSpy).toHaveBeenCalledWith(401);
+      expect(next).not.toHaveBeenCalled();
+    });
+
     it('should return 401 when no token provided', async () => {
       const req = createMockRequest() as Request;
       const { res, statusSpy } = createMockResponse();
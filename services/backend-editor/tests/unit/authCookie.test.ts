@@ -0,0 +1,57 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { createCipheriv, randomBytes } from 'node:crypto';
+import { clearAuthEnv } from '../helpers/authHelpers.js';
+import { readAuthCookie } from '../../src/utils/authCookie.js';
+
+const secret = Buffer.alloc(32, 7);
+
+/** Seals value the way auth-service does (services/authcookie) */
+function seal(name: string, value: string, expiresInSeconds = 3600, keyId = 'k1'): string {
+  const nonce = randomBytes(12);
+  const expiry = Buffer.alloc(8);
+  expiry.writeBigUInt64BE(BigInt(Math.floor(Date.now() / 1000) + expiresInSeconds));
+
+  const cipher = createCipheriv('aes-256-gcm', secret, nonce);
+  cipher.setAAD(Buffer.from(`authcookie:v1:${name}`));
+  const ciphertext = Buffer.concat([cipher.update(Buffer.concat([expiry, Buffer.from(value)])), cipher.final()]);
+  return `v1.${keyId}.${Buffer.concat([nonce, ciphertext, cipher.getAuthTag()]).toString('base64url')}`;
+}
+
+describe('readAuthCookie', () => {
+  beforeEach(() => clearAuthEnv());
+  afterEach(() => clearAuthEnv());
+
+  it('should return plain values without AUTH_COOKIE_KEYS', () => {
+    expect(readAuthCookie({ id_token: 'eyJ.token' }, 'id_token')).toBe('eyJ.token');
+    expect(readAuthCookie({}, 'id_token')).toBeNull();
+  });
+
+  it('should decrypt values sealed with a configured key', () => {
+    process.env.AUTH_COOKIE_KEYS = `new:${Buffer.alloc(32, 1).toString('base64')},k1:${secret.toString('base64')}`;
+    expect(readAuthCookie({ id_token: seal('id_token', 'eyJ.token') }, 'id_token')).toBe('eyJ.token');
+  });
+
+  it('should join chunked values', () => {
+    process.env.AUTH_COOKIE_KEYS = `k1:${secret.toString('base64')}`;
+    const token = 'x'.repeat(9000);
+    const sealed = seal('id_token', token);
+    const cookies: Record<string, string> = { id_token: 'chunks-3' };
+    const size = Math.ceil(sealed.length / 3);
+    for (let i = 0; i < 3; i++) {
+      cookies[`id_token_${i + 1}`] = sealed.slice(i * size, (i + 1) * size);
+    }
+
+    expect(readAuthCookie(cookies, 'id_token')).toBe(token);
+
+    delete cookies.id_token_2;
+    expect(readAuthCookie(cookies, 'id_token')).toBeNull();
+  });
+
+  it('should reject plain, expired, foreign and unknown-key values when keys are set', () => {
+    process.env.AUTH_COOKIE_KEYS = `k1:${secret.toString('base64')}`;
+    expect(readAuthCookie({ id_token: 'eyJ.plain.jwt' }, 'id_token')).toBeNull();
+    expect(readAuthCookie({ id_token: seal('id_token', 'token', -1) }, 'id_token')).toBeNull();
+    expect(readAuthCookie({ id_token: seal('graph_access_token', 'token') }, 'id_token')).toBeNull();
+    expect(readAuthCookie({ id_token: seal('id_token', 'token', 3600, 'retired') }, 'id_token')).toBeNull();
+  });
+});
@@ -76,6 +76,18 @@ describe('OIDC Service', () => {
       expect(result.claims?.email).toBe(mockClaims.email);
     });
 
+    it('should accept tokens from providers without oid by their sub', async () => {
+      setupOIDCEnv({ clientId: 'rosetta' });
+      process.env.OIDC_ISSUER_URL = 'https://keycloak.example.com/realms/rosetta';
+      mockValidToken(createMockClaims({ oid: undefined, sub: 'kc-user-1' }));
+
+      const oidcService = await importOIDCService();
+      const result = await oidcService.validateToken('keycloak-token');
+
+      expect(result.valid).toBe(true);
+      expect(result.claims?.sub).toBe('kc-user-1');
+    });
+
     it('should return error when token is empty', async () => {
       setupOIDCEnv({ tenantId: 'test-tenant', clientId: 'test-client' });
 
@@ -96,6 +108,24 @@ describe('OIDC Service', () => {
       expect(result.error).toContain('OIDC not configured');
     });
 
+    it('should verify against OIDC_ISSUER_URL when set', async () => {
+      setupOIDCEnv({ clientId: 'rosetta-dev' });
+      process.env.OIDC_ISSUER_URL = 'http://localhost:3002/auth/dev/';
+      mockValidToken();
+
+      const oidcService = await importOIDCService();
+      const result = await oidcService.validateToken('dev-token');
+
+      expect(result.valid).toBe(true);
+      expect(jose.createRemoteJWKSet).toHaveBeenCalledWith(
+        new URL('http://localhost:3002/auth/dev/keys'),
+      );
+      expect(jose.jwtVerify).toHaveBeenCalledWith('dev-token', 'mock-jwks', {
+        issuer: 'http://localhost:3002/auth/dev',
+        audience: 'rosetta-dev',
+      });
+    });
+
     it('should return error for expired token', async () => {
       setupOIDCEnv({ tenantId: 'test-tenant', clientId: 'test-client' });
       vi.mocked(jose.jwtVerify).mockRejectedValue(new jose.errors.JWTExpired('Token expired'));
@@ -0,0 +1,61 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { clearAuthEnv } from '../helpers/authHelpers.js';
+import sessionService from '../../src/services/sessionService.js';
+
+const jsonResponse = (status: number, body: unknown) =>
+  ({ ok: status >= 200 && status < 300, status, json: async () => body }) as Response;
+
+describe('SessionService', () => {
+  const fetchMock = vi.fn();
+
+  beforeEach(() => {
+    clearAuthEnv();
+    sessionService.clearCache();
+    fetchMock.mockReset();
+    vi.stubGlobal('fetch', fetchMock);
+  });
+
+  afterEach(() => {
+    vi.unstubAllGlobals();
+    clearAuthEnv();
+  });
+
+  it('should be disabled without AUTH_SERVICE_URL and INTERNAL_API_SECRET', async () => {
+    expect(sessionService.isEnabled()).toBe(false);
+    expect(await sessionService.resolveIdToken('secret')).toBeNull();
+    expect(fetchMock).not.toHaveBeenCalled();
+  });
+
+  it('should resolve the session through auth-service and cache it', async () => {
+    process.env.AUTH_SERVICE_URL = 'http://auth-service:3002/';
+    process.env.INTERNAL_API_SECRET = 'internal';
+    fetchMock.mockResolvedValue(
+      jsonResponse(200, {
+        id: 'session-id',
+        userId: 'user-oid',
+        idToken: 'id-token',
+        expiresAt: new Date(Date.now() + 3600_000).toISOString(),
+      }),
+    );
+
+    expect(await sessionService.resolveIdToken('secret')).toBe('id-token');
+    expect(await sessionService.resolveIdToken('secret')).toBe('id-token');
+
+    expect(fetchMock).toHaveBeenCalledTimes(1);
+    const [url, init] = fetchMock.mock.calls[0];
+    expect(url).toBe('http://auth-service:3002/auth/internal/sessions/resolve');
+    expect(init.headers.Authorization).toBe('Bearer internal');
+    expect(JSON.parse(init.body)).toEqual({ session: 'secret' });
+  });
+
+  it('should return null for unknown sessions and unreachable auth-service', async () => {
+    process.env.AUTH_SERVICE_URL = 'http://auth-service:3002';
+    process.env.INTERNAL_API_SECRET = 'internal';
+
+    fetchMock.mockResolvedValueOnce(jsonResponse(404, { error: 'Session not found' }));
+    expect(await sessionService.resolveIdToken('revoked')).toBeNull();
+
+    fetchMock.mockRejectedValueOnce(new Error('ECONNREFUSED'));
+    expect(await sessionService.resolveIdToken('secret')).toBeNull();
+  });
+});
@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/authcookie"
 	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/controller"
 	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/initializer"
 	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/middleware"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
 	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
 	env "dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/pkg"
 )
@@ -30,23 +35,119 @@ func main() {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     allowOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept", "X-Requested-With"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept", "X-Requested-With", middleware.RequestIDHeader, controller.IdempotencyKeyHeader},
+		ExposeHeaders:    []string{middleware.RequestIDHeader, controller.IdempotentReplayedHeader},
 		AllowCredentials: true,
 	}))
 
+	// Tag every request with an ID and render handler errors as problem+json
+	r.Use(middleware.RequestID(), middleware.ErrorHandler())
+
 	// Initialize services
 	userService := service.NewUserService(initializer.DB)
 	learningPathService := service.NewLearningPathService(initializer.DB)
-	communityService := service.NewCommunityService()
+	communityService := service.NewCommunityService(initializer.DB)
+	roleService := service.NewRoleService(initializer.DB)
+	idempotencyService := service.NewIdempotencyService(initializer.DB)
+	searchService := service.NewSearchService(initializer.DB)
+
+	// Resume or compensate sagas interrupted by a crash or rolling deploy
+	sagaRecoveryInterval := 60 * time.Second
+	if v, err := strconv.Atoi(os.Getenv("SAGA_RECOVERY_INTERVAL_SECONDS")); err == nil && v > 0 {
+		sagaRecoveryInterval = time.Duration(v) * time.Second
+	}
+	sagaRecoverer := service.NewSagaRecoverer(learningPathService)
+	go sagaRecoverer.Start(context.Background(), sagaRecoveryInterval)
+
+	// Deliver diagram renames and deletes written to the outbox; writes wake it immediately
+	outboxPollInterval := 5 * time.Second
+	if v, err := strconv.Atoi(os.Getenv("OUTBOX_POLL_INTERVAL_SECONDS")); err == nil && v > 0 {
+		outboxPollInterval = time.Duration(v) * time.Second
+	}
+	outboxDispatcher := service.NewOutboxDispatcher(learningPathService)
+	learningPathService.OnOutboxEnqueued = outboxDispatcher.Wake
+	go outboxDispatcher.Start(context.Background(), outboxPollInterval)
+
+	// Detect drift between learning paths and backend-editor diagrams (report-only unless auto-repair is on)
+	reconciler := service.NewReconciler(learningPathService)
+	if v, err := strconv.Atoi(os.Getenv("RECONCILE_INTERVAL_MINUTES")); err == nil && v > 0 {
+		autoRepair := os.Getenv("RECONCILE_AUTO_REPAIR") == "true"
+		go reconciler.Start(context.Background(), time.Duration(v)*time.Minute, autoRepair)
+	}
+
+	// Reuse verified tokens and user lookups for a short while (AUTH_CACHE_TTL_SECONDS=0 disables)
+	authCacheTTL := 60 * time.Second
+	if v, err := strconv.Atoi(os.Getenv("AUTH_CACHE_TTL_SECONDS")); err == nil && v >= 0 {
+		authCacheTTL = time.Duration(v) * time.Second
+	}
+	mappingService := service.NewCommunityMappingService(initializer.DB)
+	var authCache *service.AuthCache
+	if authCacheTTL > 0 {
+		authCache = service.NewAuthCache(authCacheTTL, 10000)
+		userService.OnUserChanged = func(user *model.User) { authCache.InvalidateUser(user.EntraID) }
+		roleService.OnRolesChanged = authCache.InvalidateUser
+		roleService.OnClaimMappingsChanged = authCache.Clear
+		communityService.OnRolesChanged = authCache.InvalidateUser
+		mappingService.OnMappingsChanged = authCache.Clear
+	}
+
+	// Verify ID tokens against Entra ID, another OIDC issuer or static keys (AUTH_VERIFIER)
+	tokenVerifier, err := middleware.NewTokenVerifierFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid token verifier configuration: %v", err)
+	}
+
+	// Resolve auth-service session cookies when sessions are enabled there, and reject sessions,
+	// tokens and users signed out in auth-service (INTERNAL_API_SECRET)
+	var sessionResolver middleware.SessionResolver
+	var revocationList *middleware.RemoteRevocationList
+	var revocations middleware.RevocationChecker
+	if secret := os.Getenv("INTERNAL_API_SECRET"); secret != "" {
+		sessionResolver = middleware.NewRemoteSessionResolver(os.Getenv("AUTH_SERVICE_URL"), secret, 15*time.Second)
+
+		revocationInterval := 10 * time.Second
+		if v, err := strconv.Atoi(os.Getenv("REVOCATION_POLL_INTERVAL_SECONDS")); err == nil && v > 0 {
+			revocationInterval = time.Duration(v) * time.Second
+		}
+		revocationList = middleware.NewRemoteRevocationList(os.Getenv("AUTH_SERVICE_URL"), secret)
+		revocations = revocationList
+		go revocationList.Start(context.Background(), revocationInterval)
+	} else {
+		log.Print("INTERNAL_API_SECRET not set, tokens signed out in auth-service are accepted until they expire")
+		log.Print("INTERNAL_API_SECRET not set, background calls to backend-editor (outbox, saga recovery, reconciliation) are rejected")
+	}
+
+	// Read the token cookies auth-service writes, encrypted with AUTH_COOKIE_KEYS
+	authCookies, err := authcookie.NewCodecFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid auth cookie configuration: %v", err)
+	}
+	if !authCookies.Encrypts() {
+		log.Print("AUTH_COOKIE_KEYS not set, token cookies are read unencrypted")
+	}
+
+	// Write users' last Graph sync times in batches rather than on every request
+	userService.GraphSyncs = service.NewGraphSyncBatcher(initializer.DB)
+	go userService.GraphSyncs.Start(context.Background(), 10*time.Second)
+
+	// Forget Idempotency-Key responses past their TTL
+	go idempotencyService.StartPurge(context.Background(), time.Hour)
 
 	// Initialize controllers
 	userController := controller.NewUserController(userService)
-	lpController := controller.NewLearningPathController(learningPathService)
+	lpController := controller.NewLearningPathController(learningPathService, idempotencyService)
 	communityController := controller.NewCommunityController(communityService)
+	adminController := controller.NewAdminController(userService, reconciler, communityService, mappingService, roleService)
+	adminController.Revocations = revocationList
+	healthController := controller.NewHealthController(learningPathService.EditorBreaker, authCache)
+	searchController := controller.NewSearchController(searchService)
+
+	// Public health check (no authentication) for probes and dashboards
+	r.GET("/api/health", healthController.Get)
 
 	// Protected routes - all require authentication
 	protected := r.Group("/")
-	protected.Use(middleware.Auth())
+	protected.Use(middleware.Auth(tokenVerifier, sessionResolver, revocations, authCookies, userService, authCache))
 	{
 
 		// User API
@@ -69,6 +170,45 @@ func main() {
 		protected.GET("/api/learning-paths/favorites", lpController.GetUserFavorites)
 		protected.POST("/api/learning-paths/:id/favorite", lpController.AddToFavorites)
 		protected.DELETE("/api/learning-paths/:id/favorite", lpController.RemoveFromFavorites)
+		// LP collaborators and their per-path roles
+		protected.GET("/api/learning-paths/:id/collaborators", lpController.GetCollaborators)
+		protected.POST("/api/learning-paths/:id/collaborators", lpController.SetCollaborator)
+		protected.DELETE("/api/learning-paths/:id/collaborators/:userId", lpController.RemoveCollaborator)
+		// LP moderation by community moderators and admins
+		protected.POST("/api/learning-paths/:id/feature", lpController.Feature)
+		protected.DELETE("/api/learning-paths/:id/feature", lpController.Unfeature)
+		protected.POST("/api/learning-paths/:id/publication/approve", lpController.ApprovePublication)
+		protected.POST("/api/learning-paths/:id/publication/reject", lpController.RejectPublication)
+
+		// Search API
+		protected.GET("/api/search", searchController.Search)
+
+		// Admin API - auditors may read, only admins may change anything
+		admin := protected.Group("/api/admin")
+		readAdmin := middleware.RequireRole(model.PlatformRoleAdmin, model.PlatformRoleAuditor)
+		writeAdmin := middleware.RequireRole(model.PlatformRoleAdmin)
+		admin.POST("/reconcile", writeAdmin, adminController.Reconcile)
+		admin.GET("/communities", readAdmin, adminController.GetCommunities)
+		admin.POST("/communities", writeAdmin, adminController.CreateCommunity)
+		admin.PUT("/communities/:id", writeAdmin, adminController.UpdateCommunity)
+		admin.POST("/communities/:id/archive", writeAdmin, adminController.ArchiveCommunity)
+		admin.DELETE("/communities/:id/archive", writeAdmin, adminController.RestoreCommunity)
+		admin.GET("/communities/:id/moderators", readAdmin, adminController.GetModerators)
+		admin.POST("/communities/:id/moderators", writeAdmin, adminController.AddModerator)
+		admin.DELETE("/communities/:id/moderators/:userId", writeAdmin, adminController.RemoveModerator)
+		admin.GET("/community-mappings", readAdmin, adminController.GetCommunityMappings)
+		admin.POST("/community-mappings", writeAdmin, adminController.AddCommunityMapping)
+		admin.DELETE("/community-mappings/:id", writeAdmin, adminController.RemoveCommunityMapping)
+		admin.POST("/community-mappings/test", writeAdmin, adminController.TestCommunityMappings)
+		admin.GET("/community-mappings/audit", readAdmin, adminController.GetCommunityMappingAudit)
+		// Support staff look up roles to answer access questions
+		admin.GET("/roles", middleware.RequireRole(model.PlatformRoleAdmin, model.PlatformRoleAuditor, model.PlatformRoleSupport), adminController.GetRoles)
+		admin.POST("/roles", writeAdmin, adminController.GrantRole)
+		admin.DELETE("/roles/:userId/:role", writeAdmin, adminController.RevokeRole)
+		admin.POST("/users/:userId/sign-out", writeAdmin, adminController.SignOutUser)
+		admin.GET("/role-claim-mappings", readAdmin, adminController.GetRoleClaimMappings)
+		admin.POST("/role-claim-mappings", writeAdmin, adminController.AddRoleClaimMapping)
+		admin.DELETE("/role-claim-mappings/:id", writeAdmin, adminController.RemoveRoleClaimMapping)
 	}
 
 	if err := r.Run(":8080"); err != nil {
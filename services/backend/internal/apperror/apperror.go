@@ -0,0 +1,145 @@
+// Package apperror defines the domain errors returned by services.
+//
+// Services return *Error values instead of ad-hoc strings; the ErrorHandler middleware
+// maps them to HTTP status codes and renders them as RFC 7807 problem details, so
+// controllers and clients never have to inspect error messages.
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Kind classifies an error and determines the HTTP status it is rendered with
+type Kind string
+
+const (
+	KindValidation       Kind = "validation"
+	KindUnauthorized     Kind = "unauthorized"
+	KindForbidden        Kind = "forbidden"
+	KindNotFound         Kind = "not_found"
+	KindConflict         Kind = "conflict"
+	KindDependencyFailed Kind = "dependency_failed"
+	KindInternal         Kind = "internal"
+)
+
+// Status returns the HTTP status code for the kind
+func (k Kind) Status() int {
+	switch k {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindDependencyFailed:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// FieldError describes why a single request field failed validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error is a domain error. Code is a stable, machine-readable identifier
+// (e.g. "learning_path_not_found") that clients branch on; Message is safe to show to users.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  []FieldError
+	// Status overrides the status derived from Kind (optional)
+	Status int
+	// Err is the underlying cause; it is logged but never sent to clients
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatus returns the status code the error is rendered with
+func (e *Error) HTTPStatus() int {
+	if e.Status != 0 {
+		return e.Status
+	}
+	return e.Kind.Status()
+}
+
+// Wrap returns a copy of e with err as its cause
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// WithStatus returns a copy of e that is rendered with status
+func (e *Error) WithStatus(status int) *Error {
+	copied := *e
+	copied.Status = status
+	return &copied
+}
+
+func NotFound(code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+func Conflict(code, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message}
+}
+
+func Unauthorized(code, message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message}
+}
+
+// Validation reports invalid input, optionally with per-field details
+func Validation(code, message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message, Fields: fields}
+}
+
+// DependencyFailed reports that a downstream service (backend-editor, Microsoft Graph, ...)
+// failed or could not be reached
+func DependencyFailed(code, message string, err error) *Error {
+	return &Error{Kind: KindDependencyFailed, Code: code, Message: message, Err: err}
+}
+
+// Internal wraps an unexpected error; its details are never shown to clients
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Code: "internal_error", Message: "An unexpected error occurred", Err: err}
+}
+
+// From returns the *Error in err's chain, or wraps err as an internal error
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
+
+// IsKind reports whether err's chain contains an *Error of the given kind
+func IsKind(err error, kind Kind) bool {
+	var appErr *Error
+	return errors.As(err, &appErr) && appErr.Kind == kind
+}
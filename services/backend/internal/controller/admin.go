@@ -0,0 +1,478 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/middleware"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminController serves /api/admin. Its routes are guarded by middleware.RequireRole, so
+// handlers do not check roles themselves.
+type AdminController struct {
+	UserService      *service.UserService
+	Reconciler       *service.Reconciler
+	CommunityService *service.CommunityService
+	MappingService   *service.CommunityMappingService
+	RoleService      *service.RoleService
+	GraphService     *service.GraphService
+	// Revocations signs users out through auth-service; nil when INTERNAL_API_SECRET is not set
+	Revocations *middleware.RemoteRevocationList
+}
+
+func NewAdminController(userService *service.UserService, reconciler *service.Reconciler, communityService *service.CommunityService, mappingService *service.CommunityMappingService, roleService *service.RoleService) *AdminController {
+	return &AdminController{
+		UserService:      userService,
+		Reconciler:       reconciler,
+		CommunityService: communityService,
+		MappingService:   mappingService,
+		RoleService:      roleService,
+		GraphService:     service.NewGraphService(),
+	}
+}
+
+// Reconcile compares learning paths with backend-editor diagrams and repairs drift
+// POST /api/admin/reconcile?dryRun=true
+func (ctrl *AdminController) Reconcile(c *gin.Context) {
+	// Default to dry-run so an accidental call never mutates data
+	dryRun := true
+	if v := c.Query("dryRun"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			abortWithError(c, apperror.Validation("invalid_query", "dryRun must be true or false",
+				apperror.FieldError{Field: "dryRun", Code: "boolean", Message: "dryRun must be true or false"}).Wrap(err))
+			return
+		}
+		dryRun = parsed
+	}
+
+	report, err := ctrl.Reconciler.Reconcile(c.Request.Context(), dryRun)
+	if err != nil {
+		abortWithError(c, apperror.DependencyFailed("reconcile_failed", "Failed to reconcile learning paths with diagrams", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// CommunityRequest is the body of community create and update requests. Omitted fields are
+// left unchanged on update; a new community gets a slug derived from its name.
+type CommunityRequest struct {
+	Name             *string `json:"name" binding:"omitempty,max=100"`
+	Slug             *string `json:"slug" binding:"omitempty,max=100"`
+	Description      *string `json:"description"`
+	Icon             *string `json:"icon" binding:"omitempty,max=100"`
+	Color            *string `json:"color" binding:"omitempty,hexcolor"`
+	RequiresApproval *bool   `json:"requiresApproval"` // Keep new public paths private until a moderator approves them
+}
+
+func (req CommunityRequest) input() service.CommunityInput {
+	return service.CommunityInput{
+		Name:             req.Name,
+		Slug:             req.Slug,
+		Description:      req.Description,
+		Icon:             req.Icon,
+		Color:            req.Color,
+		RequiresApproval: req.RequiresApproval,
+	}
+}
+
+// GetCommunities lists all communities, archived ones included
+// GET /api/admin/communities
+func (ctrl *AdminController) GetCommunities(c *gin.Context) {
+	communities, err := ctrl.CommunityService.GetCommunities(c.Request.Context(), true)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"communities": communities})
+}
+
+// CreateCommunity adds a community
+// POST /api/admin/communities
+func (ctrl *AdminController) CreateCommunity(c *gin.Context) {
+	var req CommunityRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	community, err := ctrl.CommunityService.CreateCommunity(c.Request.Context(), req.input())
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, community)
+}
+
+// UpdateCommunity renames a community or changes its slug, description, icon or colour
+// PUT /api/admin/communities/:id
+func (ctrl *AdminController) UpdateCommunity(c *gin.Context) {
+	var req CommunityRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	community, err := ctrl.CommunityService.UpdateCommunity(c.Request.Context(), c.Param("id"), req.input())
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, community)
+}
+
+// ArchiveCommunity archives a community, which then accepts no new learning paths
+// POST /api/admin/communities/:id/archive
+func (ctrl *AdminController) ArchiveCommunity(c *gin.Context) {
+	ctrl.setCommunityArchived(c, true)
+}
+
+// RestoreCommunity makes an archived community accept new learning paths again
+// DELETE /api/admin/communities/:id/archive
+func (ctrl *AdminController) RestoreCommunity(c *gin.Context) {
+	ctrl.setCommunityArchived(c, false)
+}
+
+func (ctrl *AdminController) setCommunityArchived(c *gin.Context, archived bool) {
+	community, err := ctrl.CommunityService.SetCommunityArchived(c.Request.Context(), c.Param("id"), archived)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, community)
+}
+
+// AddModeratorRequest names the user to appoint as a community moderator
+type AddModeratorRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// GetModerators lists the moderators of a community
+// GET /api/admin/communities/:id/moderators
+func (ctrl *AdminController) GetModerators(c *gin.Context) {
+	moderators, err := ctrl.CommunityService.GetModerators(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"moderators": moderators})
+}
+
+// AddModerator appoints a user as a moderator of a community
+// POST /api/admin/communities/:id/moderators
+func (ctrl *AdminController) AddModerator(c *gin.Context) {
+	var req AddModeratorRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	moderator, err := ctrl.CommunityService.AddModerator(c.Request.Context(), c.Param("id"), req.Email)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, moderator)
+}
+
+// RemoveModerator takes away a user's moderator role in a community
+// DELETE /api/admin/communities/:id/moderators/:userId
+func (ctrl *AdminController) RemoveModerator(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 0)
+	if err != nil {
+		abortWithError(c, apperror.Validation("invalid_user_id", "Invalid user ID",
+			apperror.FieldError{Field: "userId", Code: "number", Message: "userId must be a number"}).Wrap(err))
+		return
+	}
+
+	if err := ctrl.CommunityService.RemoveModerator(c.Request.Context(), c.Param("id"), uint(userID)); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CommunityMappingRequest maps an Entra ID group to a community
+type CommunityMappingRequest struct {
+	GroupID   string `json:"groupId" binding:"required,max=100"`
+	GroupName string `json:"groupName" binding:"max=200"`
+	Community string `json:"community" binding:"required"` // Name or slug
+	Priority  int    `json:"priority"`                     // Lower wins when a user matches several groups
+}
+
+// TestCommunityMappingRequest names the user to test, or the groups to test directly
+type TestCommunityMappingRequest struct {
+	User     string   `json:"user"`     // Email or Entra object ID; groups are read from Microsoft Graph
+	GroupIDs []string `json:"groupIds"` // Used instead of user when given
+}
+
+// GetCommunityMappings lists the group mappings in priority order
+// GET /api/admin/community-mappings
+func (ctrl *AdminController) GetCommunityMappings(c *gin.Context) {
+	mappings, err := ctrl.MappingService.GetMappings(c.Request.Context())
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mappings": mappings})
+}
+
+// AddCommunityMapping maps an Entra ID group to a community
+// POST /api/admin/community-mappings
+func (ctrl *AdminController) AddCommunityMapping(c *gin.Context) {
+	admin := getUserFromContext(c)
+	if admin == nil {
+		return
+	}
+
+	var req CommunityMappingRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	mapping, err := ctrl.MappingService.AddMapping(c.Request.Context(), admin, req.GroupID, req.GroupName, req.Community, req.Priority)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, mapping)
+}
+
+// RemoveCommunityMapping deletes a group mapping
+// DELETE /api/admin/community-mappings/:id
+func (ctrl *AdminController) RemoveCommunityMapping(c *gin.Context) {
+	admin := getUserFromContext(c)
+	if admin == nil {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		abortWithError(c, apperror.Validation("invalid_mapping_id", "Invalid community mapping ID",
+			apperror.FieldError{Field: "id", Code: "uint", Message: "id must be a positive integer"}).Wrap(err))
+		return
+	}
+
+	if err := ctrl.MappingService.RemoveMapping(c.Request.Context(), admin, uint(id)); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// TestCommunityMappings shows which communities a user would be assigned to, and through which
+// mappings, without changing anything
+// POST /api/admin/community-mappings/test
+func (ctrl *AdminController) TestCommunityMappings(c *gin.Context) {
+	var req TestCommunityMappingRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	groupIDs := req.GroupIDs
+	if len(groupIDs) == 0 {
+		if req.User == "" {
+			abortWithError(c, apperror.Validation("validation_failed", "One or more fields are invalid",
+				apperror.FieldError{Field: "user", Code: "required", Message: "user or groupIds is required"}))
+			return
+		}
+
+		graphAccessToken, ok := getGraphAccessToken(c)
+		if !ok {
+			return
+		}
+		groups, err := ctrl.GraphService.GetGroupsOfUser(c.Request.Context(), graphAccessToken, req.User)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		for _, group := range groups {
+			groupIDs = append(groupIDs, group.ID)
+		}
+	}
+
+	match, err := ctrl.MappingService.MatchGroups(c.Request.Context(), groupIDs)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"groupIds": groupIDs, "community": match.Community, "communities": match.Communities, "matches": match.Matches})
+}
+
+// GetCommunityMappingAudit lists mapping changes, newest first
+// GET /api/admin/community-mappings/audit?limit=50
+func (ctrl *AdminController) GetCommunityMappingAudit(c *gin.Context) {
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > service.MaxMappingAuditLimit {
+			abortWithError(c, apperror.Validation("invalid_query", "Invalid audit query",
+				apperror.FieldError{Field: "limit", Code: "range", Message: "limit must be between 1 and " + strconv.Itoa(service.MaxMappingAuditLimit)}))
+			return
+		}
+		limit = parsed
+	}
+
+	entries, err := ctrl.MappingService.GetAudit(c.Request.Context(), limit)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
+
+// GrantRoleRequest names the user to give a platform role
+type GrantRoleRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required"`
+}
+
+// RoleClaimMappingRequest grants a platform role to everyone whose ID token carries a claim value
+type RoleClaimMappingRequest struct {
+	Claim string `json:"claim" binding:"required"` // "groups" or "roles"
+	Value string `json:"value" binding:"required,max=200"`
+	Role  string `json:"role" binding:"required"`
+}
+
+// GetRoles lists the stored platform role assignments
+// GET /api/admin/roles
+func (ctrl *AdminController) GetRoles(c *gin.Context) {
+	assignments, err := ctrl.RoleService.ListRoleAssignments(c.Request.Context())
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": model.PlatformRoles, "assignments": assignments})
+}
+
+// GrantRole gives a user a platform role
+// POST /api/admin/roles
+func (ctrl *AdminController) GrantRole(c *gin.Context) {
+	admin := getUserFromContext(c)
+	if admin == nil {
+		return
+	}
+
+	var req GrantRoleRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	assignment, err := ctrl.RoleService.GrantRole(c.Request.Context(), admin, req.Email, req.Role)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, assignment)
+}
+
+// RevokeRole takes a platform role away from a user
+// DELETE /api/admin/roles/:userId/:role
+func (ctrl *AdminController) RevokeRole(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 0)
+	if err != nil {
+		abortWithError(c, apperror.Validation("invalid_user_id", "Invalid user ID",
+			apperror.FieldError{Field: "userId", Code: "number", Message: "userId must be a number"}).Wrap(err))
+		return
+	}
+
+	if err := ctrl.RoleService.RevokeRole(c.Request.Context(), uint(userID), c.Param("role")); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetRoleClaimMappings lists the token claims that grant platform roles
+// GET /api/admin/role-claim-mappings
+func (ctrl *AdminController) GetRoleClaimMappings(c *gin.Context) {
+	mappings, err := ctrl.RoleService.GetClaimMappings(c.Request.Context())
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mappings": mappings})
+}
+
+// AddRoleClaimMapping grants a platform role to everyone in an Entra group or app role
+// POST /api/admin/role-claim-mappings
+func (ctrl *AdminController) AddRoleClaimMapping(c *gin.Context) {
+	var req RoleClaimMappingRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	mapping, err := ctrl.RoleService.AddClaimMapping(c.Request.Context(), req.Claim, req.Value, req.Role)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, mapping)
+}
+
+// RemoveRoleClaimMapping deletes a role claim mapping
+// DELETE /api/admin/role-claim-mappings/:id
+func (ctrl *AdminController) RemoveRoleClaimMapping(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		abortWithError(c, apperror.Validation("invalid_mapping_id", "Invalid role claim mapping ID",
+			apperror.FieldError{Field: "id", Code: "uint", Message: "id must be a positive integer"}).Wrap(err))
+		return
+	}
+
+	if err := ctrl.RoleService.RemoveClaimMapping(c.Request.Context(), uint(id)); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SignOutUser ends all of a user's sessions and revokes every token issued to them so far, e.g.
+// for a lost laptop or a compromised account. The user can sign in again afterwards.
+// POST /api/admin/users/:userId/sign-out
+func (ctrl *AdminController) SignOutUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 0)
+	if err != nil {
+		abortWithError(c, apperror.Validation("invalid_user_id", "Invalid user ID",
+			apperror.FieldError{Field: "userId", Code: "number", Message: "userId must be a number"}).Wrap(err))
+		return
+	}
+	if ctrl.Revocations == nil {
+		abortWithError(c, apperror.DependencyFailed("sign_out_unavailable", "Signing users out requires INTERNAL_API_SECRET", nil).WithStatus(http.StatusServiceUnavailable))
+		return
+	}
+
+	user, err := ctrl.UserService.GetUserByID(uint(userID))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	ended, err := ctrl.Revocations.SignOutUser(c.Request.Context(), user.EntraID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"userId": user.ID, "sessionsEnded": ended})
+}
@@ -17,9 +17,14 @@ func NewCommunityController(communityService *service.CommunityService) *Communi
 	}
 }
 
+// GetCommunities lists the communities that are not archived
+// GET /api/communities
 func (ctrl *CommunityController) GetCommunities(c *gin.Context) {
-	// Pass the request context for potential future use (logging, tracing, cancellation)
-	communities := ctrl.CommunityService.GetCommunities(c.Request.Context())
+	communities, err := ctrl.CommunityService.GetCommunities(c.Request.Context(), false)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
 
 	// gin.H is shorthand for map[string]interface{}
 	c.JSON(http.StatusOK, gin.H{"communities": communities})
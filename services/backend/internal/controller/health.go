@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"net/http"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	EditorBreaker *service.CircuitBreaker
+	AuthCache     *service.AuthCache
+}
+
+func NewHealthController(editorBreaker *service.CircuitBreaker, authCache *service.AuthCache) *HealthController {
+	return &HealthController{
+		EditorBreaker: editorBreaker,
+		AuthCache:     authCache,
+	}
+}
+
+// Get reports the service status, the state of the backend-editor circuit breaker and the
+// auth cache counters. An open circuit degrades the status but still answers 200, because the API keeps serving
+// reads and the container must not be restarted for a dependency outage.
+// GET /api/health
+func (ctrl *HealthController) Get(c *gin.Context) {
+	status := "ok"
+	dependencies := gin.H{}
+
+	if ctrl.EditorBreaker != nil {
+		snapshot := ctrl.EditorBreaker.Snapshot()
+		if snapshot.State != service.CircuitClosed {
+			status = "degraded"
+		}
+		dependencies["backendEditor"] = snapshot
+	}
+
+	response := gin.H{"status": status, "dependencies": dependencies}
+	if ctrl.AuthCache != nil {
+		response["authCache"] = ctrl.AuthCache.Snapshot()
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -1,33 +1,143 @@
 package controller
 
 import (
-	"log"
-	"net/http"
+	"errors"
+	"reflect"
+	"strings"
 
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/middleware"
 	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 )
 
-// respondWithError sends a standardized error response
-func respondWithError(c *gin.Context, code int, message string, details interface{}) {
-	log.Printf("Error: %s, Details: %v", message, details)
-	c.JSON(code, gin.H{"error": message})
+// abortWithError hands err to the ErrorHandler middleware, which renders it as problem+json
+func abortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// bindJSON binds the request body into obj. On failure it aborts with a validation error
+// listing the offending fields and returns false.
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		abortWithError(c, bindingError(err, obj))
+		return false
+	}
+	return true
+}
+
+// bindingError converts a binding failure into a validation error with field details.
+// Field names are reported by their JSON name so clients can match them to form inputs.
+func bindingError(err error, obj interface{}) *apperror.Error {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return apperror.Validation("invalid_request_body", "Request body is not valid JSON").Wrap(err)
+	}
+
+	fields := make([]apperror.FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		name := jsonFieldName(obj, fe.StructField())
+		fields = append(fields, apperror.FieldError{
+			Field:   name,
+			Code:    fe.Tag(),
+			Message: validationMessage(name, fe),
+		})
+	}
+	return apperror.Validation("validation_failed", "One or more fields are invalid", fields...).Wrap(err)
+}
+
+// jsonFieldName returns the JSON name of a top-level struct field, falling back to the Go name
+func jsonFieldName(obj interface{}, field string) string {
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return field
+	}
+	sf, ok := t.FieldByName(field)
+	if !ok {
+		return field
+	}
+	name := strings.Split(sf.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return field
+	}
+	return name
+}
+
+func validationMessage(field string, fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return field + " is required"
+	case "max":
+		return field + " must be at most " + fe.Param() + " characters"
+	case "min":
+		return field + " must be at least " + fe.Param() + " characters"
+	case "oneof":
+		return field + " must be one of: " + fe.Param()
+	case "hexcolor":
+		return field + " must be a hex colour such as #1E88E5"
+	default:
+		return field + " is invalid"
+	}
 }
 
 // getUserFromContext extracts and type-asserts the authenticated user from context.
-// Returns nil and sends an error response if the user is not found or invalid.
+// Returns nil and aborts with an error if the user is not found or invalid.
 func getUserFromContext(c *gin.Context) *model.User {
 	userInterface, exists := c.Get("user")
 	if !exists {
-		respondWithError(c, http.StatusUnauthorized, "User not authenticated", nil)
+		abortWithError(c, apperror.Unauthorized("authentication_required", "User not authenticated"))
 		return nil
 	}
 
 	user, ok := userInterface.(*model.User)
 	if !ok {
-		respondWithError(c, http.StatusInternalServerError, "Failed to get user information", nil)
+		abortWithError(c, apperror.Internal(errors.New("user in context has unexpected type")))
 		return nil
 	}
 
 	return user
 }
+
+// requestToken returns the token the Auth middleware found for the request (from the session, the
+// Authorization header or the decrypted cookie). Cookies are encrypted and may be split into
+// chunks, so handlers never read them directly; without the middleware there is no token.
+func requestToken(c *gin.Context, contextKey string) string {
+	return c.GetString(contextKey)
+}
+
+// getAuthToken returns the ID token the request was authenticated with, for calls to other services
+func getAuthToken(c *gin.Context) string {
+	return requestToken(c, middleware.ContextAuthToken)
+}
+
+// getGraphAccessToken returns the user's Microsoft Graph token, or aborts with 401 if there is none
+func getGraphAccessToken(c *gin.Context) (string, bool) {
+	token := requestToken(c, middleware.ContextGraphAccessToken)
+	if token == "" {
+		abortWithError(c, apperror.Unauthorized("graph_token_missing", "Graph API token not available"))
+		return "", false
+	}
+	return token, true
+}
+
+// viewerFromContext describes the authenticated user for learning path access checks.
+// Returns false and aborts with an error if the user is not found or invalid.
+func viewerFromContext(c *gin.Context) (service.Viewer, bool) {
+	user := getUserFromContext(c)
+	if user == nil {
+		return service.Viewer{}, false
+	}
+
+	return service.Viewer{
+		UserID:                user.ID,
+		CommunityIDs:          user.CommunityIDs(),
+		ModeratedCommunityIDs: user.ModeratedCommunityIDs(),
+		IsAdmin:               user.HasRole(model.PlatformRoleAdmin),
+	}, true
+}
@@ -1,48 +1,206 @@
 package controller
 
 import (
+	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
 	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type LearningPathController struct {
 	LearningPathService *service.LearningPathService
+	IdempotencyService  *service.IdempotencyService
 }
 
-func NewLearningPathController(learningPathService *service.LearningPathService) *LearningPathController {
+func NewLearningPathController(learningPathService *service.LearningPathService, idempotencyService *service.IdempotencyService) *LearningPathController {
 	return &LearningPathController{
 		LearningPathService: learningPathService,
+		IdempotencyService:  idempotencyService,
 	}
 }
 
+// IdempotencyKeyHeader lets clients retry a create without creating a duplicate learning path
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader marks a response replayed from an earlier request with the same key
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// Index lists learning paths one page at a time
+// GET /api/learning-paths?community=&skill=&visibility=&createdBy=&createdAfter=&createdBefore=&featured=&publication=&favorited=&id=&sort=&order=&limit=&cursor=
 func (res *LearningPathController) Index(c *gin.Context) {
-	paths, err := res.LearningPathService.GetLearningPaths()
+	viewer, ok := res.viewer(c)
+	if !ok {
+		return
+	}
+	query, ok := parseLearningPathQuery(c, viewer)
+	if !ok {
+		return
+	}
+	query.Community = c.Query("community")
+
+	page, err := res.LearningPathService.ListLearningPaths(c, query)
 	if err != nil {
-		respondWithError(c, http.StatusInternalServerError, "Failed to fetch learning paths", err)
+		abortWithError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, paths)
+	c.JSON(http.StatusOK, page)
+}
+
+// viewer describes the authenticated user for access checks; see viewerFromContext
+func (res *LearningPathController) viewer(c *gin.Context) (service.Viewer, bool) {
+	return viewerFromContext(c)
+}
+
+// parseLearningPathQuery reads the paging, sorting and filter parameters shared by the listing
+// endpoints. Sort and order are validated by the service. On invalid parameters it aborts with
+// a validation error listing every offending field and returns false.
+func parseLearningPathQuery(c *gin.Context, viewer service.Viewer) (service.LearningPathQuery, bool) {
+	query := service.LearningPathQuery{
+		Viewer: viewer,
+		Sort:   c.Query("sort"),
+		Order:  c.Query("order"),
+		Cursor: c.Query("cursor"),
+	}
+	var fields []apperror.FieldError
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > service.MaxLearningPathPageSize {
+			fields = append(fields, apperror.FieldError{Field: "limit", Code: "range",
+				Message: fmt.Sprintf("limit must be a number between 1 and %d", service.MaxLearningPathPageSize)})
+		}
+		query.Limit = limit
+	}
+
+	// skill may be repeated or comma-separated
+	for _, v := range c.QueryArray("skill") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				query.Skills = append(query.Skills, name)
+			}
+		}
+	}
+
+	switch v := c.Query("visibility"); v {
+	case "":
+	case "public", "private":
+		isPublic := v == "public"
+		query.IsPublic = &isPublic
+	default:
+		fields = append(fields, apperror.FieldError{Field: "visibility", Code: "oneof", Message: "visibility must be one of public, private"})
+	}
+
+	if v := c.Query("createdBy"); v == "me" {
+		query.CreatedByID = &viewer.UserID
+	} else if v != "" {
+		id, err := strconv.ParseUint(v, 10, 0)
+		if err != nil {
+			fields = append(fields, apperror.FieldError{Field: "createdBy", Code: "user_id", Message: "createdBy must be a user ID or \"me\""})
+		}
+		userID := uint(id)
+		query.CreatedByID = &userID
+	}
+
+	if v := c.Query("featured"); v != "" {
+		featured, err := strconv.ParseBool(v)
+		if err != nil {
+			fields = append(fields, apperror.FieldError{Field: "featured", Code: "boolean", Message: "featured must be true or false"})
+		}
+		query.Featured = &featured
+	}
+
+	switch v := c.Query("publication"); v {
+	case "":
+	case "pending", "approved", "rejected":
+		query.Publication = strings.ToUpper(v)
+	default:
+		fields = append(fields, apperror.FieldError{Field: "publication", Code: "oneof", Message: "publication must be one of pending, approved, rejected"})
+	}
+
+	if v := c.Query("favorited"); v != "" {
+		favorited, err := strconv.ParseBool(v)
+		if err != nil {
+			fields = append(fields, apperror.FieldError{Field: "favorited", Code: "boolean", Message: "favorited must be true or false"})
+		}
+		if favorited {
+			query.FavoritedBy = &viewer.UserID
+		}
+	}
+
+	// id may be repeated or comma-separated
+	for _, v := range c.QueryArray("id") {
+		for _, raw := range strings.Split(v, ",") {
+			if raw = strings.TrimSpace(raw); raw == "" {
+				continue
+			}
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				fields = append(fields, apperror.FieldError{Field: "id", Code: "uuid", Message: "id must be a learning path ID"})
+				break
+			}
+			query.IDs = append(query.IDs, id)
+		}
+	}
+	if len(query.IDs) > service.MaxLearningPathPageSize {
+		fields = append(fields, apperror.FieldError{Field: "id", Code: "max",
+			Message: fmt.Sprintf("id may be given at most %d times", service.MaxLearningPathPageSize)})
+	}
+
+	var field *apperror.FieldError
+	if query.CreatedAfter, field = parseDateParam(c, "createdAfter"); field != nil {
+		fields = append(fields, *field)
+	}
+	if query.CreatedBefore, field = parseDateParam(c, "createdBefore"); field != nil {
+		fields = append(fields, *field)
+	}
+
+	if len(fields) > 0 {
+		abortWithError(c, apperror.Validation("invalid_query", "Invalid listing query", fields...))
+		return query, false
+	}
+	return query, true
+}
+
+// parseDateParam parses an optional RFC 3339 timestamp or YYYY-MM-DD date (midnight UTC) query parameter
+func parseDateParam(c *gin.Context, name string) (*time.Time, *apperror.FieldError) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, &apperror.FieldError{Field: name, Code: "date", Message: name + " must be an RFC 3339 timestamp or a YYYY-MM-DD date"}
 }
 
 type CreateLearningPathRequest struct {
 	PathName    string   `json:"pathName" binding:"required"`
 	Description string   `json:"description"`
 	Skills      []string `json:"skills"`
+	IsPublic    *bool    `json:"isPublic"` // Defaults to true
 }
 
 type UpdateLearningPathRequest struct {
 	Title       string `json:"title" binding:"required"`
 	Description string `json:"description"`
+	IsPublic    *bool  `json:"isPublic"` // Unchanged when omitted
 }
 
 func (res *LearningPathController) Create(c *gin.Context) {
 	var req CreateLearningPathRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		respondWithError(c, http.StatusBadRequest, "Invalid request format", err)
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -51,75 +209,138 @@ func (res *LearningPathController) Create(c *gin.Context) {
 		return
 	}
 
-	// Determine community: from URL param (new way) or user's community (backward compat)
+	// Determine community: from URL param (new way) or user's primary community (backward compat)
 	communityName := c.Param("communityname")
 	if communityName == "" {
-		// Backward compatibility: use user's community if no URL param
-		communityName = userModel.Community
+		// Backward compatibility: use user's primary community if no URL param
+		communityName = userModel.CommunityName()
 	}
 
 	// Validate community
 	if communityName == "" {
-		respondWithError(c, http.StatusForbidden, "You must be assigned to a community to create learning paths", nil)
+		abortWithError(c, apperror.Forbidden("community_required", "You must be assigned to a community to create learning paths"))
 		return
 	}
 
-	// Validate community exists
-	communityService := service.NewCommunityService()
-	if !communityService.IsValidCommunity(c, communityName) {
-		respondWithError(c, http.StatusNotFound, "Community not found", nil)
+	// Validate community exists and is not archived
+	community, err := service.NewCommunityService(res.LearningPathService.DB).GetActiveCommunity(c, communityName)
+	if err != nil {
+		abortWithError(c, err)
 		return
 	}
 
 	// AUTHORIZATION: Check if user is admin
-	userService := service.NewUserService(res.LearningPathService.DB)
-	isAdmin := userService.IsAdmin(userModel.Email)
+	isAdmin := userModel.HasRole(model.PlatformRoleAdmin)
 
-	// AUTHORIZATION: User must be in community OR be admin
-	if userModel.Community != communityName && !isAdmin {
-		respondWithError(c, http.StatusForbidden, "You can only create learning paths for your own community", nil)
+	// AUTHORIZATION: User must be a member of the community OR be admin
+	if !userModel.IsMemberOf(community.ID) && !isAdmin {
+		abortWithError(c, apperror.Forbidden("community_forbidden", "You can only create learning paths for your own communities"))
 		return
 	}
 
 	// Extract token for service-to-service calls
-	authToken, err := c.Cookie("id_token")
-	if err != nil || authToken == "" {
-		respondWithError(c, http.StatusUnauthorized, "Missing authentication token for service calls", err)
+	authToken := getAuthToken(c)
+	if authToken == "" {
+		abortWithError(c, apperror.Unauthorized("service_token_missing", "Missing authentication token for service calls"))
 		return
 	}
 
-	learningPath, createErr := res.LearningPathService.CreateLearningPath(c, req.PathName, req.Description, true, "", req.Skills, authToken, communityName)
-	if createErr != nil {
-		// Check if error is about duplicate name
-		errMsg := createErr.Error()
-		if strings.Contains(errMsg, "already exists") || strings.Contains(errMsg, "duplicate") {
-			respondWithError(c, http.StatusConflict, errMsg, createErr)
+	// IDEMPOTENCY: A retry with the same key replays the stored response instead of creating again
+	var idempotencyRecord *model.IdempotencyRecord
+	if key := c.GetHeader(IdempotencyKeyHeader); key != "" {
+		requestHash, err := service.HashRequest(struct {
+			Community string
+			Request   CreateLearningPathRequest
+		}{communityName, req})
+		if err != nil {
+			abortWithError(c, err)
 			return
 		}
-		respondWithError(c, http.StatusInternalServerError, "Failed to create learning path", createErr)
+
+		idempotencyRecord, err = res.IdempotencyService.Begin(c, userModel.ID, key, requestHash)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		if idempotencyRecord.Status == model.IdempotencyStatusCompleted {
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(idempotencyRecord.ResponseStatus, "application/json; charset=utf-8", []byte(idempotencyRecord.ResponseBody))
+			return
+		}
+		if idempotencyRecord.LPID != nil {
+			// The path was created but its response never stored; answer for that path instead of creating another
+			learningPath, err := res.LearningPathService.GetLearningPath(c, *idempotencyRecord.LPID)
+			if err != nil {
+				abortWithError(c, err)
+				return
+			}
+			c.Header(IdempotentReplayedHeader, "true")
+			res.completeCreate(c, idempotencyRecord, learningPath)
+			return
+		}
+	}
+
+	isPublic := req.IsPublic == nil || *req.IsPublic
+	// PUBLICATION: In communities that require approval, paths stay private until a moderator approves
+	opts := service.CreateOptions{
+		RequestPublication: isPublic && community.RequiresApproval && !isAdmin && !userModel.IsModeratorOf(community.ID),
+	}
+	if idempotencyRecord != nil {
+		opts.IdempotencyRecordID = &idempotencyRecord.ID
+	}
+	learningPath, createErr := res.LearningPathService.CreateLearningPathWithOptions(c, req.PathName, req.Description, isPublic, "", req.Skills, authToken, community.ID, userModel.ID, opts)
+	if createErr != nil {
+		// Nothing was created, so the client may retry with the same key
+		if idempotencyRecord != nil {
+			res.IdempotencyService.Release(c, idempotencyRecord)
+		}
+		abortWithError(c, createErr)
 		return
 	}
 
-	c.JSON(http.StatusCreated, learningPath)
+	if idempotencyRecord == nil {
+		c.JSON(http.StatusCreated, learningPath)
+		return
+	}
+	res.completeCreate(c, idempotencyRecord, learningPath)
+}
+
+// completeCreate answers 201 for a created learning path and stores the response for replay
+func (res *LearningPathController) completeCreate(c *gin.Context, record *model.IdempotencyRecord, learningPath *model.LearningPath) {
+	body, err := json.Marshal(learningPath)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	if err := res.IdempotencyService.Complete(c, record, http.StatusCreated, body, &learningPath.ID); err != nil {
+		// The learning path exists, so still answer 201; the record already names it, so a retry
+		// replays a response for it instead of creating another
+		log.Printf("Failed to store idempotent response for LP %s: %v", learningPath.ID, err)
+	}
+	c.Data(http.StatusCreated, "application/json; charset=utf-8", body)
 }
 
 func (res *LearningPathController) Delete(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		respondWithError(c, http.StatusBadRequest, "Learning path ID is required", nil)
+		abortWithError(c, apperror.Validation("learning_path_id_required", "Learning path ID is required"))
 		return
 	}
 
-	// Extract token for service-to-service calls
-	authToken, err := c.Cookie("id_token")
-	if err != nil || authToken == "" {
-		respondWithError(c, http.StatusUnauthorized, "Missing authentication token for service calls", err)
+	viewer, ok := res.viewer(c)
+	if !ok {
+		return
+	}
+	// AUTHORIZATION: Only the owner or an admin may delete
+	if _, err := res.LearningPathService.Authorize(c, viewer, id, service.ActionDelete); err != nil {
+		abortWithError(c, err)
 		return
 	}
 
-	err = res.LearningPathService.DeleteLearningPath(c, id, authToken)
+	// The diagram is removed asynchronously by the outbox dispatcher
+	err := res.LearningPathService.DeleteLearningPath(c, id)
 	if err != nil {
-		respondWithError(c, http.StatusInternalServerError, "Failed to delete learning path", err)
+		abortWithError(c, err)
 		return
 	}
 
@@ -129,38 +350,45 @@ func (res *LearningPathController) Delete(c *gin.Context) {
 func (res *LearningPathController) Update(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		respondWithError(c, http.StatusBadRequest, "Learning path ID is required", nil)
+		abortWithError(c, apperror.Validation("learning_path_id_required", "Learning path ID is required"))
 		return
 	}
 
 	var req UpdateLearningPathRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		respondWithError(c, http.StatusBadRequest, "Invalid request format", err)
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	// Extract token for service-to-service calls
-	authToken, err := c.Cookie("id_token")
-	if err != nil || authToken == "" {
-		respondWithError(c, http.StatusUnauthorized, "Missing authentication token for service calls", err)
+	viewer, ok := res.viewer(c)
+	if !ok {
+		return
+	}
+	// AUTHORIZATION: Owners, authors, editors, community moderators and admins may update
+	current, err := res.LearningPathService.Authorize(c, viewer, id, service.ActionUpdate)
+	if err != nil {
+		abortWithError(c, err)
 		return
 	}
 
-	lp, updateErr := res.LearningPathService.UpdateLearningPath(c, id, req.Title, req.Description, authToken)
-	if updateErr != nil {
-		if strings.Contains(updateErr.Error(), "not found") {
-			respondWithError(c, http.StatusNotFound, "Learning path not found", updateErr)
-			return
-		}
-		if strings.Contains(updateErr.Error(), "authentication") || strings.Contains(updateErr.Error(), "403") {
-			respondWithError(c, http.StatusForbidden, "Service authentication failed - token may be invalid", updateErr)
-			return
-		}
-		if strings.Contains(updateErr.Error(), "saga step 2") {
-			respondWithError(c, http.StatusFailedDependency, "Failed to sync diagram - changes rolled back", updateErr)
+	// PUBLICATION: Publishing may have to wait for a moderator's approval
+	pendingApproval := false
+	if req.IsPublic != nil && *req.IsPublic && !current.IsPublic {
+		pendingApproval, err = res.LearningPathService.NeedsPublicationApproval(c, viewer, current)
+		if err != nil {
+			abortWithError(c, err)
 			return
 		}
-		respondWithError(c, http.StatusInternalServerError, "Failed to update learning path", updateErr)
+	}
+
+	// The diagram name is synced asynchronously by the outbox dispatcher
+	var lp *model.LearningPath
+	if pendingApproval {
+		lp, err = res.LearningPathService.UpdateAndRequestPublication(c, id, req.Title, req.Description)
+	} else {
+		lp, err = res.LearningPathService.UpdateLearningPath(c, id, req.Title, req.Description, req.IsPublic)
+	}
+	if err != nil {
+		abortWithError(c, err)
 		return
 	}
 
@@ -168,22 +396,22 @@ func (res *LearningPathController) Update(c *gin.Context) {
 }
 
 func (res *LearningPathController) AddToFavorites(c *gin.Context) {
-	userModel := getUserFromContext(c)
-	if userModel == nil {
+	viewer, ok := res.viewer(c)
+	if !ok {
 		return
 	}
 
 	// Extract learning path ID from URL
 	lpID := c.Param("id")
 	if lpID == "" {
-		respondWithError(c, http.StatusBadRequest, "Learning path ID is required", nil)
+		abortWithError(c, apperror.Validation("learning_path_id_required", "Learning path ID is required"))
 		return
 	}
 
 	// Call service to add to favorites
-	err := res.LearningPathService.AddToFavorites(c, userModel.ID, lpID)
+	err := res.LearningPathService.AddToFavorites(c, viewer, lpID)
 	if err != nil {
-		respondWithError(c, http.StatusInternalServerError, "Failed to add to favorites", err)
+		abortWithError(c, err)
 		return
 	}
 
@@ -199,14 +427,14 @@ func (res *LearningPathController) RemoveFromFavorites(c *gin.Context) {
 	// Extract learning path ID from URL
 	lpID := c.Param("id")
 	if lpID == "" {
-		respondWithError(c, http.StatusBadRequest, "Learning path ID is required", nil)
+		abortWithError(c, apperror.Validation("learning_path_id_required", "Learning path ID is required"))
 		return
 	}
 
 	// Call service to remove from favorites
 	err := res.LearningPathService.RemoveFromFavorites(c, userModel.ID, lpID)
 	if err != nil {
-		respondWithError(c, http.StatusInternalServerError, "Failed to remove from favorites", err)
+		abortWithError(c, err)
 		return
 	}
 
@@ -214,33 +442,179 @@ func (res *LearningPathController) RemoveFromFavorites(c *gin.Context) {
 }
 
 func (res *LearningPathController) GetUserFavorites(c *gin.Context) {
-	userModel := getUserFromContext(c)
-	if userModel == nil {
+	viewer, ok := res.viewer(c)
+	if !ok {
 		return
 	}
 
 	// Get user's favorite learning paths
-	favorites, err := res.LearningPathService.GetUserFavorites(c, userModel.ID)
+	favorites, err := res.LearningPathService.GetUserFavorites(c, viewer)
 	if err != nil {
-		respondWithError(c, http.StatusInternalServerError, "Failed to fetch favorite learning paths", err)
+		abortWithError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, favorites)
 }
 
+type SetCollaboratorRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=AUTHOR EDITOR READER"`
+}
+
+// GetCollaborators lists the users holding a role on a learning path
+// GET /api/learning-paths/:id/collaborators
+func (res *LearningPathController) GetCollaborators(c *gin.Context) {
+	viewer, ok := res.viewer(c)
+	if !ok {
+		return
+	}
+
+	collaborators, err := res.LearningPathService.ListCollaborators(c, viewer, c.Param("id"))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, collaborators)
+}
+
+// SetCollaborator invites a user to a learning path with a role, or changes their role
+// POST /api/learning-paths/:id/collaborators
+func (res *LearningPathController) SetCollaborator(c *gin.Context) {
+	var req SetCollaboratorRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	viewer, ok := res.viewer(c)
+	if !ok {
+		return
+	}
+
+	collaborator, err := res.LearningPathService.SetCollaborator(c, viewer, c.Param("id"), req.Email, req.Role)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, collaborator)
+}
+
+// RemoveCollaborator takes away a user's role on a learning path
+// DELETE /api/learning-paths/:id/collaborators/:userId
+func (res *LearningPathController) RemoveCollaborator(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 0)
+	if err != nil {
+		abortWithError(c, apperror.Validation("invalid_user_id", "Invalid user ID",
+			apperror.FieldError{Field: "userId", Code: "number", Message: "userId must be a number"}).Wrap(err))
+		return
+	}
+
+	viewer, ok := res.viewer(c)
+	if !ok {
+		return
+	}
+
+	if err := res.LearningPathService.RemoveCollaborator(c, viewer, c.Param("id"), uint(userID)); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Feature features a public learning path in its community
+// POST /api/learning-paths/:id/feature
+func (res *LearningPathController) Feature(c *gin.Context) {
+	res.setFeatured(c, true)
+}
+
+// Unfeature stops featuring a learning path
+// DELETE /api/learning-paths/:id/feature
+func (res *LearningPathController) Unfeature(c *gin.Context) {
+	res.setFeatured(c, false)
+}
+
+func (res *LearningPathController) setFeatured(c *gin.Context, featured bool) {
+	viewer, ok := res.viewer(c)
+	if !ok {
+		return
+	}
+
+	// AUTHORIZATION: Only community moderators and admins may feature
+	lp, err := res.LearningPathService.SetFeatured(c, viewer, c.Param("id"), featured)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lp)
+}
+
+// ApprovePublication makes a learning path waiting for approval public
+// POST /api/learning-paths/:id/publication/approve
+func (res *LearningPathController) ApprovePublication(c *gin.Context) {
+	res.reviewPublication(c, true)
+}
+
+// RejectPublication keeps a learning path waiting for approval private
+// POST /api/learning-paths/:id/publication/reject
+func (res *LearningPathController) RejectPublication(c *gin.Context) {
+	res.reviewPublication(c, false)
+}
+
+func (res *LearningPathController) reviewPublication(c *gin.Context, approve bool) {
+	viewer, ok := res.viewer(c)
+	if !ok {
+		return
+	}
+
+	// AUTHORIZATION: Only community moderators and admins may review
+	lp, err := res.LearningPathService.ReviewPublication(c, viewer, c.Param("id"), approve)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lp)
+}
+
+// GetByCommunity lists the learning paths of a community one page at a time, with the same
+// query parameters as Index except community
 func (res *LearningPathController) GetByCommunity(c *gin.Context) {
 	communityName := c.Param("communityname")
 	if communityName == "" {
-		respondWithError(c, http.StatusBadRequest, "Community name is required", nil)
+		abortWithError(c, apperror.Validation("community_required", "Community name is required"))
+		return
+	}
+
+	viewer, ok := res.viewer(c)
+	if !ok {
+		return
+	}
+	query, ok := parseLearningPathQuery(c, viewer)
+	if !ok {
+		return
+	}
+	query.Community = communityName
+
+	// Unknown communities are a 404 rather than an empty page; archived ones can still be read
+	valid, err := service.NewCommunityService(res.LearningPathService.DB).IsValidCommunity(c, communityName)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	if !valid {
+		abortWithError(c, apperror.NotFound("community_not_found", "Community not found"))
 		return
 	}
 
-	paths, err := res.LearningPathService.GetLearningPathsByCommunity(c, communityName)
+	page, err := res.LearningPathService.ListLearningPaths(c, query)
 	if err != nil {
-		respondWithError(c, http.StatusInternalServerError, "Failed to fetch learning paths for community", err)
+		abortWithError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, paths)
+	c.JSON(http.StatusOK, page)
 }
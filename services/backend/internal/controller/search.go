@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type SearchController struct {
+	SearchService *service.SearchService
+}
+
+func NewSearchController(searchService *service.SearchService) *SearchController {
+	return &SearchController{
+		SearchService: searchService,
+	}
+}
+
+// Search finds learning paths by title, description and skill names
+// GET /api/search?q=&community=&limit=
+func (ctrl *SearchController) Search(c *gin.Context) {
+	viewer, ok := viewerFromContext(c)
+	if !ok {
+		return
+	}
+
+	query := service.SearchQuery{
+		Viewer:    viewer,
+		Text:      c.Query("q"),
+		Community: c.Query("community"),
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > service.MaxSearchLimit {
+			abortWithError(c, apperror.Validation("invalid_query", "Invalid search query",
+				apperror.FieldError{Field: "limit", Code: "range", Message: fmt.Sprintf("limit must be a number between 1 and %d", service.MaxSearchLimit)}))
+			return
+		}
+		query.Limit = limit
+	}
+
+	results, err := ctrl.SearchService.Search(c, query)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
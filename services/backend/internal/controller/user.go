@@ -3,6 +3,8 @@ package controller
 import (
 	"net/http"
 
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
 	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
 	"github.com/gin-gonic/gin"
 )
@@ -27,19 +29,21 @@ func (ctrl *UserController) GetCurrentUser(c *gin.Context) {
 		return
 	}
 
-	isAdmin := ctrl.UserService.IsAdmin(user.Email)
-
 	response := map[string]interface{}{
-		"ID":        user.ID,
-		"CreatedAt": user.CreatedAt,
-		"UpdatedAt": user.UpdatedAt,
-		"DeletedAt": user.DeletedAt,
-		"Name":      user.Name,
-		"Email":     user.Email,
-		"EntraID":   user.EntraID,
-		"PhotoURL":  user.PhotoURL,
-		"Community": user.Community,
-		"IsAdmin":   isAdmin,
+		"ID":                   user.ID,
+		"CreatedAt":            user.CreatedAt,
+		"UpdatedAt":            user.UpdatedAt,
+		"DeletedAt":            user.DeletedAt,
+		"Name":                 user.Name,
+		"Email":                user.Email,
+		"EntraID":              user.EntraID,
+		"PhotoURL":             user.PhotoURL,
+		"Community":            user.CommunityName(),  // Primary community
+		"Communities":          user.CommunityNames(), // Every community, primary first
+		"IsAdmin":              user.HasRole(model.PlatformRoleAdmin),
+		"Roles":                user.Roles(),            // Platform roles, assigned or granted by token claims
+		"IsModerator":          len(user.Moderates) > 0, // Admins moderate every community without being listed here
+		"ModeratedCommunities": user.ModeratedCommunityNames(),
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -48,20 +52,19 @@ func (ctrl *UserController) GetCurrentUser(c *gin.Context) {
 // GetUserPhoto returns the user's photo from Microsoft Graph
 // GET /api/user/photo
 func (ctrl *UserController) GetUserPhoto(c *gin.Context) {
-	graphAccessToken, err := c.Cookie("graph_access_token")
-	if err != nil {
-		respondWithError(c, http.StatusUnauthorized, "Graph API token not available", nil)
+	graphAccessToken, ok := getGraphAccessToken(c)
+	if !ok {
 		return
 	}
 
 	photo, err := ctrl.GraphService.GetUserPhoto(c.Request.Context(), graphAccessToken)
 	if err != nil {
-		respondWithError(c, http.StatusInternalServerError, err.Error(), nil)
+		abortWithError(c, err)
 		return
 	}
 
 	if photo == nil {
-		respondWithError(c, http.StatusNotFound, "No photo available", nil)
+		abortWithError(c, apperror.NotFound("photo_not_found", "No photo available"))
 		return
 	}
 
@@ -82,8 +85,7 @@ func (ctrl *UserController) UpdateCurrentUser(c *gin.Context) {
 	}
 
 	var req UpdateUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		respondWithError(c, http.StatusBadRequest, "Invalid request body", nil)
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -102,7 +104,7 @@ func (ctrl *UserController) UpdateCurrentUser(c *gin.Context) {
 
 	updatedUser, err := ctrl.UserService.UpdateUser(user.ID, updates)
 	if err != nil {
-		respondWithError(c, http.StatusInternalServerError, "Failed to update user", err)
+		abortWithError(c, err)
 		return
 	}
 
@@ -119,8 +121,7 @@ func (ctrl *UserController) SetUserCommunity(c *gin.Context) {
 		Community string `json:"community" binding:"required"`
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		respondWithError(c, http.StatusBadRequest, "Invalid request", err)
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -129,14 +130,17 @@ func (ctrl *UserController) SetUserCommunity(c *gin.Context) {
 		return
 	}
 
-	updates := map[string]interface{}{
-		"community": req.Community,
+	// Users can only join communities that exist and still accept new learning paths
+	community, err := service.NewCommunityService(ctrl.UserService.DB).GetActiveCommunity(c, req.Community)
+	if err != nil {
+		abortWithError(c, err)
+		return
 	}
 
-	// Service handles: finding user, validating allowed fields, database update
-	updatedUser, err := ctrl.UserService.UpdateUser(user.ID, updates)
+	// Joins the community if needed and makes it the primary one
+	updatedUser, err := ctrl.UserService.SetPrimaryCommunity(c.Request.Context(), user.ID, community.ID)
 	if err != nil {
-		respondWithError(c, http.StatusInternalServerError, "Failed to update community", err)
+		abortWithError(c, err)
 		return
 	}
 
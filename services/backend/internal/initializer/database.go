@@ -35,17 +35,78 @@ func ConnectToDB() {
 
 	// Auto-migrate the database schema
 	err = DB.AutoMigrate(
+		&model.Community{}, // Referenced by users and learning paths
 		&model.User{},
+		&model.CommunityMembership{},
+		&model.CommunityModerator{},
 		&model.Skill{},
 		&model.Role{},
 		&model.LearningPath{},
 		&model.UserSkill{},
 		&model.UserLP{},
 		&model.LPSkill{},
+		&model.SagaLog{},
+		&model.OutboxMessage{},
+		&model.IdempotencyRecord{},
+		&model.CommunityGroupMapping{},
+		&model.CommunityMappingAudit{},
+		&model.UserPlatformRole{},
+		&model.PlatformRoleClaimMapping{},
 	)
 	if err != nil {
 		log.Fatalf("Failed to auto-migrate database: %v", err)
 	}
 
+	if err := SeedCommunities(DB); err != nil {
+		log.Fatalf("Failed to seed communities: %v", err)
+	}
+	if err := migrateCommunityReferences(DB); err != nil {
+		log.Fatalf("Failed to migrate community references: %v", err)
+	}
+	if err := backfillCommunityMemberships(DB); err != nil {
+		log.Fatalf("Failed to backfill community memberships: %v", err)
+	}
+	if err := importCommunityGroupMappings(DB, os.Getenv("COMMUNITY_GROUP_MAPPINGS")); err != nil {
+		log.Fatalf("Failed to import community group mappings: %v", err)
+	}
+	if err := migrateLearningPathSearch(DB); err != nil {
+		log.Fatalf("Failed to migrate learning path search index: %v", err)
+	}
+	if err := SeedRoles(DB); err != nil {
+		log.Fatalf("Failed to seed roles: %v", err)
+	}
+	if err := backfillLearningPathOwners(DB); err != nil {
+		log.Fatalf("Failed to backfill learning path owners: %v", err)
+	}
+	if err := bootstrapAdmins(DB, os.Getenv("ADMIN_EMAILS")); err != nil {
+		log.Fatalf("Failed to bootstrap admins: %v", err)
+	}
+
 	log.Println("Database schema migrated successfully!")
 }
+
+// migrateLearningPathSearch adds the full-text search column used by GET /api/search.
+// A generated column cannot read other tables, so skill names are denormalized into
+// learning_paths.skill_names when a path is created, the only place skills are written.
+// Rows from before the column existed are backfilled once; paths without skills get an
+// empty string so they are not picked up again on the next start.
+func migrateLearningPathSearch(db *gorm.DB) error {
+	statements := []string{
+		`UPDATE learning_paths lp SET skill_names = coalesce((
+			SELECT string_agg(s.name, ' ') FROM lp_skills ls JOIN skills s ON s.id = ls.skill_id
+			WHERE ls.lp_id = lp.id AND ls.deleted_at IS NULL
+		), '') WHERE lp.skill_names IS NULL`,
+		`ALTER TABLE learning_paths ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('english', coalesce(skill_names, '')), 'B') ||
+			setweight(to_tsvector('english', coalesce(description, '')), 'C')
+		) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_learning_paths_search_vector ON learning_paths USING GIN (search_vector)`,
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
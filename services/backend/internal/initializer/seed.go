@@ -0,0 +1,231 @@
+package initializer
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// defaultCommunities are the communities a fresh database starts with. They used to be hard-coded.
+var defaultCommunities = []string{
+	"Autonomous Systems",
+	"Cloud and Backend",
+	"Connectivity",
+	"Cyber Security and Software Update",
+	"Data Analytics and Data Science",
+	"E2E Solution Architecture",
+	"Embedded Software Connect",
+	"Engineering Operations and Network Integration",
+	"Frontends and Digital Experiences",
+}
+
+// SeedCommunities creates the default communities when the communities table is empty. Once
+// admins manage communities, renamed or archived defaults are left alone.
+func SeedCommunities(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&model.Community{}).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count communities: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	for _, name := range defaultCommunities {
+		community := model.Community{ID: uuid.New(), Name: name, Slug: model.CommunitySlug(name)}
+		if err := db.Create(&community).Error; err != nil {
+			return fmt.Errorf("failed to seed community %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// migrateCommunityReferences replaces the free-form community column of users and learning paths
+// with a community_id foreign key, then drops the old column. Names that match no community are
+// kept as archived communities so no reference is lost; admins can restore or rename them.
+func migrateCommunityReferences(db *gorm.DB) error {
+	for _, table := range []string{"learning_paths", "users"} {
+		if !db.Migrator().HasColumn(table, "community") {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var unknown []string
+			err := tx.Table(table).Distinct("community").
+				Where("community <> '' AND community NOT IN (SELECT name FROM communities)").
+				Pluck("community", &unknown).Error
+			if err != nil {
+				return fmt.Errorf("failed to find unknown communities: %w", err)
+			}
+
+			archivedAt := time.Now()
+			for _, name := range unknown {
+				community := model.Community{ID: uuid.New(), Name: name, Slug: model.CommunitySlug(name), ArchivedAt: &archivedAt}
+				var taken int64
+				if err := tx.Model(&model.Community{}).Where("slug = ?", community.Slug).Count(&taken).Error; err != nil {
+					return err
+				}
+				if taken > 0 || community.Slug == "" {
+					community.Slug = strings.TrimPrefix(community.Slug+"-"+community.ID.String()[:8], "-")
+				}
+				if err := tx.Create(&community).Error; err != nil {
+					return fmt.Errorf("failed to keep community %s: %w", name, err)
+				}
+			}
+
+			err = tx.Exec(fmt.Sprintf(`UPDATE %[1]s SET community_id = (
+				SELECT communities.id FROM communities WHERE communities.name = %[1]s.community
+			) WHERE community_id IS NULL AND community <> ''`, table)).Error
+			if err != nil {
+				return fmt.Errorf("failed to copy community references: %w", err)
+			}
+			return tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN community", table)).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to migrate %s.community: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// SeedRoles creates the per-learning-path roles that do not exist yet
+func SeedRoles(db *gorm.DB) error {
+	for _, name := range model.LearningPathRoles {
+		if err := db.Where(model.Role{Name: name}).FirstOrCreate(&model.Role{}).Error; err != nil {
+			return fmt.Errorf("failed to seed role %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// backfillLearningPathOwners makes the recorded creator the OWNER of paths that have none yet
+func backfillLearningPathOwners(db *gorm.DB) error {
+	var owner model.Role
+	if err := db.Where("name = ?", model.RoleOwner).First(&owner).Error; err != nil {
+		return fmt.Errorf("failed to load owner role: %w", err)
+	}
+
+	var paths []model.LearningPath
+	err := db.Where(`created_by_id IS NOT NULL AND NOT EXISTS (
+		SELECT 1 FROM user_lps WHERE user_lps.lp_id = learning_paths.id AND user_lps.role_id = ? AND user_lps.deleted_at IS NULL
+	)`, owner.ID).Find(&paths).Error
+	if err != nil {
+		return fmt.Errorf("failed to find paths without owner: %w", err)
+	}
+
+	for _, lp := range paths {
+		err := db.Where(model.UserLP{UserID: *lp.CreatedByID, LPID: lp.ID}).
+			Assign(model.UserLP{RoleID: &owner.ID}).
+			FirstOrCreate(&model.UserLP{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to record owner of learning path %s: %w", lp.ID, err)
+		}
+	}
+	return nil
+}
+
+// backfillCommunityMemberships makes users a member of their primary community. Users used to
+// belong to exactly one community; those memberships are recorded as manual so the next Graph
+// sync does not take them away.
+func backfillCommunityMemberships(db *gorm.DB) error {
+	err := db.Exec(`INSERT INTO community_memberships (user_id, community_id, source, created_at)
+		SELECT users.id, users.community_id, ?, CURRENT_TIMESTAMP FROM users
+		WHERE users.community_id IS NOT NULL AND NOT EXISTS (
+			SELECT 1 FROM community_memberships
+			WHERE community_memberships.user_id = users.id AND community_memberships.community_id = users.community_id
+		)`, model.MembershipSourceManual).Error
+	if err != nil {
+		return fmt.Errorf("failed to backfill community memberships: %w", err)
+	}
+	return nil
+}
+
+// importCommunityGroupMappings copies COMMUNITY_GROUP_MAPPINGS (GROUP_ID:Community,...) into the
+// mappings table when that table is empty, in the order given. Afterwards the variable is ignored
+// and mappings are managed through /api/admin/community-mappings.
+func importCommunityGroupMappings(db *gorm.DB, raw string) error {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(&model.CommunityGroupMapping{}).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count community mappings: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for priority, pair := range strings.Split(raw, ",") {
+			parts := strings.Split(strings.TrimSpace(pair), ":")
+			if len(parts) != 2 {
+				log.Printf("⚠️  Skipping invalid COMMUNITY_GROUP_MAPPINGS entry '%s'", pair)
+				continue
+			}
+			groupID, name := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+
+			var community model.Community
+			err := tx.Where("name = ? OR slug = ?", name, name).First(&community).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("⚠️  Skipping COMMUNITY_GROUP_MAPPINGS entry for unknown community '%s'", name)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to find community %s: %w", name, err)
+			}
+
+			mapping := model.CommunityGroupMapping{GroupID: groupID, CommunityID: community.ID, Priority: priority}
+			result := tx.Where(model.CommunityGroupMapping{GroupID: groupID}).Attrs(mapping).FirstOrCreate(&mapping)
+			if result.Error != nil {
+				return fmt.Errorf("failed to import mapping for group %s: %w", groupID, result.Error)
+			}
+			if result.RowsAffected == 0 {
+				continue // Group listed twice; the first entry wins
+			}
+
+			err = tx.Create(&model.CommunityMappingAudit{
+				Action:      model.MappingAuditImported,
+				MappingID:   mapping.ID,
+				GroupID:     mapping.GroupID,
+				CommunityID: mapping.CommunityID,
+				Priority:    mapping.Priority,
+				ActorEmail:  "COMMUNITY_GROUP_MAPPINGS",
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to audit imported mapping for group %s: %w", groupID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// bootstrapAdmins grants the admin role to existing users listed in ADMIN_EMAILS, so a fresh
+// deployment has someone to assign roles through /api/admin/roles. Users who sign in for the
+// first time later get the role when they are created. Removing an address from the variable
+// does not revoke the role.
+func bootstrapAdmins(db *gorm.DB, raw string) error {
+	emails := model.AdminEmails(raw)
+	if len(emails) == 0 {
+		return nil
+	}
+
+	var users []model.User
+	if err := db.Where("LOWER(email) IN ?", emails).Find(&users).Error; err != nil {
+		return fmt.Errorf("failed to find ADMIN_EMAILS users: %w", err)
+	}
+	for _, user := range users {
+		err := db.Where(model.UserPlatformRole{UserID: user.ID, Role: model.PlatformRoleAdmin}).
+			Attrs(model.UserPlatformRole{Source: model.RoleSourceBootstrap}).
+			FirstOrCreate(&model.UserPlatformRole{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to grant admin role to %s: %w", user.Email, err)
+		}
+	}
+	return nil
+}
@@ -1,14 +1,13 @@
 package middleware
 
 import (
-	"context"
+	"errors"
 	"log"
-	"net/http"
 	"os"
 
-	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/initializer"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/authcookie"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
 	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
-	"github.com/coreos/go-oidc"
 	"github.com/gin-gonic/gin"
 )
 
@@ -18,7 +17,7 @@ func isDebugEnabled() bool {
 }
 
 // extractAuthToken extracts the authentication token from Authorization header or id_token cookie
-func extractAuthToken(c *gin.Context) (string, error) {
+func extractAuthToken(c *gin.Context, cookies *authcookie.Codec) (string, error) {
 	// Check Authorization header first (for API clients)
 	authHeader := c.GetHeader("Authorization")
 	if authHeader != "" && len(authHeader) > 7 && authHeader[:7] == "Bearer " {
@@ -28,8 +27,9 @@ func extractAuthToken(c *gin.Context) (string, error) {
 		return authHeader[7:], nil
 	}
 
-	// Fall back to id_token cookie (primary method for browser clients)
-	idToken, err := c.Cookie("id_token")
+	// Fall back to id_token cookie (primary method for browser clients), which auth-service
+	// encrypts and may split into chunks
+	idToken, err := cookies.Read(c.Request, "id_token")
 	if err != nil {
 		return "", err
 	}
@@ -40,72 +40,151 @@ func extractAuthToken(c *gin.Context) (string, error) {
 	return idToken, nil
 }
 
-func Auth() gin.HandlerFunc {
-	clientID := os.Getenv("CLIENT_ID")
-	tenantID := os.Getenv("TENANT_ID")
+// Context keys for the tokens of the authenticated request, for handlers that call other
+// services on the user's behalf
+const (
+	ContextAuthToken        = "auth_token"
+	ContextGraphAccessToken = "graph_access_token"
+)
 
-	ctx := context.Background()
-	provider, err := oidc.NewProvider(ctx, "https://login.microsoftonline.com/"+tenantID+"/v2.0")
-	if err != nil {
-		panic("Failed to initialize OIDC provider: " + err.Error())
+// resolveSession returns the session behind the request's session cookie, or nil when sessions
+// are disabled or the request carries an Authorization header or no session cookie
+func resolveSession(c *gin.Context, sessions SessionResolver) (*ResolvedSession, error) {
+	if sessions == nil || c.GetHeader("Authorization") != "" {
+		return nil, nil
+	}
+	secret, err := c.Cookie(SessionCookie)
+	if err != nil || secret == "" {
+		return nil, nil
 	}
+	if isDebugEnabled() {
+		log.Print("[DEBUG] Resolving session cookie")
+	}
+	return sessions.Resolve(c.Request.Context(), secret)
+}
 
-	verifier := provider.Verifier(&oidc.Config{ClientID: clientID})
+// Auth verifies the request's ID token with verifier and puts the user in the context. With a
+// cache, a token seen before skips verification and the user lookup until the cache entry expires.
+// With sessions, a browser's session cookie is resolved into the session's tokens first. With
+// revocations, signed-out sessions, tokens and users are rejected, cached or not. Token cookies
+// are read through cookies, or as they are when it is nil.
+func Auth(verifier TokenVerifier, sessions SessionResolver, revocations RevocationChecker, cookies *authcookie.Codec, userService *service.UserService, cache *service.AuthCache) gin.HandlerFunc {
+	if cookies == nil {
+		cookies = authcookie.NewCodec(nil)
+	}
+	graphService := service.NewGraphService()
+	roleService := service.NewRoleService(userService.DB)
 
 	return func(c *gin.Context) {
-		token, err := extractAuthToken(c)
+		session, err := resolveSession(c, sessions)
 		if err != nil {
-			log.Print("[ERROR] No valid authentication found")
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "No valid authentication found"})
-			c.Abort()
+			if errors.Is(err, ErrSessionNotFound) {
+				AbortWithProblem(c, apperror.Unauthorized("session_expired", "Session expired or revoked"))
+				return
+			}
+			log.Printf("Session resolve failed: %v", err)
+			AbortWithProblem(c, apperror.From(err))
 			return
 		}
 
-		idToken, err := verifier.Verify(ctx, token)
+		var token, graphAccessToken string
+		if session != nil {
+			token, graphAccessToken = session.IDToken, session.GraphAccessToken
+		} else {
+			token, err = extractAuthToken(c, cookies)
+			if err != nil {
+				log.Printf("[ERROR] No valid authentication found: %v", err)
+				AbortWithProblem(c, apperror.Unauthorized("authentication_required", "No valid authentication found"))
+				return
+			}
+
+			// Get Graph API access token from cookie. It is only needed when the token does not carry
+			// the user's groups itself.
+			graphAccessToken, err = cookies.Read(c.Request, "graph_access_token")
+			if err != nil {
+				if isDebugEnabled() {
+					log.Printf("[DEBUG] graph_access_token not found in cookies: %v", err)
+				}
+				graphAccessToken = "" // Continue without graph token; groups come from the token if present
+			}
+		}
+		c.Set(ContextAuthToken, token)
+		c.Set(ContextGraphAccessToken, graphAccessToken)
+
+		// Checked before the cache, since a cached token may have been revoked since. Claims are
+		// read unverified, which only ever rejects a token.
+		if revocations != nil {
+			var sessionID string
+			if session != nil {
+				sessionID = session.ID
+			}
+			if revocations.Revoked(sessionID, unverifiedClaims(token)) {
+				AbortWithProblem(c, apperror.Unauthorized("token_revoked", "Session or token was signed out"))
+				return
+			}
+		}
+
+		if cache != nil {
+			if user, ok := cache.Get(token); ok {
+				c.Set("user", user)
+				c.Next()
+				return
+			}
+		}
+
+		verified, err := verifier.Verify(c.Request.Context(), token)
 		if err != nil {
 			log.Printf("Token verification failed: %v", err)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-			c.Abort()
+			if apperror.IsKind(err, apperror.KindDependencyFailed) {
+				AbortWithProblem(c, apperror.From(err))
+				return
+			}
+			AbortWithProblem(c, apperror.Unauthorized("invalid_token", "Invalid or expired token"))
 			return
 		}
 
 		// Token refresh is now handled by the frontend calling auth-service directly
 		// No need to refresh here - just validate the token
 
-		// Extract Entra ID from token
-		claims := map[string]interface{}{}
-		if err := idToken.Claims(&claims); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse claims: " + err.Error()})
-			c.Abort()
-			return
-		}
+		// Extract the user ID (Entra oid, or sub from other providers) from token
+		claims := verified.Claims
 
-		entraID, ok := claims["oid"].(string)
-		if !ok || entraID == "" {
-			log.Printf("Missing oid claim in token")
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token: missing user identifier"})
-			c.Abort()
+		entraID := service.TokenUserID(claims)
+		if entraID == "" {
+			log.Printf("Missing oid and sub claims in token")
+			AbortWithProblem(c, apperror.Unauthorized("invalid_token", "Invalid token: missing user identifier"))
 			return
 		}
 
-		// Get Graph API access token from cookie
-		graphAccessToken, err := c.Cookie("graph_access_token")
-		if err != nil {
-			log.Printf("Warning: graph_access_token not found in cookies: %v", err)
-			graphAccessToken = "" // Continue without graph token, community won't be updated
-		}
-
 		// Get or create user (lazy provisioning - user created on first authenticated request)
-		userService := service.NewUserService(initializer.DB)
-		graphService := service.NewGraphService()
 		user, err := userService.GetOrCreateUser(claims, graphService, graphAccessToken)
 		if err != nil {
 			log.Printf("Failed to get/create user: %s - %v", entraID, err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process user account"})
-			c.Abort()
+			AbortWithProblem(c, &apperror.Error{Kind: apperror.KindInternal, Code: "user_provisioning_failed", Message: "Failed to process user account", Err: err})
 			return
 		}
 
+		// Platform roles granted by Entra group or app role claims last as long as the token does.
+		// Groups that did not fit in the token (overage) are read from Graph instead.
+		// Only cached when this worked, so a failed lookup is retried on the next request.
+		rolesResolved := true
+		groups, overage := service.TokenGroups(claims)
+		if overage {
+			if groups, _, err = service.ResolveGroups(c.Request.Context(), claims, graphService, graphAccessToken); err != nil {
+				log.Printf("Failed to read groups of user %s from Graph: %v", entraID, err)
+				rolesResolved = false
+			}
+		}
+		user.ClaimRoles, err = roleService.RolesFromClaims(c.Request.Context(), groups, service.TokenAppRoles(claims))
+		if err != nil {
+			log.Printf("Failed to read role claims for user %s: %v", entraID, err)
+			rolesResolved = false
+		}
+
+		if cache != nil && rolesResolved {
+			cache.Put(token, user, verified.Expiry)
+		}
+
 		c.Set("user", user) // Make user available in handlers
 		c.Next()
 	}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// RequestIDHeader carries the request ID in requests and responses
+	RequestIDHeader = "X-Request-ID"
+	// requestIDKey is the gin context key the request ID is stored under
+	requestIDKey = "requestID"
+
+	problemContentType = "application/problem+json"
+)
+
+// Problem is an RFC 7807 problem details response body
+type Problem struct {
+	Type      string                `json:"type"`
+	Title     string                `json:"title"`
+	Status    int                   `json:"status"`
+	Detail    string                `json:"detail,omitempty"`
+	Instance  string                `json:"instance,omitempty"`
+	Code      string                `json:"code"`
+	RequestID string                `json:"requestId,omitempty"`
+	Errors    []apperror.FieldError `json:"errors,omitempty"`
+}
+
+// RequestID assigns every request an ID, reusing the caller's X-Request-ID when present,
+// and echoes it in the response so client reports can be matched with server logs
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = uuid.NewString()
+		}
+		c.Set(requestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// GetRequestID returns the ID assigned by RequestID, or "" if the middleware is not installed
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// ErrorHandler renders the last error attached with c.Error as problem+json.
+// Handlers report failures with c.Error(err) and return; *apperror.Error values keep their
+// status, code and details, anything else becomes a generic 500.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := apperror.From(c.Errors.Last().Err)
+		log.Printf("[ERROR] %s %s (request %s): %v", c.Request.Method, c.Request.URL.Path, GetRequestID(c), err)
+		AbortWithProblem(c, err)
+	}
+}
+
+// AbortWithProblem writes err as problem+json and stops the handler chain. It is used
+// where the ErrorHandler is not guaranteed to run, such as in other middleware.
+func AbortWithProblem(c *gin.Context, err *apperror.Error) {
+	status := err.HTTPStatus()
+	problem := Problem{
+		Type:      "/problems/" + err.Code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    err.Message,
+		Instance:  c.Request.URL.Path,
+		Code:      err.Code,
+		RequestID: GetRequestID(c),
+		Errors:    err.Fields,
+	}
+
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(status, problem)
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"strings"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole only lets users through who hold at least one of the platform roles. It must run
+// after Auth, which puts the user in the context.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("user")
+		user, ok := value.(*model.User)
+		if !ok || user == nil {
+			AbortWithProblem(c, apperror.Unauthorized("authentication_required", "User not authenticated"))
+			return
+		}
+
+		if !user.HasRole(roles...) {
+			AbortWithProblem(c, apperror.Forbidden("role_required", "Requires one of the roles: "+strings.Join(roles, ", ")))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+)
+
+// Revocation kinds, as auth-service records them
+const (
+	RevokedSession = "session" // Value is a session ID
+	RevokedToken   = "token"   // Value is an ID token's jti (uti on Entra ID)
+	RevokedUser    = "user"    // Value is a user ID; every token issued before RevokedAt is revoked
+)
+
+// Revocation is an entry of auth-service's revocation list
+type Revocation struct {
+	Kind      string    `json:"kind"`
+	Value     string    `json:"value"`
+	RevokedAt time.Time `json:"revokedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// RevocationChecker tells whether a request's session or token was signed out
+type RevocationChecker interface {
+	// Revoked reports whether the session (empty without one) or the token with claims is revoked
+	Revoked(sessionID string, claims map[string]interface{}) bool
+}
+
+// RemoteRevocationList keeps a copy of auth-service's revocation list, refreshed by Start, so
+// checking a token costs no round trip. A revocation takes up to the refresh interval to reach
+// the backend. Until the first refresh, and while auth-service is unreachable, the last copy
+// is used.
+type RemoteRevocationList struct {
+	BaseURL string
+	Secret  string // INTERNAL_API_SECRET shared with auth-service
+	Client  *http.Client
+
+	mu      sync.RWMutex
+	entries map[string]Revocation // keyed by kind and value
+}
+
+func NewRemoteRevocationList(baseURL, secret string) *RemoteRevocationList {
+	return &RemoteRevocationList{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Secret:  secret,
+		Client:  &http.Client{Timeout: 5 * time.Second},
+		entries: make(map[string]Revocation),
+	}
+}
+
+// Start refreshes the list every interval until ctx is cancelled
+func (l *RemoteRevocationList) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := l.Refresh(ctx); err != nil {
+			log.Printf("Revocation list refresh failed, keeping the last copy: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh replaces the list with auth-service's current one
+func (l *RemoteRevocationList) Refresh(ctx context.Context) error {
+	var body struct {
+		Revocations []Revocation `json:"revocations"`
+	}
+	if err := l.call(ctx, http.MethodGet, "/auth/internal/revocations", &body); err != nil {
+		return err
+	}
+
+	entries := make(map[string]Revocation, len(body.Revocations))
+	for _, revocation := range body.Revocations {
+		entries[revocation.Kind+":"+revocation.Value] = revocation
+	}
+	l.mu.Lock()
+	l.entries = entries
+	l.mu.Unlock()
+	return nil
+}
+
+// SignOutUser has auth-service end all of the user's sessions and revoke every token issued to
+// the user so far, then refreshes the list so this instance rejects them right away. It returns
+// how many sessions were ended.
+func (l *RemoteRevocationList) SignOutUser(ctx context.Context, userID string) (int, error) {
+	var body struct {
+		SessionsEnded int `json:"sessionsEnded"`
+	}
+	if err := l.call(ctx, http.MethodPost, "/auth/internal/users/"+url.PathEscape(userID)+"/sign-out", &body); err != nil {
+		return 0, err
+	}
+	if err := l.Refresh(ctx); err != nil {
+		log.Printf("Revocation list refresh after signing out user %s failed: %v", userID, err)
+	}
+	return body.SessionsEnded, nil
+}
+
+func (l *RemoteRevocationList) call(ctx context.Context, method, path string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, l.BaseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+l.Secret)
+
+	resp, err := l.Client.Do(req)
+	if err != nil {
+		return apperror.DependencyFailed("auth_service_unavailable", "Auth service is unavailable", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return apperror.DependencyFailed("auth_service_unavailable", "Auth service is unavailable",
+			fmt.Errorf("%s %s returned status %d", method, path, resp.StatusCode))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return apperror.DependencyFailed("auth_service_unavailable", "Auth service returned an unreadable response", err)
+	}
+	return nil
+}
+
+func (l *RemoteRevocationList) lookup(kind, value string, now time.Time) (Revocation, bool) {
+	if value == "" {
+		return Revocation{}, false
+	}
+	revocation, ok := l.entries[kind+":"+value]
+	return revocation, ok && now.Before(revocation.ExpiresAt)
+}
+
+func (l *RemoteRevocationList) Revoked(sessionID string, claims map[string]interface{}) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	now := time.Now()
+	if _, ok := l.lookup(RevokedSession, sessionID, now); ok {
+		return true
+	}
+	if _, ok := l.lookup(RevokedToken, service.TokenID(claims), now); ok {
+		return true
+	}
+	if revocation, ok := l.lookup(RevokedUser, service.TokenUserID(claims), now); ok {
+		// Tokens issued in the second of the sign-out are revoked too; iat has no finer precision
+		iat, _ := claims["iat"].(float64)
+		return !time.Unix(int64(iat), 0).After(revocation.RevokedAt)
+	}
+	return false
+}
+
+// unverifiedClaims decodes a JWT's claims without checking its signature. It is only good for
+// rejecting a token, never for accepting one. Returns nil for malformed tokens.
+func unverifiedClaims(token string) map[string]interface{} {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil
+	}
+	return claims
+}
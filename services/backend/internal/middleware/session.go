@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+)
+
+// SessionCookie holds the opaque secret of an auth-service session
+const SessionCookie = "rosetta_session"
+
+// ErrSessionNotFound is returned for session cookies that are unknown, expired or revoked
+var ErrSessionNotFound = errors.New("session not found")
+
+// ResolvedSession is the part of an auth-service session the backend uses
+type ResolvedSession struct {
+	ID               string    `json:"id"`
+	UserID           string    `json:"userId"`
+	IDToken          string    `json:"idToken"`
+	GraphAccessToken string    `json:"graphAccessToken,omitempty"`
+	ExpiresAt        time.Time `json:"expiresAt"` // When IDToken expires
+}
+
+// SessionResolver turns a session cookie into the session's tokens
+type SessionResolver interface {
+	Resolve(ctx context.Context, secret string) (*ResolvedSession, error)
+}
+
+// RemoteSessionResolver asks auth-service for sessions and remembers answers for CacheTTL, so
+// a page load's burst of API calls costs one round trip
+type RemoteSessionResolver struct {
+	BaseURL  string
+	Secret   string // INTERNAL_API_SECRET shared with auth-service
+	CacheTTL time.Duration
+	Client   *http.Client
+
+	mu    sync.Mutex
+	cache map[string]resolvedSessionEntry // keyed by SHA-256 of the secret
+}
+
+type resolvedSessionEntry struct {
+	session   *ResolvedSession
+	expiresAt time.Time
+}
+
+func NewRemoteSessionResolver(baseURL, secret string, cacheTTL time.Duration) *RemoteSessionResolver {
+	return &RemoteSessionResolver{
+		BaseURL:  strings.TrimSuffix(baseURL, "/"),
+		Secret:   secret,
+		CacheTTL: cacheTTL,
+		Client:   &http.Client{Timeout: 5 * time.Second},
+		cache:    make(map[string]resolvedSessionEntry),
+	}
+}
+
+func (r *RemoteSessionResolver) Resolve(ctx context.Context, secret string) (*ResolvedSession, error) {
+	sum := sha256.Sum256([]byte(secret))
+	key := hex.EncodeToString(sum[:])
+
+	now := time.Now()
+	r.mu.Lock()
+	entry, ok := r.cache[key]
+	r.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.session, nil
+	}
+
+	session, err := r.fetch(ctx, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.CacheTTL > 0 {
+		expiresAt := now.Add(r.CacheTTL)
+		if session.ExpiresAt.Before(expiresAt) {
+			expiresAt = session.ExpiresAt
+		}
+		r.mu.Lock()
+		for k, e := range r.cache {
+			if !now.Before(e.expiresAt) {
+				delete(r.cache, k)
+			}
+		}
+		r.cache[key] = resolvedSessionEntry{session: session, expiresAt: expiresAt}
+		r.mu.Unlock()
+	}
+	return session, nil
+}
+
+func (r *RemoteSessionResolver) fetch(ctx context.Context, secret string) (*ResolvedSession, error) {
+	body, err := json.Marshal(map[string]string{"session": secret})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.BaseURL+"/auth/internal/sessions/resolve", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+r.Secret)
+
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return nil, apperror.DependencyFailed("auth_service_unavailable", "Auth service is unavailable", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var session ResolvedSession
+		if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+			return nil, apperror.DependencyFailed("auth_service_unavailable", "Auth service returned an unreadable session", err)
+		}
+		return &session, nil
+	case http.StatusNotFound:
+		return nil, ErrSessionNotFound
+	default:
+		return nil, apperror.DependencyFailed("auth_service_unavailable", "Auth service is unavailable",
+			fmt.Errorf("session resolve returned status %d", resp.StatusCode))
+	}
+}
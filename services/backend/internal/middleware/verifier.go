@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"github.com/coreos/go-oidc"
+	jose "gopkg.in/go-jose/go-jose.v2"
+)
+
+// TokenVerifier checks the signature, issuer, audience and expiry of an ID token
+type TokenVerifier interface {
+	Verify(ctx context.Context, rawToken string) (*VerifiedToken, error)
+}
+
+// VerifiedToken is the outcome of a successful verification
+type VerifiedToken struct {
+	Claims map[string]interface{}
+	Expiry time.Time
+}
+
+// Values of AUTH_VERIFIER
+const (
+	VerifierEntra  = "entra"  // Microsoft Entra ID tenant TENANT_ID (default)
+	VerifierOIDC   = "oidc"   // Any OpenID Connect issuer at OIDC_ISSUER_URL, keys found through discovery
+	VerifierStatic = "static" // Keys from AUTH_JWKS_FILE or the shared secret AUTH_HMAC_SECRET; for tests
+)
+
+// NewTokenVerifierFromEnv builds the verifier selected by AUTH_VERIFIER. Tokens must be issued
+// for CLIENT_ID.
+func NewTokenVerifierFromEnv() (TokenVerifier, error) {
+	clientID := os.Getenv("CLIENT_ID")
+
+	switch kind := os.Getenv("AUTH_VERIFIER"); kind {
+	case "", VerifierEntra:
+		return NewEntraVerifier(os.Getenv("TENANT_ID"), clientID), nil
+	case VerifierOIDC:
+		issuerURL := os.Getenv("OIDC_ISSUER_URL")
+		if issuerURL == "" {
+			return nil, errors.New("OIDC_ISSUER_URL is required when AUTH_VERIFIER=oidc")
+		}
+		return NewOIDCVerifier(issuerURL, clientID), nil
+	case VerifierStatic:
+		issuer := os.Getenv("OIDC_ISSUER_URL")
+		if secret := os.Getenv("AUTH_HMAC_SECRET"); secret != "" {
+			return NewHMACVerifier(issuer, clientID, []byte(secret)), nil
+		}
+		if path := os.Getenv("AUTH_JWKS_FILE"); path != "" {
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read AUTH_JWKS_FILE: %w", err)
+			}
+			return NewStaticJWKSVerifier(issuer, clientID, raw)
+		}
+		return nil, errors.New("AUTH_HMAC_SECRET or AUTH_JWKS_FILE is required when AUTH_VERIFIER=static")
+	default:
+		return nil, fmt.Errorf("unknown AUTH_VERIFIER %q", kind)
+	}
+}
+
+// NewEntraVerifier verifies ID tokens issued by a Microsoft Entra ID tenant
+func NewEntraVerifier(tenantID, clientID string) TokenVerifier {
+	return NewOIDCVerifier("https://login.microsoftonline.com/"+tenantID+"/v2.0", clientID)
+}
+
+// oidcVerifier finds the issuer's keys through OpenID Connect discovery. Discovery happens on the
+// first token rather than at startup, and is retried until it succeeds, so the API starts even
+// when the issuer is unreachable.
+type oidcVerifier struct {
+	issuerURL string
+	clientID  string
+
+	mu       sync.Mutex
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewOIDCVerifier verifies ID tokens issued by the OpenID Connect issuer at issuerURL
+func NewOIDCVerifier(issuerURL, clientID string) TokenVerifier {
+	return &oidcVerifier{issuerURL: issuerURL, clientID: clientID}
+}
+
+func (v *oidcVerifier) Verify(ctx context.Context, rawToken string) (*VerifiedToken, error) {
+	verifier, err := v.idTokenVerifier()
+	if err != nil {
+		return nil, apperror.DependencyFailed("identity_provider_unavailable", "The identity provider is unavailable", err)
+	}
+	return verifyIDToken(ctx, verifier, rawToken)
+}
+
+func (v *oidcVerifier) idTokenVerifier() (*oidc.IDTokenVerifier, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.verifier == nil {
+		// The provider fetches keys with this context later on, so it must outlive the request
+		provider, err := oidc.NewProvider(context.Background(), v.issuerURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover OIDC issuer %s: %w", v.issuerURL, err)
+		}
+		v.verifier = provider.Verifier(&oidc.Config{ClientID: v.clientID})
+	}
+	return v.verifier, nil
+}
+
+// staticVerifier checks tokens against keys known up front. The issuer is only checked when given.
+type staticVerifier struct {
+	verifier *oidc.IDTokenVerifier
+}
+
+func newStaticVerifier(issuer, clientID string, keySet oidc.KeySet, algs []string) TokenVerifier {
+	config := &oidc.Config{ClientID: clientID, SupportedSigningAlgs: algs, SkipIssuerCheck: issuer == ""}
+	return &staticVerifier{verifier: oidc.NewVerifier(issuer, keySet, config)}
+}
+
+// NewHMACVerifier verifies HS256 tokens signed with a shared secret
+func NewHMACVerifier(issuer, clientID string, secret []byte) TokenVerifier {
+	return newStaticVerifier(issuer, clientID, &staticKeySet{keys: []interface{}{secret}}, []string{string(jose.HS256)})
+}
+
+// NewStaticJWKSVerifier verifies tokens signed with one of the keys of a JSON Web Key Set
+func NewStaticJWKSVerifier(issuer, clientID string, rawJWKS []byte) (TokenVerifier, error) {
+	var jwks jose.JSONWebKeySet
+	if err := json.Unmarshal(rawJWKS, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+	if len(jwks.Keys) == 0 {
+		return nil, errors.New("JWKS has no keys")
+	}
+
+	keySet := &staticKeySet{}
+	algs := []string{}
+	for _, key := range jwks.Keys {
+		keySet.keys = append(keySet.keys, key.Key)
+		keySet.keyIDs = append(keySet.keyIDs, key.KeyID)
+		if key.Algorithm != "" {
+			algs = append(algs, key.Algorithm)
+		}
+	}
+	if len(algs) == 0 {
+		algs = []string{oidc.RS256}
+	}
+	return newStaticVerifier(issuer, clientID, keySet, algs), nil
+}
+
+func (v *staticVerifier) Verify(ctx context.Context, rawToken string) (*VerifiedToken, error) {
+	return verifyIDToken(ctx, v.verifier, rawToken)
+}
+
+// staticKeySet implements oidc.KeySet with keys held in memory
+type staticKeySet struct {
+	keys   []interface{}
+	keyIDs []string // parallel to keys; empty IDs match any token
+}
+
+func (s *staticKeySet) VerifySignature(_ context.Context, rawToken string) ([]byte, error) {
+	jws, err := jose.ParseSigned(rawToken)
+	if err != nil {
+		return nil, fmt.Errorf("malformed token: %w", err)
+	}
+	keyID := ""
+	if len(jws.Signatures) > 0 {
+		keyID = jws.Signatures[0].Header.KeyID
+	}
+
+	for i, key := range s.keys {
+		if i < len(s.keyIDs) && s.keyIDs[i] != "" && keyID != "" && s.keyIDs[i] != keyID {
+			continue
+		}
+		if payload, err := jws.Verify(key); err == nil {
+			return payload, nil
+		}
+	}
+	return nil, errors.New("no key matches the token's signature")
+}
+
+func verifyIDToken(ctx context.Context, verifier *oidc.IDTokenVerifier, rawToken string) (*VerifiedToken, error) {
+	idToken, err := verifier.Verify(ctx, rawToken)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := map[string]interface{}{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to parse claims: %w", err)
+	}
+	return &VerifiedToken{Claims: claims, Expiry: idToken.Expiry}, nil
+}
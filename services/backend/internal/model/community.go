@@ -1,17 +1,37 @@
 package model
 
 import (
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 )
 
+// Community groups learning paths and the users who create them. Communities are managed by
+// admins; an archived community keeps its paths but accepts no new ones.
 type Community struct {
-	ID          uuid.UUID      `gorm:"type:uuid;primaryKey" json:"ID"`
-	Name        string         `gorm:"size:200;not null" json:"Title"`
-	Description string         `gorm:"type:text" json:"Description"`
-	CreatedAt   time.Time      `json:"CreatedAt"`
-	UpdatedAt   time.Time      `json:"UpdatedAt"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"DeletedAt,omitempty"`
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"ID"`
+	Name        string    `gorm:"size:100;uniqueIndex;not null" json:"Name"`
+	Slug        string    `gorm:"size:100;uniqueIndex;not null" json:"Slug"` // URL-safe name, e.g. cloud-and-backend
+	Description string    `gorm:"type:text" json:"Description"`
+	Icon        string    `gorm:"size:100" json:"Icon"`
+	Color       string    `gorm:"size:7" json:"Color"` // Hex colour, e.g. #1E88E5
+	// RequiresApproval keeps learning paths private until a moderator approves their publication
+	RequiresApproval bool       `gorm:"not null;default:false" json:"RequiresApproval"`
+	ArchivedAt       *time.Time `gorm:"index" json:"ArchivedAt,omitempty"`
+	CreatedAt        time.Time  `json:"CreatedAt"`
+	UpdatedAt        time.Time  `json:"UpdatedAt"`
+}
+
+// IsArchived reports whether the community has been archived
+func (c *Community) IsArchived() bool {
+	return c.ArchivedAt != nil
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// CommunitySlug derives a slug from a community name: "Cloud and Backend" becomes "cloud-and-backend"
+func CommunitySlug(name string) string {
+	return strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
 }
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Community mapping audit actions
+const (
+	MappingAuditCreated  = "created"
+	MappingAuditDeleted  = "deleted"
+	MappingAuditImported = "imported" // Copied from COMMUNITY_GROUP_MAPPINGS at startup
+)
+
+// CommunityGroupMapping assigns members of an Entra ID group to a community. When a user is in
+// several mapped groups, the mapping with the lowest Priority wins.
+type CommunityGroupMapping struct {
+	ID          uint       `gorm:"primaryKey" json:"ID"`
+	GroupID     string     `gorm:"size:100;uniqueIndex;not null" json:"GroupID"` // Entra group object ID
+	GroupName   string     `gorm:"size:200" json:"GroupName"`                    // For admins only, not used for matching
+	CommunityID uuid.UUID  `gorm:"type:uuid;not null;index" json:"CommunityID"`
+	Community   *Community `gorm:"constraint:OnDelete:RESTRICT" json:"Community,omitempty"`
+	Priority    int        `gorm:"not null;default:0;index" json:"Priority"`
+	CreatedAt   time.Time  `json:"CreatedAt"`
+	UpdatedAt   time.Time  `json:"UpdatedAt"`
+}
+
+// CommunityMappingAudit records one change to the community group mappings. Entries are never
+// updated or deleted, and outlive the mapping they describe.
+type CommunityMappingAudit struct {
+	ID          uint      `gorm:"primaryKey" json:"ID"`
+	Action      string    `gorm:"size:20;not null" json:"Action"`
+	MappingID   uint      `gorm:"not null;index" json:"MappingID"`
+	GroupID     string    `gorm:"size:100;not null" json:"GroupID"`
+	CommunityID uuid.UUID `gorm:"type:uuid;not null" json:"CommunityID"`
+	Priority    int       `gorm:"not null" json:"Priority"`
+	ActorID     *uint     `json:"ActorID,omitempty"` // nil for changes made at startup
+	ActorEmail  string    `gorm:"size:100" json:"ActorEmail"`
+	CreatedAt   time.Time `gorm:"index" json:"CreatedAt"`
+}
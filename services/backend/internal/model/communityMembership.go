@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Where a community membership comes from
+const (
+	MembershipSourceGraph  = "graph"  // Derived from the user's Entra groups; replaced on every Graph sync
+	MembershipSourceManual = "manual" // Chosen by the user; kept across Graph syncs
+)
+
+// CommunityMembership records that a user belongs to a community. A user may belong to several;
+// User.CommunityID is their primary community and always one of them.
+type CommunityMembership struct {
+	UserID      uint       `gorm:"primaryKey" json:"UserID"`
+	CommunityID uuid.UUID  `gorm:"type:uuid;primaryKey;index" json:"CommunityID"`
+	Community   *Community `gorm:"constraint:OnDelete:CASCADE" json:"Community,omitempty"`
+	Source      string     `gorm:"size:20;not null" json:"Source"`
+	CreatedAt   time.Time  `json:"CreatedAt"`
+}
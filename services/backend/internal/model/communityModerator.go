@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CommunityModerator lets a user manage the learning paths of a community: edit and delete any of
+// them, choose featured paths and approve publication. Moderators are appointed by admins.
+type CommunityModerator struct {
+	UserID      uint       `gorm:"primaryKey" json:"UserID"`
+	CommunityID uuid.UUID  `gorm:"type:uuid;primaryKey;index" json:"CommunityID"`
+	User        *User      `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Community   *Community `gorm:"constraint:OnDelete:CASCADE" json:"Community,omitempty"`
+	CreatedAt   time.Time  `json:"CreatedAt"`
+}
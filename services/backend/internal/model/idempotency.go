@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Idempotency record statuses
+const (
+	IdempotencyStatusInProgress = "in_progress" // The first request with the key is still running
+	IdempotencyStatusCompleted  = "completed"   // The response is stored and replayed on retries
+)
+
+// IdempotencyRecord stores the outcome of a request sent with an Idempotency-Key header, so a
+// client retrying after a timeout gets the original response instead of creating a duplicate.
+// Keys are scoped per user and expire after a TTL.
+type IdempotencyRecord struct {
+	ID     uuid.UUID `gorm:"type:uuid;primaryKey" json:"ID"`
+	UserID uint      `gorm:"not null;uniqueIndex:idx_idempotency_user_key" json:"UserID"`
+	Key    string    `gorm:"column:idempotency_key;size:255;not null;uniqueIndex:idx_idempotency_user_key" json:"Key"`
+	// RequestHash fingerprints the payload; reusing the key with a different payload is rejected
+	RequestHash    string     `gorm:"size:64;not null" json:"RequestHash"`
+	Status         string     `gorm:"size:20;not null" json:"Status"`
+	ResponseStatus int        `json:"ResponseStatus"`
+	ResponseBody   string     `gorm:"type:text" json:"ResponseBody"`
+	LPID           *uuid.UUID `gorm:"type:uuid" json:"LPID,omitempty"`
+	ExpiresAt      time.Time  `gorm:"not null;index" json:"ExpiresAt"`
+	CreatedAt      time.Time  `json:"CreatedAt"`
+	UpdatedAt      time.Time  `json:"UpdatedAt"`
+}
@@ -7,18 +7,30 @@ import (
 	"gorm.io/gorm"
 )
 
+// Publication statuses of a learning path in a community that requires approval
+const (
+	PublicationPending  = "PENDING"  // Waiting for a moderator; the path stays private meanwhile
+	PublicationApproved = "APPROVED" // Made public by a moderator
+	PublicationRejected = "REJECTED" // Kept private by a moderator
+)
+
 type LearningPath struct {
-	ID          uuid.UUID      `gorm:"type:uuid;primaryKey" json:"ID"`
-	Title       string         `gorm:"size:200;not null" json:"Title"`
-	Description string         `gorm:"type:text" json:"Description"`
-	IsPublic    bool           `gorm:"not null" json:"IsPublic"`
-	Thumbnail   string         `gorm:"type:text" json:"Thumbnail"`
-	Community   string         `gorm:"size:100"`
-	DiagramID   string         `gorm:"size:24;index:unique,unique_diagram_id;not null" json:"DiagramID"` // MongoDB ObjectID
-	Users       []UserLP       `gorm:"foreignKey:LPID" json:"Users,omitempty"`
-	Skills      []LPSkill      `gorm:"foreignKey:LPID" json:"-"`  // Don't serialize join table
-	SkillsList  []Skill        `gorm:"-" json:"Skills,omitempty"` // Custom field for serialized skills
-	CreatedAt   time.Time      `json:"CreatedAt"`
-	UpdatedAt   time.Time      `json:"UpdatedAt"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"DeletedAt,omitempty"`
+	ID                uuid.UUID      `gorm:"type:uuid;primaryKey" json:"ID"`
+	Title             string         `gorm:"size:200;not null" json:"Title"`
+	Description       string         `gorm:"type:text" json:"Description"`
+	IsPublic          bool           `gorm:"not null" json:"IsPublic"`
+	Thumbnail         string         `gorm:"type:text" json:"Thumbnail"`
+	CommunityID       *uuid.UUID     `gorm:"type:uuid;index" json:"CommunityID,omitempty"`
+	Community         *Community     `gorm:"constraint:OnDelete:RESTRICT" json:"Community,omitempty"`
+	CreatedByID       *uint          `gorm:"index" json:"CreatedByID,omitempty"`                               // nil for paths created before creators were recorded
+	FeaturedAt        *time.Time     `gorm:"index" json:"FeaturedAt,omitempty"`                                // Set while a moderator features the path
+	PublicationStatus string         `gorm:"size:20;index" json:"PublicationStatus,omitempty"`                 // One of the Publication* statuses; empty if never reviewed
+	DiagramID         string         `gorm:"size:24;index:unique,unique_diagram_id;not null" json:"DiagramID"` // MongoDB ObjectID
+	Users             []UserLP       `gorm:"foreignKey:LPID" json:"Users,omitempty"`
+	Skills            []LPSkill      `gorm:"foreignKey:LPID" json:"-"`  // Don't serialize join table
+	SkillsList        []Skill        `gorm:"-" json:"Skills,omitempty"` // Custom field for serialized skills
+	SkillNames        string         `gorm:"type:text" json:"-"`        // Space-separated skill names, indexed for search
+	CreatedAt         time.Time      `json:"CreatedAt"`
+	UpdatedAt         time.Time      `json:"UpdatedAt"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"DeletedAt,omitempty"`
 }
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Outbox message types, one per backend-editor side-effect
+const (
+	OutboxTypeDiagramRename = "diagram.rename"
+	OutboxTypeDiagramDelete = "diagram.delete"
+)
+
+// Outbox message statuses
+const (
+	OutboxStatusPending    = "pending"    // Waiting for (re)delivery
+	OutboxStatusDelivered  = "delivered"  // Acknowledged by backend-editor
+	OutboxStatusSuperseded = "superseded" // Replaced by a newer message before delivery
+	OutboxStatusDead       = "dead"       // Gave up after max attempts, requires manual intervention
+)
+
+// OutboxMessage is a backend-editor side-effect written in the same transaction as the
+// learning path change that caused it, and delivered asynchronously by the OutboxDispatcher.
+// Messages for the same aggregate (learning path) are delivered in creation order.
+type OutboxMessage struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey" json:"ID"`
+	AggregateID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"AggregateID"`
+	Type          string     `gorm:"size:50;not null" json:"Type"`
+	Payload       string     `gorm:"type:text" json:"Payload"` // JSON-encoded, message type specific
+	Status        string     `gorm:"size:20;not null;index" json:"Status"`
+	Attempts      int        `gorm:"not null;default:0" json:"Attempts"`
+	NextAttemptAt time.Time  `gorm:"not null;index" json:"NextAttemptAt"`
+	LastError     string     `gorm:"type:text" json:"LastError,omitempty"`
+	DeliveredAt   *time.Time `json:"DeliveredAt,omitempty"`
+	CreatedAt     time.Time  `gorm:"index" json:"CreatedAt"`
+	UpdatedAt     time.Time  `json:"UpdatedAt"`
+}
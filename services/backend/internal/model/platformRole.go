@@ -0,0 +1,58 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Platform roles apply across the whole application, unlike the per-learning-path roles in role.go
+const (
+	PlatformRoleAdmin   = "admin"   // Manages communities, mappings, roles and every learning path
+	PlatformRoleAuditor = "auditor" // Reads admin listings and audit logs without changing anything
+	PlatformRoleSupport = "support" // Looks up users and their roles to help them
+)
+
+// PlatformRoles lists every platform role
+var PlatformRoles = []string{PlatformRoleAdmin, PlatformRoleAuditor, PlatformRoleSupport}
+
+// Where a platform role assignment comes from
+const (
+	RoleSourceManual    = "manual"       // Assigned through the admin API
+	RoleSourceBootstrap = "ADMIN_EMAILS" // Listed in ADMIN_EMAILS; assigned at startup or on first sign-in
+)
+
+// UserPlatformRole assigns a platform role to a user
+type UserPlatformRole struct {
+	UserID      uint      `gorm:"primaryKey" json:"UserID"`
+	Role        string    `gorm:"size:20;primaryKey" json:"Role"`
+	Source      string    `gorm:"size:20;not null" json:"Source"`
+	GrantedByID *uint     `json:"GrantedByID,omitempty"` // nil for bootstrapped admins
+	CreatedAt   time.Time `json:"CreatedAt"`
+}
+
+// AdminEmails parses ADMIN_EMAILS (comma-separated) into lower-case addresses
+func AdminEmails(raw string) []string {
+	emails := []string{}
+	for _, email := range strings.Split(raw, ",") {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+// Claims of the ID token that can grant platform roles
+const (
+	ClaimTypeGroup   = "groups" // Entra group object IDs
+	ClaimTypeAppRole = "roles"  // App roles assigned to the user in the Entra app registration
+)
+
+// PlatformRoleClaimMapping grants a platform role to everyone whose ID token carries a group or
+// app role. Such roles are not stored per user: they last as long as the claim does.
+type PlatformRoleClaimMapping struct {
+	ID        uint      `gorm:"primaryKey" json:"ID"`
+	ClaimType string    `gorm:"size:20;not null;uniqueIndex:idx_role_claim" json:"ClaimType"`
+	Value     string    `gorm:"size:200;not null;uniqueIndex:idx_role_claim" json:"Value"`
+	Role      string    `gorm:"size:20;not null;uniqueIndex:idx_role_claim" json:"Role"`
+	CreatedAt time.Time `json:"CreatedAt"`
+}
@@ -1,12 +1,21 @@
 package model
 
 import (
-
 	"gorm.io/gorm"
 )
 
+// Per-learning-path roles, stored on UserLP.RoleID
+const (
+	RoleOwner  = "OWNER"  // Created the path; may change, delete and share it
+	RoleAuthor = "AUTHOR" // May change the path and share it
+	RoleEditor = "EDITOR" // May change the path
+	RoleReader = "READER" // May view the path while it is private
+)
+
+// LearningPathRoles lists every per-path role, seeded at migration time
+var LearningPathRoles = []string{RoleOwner, RoleAuthor, RoleEditor, RoleReader}
 
 type Role struct {
 	gorm.Model
-	Name string `gorm:"size:50;unique;not null"` // e.g., 'AUTHOR', 'READER'
+	Name string `gorm:"size:50;unique;not null"` // One of the Role* constants
 }
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Saga types (updates and deletes go through the outbox instead)
+const (
+	SagaTypeCreateLearningPath = "create_learning_path"
+)
+
+// Saga statuses
+const (
+	SagaStatusRunning      = "running"      // Forward steps in progress
+	SagaStatusCompensating = "compensating" // A step failed and compensation has not finished yet
+	SagaStatusCompleted    = "completed"    // All forward steps succeeded
+	SagaStatusCompensated  = "compensated"  // Rolled back successfully
+	SagaStatusFailed       = "failed"       // Gave up after max attempts, requires manual intervention
+)
+
+// SagaLog persists the progress of a distributed learning path operation so that
+// unfinished sagas can be resumed or compensated after a crash.
+type SagaLog struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"ID"`
+	Type      string    `gorm:"size:50;not null;index" json:"Type"`
+	LPID      uuid.UUID `gorm:"type:uuid;not null;index" json:"LPID"`
+	Step      string    `gorm:"size:50;not null" json:"Step"`
+	Payload   string    `gorm:"type:text" json:"Payload"` // JSON-encoded, saga type specific
+	Status    string    `gorm:"size:20;not null;index" json:"Status"`
+	Attempts  int       `gorm:"not null;default:0" json:"Attempts"`
+	LastError string    `gorm:"type:text" json:"LastError,omitempty"`
+	CreatedAt time.Time `json:"CreatedAt"`
+	UpdatedAt time.Time `gorm:"index" json:"UpdatedAt"`
+}
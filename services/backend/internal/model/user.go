@@ -3,17 +3,132 @@ package model
 import (
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type User struct {
 	gorm.Model
-	Name          string      `gorm:"size:100;not null"`
-	Email         string      `gorm:"size:100;unique;not null"`
-	EntraID       string      `gorm:"size:100;unique"`
-	PhotoURL      string      `gorm:"type:text"`
-	Community     string      `gorm:"size:100"`
-	LastGraphSync *time.Time  `gorm:"index"`
-	Skills        []UserSkill `gorm:"foreignKey:UserID"`
-	LearningPaths []UserLP    `gorm:"foreignKey:UserID"`
+	Name          string                `gorm:"size:100;not null"`
+	Email         string                `gorm:"size:100;unique;not null"`
+	EntraID       string                `gorm:"size:100;unique"`
+	PhotoURL      string                `gorm:"type:text"`
+	CommunityID   *uuid.UUID            `gorm:"type:uuid;index"` // Primary community
+	Community     *Community            `gorm:"constraint:OnDelete:RESTRICT"`
+	Memberships   []CommunityMembership `gorm:"foreignKey:UserID"`
+	Moderates     []CommunityModerator  `gorm:"foreignKey:UserID"`
+	PlatformRoles []UserPlatformRole    `gorm:"foreignKey:UserID"`
+	ClaimRoles    []string              `gorm:"-"` // Platform roles granted by the current ID token's claims
+	LastGraphSync *time.Time            `gorm:"index"`
+	Skills        []UserSkill           `gorm:"foreignKey:UserID"`
+	LearningPaths []UserLP              `gorm:"foreignKey:UserID"`
+}
+
+// CommunityName returns the name of the user's community, or "" if they have none or it was not loaded
+func (u *User) CommunityName() string {
+	if u.Community == nil {
+		return ""
+	}
+	return u.Community.Name
+}
+
+// CommunityIDs returns the IDs of every community the user belongs to, primary community first
+func (u *User) CommunityIDs() []uuid.UUID {
+	var ids []uuid.UUID
+	if u.CommunityID != nil {
+		ids = append(ids, *u.CommunityID)
+	}
+	for _, membership := range u.Memberships {
+		if u.CommunityID == nil || membership.CommunityID != *u.CommunityID {
+			ids = append(ids, membership.CommunityID)
+		}
+	}
+	return ids
+}
+
+// IsMemberOf reports whether the user belongs to the community, as their primary one or otherwise
+func (u *User) IsMemberOf(communityID uuid.UUID) bool {
+	for _, id := range u.CommunityIDs() {
+		if id == communityID {
+			return true
+		}
+	}
+	return false
+}
+
+// CommunityNames returns the names of the user's communities, primary community first. Only
+// communities that were loaded are included.
+func (u *User) CommunityNames() []string {
+	names := []string{}
+	if u.Community != nil {
+		names = append(names, u.Community.Name)
+	}
+	for _, membership := range u.Memberships {
+		if membership.Community != nil && (u.CommunityID == nil || membership.CommunityID != *u.CommunityID) {
+			names = append(names, membership.Community.Name)
+		}
+	}
+	return names
+}
+
+// ModeratedCommunityIDs returns the IDs of the communities the user moderates
+func (u *User) ModeratedCommunityIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, len(u.Moderates))
+	for i, moderator := range u.Moderates {
+		ids[i] = moderator.CommunityID
+	}
+	return ids
+}
+
+// ModeratedCommunityNames returns the names of the communities the user moderates. Only
+// communities that were loaded are included.
+func (u *User) ModeratedCommunityNames() []string {
+	names := []string{}
+	for _, moderator := range u.Moderates {
+		if moderator.Community != nil {
+			names = append(names, moderator.Community.Name)
+		}
+	}
+	return names
+}
+
+// IsModeratorOf reports whether the user moderates the community
+func (u *User) IsModeratorOf(communityID uuid.UUID) bool {
+	for _, moderator := range u.Moderates {
+		if moderator.CommunityID == communityID {
+			return true
+		}
+	}
+	return false
+}
+
+// Roles returns the user's platform roles, whether assigned or granted by token claims
+func (u *User) Roles() []string {
+	roles := []string{}
+	seen := make(map[string]bool)
+	add := func(role string) {
+		if !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	for _, assignment := range u.PlatformRoles {
+		add(assignment.Role)
+	}
+	for _, role := range u.ClaimRoles {
+		add(role)
+	}
+	return roles
+}
+
+// HasRole reports whether the user has at least one of the platform roles
+func (u *User) HasRole(roles ...string) bool {
+	for _, have := range u.Roles() {
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
 }
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+)
+
+// AuthCache remembers the user behind each verified token, so repeated requests with the same
+// token skip signature verification and the user lookup. Entries live for TTL, but never past
+// the token's expiry. Changes the services report through their change hooks show right away;
+// any other change to a user shows after at most TTL.
+type AuthCache struct {
+	// TTL bounds how long a user lookup is reused
+	TTL time.Duration
+	// MaxEntries bounds the memory used; expired entries are dropped first when it is reached
+	MaxEntries int
+
+	mu      sync.Mutex
+	entries map[string]authCacheEntry // keyed by SHA-256 of the token
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+}
+
+type authCacheEntry struct {
+	user      *model.User
+	expiresAt time.Time
+}
+
+// AuthCacheSnapshot describes an AuthCache for health reporting
+type AuthCacheSnapshot struct {
+	Entries   int     `json:"entries"`
+	Hits      int64   `json:"hits"`
+	Misses    int64   `json:"misses"`
+	Evictions int64   `json:"evictions"`
+	HitRatio  float64 `json:"hitRatio"`
+}
+
+func NewAuthCache(ttl time.Duration, maxEntries int) *AuthCache {
+	return &AuthCache{
+		TTL:        ttl,
+		MaxEntries: maxEntries,
+		entries:    make(map[string]authCacheEntry),
+	}
+}
+
+func tokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns a copy of the user cached for token. Callers may change the copy's fields but
+// must not modify the slices it shares with the cache.
+func (c *AuthCache) Get(token string) (*model.User, bool) {
+	key := tokenKey(token)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && !time.Now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	user := *entry.user
+	return &user, true
+}
+
+// Put caches user for token until TTL passes or the token expires, whichever comes first
+func (c *AuthCache) Put(token string, user *model.User, tokenExpiry time.Time) {
+	expiresAt := time.Now().Add(c.TTL)
+	if tokenExpiry.Before(expiresAt) {
+		expiresAt = tokenExpiry
+	}
+	if !time.Now().Before(expiresAt) {
+		return
+	}
+	cached := *user
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.MaxEntries > 0 && len(c.entries) >= c.MaxEntries {
+		c.evictLocked()
+	}
+	c.entries[tokenKey(token)] = authCacheEntry{user: &cached, expiresAt: expiresAt}
+}
+
+// evictLocked drops expired entries, and arbitrary ones if that does not free enough room
+func (c *AuthCache) evictLocked() {
+	now := time.Now()
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+			c.evictions.Add(1)
+		}
+	}
+	for key := range c.entries {
+		if len(c.entries) < c.MaxEntries {
+			return
+		}
+		delete(c.entries, key)
+		c.evictions.Add(1)
+	}
+}
+
+// InvalidateUser forgets every token of the user with the given Entra object ID
+func (c *AuthCache) InvalidateUser(entraID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if entry.user.EntraID == entraID {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Clear forgets every cached token, e.g. after a change that may affect any user's roles
+func (c *AuthCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]authCacheEntry)
+}
+
+// Snapshot returns the size and hit/miss counters of the cache
+func (c *AuthCache) Snapshot() AuthCacheSnapshot {
+	c.mu.Lock()
+	entries := len(c.entries)
+	c.mu.Unlock()
+
+	snapshot := AuthCacheSnapshot{
+		Entries:   entries,
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+	if total := snapshot.Hits + snapshot.Misses; total > 0 {
+		snapshot.HitRatio = float64(snapshot.Hits) / float64(total)
+	}
+	return snapshot
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Collaborator is a user holding a role on a learning path
+type Collaborator struct {
+	UserID uint   `json:"userId"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+}
+
+var errOwnerRoleImmutable = apperror.Conflict("owner_role_immutable", "The owner's role cannot be changed or removed")
+
+// grantRole gives userID the named role on lpID, replacing any role they had. Favorites are kept.
+func grantRole(tx *gorm.DB, userID uint, lpID uuid.UUID, roleName string) error {
+	var role model.Role
+	if err := tx.Where("name = ?", roleName).First(&role).Error; err != nil {
+		return fmt.Errorf("failed to load role %s: %w", roleName, err)
+	}
+
+	err := tx.Where(model.UserLP{UserID: userID, LPID: lpID}).
+		Assign(model.UserLP{RoleID: &role.ID}).
+		FirstOrCreate(&model.UserLP{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to grant role %s: %w", roleName, err)
+	}
+	return nil
+}
+
+// ListCollaborators returns the users holding a role on a learning path the viewer can see
+func (s *LearningPathService) ListCollaborators(ctx context.Context, viewer Viewer, lpID string) ([]Collaborator, error) {
+	lp, err := s.Authorize(ctx, viewer, lpID, ActionView)
+	if err != nil {
+		return nil, err
+	}
+
+	collaborators := []Collaborator{}
+	err = s.DB.WithContext(ctx).Model(&model.UserLP{}).
+		Select("users.id AS user_id, users.name, users.email, roles.name AS role").
+		Joins("JOIN users ON users.id = user_lps.user_id").
+		Joins("JOIN roles ON roles.id = user_lps.role_id").
+		Where("user_lps.lp_id = ?", lp.ID).
+		Order("user_lps.created_at").
+		Scan(&collaborators).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collaborators: %w", err)
+	}
+	return collaborators, nil
+}
+
+// SetCollaborator gives the user with the given email an AUTHOR, EDITOR or READER role on a
+// learning path, or changes the role they have. Ownership cannot be granted or taken this way.
+func (s *LearningPathService) SetCollaborator(ctx context.Context, viewer Viewer, lpID, email, roleName string) (*Collaborator, error) {
+	if roleName == model.RoleOwner || rolePermissions[roleName] == nil {
+		return nil, apperror.Validation("invalid_role", "Invalid collaborator role",
+			apperror.FieldError{Field: "role", Code: "oneof", Message: "role must be one of AUTHOR, EDITOR, READER"})
+	}
+
+	lp, err := s.Authorize(ctx, viewer, lpID, ActionManageCollaborators)
+	if err != nil {
+		return nil, err
+	}
+
+	var user model.User
+	if err := s.DB.WithContext(ctx).Where("LOWER(email) = LOWER(?)", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("user_not_found", "No user with this email has signed in yet")
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	err = s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current, err := viewerRole(tx, user.ID, lp)
+		if err != nil {
+			return err
+		}
+		if current == model.RoleOwner {
+			return errOwnerRoleImmutable
+		}
+		return grantRole(tx, user.ID, lp.ID, roleName)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Collaborator{UserID: user.ID, Name: user.Name, Email: user.Email, Role: roleName}, nil
+}
+
+// RemoveCollaborator takes away the role userID holds on a learning path. Their favorite is kept.
+func (s *LearningPathService) RemoveCollaborator(ctx context.Context, viewer Viewer, lpID string, userID uint) error {
+	lp, err := s.Authorize(ctx, viewer, lpID, ActionManageCollaborators)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current, err := viewerRole(tx, userID, lp)
+		if err != nil {
+			return err
+		}
+		switch current {
+		case "":
+			return apperror.NotFound("collaborator_not_found", "The user has no role on this learning path")
+		case model.RoleOwner:
+			return errOwnerRoleImmutable
+		}
+
+		err = tx.Model(&model.UserLP{}).
+			Where("user_id = ? AND lp_id = ?", userID, lp.ID).
+			Update("role_id", nil).Error
+		if err != nil {
+			return fmt.Errorf("failed to remove collaborator: %w", err)
+		}
+		return nil
+	})
+}
@@ -1,37 +1,236 @@
 package service
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
-type CommunityService struct{}
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
 
-func NewCommunityService() *CommunityService {
-	return &CommunityService{}
+type CommunityService struct {
+	DB *gorm.DB
+	// OnRolesChanged, when set, is called with the user's Entra object ID after they were
+	// appointed or removed as a moderator, e.g. to drop cached copies of the user
+	OnRolesChanged func(entraID string)
 }
 
-// Future Enhancement:
-// - Could be moved to configuration file, environment variable or DB if needed
-// - Could be extended to include metadata (description, icon, color)
-func (s *CommunityService) GetCommunities(ctx context.Context) []string {
-	return []string{
-		"Autonomous Systems",
-		"Cloud and Backend",
-		"Connectivity",
-		"Cyber Security and Software Update",
-		"Data Analytics and Data Science",
-		"E2E Solution Architecture",
-		"Embedded Software Connect",
-		"Engineering Operations and Network Integration",
-		"Frontends and Digital Experiences",
+func NewCommunityService(db *gorm.DB) *CommunityService {
+	return &CommunityService{DB: db}
+}
+
+// communityRefFilter matches rows whose community_id points at the community with the given
+// name or slug; it takes the name or slug twice
+const communityRefFilter = "community_id IN (SELECT id FROM communities WHERE name = ? OR slug = ?)"
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+var (
+	errCommunityNotFound = apperror.NotFound("community_not_found", "Community not found")
+	errCommunityArchived = apperror.Conflict("community_archived", "This community is archived and accepts no new learning paths")
+)
+
+// CommunityInput holds the editable fields of a community. Nil fields are left unchanged on update.
+type CommunityInput struct {
+	Name             *string
+	Slug             *string
+	Description      *string
+	Icon             *string
+	Color            *string
+	RequiresApproval *bool
+}
+
+// GetCommunities returns the communities ordered by name. Archived ones are only included when asked for.
+func (s *CommunityService) GetCommunities(ctx context.Context, includeArchived bool) ([]model.Community, error) {
+	db := s.DB.WithContext(ctx).Order("name ASC")
+	if !includeArchived {
+		db = db.Where("archived_at IS NULL")
+	}
+
+	var communities []model.Community
+	if err := db.Find(&communities).Error; err != nil {
+		return nil, fmt.Errorf("failed to list communities: %w", err)
+	}
+	return communities, nil
+}
+
+// findCommunity looks a community up by name or slug
+func findCommunity(db *gorm.DB, nameOrSlug string) (*model.Community, error) {
+	var community model.Community
+	err := db.Where("name = ? OR slug = ?", nameOrSlug, nameOrSlug).First(&community).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errCommunityNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find community: %w", err)
+	}
+	return &community, nil
+}
+
+// GetCommunity looks a community up by name or slug, archived or not
+func (s *CommunityService) GetCommunity(ctx context.Context, nameOrSlug string) (*model.Community, error) {
+	return findCommunity(s.DB.WithContext(ctx), nameOrSlug)
+}
+
+// GetActiveCommunity looks a community up by name or slug and fails if it is archived
+func (s *CommunityService) GetActiveCommunity(ctx context.Context, nameOrSlug string) (*model.Community, error) {
+	community, err := s.GetCommunity(ctx, nameOrSlug)
+	if err != nil {
+		return nil, err
+	}
+	if community.IsArchived() {
+		return nil, errCommunityArchived
+	}
+	return community, nil
+}
+
+// IsValidCommunity checks if a community with this name or slug exists. Archived communities
+// are still valid: their learning paths can be read.
+func (s *CommunityService) IsValidCommunity(ctx context.Context, nameOrSlug string) (bool, error) {
+	_, err := s.GetCommunity(ctx, nameOrSlug)
+	if apperror.IsKind(err, apperror.KindNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// CreateCommunity adds a community. The slug is derived from the name when not given.
+func (s *CommunityService) CreateCommunity(ctx context.Context, input CommunityInput) (*model.Community, error) {
+	community := model.Community{ID: uuid.New()}
+	if err := applyCommunityInput(&community, input); err != nil {
+		return nil, err
+	}
+
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkCommunityUnique(tx, &community); err != nil {
+			return err
+		}
+		return tx.Create(&community).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create community: %w", err)
+	}
+	return &community, nil
+}
+
+// UpdateCommunity changes the fields set in input, e.g. to rename a community. Learning paths and
+// users reference communities by ID, so they follow a rename.
+func (s *CommunityService) UpdateCommunity(ctx context.Context, id string, input CommunityInput) (*model.Community, error) {
+	var community model.Community
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := loadCommunity(tx, id, &community); err != nil {
+			return err
+		}
+		if err := applyCommunityInput(&community, input); err != nil {
+			return err
+		}
+		if err := checkCommunityUnique(tx, &community); err != nil {
+			return err
+		}
+		return tx.Save(&community).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update community: %w", err)
 	}
+	return &community, nil
 }
 
-// IsValidCommunity checks if a community name exists in the valid communities list
-func (s *CommunityService) IsValidCommunity(ctx context.Context, name string) bool {
-	validCommunities := s.GetCommunities(ctx)
-	for _, community := range validCommunities {
-		if community == name {
-			return true
+// SetCommunityArchived archives or restores a community. Archiving keeps its learning paths and
+// members, but no new learning paths can be created in it.
+func (s *CommunityService) SetCommunityArchived(ctx context.Context, id string, archived bool) (*model.Community, error) {
+	var community model.Community
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := loadCommunity(tx, id, &community); err != nil {
+			return err
 		}
+		if archived == community.IsArchived() {
+			return nil
+		}
+
+		community.ArchivedAt = nil
+		if archived {
+			now := time.Now()
+			community.ArchivedAt = &now
+		}
+		return tx.Model(&community).Update("archived_at", community.ArchivedAt).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive community: %w", err)
+	}
+	return &community, nil
+}
+
+func loadCommunity(tx *gorm.DB, id string, community *model.Community) error {
+	communityID, err := uuid.Parse(id)
+	if err != nil {
+		return apperror.Validation("invalid_community_id", "Invalid community ID format").Wrap(err)
+	}
+	if err := tx.Where("id = ?", communityID).First(community).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errCommunityNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// applyCommunityInput copies the set fields of input onto community and validates the result
+func applyCommunityInput(community *model.Community, input CommunityInput) error {
+	if input.Name != nil {
+		community.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Slug != nil {
+		community.Slug = strings.TrimSpace(*input.Slug)
+	}
+	if input.Description != nil {
+		community.Description = *input.Description
+	}
+	if input.Icon != nil {
+		community.Icon = *input.Icon
+	}
+	if input.Color != nil {
+		community.Color = *input.Color
+	}
+	if input.RequiresApproval != nil {
+		community.RequiresApproval = *input.RequiresApproval
+	}
+	if community.Slug == "" {
+		community.Slug = model.CommunitySlug(community.Name)
+	}
+
+	var fields []apperror.FieldError
+	if community.Name == "" {
+		fields = append(fields, apperror.FieldError{Field: "name", Code: "required", Message: "name is required"})
+	}
+	if !slugPattern.MatchString(community.Slug) {
+		fields = append(fields, apperror.FieldError{Field: "slug", Code: "slug", Message: "slug may only contain lowercase letters, digits and single hyphens"})
+	}
+	if len(fields) > 0 {
+		return apperror.Validation("invalid_community", "Invalid community", fields...)
+	}
+	return nil
+}
+
+// checkCommunityUnique rejects a name or slug already used by another community. Names are
+// compared case-insensitively so "Cloud and backend" cannot sit next to "Cloud and Backend".
+func checkCommunityUnique(tx *gorm.DB, community *model.Community) error {
+	var existing model.Community
+	err := tx.Where("id <> ? AND (LOWER(name) = LOWER(?) OR slug = ?)", community.ID, community.Name, community.Slug).
+		First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(existing.Name, community.Name) {
+		return apperror.Conflict("community_name_taken", "A community with this name already exists")
 	}
-	return false
+	return apperror.Conflict("community_slug_taken", "A community with this slug already exists")
 }
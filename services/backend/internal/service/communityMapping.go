@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultMappingAuditLimit and MaxMappingAuditLimit bound GET /api/admin/community-mappings/audit
+const (
+	DefaultMappingAuditLimit = 50
+	MaxMappingAuditLimit     = 500
+)
+
+// CommunityMappingService manages which Entra ID groups put their members in which community
+type CommunityMappingService struct {
+	DB *gorm.DB
+	// OnMappingsChanged, when set, is called after a mapping was added or removed, which may
+	// change the communities of any user
+	OnMappingsChanged func()
+}
+
+func NewCommunityMappingService(db *gorm.DB) *CommunityMappingService {
+	return &CommunityMappingService{DB: db}
+}
+
+// CommunityMatch is the outcome of matching a user's groups against the mappings
+type CommunityMatch struct {
+	Community   *model.Community              `json:"community"`   // Primary community; nil when no mapping applies
+	Communities []model.Community             `json:"communities"` // Every active community the user is mapped to, in priority order
+	Matches     []model.CommunityGroupMapping `json:"matches"`     // Every mapping of the user's groups, in priority order
+}
+
+var errMappingNotFound = apperror.NotFound("community_mapping_not_found", "Community mapping not found")
+
+// GetMappings returns all mappings in priority order
+func (s *CommunityMappingService) GetMappings(ctx context.Context) ([]model.CommunityGroupMapping, error) {
+	var mappings []model.CommunityGroupMapping
+	if err := s.DB.WithContext(ctx).Preload("Community").Order("priority ASC, id ASC").Find(&mappings).Error; err != nil {
+		return nil, fmt.Errorf("failed to list community mappings: %w", err)
+	}
+	return mappings, nil
+}
+
+// AddMapping maps groupID to the community with the given name or slug. A group maps to at
+// most one community.
+func (s *CommunityMappingService) AddMapping(ctx context.Context, actor *model.User, groupID, groupName, community string, priority int) (*model.CommunityGroupMapping, error) {
+	groupID = strings.TrimSpace(groupID)
+	if groupID == "" {
+		return nil, apperror.Validation("validation_failed", "One or more fields are invalid",
+			apperror.FieldError{Field: "groupId", Code: "required", Message: "groupId is required"})
+	}
+
+	var mapping model.CommunityGroupMapping
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		target, err := findCommunity(tx, community)
+		if err != nil {
+			return err
+		}
+
+		var taken int64
+		if err := tx.Model(&model.CommunityGroupMapping{}).Where("group_id = ?", groupID).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return apperror.Conflict("community_mapping_exists", "This group is already mapped to a community")
+		}
+
+		mapping = model.CommunityGroupMapping{
+			GroupID:     groupID,
+			GroupName:   groupName,
+			CommunityID: target.ID,
+			Priority:    priority,
+		}
+		if err := tx.Create(&mapping).Error; err != nil {
+			return err
+		}
+		mapping.Community = target
+		return recordMappingChange(tx, model.MappingAuditCreated, &mapping, actor)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add community mapping: %w", err)
+	}
+	s.mappingsChanged()
+	return &mapping, nil
+}
+
+// RemoveMapping deletes a mapping. Users keep their community until it is next re-evaluated.
+func (s *CommunityMappingService) RemoveMapping(ctx context.Context, actor *model.User, id uint) error {
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var mapping model.CommunityGroupMapping
+		if err := tx.First(&mapping, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errMappingNotFound
+			}
+			return err
+		}
+		if err := tx.Delete(&mapping).Error; err != nil {
+			return err
+		}
+		return recordMappingChange(tx, model.MappingAuditDeleted, &mapping, actor)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove community mapping: %w", err)
+	}
+	s.mappingsChanged()
+	return nil
+}
+
+func (s *CommunityMappingService) mappingsChanged() {
+	if s.OnMappingsChanged != nil {
+		s.OnMappingsChanged()
+	}
+}
+
+// recordMappingChange writes an audit entry and marks every user's Graph data stale, so
+// communities are re-evaluated against the new mappings on each user's next request
+func recordMappingChange(tx *gorm.DB, action string, mapping *model.CommunityGroupMapping, actor *model.User) error {
+	entry := model.CommunityMappingAudit{
+		Action:      action,
+		MappingID:   mapping.ID,
+		GroupID:     mapping.GroupID,
+		CommunityID: mapping.CommunityID,
+		Priority:    mapping.Priority,
+	}
+	if actor != nil {
+		entry.ActorID = &actor.ID
+		entry.ActorEmail = actor.Email
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return fmt.Errorf("failed to audit community mapping change: %w", err)
+	}
+	return tx.Model(&model.User{}).Where("last_graph_sync IS NOT NULL").Update("last_graph_sync", nil).Error
+}
+
+// GetAudit returns the most recent mapping changes first
+func (s *CommunityMappingService) GetAudit(ctx context.Context, limit int) ([]model.CommunityMappingAudit, error) {
+	if limit <= 0 {
+		limit = DefaultMappingAuditLimit
+	}
+	if limit > MaxMappingAuditLimit {
+		limit = MaxMappingAuditLimit
+	}
+
+	var entries []model.CommunityMappingAudit
+	if err := s.DB.WithContext(ctx).Order("created_at DESC, id DESC").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list community mapping audit: %w", err)
+	}
+	return entries, nil
+}
+
+// MatchGroups finds the communities of a user in groupIDs. Archived communities are skipped. The
+// primary community is that of the matching mapping with the lowest priority; ties go to the
+// oldest mapping.
+func (s *CommunityMappingService) MatchGroups(ctx context.Context, groupIDs []string) (*CommunityMatch, error) {
+	match := &CommunityMatch{Communities: []model.Community{}, Matches: []model.CommunityGroupMapping{}}
+	if len(groupIDs) == 0 {
+		return match, nil
+	}
+
+	err := s.DB.WithContext(ctx).Preload("Community").
+		Where("group_id IN ?", groupIDs).
+		Order("priority ASC, id ASC").
+		Find(&match.Matches).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to match community mappings: %w", err)
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	for _, mapping := range match.Matches {
+		if mapping.Community == nil || mapping.Community.IsArchived() || seen[mapping.CommunityID] {
+			continue
+		}
+		seen[mapping.CommunityID] = true
+		match.Communities = append(match.Communities, *mapping.Community)
+	}
+	if len(match.Communities) > 0 {
+		match.Community = &match.Communities[0]
+	}
+	return match, nil
+}
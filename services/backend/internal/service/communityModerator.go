@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// Moderator is a user appointed to moderate a community
+type Moderator struct {
+	UserID uint   `json:"userId"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+}
+
+// GetModerators lists the moderators of a community in the order they were appointed
+func (s *CommunityService) GetModerators(ctx context.Context, id string) ([]Moderator, error) {
+	db := s.DB.WithContext(ctx)
+	var community model.Community
+	if err := loadCommunity(db, id, &community); err != nil {
+		return nil, err
+	}
+
+	moderators := []Moderator{}
+	err := db.Model(&model.CommunityModerator{}).
+		Select("users.id AS user_id, users.name, users.email").
+		Joins("JOIN users ON users.id = community_moderators.user_id").
+		Where("community_moderators.community_id = ?", community.ID).
+		Order("community_moderators.created_at").
+		Scan(&moderators).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list moderators: %w", err)
+	}
+	return moderators, nil
+}
+
+// AddModerator appoints the user with the given email as a moderator of a community. Appointing
+// an existing moderator again changes nothing.
+func (s *CommunityService) AddModerator(ctx context.Context, id, email string) (*Moderator, error) {
+	var user model.User
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var community model.Community
+		if err := loadCommunity(tx, id, &community); err != nil {
+			return err
+		}
+		if err := tx.Where("LOWER(email) = LOWER(?)", email).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperror.NotFound("user_not_found", "No user with this email has signed in yet")
+			}
+			return err
+		}
+		return tx.Where(model.CommunityModerator{UserID: user.ID, CommunityID: community.ID}).
+			FirstOrCreate(&model.CommunityModerator{}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add moderator: %w", err)
+	}
+	if s.OnRolesChanged != nil {
+		s.OnRolesChanged(user.EntraID)
+	}
+	return &Moderator{UserID: user.ID, Name: user.Name, Email: user.Email}, nil
+}
+
+// RemoveModerator takes away a user's moderator role in a community
+func (s *CommunityService) RemoveModerator(ctx context.Context, id string, userID uint) error {
+	db := s.DB.WithContext(ctx)
+	var community model.Community
+	if err := loadCommunity(db, id, &community); err != nil {
+		return err
+	}
+
+	result := db.Where("user_id = ? AND community_id = ?", userID, community.ID).Delete(&model.CommunityModerator{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove moderator: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperror.NotFound("moderator_not_found", "The user does not moderate this community")
+	}
+	reportRolesChanged(db, s.OnRolesChanged, userID)
+	return nil
+}
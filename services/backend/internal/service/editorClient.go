@@ -0,0 +1,238 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+)
+
+// HTTPClient interface for dependency injection (enables mocking in tests)
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Diagram is the metadata backend-editor keeps for a learning path diagram
+type Diagram struct {
+	ID             string `json:"_id"`
+	LearningPathID string `json:"learningPathId"`
+	Name           string `json:"name"`
+}
+
+// EditorClient is the backend-editor API used by the learning path saga, outbox and reconciler.
+// Diagrams are addressed by learning path ID. authToken is forwarded as a Bearer token.
+type EditorClient interface {
+	CreateDiagram(ctx context.Context, lpID, name, authToken string) (*Diagram, error)
+	RenameDiagram(ctx context.Context, lpID, name, authToken string) error
+	DeleteDiagram(ctx context.Context, lpID, authToken string) error
+	GetDiagram(ctx context.Context, lpID, authToken string) (*Diagram, error)
+	ListDiagrams(ctx context.Context, authToken string) ([]Diagram, error)
+}
+
+// EditorErrorKind classifies a failed backend-editor call
+type EditorErrorKind string
+
+const (
+	EditorErrNotFound     EditorErrorKind = "not_found"    // 404
+	EditorErrConflict     EditorErrorKind = "conflict"     // 409, e.g. duplicate diagram name
+	EditorErrUnauthorized EditorErrorKind = "unauthorized" // 401/403, token missing, invalid or lacking access
+	EditorErrUnavailable  EditorErrorKind = "unavailable"  // Network failure, timeout, 429 or 5xx; safe to retry
+	EditorErrUnexpected   EditorErrorKind = "unexpected"   // Any other status or an unreadable response
+)
+
+// EditorError is returned by every EditorClient method when a call fails.
+// Use errors.As to inspect Kind; wrapping with %w preserves it.
+type EditorError struct {
+	Kind       EditorErrorKind
+	StatusCode int    // 0 when no response was received
+	Message    string // Message reported by backend-editor, if any
+	Err        error  // Underlying transport or decoding error, if any
+}
+
+// NewEditorError creates an EditorError without an HTTP response (used by fakes and decorators)
+func NewEditorError(kind EditorErrorKind, message string) *EditorError {
+	return &EditorError{Kind: kind, Message: message}
+}
+
+func (e *EditorError) Error() string {
+	switch {
+	case e.StatusCode != 0 && e.Message != "":
+		return fmt.Sprintf("backend-editor returned status %d: %s", e.StatusCode, e.Message)
+	case e.StatusCode != 0:
+		return fmt.Sprintf("backend-editor returned status %d", e.StatusCode)
+	case e.Err != nil:
+		return fmt.Sprintf("backend-editor %s: %v", e.Kind, e.Err)
+	default:
+		return fmt.Sprintf("backend-editor %s: %s", e.Kind, e.Message)
+	}
+}
+
+func (e *EditorError) Unwrap() error {
+	return e.Err
+}
+
+// IsEditorError reports whether err is an EditorError of the given kind
+func IsEditorError(err error, kind EditorErrorKind) bool {
+	var editorErr *EditorError
+	return errors.As(err, &editorErr) && editorErr.Kind == kind
+}
+
+// editorErrorKindForStatus maps a backend-editor status code to an error kind
+func editorErrorKindForStatus(status int) EditorErrorKind {
+	switch {
+	case status == http.StatusNotFound:
+		return EditorErrNotFound
+	case status == http.StatusConflict:
+		return EditorErrConflict
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return EditorErrUnauthorized
+	case status == http.StatusTooManyRequests || status == http.StatusRequestTimeout || status >= 500:
+		return EditorErrUnavailable
+	default:
+		return EditorErrUnexpected
+	}
+}
+
+// editorDomainError translates a failed editor call into the domain error returned to
+// API clients. err is kept as the cause, so IsEditorError still works on the result.
+func editorDomainError(err error) error {
+	var editorErr *EditorError
+	if !errors.As(err, &editorErr) {
+		return err
+	}
+
+	switch editorErr.Kind {
+	case EditorErrConflict:
+		message := editorErr.Message
+		if message == "" {
+			message = "A learning path with this name already exists"
+		}
+		return apperror.Conflict("learning_path_title_taken", message).Wrap(err)
+	case EditorErrUnauthorized:
+		return apperror.Forbidden("editor_auth_failed", "Service authentication failed - token may be invalid").Wrap(err)
+	case EditorErrUnavailable:
+		return apperror.DependencyFailed("editor_unavailable", "Diagram service is unavailable, please try again later", err).
+			WithStatus(http.StatusServiceUnavailable)
+	default:
+		return apperror.DependencyFailed("editor_failed", "Diagram service returned an unexpected error", err)
+	}
+}
+
+// HTTPEditorClient talks to backend-editor over its REST API
+type HTTPEditorClient struct {
+	BaseURL    string
+	HTTPClient HTTPClient
+}
+
+func NewHTTPEditorClient(baseURL string, client HTTPClient) *HTTPEditorClient {
+	return &HTTPEditorClient{BaseURL: baseURL, HTTPClient: client}
+}
+
+// CreateDiagram creates the diagram for an LP. backend-editor answers 200 with the
+// existing diagram when one is already linked to the LP, which makes retries safe.
+func (c *HTTPEditorClient) CreateDiagram(ctx context.Context, lpID, name, authToken string) (*Diagram, error) {
+	body := map[string]string{"learningPathId": lpID, "name": name}
+
+	var d Diagram
+	if err := c.do(ctx, http.MethodPost, "/diagrams/by-lp", authToken, body, &d, http.StatusOK, http.StatusCreated); err != nil {
+		return nil, err
+	}
+	if d.ID == "" {
+		return nil, &EditorError{Kind: EditorErrUnexpected, Message: "diagram _id missing from response"}
+	}
+
+	return &d, nil
+}
+
+// RenameDiagram sets the name of the LP's diagram
+func (c *HTTPEditorClient) RenameDiagram(ctx context.Context, lpID, name, authToken string) error {
+	body := map[string]string{"name": name}
+	return c.do(ctx, http.MethodPatch, "/diagrams/by-lp/"+url.PathEscape(lpID), authToken, body, nil, http.StatusOK)
+}
+
+// DeleteDiagram deletes the LP's diagram
+func (c *HTTPEditorClient) DeleteDiagram(ctx context.Context, lpID, authToken string) error {
+	return c.do(ctx, http.MethodDelete, "/diagrams/by-lp/"+url.PathEscape(lpID), authToken, nil, nil, http.StatusNoContent)
+}
+
+// GetDiagram fetches the LP's diagram (backend-editor resolves the key as learningPathId first)
+func (c *HTTPEditorClient) GetDiagram(ctx context.Context, lpID, authToken string) (*Diagram, error) {
+	var d Diagram
+	if err := c.do(ctx, http.MethodGet, "/diagrams/"+url.PathEscape(lpID), authToken, nil, &d, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// ListDiagrams fetches metadata for every diagram held by backend-editor
+func (c *HTTPEditorClient) ListDiagrams(ctx context.Context, authToken string) ([]Diagram, error) {
+	var diagrams []Diagram
+	if err := c.do(ctx, http.MethodGet, "/diagrams", authToken, nil, &diagrams, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return diagrams, nil
+}
+
+// do sends a request and decodes the response into out when the status is one of okStatuses
+func (c *HTTPEditorClient) do(ctx context.Context, method, path, authToken string, body, out interface{}, okStatuses ...int) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return &EditorError{Kind: EditorErrUnexpected, Err: fmt.Errorf("failed to encode request: %w", err)}
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return &EditorError{Kind: EditorErrUnexpected, Err: fmt.Errorf("failed to create request: %w", err)}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Zero Trust: Authenticate with user token for audit trail
+	if authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+authToken)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return &EditorError{Kind: EditorErrUnavailable, Err: fmt.Errorf("request failed: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	for _, status := range okStatuses {
+		if resp.StatusCode != status {
+			continue
+		}
+		if out == nil {
+			return nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return &EditorError{Kind: EditorErrUnexpected, StatusCode: resp.StatusCode, Err: fmt.Errorf("failed to decode response: %w", err)}
+		}
+		return nil
+	}
+
+	editorErr := &EditorError{Kind: editorErrorKindForStatus(resp.StatusCode), StatusCode: resp.StatusCode}
+	var errorResp struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&errorResp); err == nil {
+		editorErr.Message = errorResp.Error
+		if editorErr.Message == "" {
+			editorErr.Message = errorResp.Message
+		}
+	}
+
+	return editorErr
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState string
+
+const (
+	// CircuitClosed lets every call through
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rejects calls without contacting the dependency until the cooldown ends
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single probe call through to test whether the dependency recovered
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// ErrCircuitOpen is the cause of the EditorError returned while the breaker rejects calls
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreaker stops calling a dependency after consecutive failures, so requests fail fast
+// instead of each waiting for a timeout while the dependency is down
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before a probe call is allowed
+	OpenTimeout time.Duration
+
+	mu                  sync.Mutex
+	state               CircuitState
+	consecutiveFailures int
+	openedAt            time.Time
+	probeInFlight       bool
+}
+
+// CircuitSnapshot describes a CircuitBreaker for health reporting
+type CircuitSnapshot struct {
+	State               CircuitState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutiveFailures"`
+	OpenedAt            *time.Time   `json:"openedAt,omitempty"`
+	RetryAt             *time.Time   `json:"retryAt,omitempty"`
+}
+
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		FailureThreshold: failureThreshold,
+		OpenTimeout:      openTimeout,
+		state:            CircuitClosed,
+	}
+}
+
+// Allow reports whether a call may proceed. In the half-open state only one probe is let through.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if time.Since(b.openedAt) < b.OpenTimeout {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		b.probeInFlight = true
+		return true
+	case CircuitHalfOpen:
+		if b.probeInFlight {
+			return false
+		}
+		b.probeInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+// RecordSuccess closes the circuit
+func (b *CircuitBreaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != CircuitClosed {
+		log.Printf("Circuit breaker closed after successful probe")
+	}
+	b.state = CircuitClosed
+	b.consecutiveFailures = 0
+	b.probeInFlight = false
+}
+
+// RecordFailure counts a failure and opens the circuit once the threshold is reached.
+// A failed probe reopens the circuit immediately.
+func (b *CircuitBreaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.consecutiveFailures++
+	if b.state == CircuitHalfOpen || b.consecutiveFailures >= b.FailureThreshold {
+		if b.state != CircuitOpen {
+			log.Printf("Circuit breaker opened after %d consecutive failures", b.consecutiveFailures)
+		}
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+	}
+	b.probeInFlight = false
+}
+
+// probeDone releases a half-open probe slot without changing the circuit state
+func (b *CircuitBreaker) probeDone() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probeInFlight = false
+}
+
+// Snapshot returns the current state of the breaker
+func (b *CircuitBreaker) Snapshot() CircuitSnapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	snapshot := CircuitSnapshot{State: b.state, ConsecutiveFailures: b.consecutiveFailures}
+	if b.state != CircuitClosed {
+		openedAt := b.openedAt
+		retryAt := openedAt.Add(b.OpenTimeout)
+		snapshot.OpenedAt = &openedAt
+		snapshot.RetryAt = &retryAt
+	}
+	return snapshot
+}
+
+// RetryPolicy configures retries of failed editor calls
+type RetryPolicy struct {
+	// MaxAttempts includes the first call; 1 disables retries
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// delay returns the wait before retry number attempt (1-based), doubling each time
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return delay
+}
+
+// ResilientEditorClient wraps an EditorClient with retries and a circuit breaker.
+//
+// Every EditorClient operation is safe to repeat: create is idempotent per learning path
+// (POST /diagrams/by-lp returns the existing diagram), rename sets an absolute name, and
+// delete/get/list have no cumulative effect. Only EditorErrUnavailable failures are retried
+// and counted by the breaker; conflicts, missing diagrams and auth errors are answers, not outages.
+type ResilientEditorClient struct {
+	Next    EditorClient
+	Breaker *CircuitBreaker
+	Retry   RetryPolicy
+}
+
+// NewResilientEditorClient wraps next using EDITOR_RETRY_MAX_ATTEMPTS, EDITOR_RETRY_BASE_DELAY_MS,
+// EDITOR_BREAKER_FAILURE_THRESHOLD and EDITOR_BREAKER_OPEN_SECONDS
+func NewResilientEditorClient(next EditorClient) *ResilientEditorClient {
+	maxAttempts := 3
+	if v, err := strconv.Atoi(os.Getenv("EDITOR_RETRY_MAX_ATTEMPTS")); err == nil && v > 0 {
+		maxAttempts = v
+	}
+	baseDelay := 200 * time.Millisecond
+	if v, err := strconv.Atoi(os.Getenv("EDITOR_RETRY_BASE_DELAY_MS")); err == nil && v >= 0 {
+		baseDelay = time.Duration(v) * time.Millisecond
+	}
+	failureThreshold := 5
+	if v, err := strconv.Atoi(os.Getenv("EDITOR_BREAKER_FAILURE_THRESHOLD")); err == nil && v > 0 {
+		failureThreshold = v
+	}
+	openTimeout := 30 * time.Second
+	if v, err := strconv.Atoi(os.Getenv("EDITOR_BREAKER_OPEN_SECONDS")); err == nil && v > 0 {
+		openTimeout = time.Duration(v) * time.Second
+	}
+
+	return &ResilientEditorClient{
+		Next:    next,
+		Breaker: NewCircuitBreaker(failureThreshold, openTimeout),
+		Retry:   RetryPolicy{MaxAttempts: maxAttempts, BaseDelay: baseDelay, MaxDelay: 2 * time.Second},
+	}
+}
+
+// call runs fn through the breaker, retrying unavailable errors with exponential backoff
+func (c *ResilientEditorClient) call(ctx context.Context, op string, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if !c.Breaker.Allow() {
+			return &EditorError{Kind: EditorErrUnavailable, Err: ErrCircuitOpen}
+		}
+
+		err = fn()
+		switch {
+		case err == nil || !IsEditorError(err, EditorErrUnavailable):
+			// Any answer from backend-editor, including an error response, means it is reachable
+			c.Breaker.RecordSuccess()
+			return err
+		case ctx.Err() != nil:
+			// The caller gave up; that says nothing about backend-editor's health
+			c.Breaker.probeDone()
+			return err
+		}
+
+		c.Breaker.RecordFailure()
+		if attempt >= c.Retry.MaxAttempts {
+			return err
+		}
+
+		delay := c.Retry.delay(attempt)
+		log.Printf("backend-editor %s attempt %d failed, retrying in %v: %v", op, attempt, delay, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (c *ResilientEditorClient) CreateDiagram(ctx context.Context, lpID, name, authToken string) (*Diagram, error) {
+	var d *Diagram
+	err := c.call(ctx, "create", func() error {
+		var err error
+		d, err = c.Next.CreateDiagram(ctx, lpID, name, authToken)
+		return err
+	})
+	return d, err
+}
+
+func (c *ResilientEditorClient) RenameDiagram(ctx context.Context, lpID, name, authToken string) error {
+	return c.call(ctx, "rename", func() error {
+		return c.Next.RenameDiagram(ctx, lpID, name, authToken)
+	})
+}
+
+func (c *ResilientEditorClient) DeleteDiagram(ctx context.Context, lpID, authToken string) error {
+	return c.call(ctx, "delete", func() error {
+		return c.Next.DeleteDiagram(ctx, lpID, authToken)
+	})
+}
+
+func (c *ResilientEditorClient) GetDiagram(ctx context.Context, lpID, authToken string) (*Diagram, error) {
+	var d *Diagram
+	err := c.call(ctx, "get", func() error {
+		var err error
+		d, err = c.Next.GetDiagram(ctx, lpID, authToken)
+		return err
+	})
+	return d, err
+}
+
+func (c *ResilientEditorClient) ListDiagrams(ctx context.Context, authToken string) ([]Diagram, error) {
+	var diagrams []Diagram
+	err := c.call(ctx, "list", func() error {
+		var err error
+		diagrams, err = c.Next.ListDiagrams(ctx, authToken)
+		return err
+	})
+	return diagrams, err
+}
@@ -8,6 +8,9 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/url"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
 )
 
 type GraphService struct {
@@ -26,6 +29,13 @@ func NewGraphService() *GraphService {
 	}
 }
 
+// NewGraphServiceWithClient creates a service with a custom HTTP client (for testing)
+func NewGraphServiceWithClient(client *http.Client) *GraphService {
+	return &GraphService{
+		httpClient: client,
+	}
+}
+
 // GetUserPhoto fetches the user's profile photo from Microsoft Graph
 func (s *GraphService) GetUserPhoto(ctx context.Context, accessToken string) ([]byte, error) {
 	req, err := http.NewRequestWithContext(
@@ -42,7 +52,7 @@ func (s *GraphService) GetUserPhoto(ctx context.Context, accessToken string) ([]
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, apperror.DependencyFailed("graph_unavailable", "Microsoft Graph is unavailable", err)
 	}
 	defer resp.Body.Close()
 
@@ -51,7 +61,7 @@ func (s *GraphService) GetUserPhoto(ctx context.Context, accessToken string) ([]
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to fetch photo: status %d", resp.StatusCode)
+		return nil, apperror.DependencyFailed("graph_request_failed", "Failed to fetch photo from Microsoft Graph", fmt.Errorf("status %d", resp.StatusCode))
 	}
 
 	return io.ReadAll(resp.Body)
@@ -59,40 +69,70 @@ func (s *GraphService) GetUserPhoto(ctx context.Context, accessToken string) ([]
 
 // GetUserGroups fetches the groups the user belongs to from Microsoft Graph
 func (s *GraphService) GetUserGroups(ctx context.Context, accessToken string) ([]Group, error) {
+	return s.getGroups(ctx, accessToken, "https://graph.microsoft.com/v1.0/me/memberOf")
+}
+
+// GetGroupsOfUser fetches the groups of another user, identified by Entra object ID or user
+// principal name. The token's owner needs permission to read other users' memberships.
+func (s *GraphService) GetGroupsOfUser(ctx context.Context, accessToken, user string) ([]Group, error) {
+	return s.getGroups(ctx, accessToken, "https://graph.microsoft.com/v1.0/users/"+url.PathEscape(user)+"/memberOf")
+}
+
+// getGroups follows @odata.nextLink, since users in many groups (the ones whose tokens overflow)
+// get their memberships over several pages
+func (s *GraphService) getGroups(ctx context.Context, accessToken, groupsURL string) ([]Group, error) {
+	groups := []Group{}
+	for groupsURL != "" {
+		page, nextURL, err := s.getGroupsPage(ctx, accessToken, groupsURL)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, page...)
+		groupsURL = nextURL
+	}
+
+	log.Printf("User belongs to %d groups", len(groups))
+	for _, group := range groups {
+		log.Printf("Group: %s (ID: %s)", group.DisplayName, group.ID)
+	}
+
+	return groups, nil
+}
+
+func (s *GraphService) getGroupsPage(ctx context.Context, accessToken, groupsURL string) ([]Group, string, error) {
 	req, err := http.NewRequestWithContext(
 		ctx,
 		"GET",
-		"https://graph.microsoft.com/v1.0/me/memberOf",
+		groupsURL,
 		nil,
 	)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, "", apperror.DependencyFailed("graph_unavailable", "Microsoft Graph is unavailable", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", apperror.NotFound("user_not_found", "User not found in Microsoft Entra ID")
+	}
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to fetch groups: status %d", resp.StatusCode)
+		return nil, "", apperror.DependencyFailed("graph_request_failed", "Failed to fetch groups from Microsoft Graph", fmt.Errorf("status %d", resp.StatusCode))
 	}
 
 	var result struct {
-		Value []Group `json:"value"`
+		Value    []Group `json:"value"`
+		NextLink string  `json:"@odata.nextLink"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
-	}
-
-	log.Printf("User belongs to %d groups", len(result.Value))
-	for _, group := range result.Value {
-		log.Printf("Group: %s (ID: %s)", group.DisplayName, group.ID)
+		return nil, "", err
 	}
 
-	return result.Value, nil
+	return result.Value, result.NextLink, nil
 }
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// GraphSyncBatcher collects the users whose groups were just re-evaluated and writes their
+// last_graph_sync in one UPDATE per flush, instead of one write per request
+type GraphSyncBatcher struct {
+	DB *gorm.DB
+
+	mu      sync.Mutex
+	pending map[uint]time.Time
+}
+
+func NewGraphSyncBatcher(db *gorm.DB) *GraphSyncBatcher {
+	return &GraphSyncBatcher{DB: db, pending: make(map[uint]time.Time)}
+}
+
+// Record notes that userID was synced at the given time
+func (b *GraphSyncBatcher) Record(userID uint, at time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending[userID] = at
+}
+
+// Pending returns the sync time recorded for userID that is not written yet
+func (b *GraphSyncBatcher) Pending(userID uint) (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	at, ok := b.pending[userID]
+	return at, ok
+}
+
+// Flush writes the recorded sync times. A batch gets its earliest time, so no user is
+// considered fresh for longer than they should be.
+func (b *GraphSyncBatcher) Flush(ctx context.Context) (int, error) {
+	b.mu.Lock()
+	batch := b.pending
+	b.pending = make(map[uint]time.Time)
+	b.mu.Unlock()
+
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]uint, 0, len(batch))
+	var earliest time.Time
+	for id, at := range batch {
+		ids = append(ids, id)
+		if earliest.IsZero() || at.Before(earliest) {
+			earliest = at
+		}
+	}
+
+	err := b.DB.WithContext(ctx).Model(&model.User{}).Where("id IN ?", ids).UpdateColumn("last_graph_sync", earliest).Error
+	if err != nil {
+		// Keep the batch for the next flush unless newer times were recorded meanwhile
+		b.mu.Lock()
+		for id, at := range batch {
+			if _, ok := b.pending[id]; !ok {
+				b.pending[id] = at
+			}
+		}
+		b.mu.Unlock()
+		return 0, fmt.Errorf("failed to write last graph sync: %w", err)
+	}
+	return len(ids), nil
+}
+
+// Start flushes every interval until ctx is cancelled, then flushes once more
+func (b *GraphSyncBatcher) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if _, err := b.Flush(context.Background()); err != nil {
+				log.Printf("Final graph sync flush failed: %v", err)
+			}
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := b.Flush(ctx); err != nil {
+			log.Printf("Graph sync flush failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxIdempotencyKeyLength bounds client-supplied Idempotency-Key values
+const MaxIdempotencyKeyLength = 255
+
+// IdempotencyService stores responses of requests sent with an Idempotency-Key so retries replay
+// the original response instead of repeating the side effects
+type IdempotencyService struct {
+	DB *gorm.DB
+	// TTL is how long a key is remembered after its first use
+	TTL time.Duration
+	// LockTimeout is how long an unfinished request holds its key before a retry may take it over,
+	// so a key is not blocked until TTL expiry when a replica crashes mid-request
+	LockTimeout time.Duration
+}
+
+// NewIdempotencyService creates a service configured from IDEMPOTENCY_TTL_HOURS
+func NewIdempotencyService(db *gorm.DB) *IdempotencyService {
+	ttl := 24 * time.Hour
+	if v, err := strconv.Atoi(os.Getenv("IDEMPOTENCY_TTL_HOURS")); err == nil && v > 0 {
+		ttl = time.Duration(v) * time.Hour
+	}
+
+	return &IdempotencyService{
+		DB:          db,
+		TTL:         ttl,
+		LockTimeout: 2 * time.Minute,
+	}
+}
+
+// HashRequest fingerprints a request payload for comparison with later uses of the same key
+func HashRequest(payload interface{}) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash request: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Begin claims key for userID. It returns a completed record when the request was already
+// processed (the caller replays it) or a new in-progress record the caller must Complete or Release.
+// An in-progress record with an LPID belongs to a request that created its learning path but did
+// not store the response; the caller replays a response for that path and completes the record.
+// Reusing a key with a different payload, or while the first request is still running, is an error.
+func (s *IdempotencyService) Begin(ctx context.Context, userID uint, key, requestHash string) (*model.IdempotencyRecord, error) {
+	if len(key) > MaxIdempotencyKeyLength {
+		return nil, apperror.Validation("invalid_idempotency_key", "Idempotency-Key is too long",
+			apperror.FieldError{Field: "Idempotency-Key", Code: "max", Message: fmt.Sprintf("Idempotency-Key must be at most %d characters", MaxIdempotencyKeyLength)})
+	}
+
+	db := s.DB.WithContext(ctx)
+
+	// Two rounds: if the stored record is stale it is deleted and the insert is retried once
+	for round := 0; round < 2; round++ {
+		now := time.Now()
+		record := &model.IdempotencyRecord{
+			ID:          uuid.New(),
+			UserID:      userID,
+			Key:         key,
+			RequestHash: requestHash,
+			Status:      model.IdempotencyStatusInProgress,
+			ExpiresAt:   now.Add(s.TTL),
+		}
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to store idempotency key: %w", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			return record, nil
+		}
+
+		var existing model.IdempotencyRecord
+		err := db.Where("user_id = ? AND idempotency_key = ?", userID, key).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue // Deleted concurrently; try to claim it again
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load idempotency key: %w", err)
+		}
+
+		expired := now.After(existing.ExpiresAt)
+		if !expired && existing.RequestHash != requestHash {
+			return nil, apperror.Validation("idempotency_key_reused", "Idempotency-Key was already used with a different request").
+				WithStatus(http.StatusUnprocessableEntity)
+		}
+		if !expired && (existing.Status == model.IdempotencyStatusCompleted || existing.LPID != nil) {
+			return &existing, nil
+		}
+		if !expired && now.Sub(existing.UpdatedAt) < s.LockTimeout {
+			return nil, apperror.Conflict("idempotency_request_in_progress", "A request with this Idempotency-Key is still being processed")
+		}
+
+		// Expired, or abandoned by a request that crashed before creating anything. Records get a
+		// new ID when reclaimed, so deleting by ID cannot remove a record another retry has just inserted.
+		result = db.Where("id = ?", existing.ID).Delete(&model.IdempotencyRecord{})
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to reclaim idempotency key: %w", result.Error)
+		}
+	}
+
+	return nil, apperror.Conflict("idempotency_request_in_progress", "A request with this Idempotency-Key is still being processed")
+}
+
+// Complete stores the response for replay. lpID is the learning path the request created, if any.
+func (s *IdempotencyService) Complete(ctx context.Context, record *model.IdempotencyRecord, status int, body []byte, lpID *uuid.UUID) error {
+	err := s.DB.WithContext(ctx).Model(record).Updates(map[string]interface{}{
+		"status":          model.IdempotencyStatusCompleted,
+		"response_status": status,
+		"response_body":   string(body),
+		"lp_id":           lpID,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+// Release forgets an in-progress key after the request failed, so the client can retry it
+func (s *IdempotencyService) Release(ctx context.Context, record *model.IdempotencyRecord) {
+	err := s.DB.WithContext(context.WithoutCancel(ctx)).
+		Where("id = ? AND status = ?", record.ID, model.IdempotencyStatusInProgress).
+		Delete(&model.IdempotencyRecord{}).Error
+	if err != nil {
+		// The key stays blocked until LockTimeout passes
+		log.Printf("Failed to release idempotency key %s: %v", record.Key, err)
+	}
+}
+
+// PurgeExpired deletes records past their TTL and returns how many were removed
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	result := s.DB.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&model.IdempotencyRecord{})
+	return result.RowsAffected, result.Error
+}
+
+// StartPurge removes expired records every interval until ctx is cancelled
+func (s *IdempotencyService) StartPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := s.PurgeExpired(ctx); err != nil {
+			log.Printf("Idempotency key purge failed: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d expired idempotency key(s)", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,50 +1,62 @@
 package service
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
 	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-// HTTPClient interface for dependency injection (enables mocking in tests)
-type HTTPClient interface {
-	Do(req *http.Request) (*http.Response, error)
-}
-
 type LearningPathService struct {
-	DB         *gorm.DB
-	HTTPClient HTTPClient
-	EditorURL  string
+	DB     *gorm.DB
+	Editor EditorClient
+	// EditorBreaker guards Editor calls and is reported by the health endpoint (nil if Editor is unguarded)
+	EditorBreaker *CircuitBreaker
+	// ServiceToken authenticates background work (saga recovery, outbox delivery, reconciliation)
+	// against backend-editor, where no user token is available. It is the INTERNAL_API_SECRET
+	// shared with backend-editor, which accepts it in place of a user's ID token.
+	ServiceToken string
+	// OnOutboxEnqueued is called after a transaction with outbox messages commits (optional)
+	OnOutboxEnqueued func()
+	// SagaHeartbeat is how often a running saga is marked alive while its steps are in progress;
+	// it must stay well below SagaRecoverer.StaleAfter. Zero uses DefaultSagaHeartbeat.
+	SagaHeartbeat time.Duration
 }
 
-// NewLearningPathService creates a service with default HTTP client
+// NewLearningPathService creates a service with an HTTP backend-editor client guarded by retries
+// and a circuit breaker
 func NewLearningPathService(db *gorm.DB) *LearningPathService {
 	editorURL := os.Getenv("EDITOR_BASE_URL")
 	if editorURL == "" {
 		editorURL = "http://localhost:3001/api"
 	}
+	editor := NewResilientEditorClient(NewHTTPEditorClient(editorURL, &http.Client{Timeout: 10 * time.Second}))
 	return &LearningPathService{
-		DB:         db,
-		HTTPClient: &http.Client{Timeout: 10 * time.Second},
-		EditorURL:  editorURL,
+		DB:            db,
+		Editor:        editor,
+		EditorBreaker: editor.Breaker,
+		ServiceToken:  os.Getenv("INTERNAL_API_SECRET"),
 	}
 }
 
 // NewLearningPathServiceWithClient creates a service with custom HTTP client (for testing)
 func NewLearningPathServiceWithClient(db *gorm.DB, client HTTPClient, editorURL string) *LearningPathService {
+	return NewLearningPathServiceWithEditor(db, NewHTTPEditorClient(editorURL, client))
+}
+
+// NewLearningPathServiceWithEditor creates a service with a custom EditorClient (for testing)
+func NewLearningPathServiceWithEditor(db *gorm.DB, editor EditorClient) *LearningPathService {
 	return &LearningPathService{
-		DB:         db,
-		HTTPClient: client,
-		EditorURL:  editorURL,
+		DB:     db,
+		Editor: editor,
 	}
 }
 
@@ -63,94 +75,73 @@ func populateSkillsListForPaths(paths []model.LearningPath) {
 	}
 }
 
-type diagramResponse struct {
-	ID             string `json:"_id"`
-	LearningPathID string `json:"learningPathId"`
-	Name           string `json:"name"`
-}
+var errLearningPathNotFound = apperror.NotFound("learning_path_not_found", "Learning path not found")
 
-func (s *LearningPathService) GetLearningPaths() ([]model.LearningPath, error) {
-	var paths []model.LearningPath
-	if err := s.DB.Preload("Skills.Skill").Find(&paths).Error; err != nil {
-		return nil, err
+// parseLearningPathID parses a learning path ID taken from a request
+func parseLearningPathID(lpID string) (uuid.UUID, error) {
+	lpUUID, err := uuid.Parse(lpID)
+	if err != nil {
+		return uuid.Nil, apperror.Validation("invalid_learning_path_id", "Invalid learning path ID format",
+			apperror.FieldError{Field: "id", Code: "uuid", Message: "must be a valid UUID"}).Wrap(err)
 	}
+	return lpUUID, nil
+}
 
-	populateSkillsListForPaths(paths)
+// CreateOptions are the optional parts of creating a learning path
+type CreateOptions struct {
+	// IdempotencyRecordID, when set, names the idempotency record of the request. The new path's
+	// ID is written to it in the transaction that creates the path, so a retry can find the path
+	// even when the response was never stored.
+	IdempotencyRecordID *uuid.UUID
+	// RequestPublication creates the path private and waiting for a moderator to approve its
+	// publication, in the same transaction
+	RequestPublication bool
+}
 
-	return paths, nil
+func (s *LearningPathService) CreateLearningPath(ctx context.Context, title, description string, isPublic bool, thumbnail string, skillNames []string, authToken string, communityID uuid.UUID, createdByID uint) (*model.LearningPath, error) {
+	return s.CreateLearningPathWithOptions(ctx, title, description, isPublic, thumbnail, skillNames, authToken, communityID, createdByID, CreateOptions{})
 }
 
-func (s *LearningPathService) CreateLearningPath(ctx context.Context, title, description string, isPublic bool, thumbnail string, skillNames []string, authToken string, community string) (*model.LearningPath, error) {
+// CreateLearningPathWithOptions creates a learning path like CreateLearningPath, applying opts
+func (s *LearningPathService) CreateLearningPathWithOptions(ctx context.Context, title, description string, isPublic bool, thumbnail string, skillNames []string, authToken string, communityID uuid.UUID, createdByID uint, opts CreateOptions) (*model.LearningPath, error) {
 	lpID := uuid.New()
 
+	// Persist the saga before touching MongoDB so a crash at any point can be recovered
+	saga, err := s.beginSaga(ctx, model.SagaTypeCreateLearningPath, lpID, createSagaPayload{Title: title})
+	if err != nil {
+		return nil, err
+	}
+	defer s.keepSagaAlive(ctx, saga)()
+
 	// SAGA STEP 1: Create diagram in MongoDB (idempotent - safe to retry)
-	dr, err := s.createDiagramInMongo(ctx, lpID.String(), title, authToken)
+	dr, err := s.Editor.CreateDiagram(ctx, lpID.String(), title, authToken)
 	if err != nil {
-		return nil, fmt.Errorf("saga step 1 failed (create diagram): %w", err)
+		// Nothing was created, but the diagram may exist if only the response got lost;
+		// the recoverer deletes it idempotently
+		s.finishSaga(ctx, saga, model.SagaStatusCompensating, err)
+		return nil, editorDomainError(fmt.Errorf("saga step 1 failed (create diagram): %w", err))
 	}
+	s.advanceSaga(ctx, saga, sagaStepDiagramCreated, createSagaPayload{Title: title, DiagramID: dr.ID})
 
 	// SAGA STEP 2: Create LP and skills in PostgreSQL within a transaction
-	lp, err := s.createLPWithSkillsInTransaction(ctx, lpID, title, description, isPublic, thumbnail, dr.ID, community, skillNames)
+	lp, err := s.createLPWithSkillsInTransaction(ctx, lpID, title, description, isPublic, thumbnail, dr.ID, communityID, skillNames, createdByID, opts)
 	if err != nil {
 		// COMPENSATION: Delete the MongoDB diagram we just created
 		if compErr := s.deleteDiagramByLP(ctx, lpID.String(), authToken); compErr != nil {
-			// Both operations failed - diagram orphaned in MongoDB, requires manual cleanup
+			// Both operations failed - the saga stays open so the recoverer removes the orphaned diagram
+			s.finishSaga(ctx, saga, model.SagaStatusCompensating, compErr)
 			return nil, fmt.Errorf("saga step 2 failed (create LP): %w, compensation failed (orphaned diagram %s): %v", err, lpID.String(), compErr)
 		}
+		s.finishSaga(ctx, saga, model.SagaStatusCompensated, err)
 		return nil, fmt.Errorf("saga step 2 failed (create LP): %w", err)
 	}
 
+	s.finishSaga(ctx, saga, model.SagaStatusCompleted, nil)
 	return lp, nil
 }
 
-// createDiagramInMongo handles the MongoDB diagram creation with proper error handling
-func (s *LearningPathService) createDiagramInMongo(ctx context.Context, lpID, title, authToken string) (*diagramResponse, error) {
-	body, _ := json.Marshal(map[string]string{
-		"learningPathId": lpID,
-		"name":           title,
-	})
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/diagrams/by-lp", s.EditorURL), bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	// Zero Trust: Authenticate with user token for audit trail
-	if authToken != "" {
-		req.Header.Set("Authorization", "Bearer "+authToken)
-	}
-
-	resp, err := s.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// 200 OK = idempotent retry (diagram already exists), 201 Created = new diagram
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		var errorResp map[string]interface{}
-		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err == nil {
-			if errorMsg, ok := errorResp["error"].(string); ok {
-				return nil, fmt.Errorf("%s", errorMsg)
-			}
-		}
-		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
-	}
-
-	var dr diagramResponse
-	if err := json.NewDecoder(resp.Body).Decode(&dr); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-	if dr.ID == "" {
-		return nil, errors.New("diagram _id missing from response")
-	}
-
-	return &dr, nil
-}
-
 // createLPWithSkillsInTransaction wraps LP and skill creation in a single PostgreSQL transaction
-func (s *LearningPathService) createLPWithSkillsInTransaction(ctx context.Context, lpID uuid.UUID, title, description string, isPublic bool, thumbnail, diagramID, community string, skillNames []string) (*model.LearningPath, error) {
+func (s *LearningPathService) createLPWithSkillsInTransaction(ctx context.Context, lpID uuid.UUID, title, description string, isPublic bool, thumbnail, diagramID string, communityID uuid.UUID, skillNames []string, createdByID uint, opts CreateOptions) (*model.LearningPath, error) {
 	lp := &model.LearningPath{
 		ID:          lpID,
 		Title:       title,
@@ -158,7 +149,15 @@ func (s *LearningPathService) createLPWithSkillsInTransaction(ctx context.Contex
 		IsPublic:    isPublic,
 		Thumbnail:   thumbnail,
 		DiagramID:   diagramID,
-		Community:   community,
+		CommunityID: &communityID,
+		SkillNames:  strings.Join(skillNames, " "),
+	}
+	if opts.RequestPublication {
+		lp.IsPublic = false
+		lp.PublicationStatus = model.PublicationPending
+	}
+	if createdByID != 0 {
+		lp.CreatedByID = &createdByID
 	}
 
 	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -190,6 +189,15 @@ func (s *LearningPathService) createLPWithSkillsInTransaction(ctx context.Contex
 			}
 		}
 
+		if opts.IdempotencyRecordID != nil {
+			if err := tx.Model(&model.IdempotencyRecord{}).Where("id = ?", *opts.IdempotencyRecordID).Update("lp_id", lpID).Error; err != nil {
+				return fmt.Errorf("failed to link idempotency key: %w", err)
+			}
+		}
+
+		if createdByID != 0 {
+			return grantRole(tx, createdByID, lpID, model.RoleOwner)
+		}
 		return nil
 	})
 
@@ -198,7 +206,7 @@ func (s *LearningPathService) createLPWithSkillsInTransaction(ctx context.Contex
 	}
 
 	// Reload with skills outside transaction
-	if err := s.DB.WithContext(ctx).Preload("Skills.Skill").First(lp, "id = ?", lpID).Error; err != nil {
+	if err := s.DB.WithContext(ctx).Preload("Skills.Skill").Preload("Community").First(lp, "id = ?", lpID).Error; err != nil {
 		// If reload fails, return the LP without skills rather than failing entirely
 		return lp, nil
 	}
@@ -207,117 +215,81 @@ func (s *LearningPathService) createLPWithSkillsInTransaction(ctx context.Contex
 	return lp, nil
 }
 
+// deleteDiagramByLP deletes the LP's diagram, treating an already missing diagram as success
 func (s *LearningPathService) deleteDiagramByLP(ctx context.Context, lpID, authToken string) error {
-	// For compensation/cleanup operations, use a background context with a short timeout
+	// For compensation/cleanup operations, detach from cancellation with a short timeout
 	// to ensure cleanup completes even if the original request context is canceled
-	cleanupCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
 	defer cancel()
 
-	req, _ := http.NewRequestWithContext(cleanupCtx, http.MethodDelete, fmt.Sprintf("%s/diagrams/by-lp/%s", s.EditorURL, lpID), nil)
-	// Zero Trust: Authenticate with user token for audit trail
-	if authToken != "" {
-		req.Header.Set("Authorization", "Bearer "+authToken)
-	}
-	resp, err := s.HTTPClient.Do(req)
-	if err != nil {
+	if err := s.Editor.DeleteDiagram(cleanupCtx, lpID, authToken); err != nil && !IsEditorError(err, EditorErrNotFound) {
 		return err
 	}
-	defer resp.Body.Close()
-	// 204 is expected; if not found, we consider it already cleaned up.
-	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
-		return fmt.Errorf("unexpected status deleting diagram: %d", resp.StatusCode)
-	}
 	return nil
 }
 
-// updateDiagramName syncs the diagram name in MongoDB with the learning path title
+// updateDiagramName syncs the diagram name in MongoDB with the learning path title.
+// A missing diagram is left to the reconciler.
 func (s *LearningPathService) updateDiagramName(ctx context.Context, lpID, name, authToken string) error {
-	body, _ := json.Marshal(map[string]string{"name": name})
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, fmt.Sprintf("%s/diagrams/by-lp/%s", s.EditorURL, lpID), bytes.NewReader(body))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	// Zero Trust: Authenticate with user token for audit trail
-	if authToken != "" {
-		req.Header.Set("Authorization", "Bearer "+authToken)
-	}
-
-	resp, err := s.HTTPClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Check for non-OK responses
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
-		return fmt.Errorf("backend-editor returned status %d", resp.StatusCode)
+	if err := s.Editor.RenameDiagram(ctx, lpID, name, authToken); err != nil && !IsEditorError(err, EditorErrNotFound) {
+		return err
 	}
-
 	return nil
 }
 
-func (s *LearningPathService) DeleteLearningPath(ctx context.Context, lpID string, authToken string) error {
-	// SAGA DELETE: Uses soft-delete pattern for safe distributed deletion
-	//
-	// Order of operations (saga-safe):
-	// 1. Find the LP (validate it exists)
-	// 2. Soft-delete LP in PostgreSQL (recoverable if MongoDB fails)
-	// 3. Delete diagram from MongoDB
-	// 4. If MongoDB fails, restore LP (undelete)
-	// 5. If MongoDB succeeds, optionally hard-delete LP (or let cleanup job handle it)
+// DeleteLearningPath soft-deletes the LP and enqueues the diagram delete in the same transaction.
+// The OutboxDispatcher deletes the diagram and then hard-deletes the LP, so an unavailable
+// backend-editor delays the cleanup instead of failing the request.
+func (s *LearningPathService) DeleteLearningPath(ctx context.Context, lpID string) error {
+	// An ID that is not a UUID cannot name an existing learning path
+	lpUUID, err := uuid.Parse(lpID)
+	if err != nil {
+		return errLearningPathNotFound
+	}
 
 	var lp model.LearningPath
-	if err := s.DB.WithContext(ctx).Where("id = ?", lpID).First(&lp).Error; err != nil {
+	if err := s.DB.WithContext(ctx).Where("id = ?", lpUUID).First(&lp).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("learning path not found")
+			return errLearningPathNotFound
 		}
 		return fmt.Errorf("failed to find learning path: %w", err)
 	}
 
-	// SAGA STEP 1: Soft-delete LP in PostgreSQL (recoverable)
-	// This uses GORM's soft-delete which sets DeletedAt timestamp
-	if err := s.DB.WithContext(ctx).Delete(&lp).Error; err != nil {
-		return fmt.Errorf("saga step 1 failed (soft-delete LP): %w", err)
-	}
-
-	// SAGA STEP 2: Delete diagram from MongoDB
-	if err := s.deleteDiagramByLP(ctx, lp.ID.String(), authToken); err != nil {
-		// COMPENSATION: Restore the soft-deleted LP
-		if restoreErr := s.restoreSoftDeletedLP(ctx, lp.ID); restoreErr != nil {
-			// Critical: Both operations failed, LP is soft-deleted but diagram still exists
-			return fmt.Errorf("saga failed and compensation failed: delete diagram: %w, restore LP: %v", err, restoreErr)
+	err = s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Soft-delete hides the LP immediately while the diagram is still being removed
+		if err := tx.Delete(&lp).Error; err != nil {
+			return fmt.Errorf("failed to soft-delete learning path: %w", err)
 		}
-		return fmt.Errorf("saga step 2 failed (delete diagram), LP restored: %w", err)
+		return enqueueOutbox(tx, model.OutboxTypeDiagramDelete, lp.ID, struct{}{})
+	})
+	if err != nil {
+		return err
 	}
 
-	// SAGA STEP 3: Hard-delete the LP now that MongoDB diagram is gone
-	// This permanently removes the record (Unscoped bypasses soft-delete)
-	// Non-critical if this fails: LP is soft-deleted and diagram is gone, cleanup job can handle this
-	_ = s.DB.WithContext(ctx).Unscoped().Delete(&lp).Error
-
+	s.notifyOutbox()
 	return nil
 }
 
-// restoreSoftDeletedLP restores a soft-deleted learning path (compensation action)
-func (s *LearningPathService) restoreSoftDeletedLP(ctx context.Context, lpID uuid.UUID) error {
-	// Use Unscoped to find soft-deleted records, then set DeletedAt to NULL
-	return s.DB.WithContext(ctx).Unscoped().
-		Model(&model.LearningPath{}).
-		Where("id = ?", lpID).
-		Update("deleted_at", nil).Error
-}
-
-// AddToFavorites adds a learning path to user's favorites
-func (s *LearningPathService) AddToFavorites(ctx context.Context, userID uint, lpID string) error {
+// AddToFavorites adds a learning path the viewer can see to their favorites
+func (s *LearningPathService) AddToFavorites(ctx context.Context, viewer Viewer, lpID string) error {
 	// Parse string ID to UUID
-	lpUUID, err := uuid.Parse(lpID)
+	lpUUID, err := parseLearningPathID(lpID)
 	if err != nil {
-		return fmt.Errorf("invalid learning path ID format: %w", err)
+		return err
 	}
 
+	var visible int64
+	err = viewer.scopeVisible(s.DB.WithContext(ctx).Model(&model.LearningPath{})).
+		Where("learning_paths.id = ?", lpUUID).
+		Count(&visible).Error
+	if err != nil {
+		return fmt.Errorf("failed to find learning path: %w", err)
+	}
+	if visible == 0 {
+		return errLearningPathNotFound
+	}
+	userID := viewer.UserID
+
 	// Check if relationship already exists
 	var userLP model.UserLP
 	err = s.DB.WithContext(ctx).
@@ -345,9 +317,9 @@ func (s *LearningPathService) AddToFavorites(ctx context.Context, userID uint, l
 // RemoveFromFavorites removes a learning path from user's favorites
 func (s *LearningPathService) RemoveFromFavorites(ctx context.Context, userID uint, lpID string) error {
 	// Parse string ID to UUID
-	lpUUID, err := uuid.Parse(lpID)
+	lpUUID, err := parseLearningPathID(lpID)
 	if err != nil {
-		return fmt.Errorf("invalid learning path ID format: %w", err)
+		return err
 	}
 
 	return s.DB.WithContext(ctx).
@@ -356,12 +328,13 @@ func (s *LearningPathService) RemoveFromFavorites(ctx context.Context, userID ui
 		Update("is_favorite", false).Error
 }
 
-// GetUserFavorites retrieves all favorite learning paths for a user
-func (s *LearningPathService) GetUserFavorites(ctx context.Context, userID uint) ([]model.LearningPath, error) {
+// GetUserFavorites retrieves the viewer's favorite learning paths. Favorites that were made
+// private since, and the viewer may no longer see, are left out.
+func (s *LearningPathService) GetUserFavorites(ctx context.Context, viewer Viewer) ([]model.LearningPath, error) {
 	var paths []model.LearningPath
-	err := s.DB.WithContext(ctx).
+	err := viewer.scopeVisible(s.DB.WithContext(ctx)).
 		Joins("JOIN user_lps ON user_lps.lp_id = learning_paths.id").
-		Where("user_lps.user_id = ? AND user_lps.is_favorite = ?", userID, true).
+		Where("user_lps.user_id = ? AND user_lps.is_favorite = ?", viewer.UserID, true).
 		Preload("Skills.Skill").
 		Find(&paths).Error
 
@@ -374,67 +347,56 @@ func (s *LearningPathService) GetUserFavorites(ctx context.Context, userID uint)
 	return paths, nil
 }
 
-// GetLearningPathsByCommunity retrieves all learning paths for a specific community
-func (s *LearningPathService) GetLearningPathsByCommunity(ctx context.Context, communityName string) ([]model.LearningPath, error) {
-	var paths []model.LearningPath
-	err := s.DB.WithContext(ctx).
-		Where("community = ?", communityName).
-		Preload("Skills.Skill").
-		Find(&paths).Error
-
-	if err != nil {
-		return nil, err
-	}
-
-	populateSkillsListForPaths(paths)
-
-	return paths, nil
+// UpdateLearningPath updates the title and description of a learning path, and its visibility
+// unless isPublic is nil. A title change enqueues a diagram rename in the same transaction; the OutboxDispatcher
+// syncs backend-editor afterwards, so the LP change is never rolled back for it.
+func (s *LearningPathService) UpdateLearningPath(ctx context.Context, lpID, title, description string, isPublic *bool) (*model.LearningPath, error) {
+	return s.updateLearningPath(ctx, lpID, title, description, isPublic, false)
 }
 
-// UpdateLearningPath updates the title and description of a learning path
-func (s *LearningPathService) UpdateLearningPath(ctx context.Context, lpID, title, description, authToken string) (*model.LearningPath, error) {
-	// SAGA UPDATE: Atomic distributed update with rollback
-	//
-	// Order of operations:
-	// 1. Find LP and save old values (for compensation)
-	// 2. Update LP in PostgreSQL
-	// 3. Update diagram in MongoDB
-	// 4. If MongoDB fails, rollback PostgreSQL to old values
-
-	lpUUID, err := uuid.Parse(lpID)
+// updateLearningPath applies an update; requestPublication keeps the path private and marks it as
+// waiting for a moderator's approval in the same transaction
+func (s *LearningPathService) updateLearningPath(ctx context.Context, lpID, title, description string, isPublic *bool, requestPublication bool) (*model.LearningPath, error) {
+	lpUUID, err := parseLearningPathID(lpID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid learning path ID format: %w", err)
+		return nil, err
 	}
 
-	// SAGA STEP 0: Get current values for compensation
 	var lp model.LearningPath
-	if err := s.DB.WithContext(ctx).Where("id = ?", lpUUID).First(&lp).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("learning path not found")
+	renamed := false
+	err = s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", lpUUID).First(&lp).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errLearningPathNotFound
+			}
+			return fmt.Errorf("failed to find learning path: %w", err)
 		}
-		return nil, fmt.Errorf("failed to find learning path: %w", err)
-	}
 
-	oldTitle := lp.Title
-	oldDescription := lp.Description
+		renamed = lp.Title != title
+		lp.Title = title
+		lp.Description = description
+		if isPublic != nil {
+			lp.IsPublic = *isPublic
+		}
+		if requestPublication {
+			lp.IsPublic = false
+			lp.PublicationStatus = model.PublicationPending
+		}
+		if err := tx.Save(&lp).Error; err != nil {
+			return fmt.Errorf("failed to update learning path: %w", err)
+		}
 
-	// SAGA STEP 1: Update PostgreSQL
-	lp.Title = title
-	lp.Description = description
-	if err := s.DB.WithContext(ctx).Save(&lp).Error; err != nil {
-		return nil, fmt.Errorf("saga step 1 failed (update LP): %w", err)
+		if !renamed {
+			return nil
+		}
+		return enqueueOutbox(tx, model.OutboxTypeDiagramRename, lp.ID, renameDiagramPayload{Name: title})
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// SAGA STEP 2: Update MongoDB diagram
-	if err := s.updateDiagramName(ctx, lpID, title, authToken); err != nil {
-		// COMPENSATION: Rollback PostgreSQL to old values
-		lp.Title = oldTitle
-		lp.Description = oldDescription
-		if compErr := s.DB.WithContext(ctx).Save(&lp).Error; compErr != nil {
-			// Critical: Both operations failed, data may be inconsistent
-			return nil, fmt.Errorf("saga failed and compensation failed: update diagram: %w, restore LP: %v", err, compErr)
-		}
-		return nil, fmt.Errorf("saga step 2 failed (update diagram), LP restored: %w", err)
+	if renamed {
+		s.notifyOutbox()
 	}
 
 	// Reload with skills
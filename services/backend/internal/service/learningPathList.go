@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Sort options for learning path listings
+const (
+	LearningPathSortCreated    = "created"
+	LearningPathSortUpdated    = "updated"
+	LearningPathSortTitle      = "title"
+	LearningPathSortPopularity = "popularity" // Number of users who favorited the path
+)
+
+// Sort directions for learning path listings
+const (
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+const (
+	// DefaultLearningPathPageSize is used when a listing does not ask for a page size
+	DefaultLearningPathPageSize = 20
+	// MaxLearningPathPageSize bounds the page size a client may ask for
+	MaxLearningPathPageSize = 100
+)
+
+// favoriteCountExpr counts the users who favorited the learning path of the current row
+const favoriteCountExpr = "(SELECT COUNT(*) FROM user_lps WHERE user_lps.lp_id = learning_paths.id AND user_lps.is_favorite AND user_lps.deleted_at IS NULL)"
+
+// learningPathSortColumns maps sort options to the SQL expression they order by
+var learningPathSortColumns = map[string]string{
+	LearningPathSortCreated:    "learning_paths.created_at",
+	LearningPathSortUpdated:    "learning_paths.updated_at",
+	LearningPathSortTitle:      "learning_paths.title",
+	LearningPathSortPopularity: favoriteCountExpr,
+}
+
+// LearningPathQuery selects one page of a learning path listing. Zero values mean "no filter".
+type LearningPathQuery struct {
+	Viewer Viewer // Private paths the viewer may not see are left out
+
+	Community     string   // Community name or slug
+	Skills        []string // Paths with at least one of these skills (case-insensitive)
+	IsPublic      *bool
+	CreatedByID   *uint
+	CreatedAfter  *time.Time // Inclusive
+	CreatedBefore *time.Time // Exclusive
+	Featured      *bool
+	Publication   string      // One of the model.Publication* statuses
+	FavoritedBy   *uint       // Paths this user favorited
+	IDs           []uuid.UUID // Only these paths
+
+	Sort   string // One of the LearningPathSort options (default: created)
+	Order  string // asc or desc (default: asc for title, desc otherwise)
+	Cursor string // NextCursor of the previous page; empty for the first page
+	Limit  int    // Page size (default: DefaultLearningPathPageSize)
+}
+
+// LearningPathPage is one page of a learning path listing
+type LearningPathPage struct {
+	Items []model.LearningPath `json:"items"`
+	// NextCursor fetches the following page with the same query; nil on the last page
+	NextCursor *string `json:"nextCursor"`
+	// Total is the number of paths matching the filters across all pages
+	Total int64 `json:"total"`
+}
+
+// listCursor is the position after the last item of a page. It records the sort it was issued for,
+// so a cursor is not silently applied to a differently ordered listing.
+type listCursor struct {
+	Sort      string    `json:"s"`
+	Order     string    `json:"o"`
+	Time      time.Time `json:"t"`
+	Title     string    `json:"n,omitempty"`
+	Favorites int64     `json:"f,omitempty"`
+	ID        uuid.UUID `json:"id"`
+}
+
+func encodeListCursor(c listCursor) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeListCursor(raw string) (listCursor, error) {
+	var c listCursor
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil {
+		return c, invalidCursorError("must be a cursor returned by a previous page")
+	}
+	return c, nil
+}
+
+func invalidCursorError(message string) error {
+	return apperror.Validation("invalid_cursor", "Invalid pagination cursor",
+		apperror.FieldError{Field: "cursor", Code: "invalid", Message: message})
+}
+
+// normalize fills in defaults and rejects unknown sort options
+func (q *LearningPathQuery) normalize() error {
+	if q.Sort == "" {
+		q.Sort = LearningPathSortCreated
+	}
+	if _, ok := learningPathSortColumns[q.Sort]; !ok {
+		return apperror.Validation("invalid_query", "Invalid listing query",
+			apperror.FieldError{Field: "sort", Code: "oneof", Message: "sort must be one of created, updated, title, popularity"})
+	}
+
+	if q.Order == "" {
+		q.Order = SortOrderDesc
+		if q.Sort == LearningPathSortTitle {
+			q.Order = SortOrderAsc
+		}
+	}
+	if q.Order != SortOrderAsc && q.Order != SortOrderDesc {
+		return apperror.Validation("invalid_query", "Invalid listing query",
+			apperror.FieldError{Field: "order", Code: "oneof", Message: "order must be one of asc, desc"})
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = DefaultLearningPathPageSize
+	}
+	if q.Limit > MaxLearningPathPageSize {
+		q.Limit = MaxLearningPathPageSize
+	}
+	return nil
+}
+
+// applyFilters restricts db to the paths matching the query filters
+func (q *LearningPathQuery) applyFilters(db *gorm.DB) *gorm.DB {
+	db = q.Viewer.scopeVisible(db)
+	if q.Community != "" {
+		db = db.Where("learning_paths."+communityRefFilter, q.Community, q.Community)
+	}
+	if len(q.Skills) > 0 {
+		names := make([]string, len(q.Skills))
+		for i, name := range q.Skills {
+			names[i] = strings.ToLower(name)
+		}
+		db = db.Where(`EXISTS (SELECT 1 FROM lp_skills JOIN skills ON skills.id = lp_skills.skill_id
+			WHERE lp_skills.lp_id = learning_paths.id AND lp_skills.deleted_at IS NULL AND LOWER(skills.name) IN ?)`, names)
+	}
+	if q.IsPublic != nil {
+		db = db.Where("learning_paths.is_public = ?", *q.IsPublic)
+	}
+	if q.CreatedByID != nil {
+		db = db.Where("learning_paths.created_by_id = ?", *q.CreatedByID)
+	}
+	if q.CreatedAfter != nil {
+		db = db.Where("learning_paths.created_at >= ?", *q.CreatedAfter)
+	}
+	if q.CreatedBefore != nil {
+		db = db.Where("learning_paths.created_at < ?", *q.CreatedBefore)
+	}
+	if q.Featured != nil {
+		if *q.Featured {
+			db = db.Where("learning_paths.featured_at IS NOT NULL")
+		} else {
+			db = db.Where("learning_paths.featured_at IS NULL")
+		}
+	}
+	if q.Publication != "" {
+		db = db.Where("learning_paths.publication_status = ?", q.Publication)
+	}
+	if q.FavoritedBy != nil {
+		db = db.Where(`EXISTS (SELECT 1 FROM user_lps WHERE user_lps.lp_id = learning_paths.id
+			AND user_lps.user_id = ? AND user_lps.is_favorite AND user_lps.deleted_at IS NULL)`, *q.FavoritedBy)
+	}
+	if len(q.IDs) > 0 {
+		db = db.Where("learning_paths.id IN ?", q.IDs)
+	}
+	return db
+}
+
+// applyCursor restricts db to the rows after the cursor position, breaking ties on ID
+func (q *LearningPathQuery) applyCursor(db *gorm.DB, c listCursor) *gorm.DB {
+	column := learningPathSortColumns[q.Sort]
+	op := ">"
+	if q.Order == SortOrderDesc {
+		op = "<"
+	}
+
+	var value interface{}
+	switch q.Sort {
+	case LearningPathSortTitle:
+		value = c.Title
+	case LearningPathSortPopularity:
+		value = c.Favorites
+	default:
+		value = c.Time
+	}
+
+	return db.Where(fmt.Sprintf("((%s %s ?) OR (%s = ? AND learning_paths.id %s ?))", column, op, column, op),
+		value, value, c.ID)
+}
+
+// ListLearningPaths returns one page of the learning paths matching q, with skills loaded for that page only.
+// Pages are keyset-paginated, so paths created while a client pages through are neither skipped nor repeated.
+func (s *LearningPathService) ListLearningPaths(ctx context.Context, q LearningPathQuery) (*LearningPathPage, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+
+	db := s.DB.WithContext(ctx)
+
+	var total int64
+	if err := q.applyFilters(db.Model(&model.LearningPath{})).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count learning paths: %w", err)
+	}
+
+	pageQuery := q.applyFilters(db.Model(&model.LearningPath{}))
+	if q.Cursor != "" {
+		c, err := decodeListCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if c.Sort != q.Sort || c.Order != q.Order {
+			return nil, invalidCursorError("cursor was issued for a different sort order")
+		}
+		pageQuery = q.applyCursor(pageQuery, c)
+	}
+
+	// One extra row tells whether another page follows
+	direction := strings.ToUpper(q.Order)
+	var paths []model.LearningPath
+	err := pageQuery.
+		Order(fmt.Sprintf("%s %s, learning_paths.id %s", learningPathSortColumns[q.Sort], direction, direction)).
+		Limit(q.Limit + 1).
+		Preload("Skills.Skill").
+		Preload("Community").
+		Find(&paths).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list learning paths: %w", err)
+	}
+
+	page := &LearningPathPage{Items: paths, Total: total}
+	if len(paths) > q.Limit {
+		page.Items = paths[:q.Limit]
+		next, err := s.nextListCursor(ctx, q, page.Items[q.Limit-1])
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor = &next
+	}
+	populateSkillsListForPaths(page.Items)
+
+	return page, nil
+}
+
+// nextListCursor builds the cursor that continues a listing after last
+func (s *LearningPathService) nextListCursor(ctx context.Context, q LearningPathQuery, last model.LearningPath) (string, error) {
+	c := listCursor{Sort: q.Sort, Order: q.Order, ID: last.ID}
+	switch q.Sort {
+	case LearningPathSortCreated:
+		c.Time = last.CreatedAt
+	case LearningPathSortUpdated:
+		c.Time = last.UpdatedAt
+	case LearningPathSortTitle:
+		c.Title = last.Title
+	case LearningPathSortPopularity:
+		err := s.DB.WithContext(ctx).Model(&model.UserLP{}).
+			Where("lp_id = ? AND is_favorite = ?", last.ID, true).
+			Count(&c.Favorites).Error
+		if err != nil {
+			return "", fmt.Errorf("failed to count favorites: %w", err)
+		}
+	}
+	return encodeListCursor(c)
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NeedsPublicationApproval reports whether making lp public must wait for a moderator: the
+// community requires approval and the viewer cannot approve it themselves
+func (s *LearningPathService) NeedsPublicationApproval(ctx context.Context, viewer Viewer, lp *model.LearningPath) (bool, error) {
+	if lp.CommunityID == nil || viewer.CanModerate(lp.CommunityID) {
+		return false, nil
+	}
+
+	var community model.Community
+	if err := s.DB.WithContext(ctx).Where("id = ?", *lp.CommunityID).First(&community).Error; err != nil {
+		return false, fmt.Errorf("failed to load community: %w", err)
+	}
+	return community.RequiresApproval, nil
+}
+
+// UpdateAndRequestPublication updates a learning path like UpdateLearningPath and, in the same
+// transaction, keeps it private and marks it as waiting for a moderator to approve its publication
+func (s *LearningPathService) UpdateAndRequestPublication(ctx context.Context, lpID, title, description string) (*model.LearningPath, error) {
+	return s.updateLearningPath(ctx, lpID, title, description, nil, true)
+}
+
+// ReviewPublication approves or rejects a pending publication. Approval makes the path public;
+// rejection keeps it private. Only moderators of the path's community and admins may review.
+func (s *LearningPathService) ReviewPublication(ctx context.Context, viewer Viewer, lpID string, approve bool) (*model.LearningPath, error) {
+	lp, err := s.Authorize(ctx, viewer, lpID, ActionReviewPublication)
+	if err != nil {
+		return nil, err
+	}
+	if lp.PublicationStatus != model.PublicationPending {
+		return nil, apperror.Conflict("publication_not_pending", "This learning path is not waiting for publication approval")
+	}
+
+	updates := map[string]interface{}{"publication_status": model.PublicationRejected}
+	if approve {
+		updates = map[string]interface{}{"is_public": true, "publication_status": model.PublicationApproved}
+	}
+	// Only the pending request is reviewed, even if another moderator got there first
+	result := s.DB.WithContext(ctx).Model(&model.LearningPath{}).
+		Where("id = ? AND publication_status = ?", lp.ID, model.PublicationPending).
+		Updates(updates)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to review publication: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, apperror.Conflict("publication_not_pending", "This learning path is not waiting for publication approval")
+	}
+	return s.reloadLearningPath(ctx, lp.ID)
+}
+
+// SetFeatured features a public learning path in its community, or stops featuring it. Only
+// moderators of the path's community and admins may do so.
+func (s *LearningPathService) SetFeatured(ctx context.Context, viewer Viewer, lpID string, featured bool) (*model.LearningPath, error) {
+	lp, err := s.Authorize(ctx, viewer, lpID, ActionFeature)
+	if err != nil {
+		return nil, err
+	}
+	if featured && !lp.IsPublic {
+		return nil, apperror.Conflict("learning_path_not_public", "Only public learning paths can be featured")
+	}
+
+	var featuredAt *time.Time
+	if featured {
+		now := time.Now()
+		featuredAt = &now
+	}
+	// Featuring is not an edit of the path, so updated_at is left alone
+	if err := s.DB.WithContext(ctx).Model(lp).UpdateColumn("featured_at", featuredAt).Error; err != nil {
+		return nil, fmt.Errorf("failed to feature learning path: %w", err)
+	}
+	return s.reloadLearningPath(ctx, lp.ID)
+}
+
+// GetLearningPath loads a learning path with its skills and community
+func (s *LearningPathService) GetLearningPath(ctx context.Context, lpID uuid.UUID) (*model.LearningPath, error) {
+	return s.reloadLearningPath(ctx, lpID)
+}
+
+// reloadLearningPath reads a learning path with its skills and community
+func (s *LearningPathService) reloadLearningPath(ctx context.Context, lpID uuid.UUID) (*model.LearningPath, error) {
+	var lp model.LearningPath
+	err := s.DB.WithContext(ctx).Preload("Skills.Skill").Preload("Community").First(&lp, "id = ?", lpID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errLearningPathNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load learning path: %w", err)
+	}
+	populateSkillsList(&lp)
+	return &lp, nil
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type renameDiagramPayload struct {
+	Name string `json:"name"`
+}
+
+// enqueueOutbox writes an outbox message using tx, so it commits or rolls back with the LP change
+func enqueueOutbox(tx *gorm.DB, msgType string, aggregateID uuid.UUID, payload interface{}) error {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox payload: %w", err)
+	}
+
+	// A newer rename makes older undelivered renames pointless
+	if msgType == model.OutboxTypeDiagramRename {
+		err := tx.Model(&model.OutboxMessage{}).
+			Where("aggregate_id = ? AND type = ? AND status = ? AND attempts = 0", aggregateID, msgType, model.OutboxStatusPending).
+			Update("status", model.OutboxStatusSuperseded).Error
+		if err != nil {
+			return fmt.Errorf("failed to supersede pending renames: %w", err)
+		}
+	}
+
+	msg := &model.OutboxMessage{
+		ID:            uuid.New(),
+		AggregateID:   aggregateID,
+		Type:          msgType,
+		Payload:       string(encoded),
+		Status:        model.OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := tx.Create(msg).Error; err != nil {
+		return fmt.Errorf("failed to write outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// notifyOutbox wakes the dispatcher, if one is wired, after a transaction with outbox writes commits
+func (s *LearningPathService) notifyOutbox() {
+	if s.OnOutboxEnqueued != nil {
+		s.OnOutboxEnqueued()
+	}
+}
+
+// OutboxDispatcher delivers outbox messages to backend-editor with retries and exponential backoff
+type OutboxDispatcher struct {
+	LPService   *LearningPathService
+	BatchSize   int
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Lease is how long a claimed message is hidden from other replicas while being delivered
+	Lease time.Duration
+
+	wake chan struct{}
+}
+
+// NewOutboxDispatcher creates a dispatcher configured from OUTBOX_MAX_ATTEMPTS
+func NewOutboxDispatcher(lpService *LearningPathService) *OutboxDispatcher {
+	maxAttempts := 12
+	if v, err := strconv.Atoi(os.Getenv("OUTBOX_MAX_ATTEMPTS")); err == nil && v > 0 {
+		maxAttempts = v
+	}
+
+	return &OutboxDispatcher{
+		LPService:   lpService,
+		BatchSize:   50,
+		MaxAttempts: maxAttempts,
+		BaseBackoff: 2 * time.Second,
+		MaxBackoff:  10 * time.Minute,
+		Lease:       30 * time.Second,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Wake triggers a dispatch pass without waiting for the next poll
+func (d *OutboxDispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default: // A pass is already scheduled
+	}
+}
+
+// Start dispatches pending messages on every interval, or sooner when woken, until ctx is canceled
+func (d *OutboxDispatcher) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DispatchPending(ctx); err != nil {
+			log.Printf("Outbox dispatch pass failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// DispatchPending delivers every due message and returns how many were delivered.
+// Only the oldest pending message of each aggregate is eligible, which keeps per-LP ordering.
+func (d *OutboxDispatcher) DispatchPending(ctx context.Context) (int, error) {
+	delivered := 0
+	for {
+		var batch []model.OutboxMessage
+		err := d.LPService.DB.WithContext(ctx).
+			Where("status = ? AND next_attempt_at <= ?", model.OutboxStatusPending, time.Now()).
+			Where("NOT EXISTS (?)", d.LPService.DB.
+				Model(&model.OutboxMessage{}).
+				Select("1").
+				Where("earlier.aggregate_id = outbox_messages.aggregate_id AND earlier.status = ? AND earlier.created_at < outbox_messages.created_at", model.OutboxStatusPending).
+				Table("outbox_messages AS earlier")).
+			Order("created_at").
+			Limit(d.BatchSize).
+			Find(&batch).Error
+		if err != nil {
+			return delivered, fmt.Errorf("failed to load outbox messages: %w", err)
+		}
+		if len(batch) == 0 {
+			return delivered, nil
+		}
+
+		progressed := 0
+		for i := range batch {
+			ok, err := d.dispatch(ctx, &batch[i])
+			if err != nil {
+				return delivered, err
+			}
+			if ok {
+				delivered++
+				progressed++
+			}
+		}
+
+		// Everything left failed and is backing off, or was claimed by another replica
+		if progressed == 0 {
+			return delivered, nil
+		}
+	}
+}
+
+// dispatch claims and delivers a single message, recording the outcome
+func (d *OutboxDispatcher) dispatch(ctx context.Context, msg *model.OutboxMessage) (bool, error) {
+	db := d.LPService.DB.WithContext(ctx)
+
+	claim := db.Model(&model.OutboxMessage{}).
+		Where("id = ? AND status = ? AND attempts = ?", msg.ID, model.OutboxStatusPending, msg.Attempts).
+		Updates(map[string]interface{}{"attempts": msg.Attempts + 1, "next_attempt_at": time.Now().Add(d.Lease)})
+	if claim.Error != nil {
+		return false, fmt.Errorf("failed to claim outbox message %s: %w", msg.ID, claim.Error)
+	}
+	if claim.RowsAffected == 0 {
+		return false, nil // Another replica picked it up
+	}
+	msg.Attempts++
+
+	if err := d.deliver(ctx, msg); err != nil {
+		updates := map[string]interface{}{
+			"last_error":      err.Error(),
+			"next_attempt_at": time.Now().Add(d.backoff(msg.Attempts)),
+		}
+		if msg.Attempts >= d.MaxAttempts {
+			updates["status"] = model.OutboxStatusDead
+			log.Printf("OUTBOX DELIVERY GAVE UP: %s message %s for LP %s requires manual intervention: %v", msg.Type, msg.ID, msg.AggregateID, err)
+		} else {
+			log.Printf("Outbox delivery attempt %d for %s message %s failed: %v", msg.Attempts, msg.Type, msg.ID, err)
+		}
+		if dbErr := db.Model(msg).Updates(updates).Error; dbErr != nil {
+			return false, fmt.Errorf("failed to record outbox failure for %s: %w", msg.ID, dbErr)
+		}
+		return false, nil
+	}
+
+	now := time.Now()
+	err := db.Model(msg).Updates(map[string]interface{}{
+		"status":       model.OutboxStatusDelivered,
+		"delivered_at": now,
+		"last_error":   "",
+	}).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to mark outbox message %s delivered: %w", msg.ID, err)
+	}
+
+	return true, nil
+}
+
+// deliver performs the backend-editor call for a message. Every handler must be idempotent
+// because a crash between delivery and bookkeeping leads to redelivery.
+func (d *OutboxDispatcher) deliver(ctx context.Context, msg *model.OutboxMessage) error {
+	s := d.LPService
+	lpID := msg.AggregateID.String()
+
+	switch msg.Type {
+	case model.OutboxTypeDiagramRename:
+		var payload renameDiagramPayload
+		if err := json.Unmarshal([]byte(msg.Payload), &payload); err != nil {
+			return fmt.Errorf("failed to decode payload: %w", err)
+		}
+		return s.updateDiagramName(ctx, lpID, payload.Name, s.ServiceToken)
+
+	case model.OutboxTypeDiagramDelete:
+		if err := s.deleteDiagramByLP(ctx, lpID, s.ServiceToken); err != nil {
+			return err
+		}
+		// The diagram is gone, so the soft-deleted LP can be removed permanently.
+		// The deleted_at guard keeps an LP restored in the meantime untouched.
+		return s.DB.WithContext(ctx).Unscoped().
+			Where("id = ? AND deleted_at IS NOT NULL", msg.AggregateID).
+			Delete(&model.LearningPath{}).Error
+
+	default:
+		return fmt.Errorf("unknown outbox message type %q", msg.Type)
+	}
+}
+
+// backoff returns the delay before the given attempt is retried
+func (d *OutboxDispatcher) backoff(attempts int) time.Duration {
+	delay := d.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.MaxBackoff {
+			return d.MaxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// RoleService manages platform roles: who holds them, and which token claims grant them
+type RoleService struct {
+	DB *gorm.DB
+	// OnRolesChanged, when set, is called with the user's Entra object ID after a role was
+	// granted to or revoked from them, e.g. to drop cached copies of the user
+	OnRolesChanged func(entraID string)
+	// OnClaimMappingsChanged, when set, is called after a claim mapping was added or removed,
+	// which may change the roles of any user
+	OnClaimMappingsChanged func()
+}
+
+func NewRoleService(db *gorm.DB) *RoleService {
+	return &RoleService{DB: db}
+}
+
+// RoleAssignment is a platform role held by a user
+type RoleAssignment struct {
+	UserID      uint      `json:"userId"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	Source      string    `json:"source"`
+	GrantedByID *uint     `json:"grantedById,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+var (
+	errRoleAssignmentNotFound   = apperror.NotFound("role_assignment_not_found", "The user does not have this role")
+	errRoleClaimMappingNotFound = apperror.NotFound("role_claim_mapping_not_found", "Role claim mapping not found")
+)
+
+// validatePlatformRole rejects names that are not platform roles
+func validatePlatformRole(role string) error {
+	for _, known := range model.PlatformRoles {
+		if role == known {
+			return nil
+		}
+	}
+	return apperror.Validation("invalid_role", "Unknown platform role",
+		apperror.FieldError{Field: "role", Code: "oneof", Message: "role must be one of: " + strings.Join(model.PlatformRoles, ", ")})
+}
+
+// ListRoleAssignments returns every stored role assignment, ordered by role and user. Roles
+// granted by token claims are not listed; see GetClaimMappings.
+func (s *RoleService) ListRoleAssignments(ctx context.Context) ([]RoleAssignment, error) {
+	assignments := []RoleAssignment{}
+	err := s.DB.WithContext(ctx).Table("user_platform_roles").
+		Select("user_platform_roles.user_id, users.name, users.email, user_platform_roles.role, user_platform_roles.source, user_platform_roles.granted_by_id, user_platform_roles.created_at").
+		Joins("JOIN users ON users.id = user_platform_roles.user_id AND users.deleted_at IS NULL").
+		Order("user_platform_roles.role ASC, users.email ASC").
+		Scan(&assignments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role assignments: %w", err)
+	}
+	return assignments, nil
+}
+
+// GrantRole gives the user with the given email a platform role. Granting a role the user
+// already has changes nothing.
+func (s *RoleService) GrantRole(ctx context.Context, actor *model.User, email, role string) (*RoleAssignment, error) {
+	if err := validatePlatformRole(role); err != nil {
+		return nil, err
+	}
+
+	var user model.User
+	err := s.DB.WithContext(ctx).Where("LOWER(email) = LOWER(?)", strings.TrimSpace(email)).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.NotFound("user_not_found", "No user with this email has signed in yet")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	assignment := model.UserPlatformRole{Source: model.RoleSourceManual}
+	if actor != nil {
+		assignment.GrantedByID = &actor.ID
+	}
+	err = s.DB.WithContext(ctx).Where(model.UserPlatformRole{UserID: user.ID, Role: role}).
+		Attrs(assignment).
+		FirstOrCreate(&assignment).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to grant role: %w", err)
+	}
+	if s.OnRolesChanged != nil {
+		s.OnRolesChanged(user.EntraID)
+	}
+
+	return &RoleAssignment{
+		UserID:      user.ID,
+		Name:        user.Name,
+		Email:       user.Email,
+		Role:        assignment.Role,
+		Source:      assignment.Source,
+		GrantedByID: assignment.GrantedByID,
+		CreatedAt:   assignment.CreatedAt,
+	}, nil
+}
+
+// RevokeRole takes a platform role away from a user. The last stored admin cannot be removed,
+// since nobody would be left to grant roles.
+func (s *RoleService) RevokeRole(ctx context.Context, userID uint, role string) error {
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var assignment model.UserPlatformRole
+		err := tx.Where("user_id = ? AND role = ?", userID, role).First(&assignment).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errRoleAssignmentNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if role == model.PlatformRoleAdmin {
+			var admins int64
+			if err := tx.Model(&model.UserPlatformRole{}).Where("role = ?", model.PlatformRoleAdmin).Count(&admins).Error; err != nil {
+				return err
+			}
+			if admins <= 1 {
+				return apperror.Conflict("last_admin", "The last admin cannot be removed")
+			}
+		}
+
+		return tx.Where("user_id = ? AND role = ?", userID, role).Delete(&model.UserPlatformRole{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke role: %w", err)
+	}
+	reportRolesChanged(s.DB.WithContext(ctx), s.OnRolesChanged, userID)
+	return nil
+}
+
+// reportRolesChanged looks up the Entra object ID of the user and passes it to onChanged
+func reportRolesChanged(db *gorm.DB, onChanged func(entraID string), userID uint) {
+	if onChanged == nil {
+		return
+	}
+	var user model.User
+	if err := db.Unscoped().Select("entra_id").First(&user, userID).Error; err != nil {
+		log.Printf("Failed to report role change of user %d: %v", userID, err)
+		return
+	}
+	onChanged(user.EntraID)
+}
+
+// grantBootstrapAdmin makes a new user an admin when their email is listed in ADMIN_EMAILS
+func grantBootstrapAdmin(db *gorm.DB, user *model.User, adminEmails []string) error {
+	email := strings.ToLower(user.Email)
+	for _, admin := range adminEmails {
+		if admin != email {
+			continue
+		}
+		assignment := model.UserPlatformRole{UserID: user.ID, Role: model.PlatformRoleAdmin, Source: model.RoleSourceBootstrap}
+		if err := db.Create(&assignment).Error; err != nil {
+			return fmt.Errorf("failed to grant admin role: %w", err)
+		}
+		user.PlatformRoles = append(user.PlatformRoles, assignment)
+		return nil
+	}
+	return nil
+}
+
+// GetClaimMappings returns the claim mappings ordered by claim type and value
+func (s *RoleService) GetClaimMappings(ctx context.Context) ([]model.PlatformRoleClaimMapping, error) {
+	mappings := []model.PlatformRoleClaimMapping{}
+	if err := s.DB.WithContext(ctx).Order("claim_type ASC, value ASC, role ASC").Find(&mappings).Error; err != nil {
+		return nil, fmt.Errorf("failed to list role claim mappings: %w", err)
+	}
+	return mappings, nil
+}
+
+// AddClaimMapping grants role to everyone whose ID token carries value in the claimType claim
+func (s *RoleService) AddClaimMapping(ctx context.Context, claimType, value, role string) (*model.PlatformRoleClaimMapping, error) {
+	value = strings.TrimSpace(value)
+	var fields []apperror.FieldError
+	if claimType != model.ClaimTypeGroup && claimType != model.ClaimTypeAppRole {
+		fields = append(fields, apperror.FieldError{Field: "claim", Code: "oneof", Message: "claim must be one of: groups, roles"})
+	}
+	if value == "" {
+		fields = append(fields, apperror.FieldError{Field: "value", Code: "required", Message: "value is required"})
+	}
+	if len(fields) > 0 {
+		return nil, apperror.Validation("validation_failed", "One or more fields are invalid", fields...)
+	}
+	if err := validatePlatformRole(role); err != nil {
+		return nil, err
+	}
+
+	mapping := model.PlatformRoleClaimMapping{ClaimType: claimType, Value: value, Role: role}
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var taken int64
+		if err := tx.Model(&model.PlatformRoleClaimMapping{}).Where("claim_type = ? AND value = ? AND role = ?", claimType, value, role).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return apperror.Conflict("role_claim_mapping_exists", "This claim already grants this role")
+		}
+		return tx.Create(&mapping).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add role claim mapping: %w", err)
+	}
+	s.claimMappingsChanged()
+	return &mapping, nil
+}
+
+// RemoveClaimMapping deletes a claim mapping. Users lose the role on their next request.
+func (s *RoleService) RemoveClaimMapping(ctx context.Context, id uint) error {
+	result := s.DB.WithContext(ctx).Delete(&model.PlatformRoleClaimMapping{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove role claim mapping: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errRoleClaimMappingNotFound
+	}
+	s.claimMappingsChanged()
+	return nil
+}
+
+func (s *RoleService) claimMappingsChanged() {
+	if s.OnClaimMappingsChanged != nil {
+		s.OnClaimMappingsChanged()
+	}
+}
+
+// RolesFromClaims returns the platform roles granted by the user's groups and app roles, as read
+// by ResolveGroups and TokenAppRoles
+func (s *RoleService) RolesFromClaims(ctx context.Context, groups, appRoles []string) ([]string, error) {
+	if len(groups) == 0 && len(appRoles) == 0 {
+		return nil, nil
+	}
+
+	var roles []string
+	err := s.DB.WithContext(ctx).Model(&model.PlatformRoleClaimMapping{}).
+		Where("(claim_type = ? AND value IN ?) OR (claim_type = ? AND value IN ?)",
+			model.ClaimTypeGroup, groups, model.ClaimTypeAppRole, appRoles).
+		Distinct().Order("role ASC").Pluck("role", &roles).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to match role claims: %w", err)
+	}
+	return roles, nil
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"github.com/google/uuid"
+)
+
+// Drift kinds detected between learning_paths and backend-editor diagrams
+const (
+	DriftOrphanedDiagram   = "orphaned_diagram"    // Diagram whose learning path does not exist
+	DriftSoftDeletedLP     = "soft_deleted_lp"     // LP soft-deleted but never hard-deleted
+	DriftMissingDiagram    = "missing_diagram"     // Active LP whose diagram does not exist
+	DriftDiagramIDMismatch = "diagram_id_mismatch" // LP points to a different diagram than the one linked to it
+	DriftUnlinkedDiagram   = "unlinked_diagram"    // Diagram without learningPathId (legacy), reported only
+)
+
+// ReconciliationIssue describes one drift between PostgreSQL and backend-editor
+type ReconciliationIssue struct {
+	Kind      string `json:"kind"`
+	LPID      string `json:"lpId,omitempty"`
+	DiagramID string `json:"diagramId,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Action    string `json:"action"`
+	Repaired  bool   `json:"repaired"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ReconciliationReport is the result of a single reconciliation pass
+type ReconciliationReport struct {
+	DryRun        bool                  `json:"dryRun"`
+	StartedAt     time.Time             `json:"startedAt"`
+	FinishedAt    time.Time             `json:"finishedAt"`
+	Diagrams      int                   `json:"diagramsChecked"`
+	LearningPaths int                   `json:"learningPathsChecked"`
+	Skipped       int                   `json:"skippedInFlight"`
+	Issues        []ReconciliationIssue `json:"issues"`
+	Repaired      int                   `json:"repaired"`
+	Failed        int                   `json:"failed"`
+}
+
+// Reconciler detects and repairs drift between learning paths and backend-editor diagrams
+type Reconciler struct {
+	LPService *LearningPathService
+}
+
+func NewReconciler(lpService *LearningPathService) *Reconciler {
+	return &Reconciler{LPService: lpService}
+}
+
+// Start runs a reconciliation pass on every interval until ctx is canceled.
+// Without autoRepair the periodic passes only report drift.
+func (r *Reconciler) Start(ctx context.Context, interval time.Duration, autoRepair bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		report, err := r.Reconcile(ctx, !autoRepair)
+		if err != nil {
+			log.Printf("Reconciliation pass failed: %v", err)
+			continue
+		}
+		if len(report.Issues) > 0 {
+			log.Printf("RECONCILIATION DRIFT: %d issue(s) found, %d repaired, %d failed (dry run: %v)", len(report.Issues), report.Repaired, report.Failed, report.DryRun)
+		}
+	}
+}
+
+// Reconcile compares diagrams in backend-editor with learning paths in PostgreSQL.
+// In dry-run mode issues are only reported; otherwise each one is repaired.
+func (r *Reconciler) Reconcile(ctx context.Context, dryRun bool) (*ReconciliationReport, error) {
+	s := r.LPService
+	report := &ReconciliationReport{DryRun: dryRun, StartedAt: time.Now(), Issues: []ReconciliationIssue{}}
+
+	// Diagrams are listed before LPs and sagas are loaded: every diagram in the list had its
+	// saga row written before it was created, so it is either in-flight or its LP is visible below
+	diagrams, err := s.Editor.ListDiagrams(ctx, s.ServiceToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list diagrams: %w", err)
+	}
+
+	var paths []model.LearningPath
+	if err := s.DB.WithContext(ctx).Unscoped().Find(&paths).Error; err != nil {
+		return nil, fmt.Errorf("failed to load learning paths: %w", err)
+	}
+
+	// LPs with unfinished sagas are owned by the SagaRecoverer
+	var inFlight []uuid.UUID
+	err = s.DB.WithContext(ctx).
+		Model(&model.SagaLog{}).
+		Where("status IN ?", []string{model.SagaStatusRunning, model.SagaStatusCompensating}).
+		Pluck("lp_id", &inFlight).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load in-flight sagas: %w", err)
+	}
+	// LPs with undelivered outbox messages are owned by the OutboxDispatcher
+	var pending []uuid.UUID
+	err = s.DB.WithContext(ctx).
+		Model(&model.OutboxMessage{}).
+		Where("status = ?", model.OutboxStatusPending).
+		Pluck("aggregate_id", &pending).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pending outbox messages: %w", err)
+	}
+	skip := make(map[string]bool, len(inFlight)+len(pending))
+	for _, id := range append(inFlight, pending...) {
+		skip[id.String()] = true
+	}
+
+	report.Diagrams = len(diagrams)
+	report.LearningPaths = len(paths)
+
+	diagramsByLP := make(map[string]Diagram, len(diagrams))
+	for _, d := range diagrams {
+		if d.LearningPathID == "" {
+			report.Issues = append(report.Issues, ReconciliationIssue{
+				Kind:      DriftUnlinkedDiagram,
+				DiagramID: d.ID,
+				Name:      d.Name,
+				Action:    "none (manual review)",
+			})
+			continue
+		}
+		diagramsByLP[d.LearningPathID] = d
+	}
+
+	pathsByID := make(map[string]*model.LearningPath, len(paths))
+	for i := range paths {
+		lp := &paths[i]
+		lpID := lp.ID.String()
+		pathsByID[lpID] = lp
+		if skip[lpID] {
+			report.Skipped++
+			continue
+		}
+
+		diagram, hasDiagram := diagramsByLP[lpID]
+		switch {
+		case lp.DeletedAt.Valid:
+			issue := ReconciliationIssue{Kind: DriftSoftDeletedLP, LPID: lpID, DiagramID: lp.DiagramID, Name: lp.Title, Action: "delete diagram and hard-delete LP"}
+			r.apply(report, issue, func() error { return r.finishDelete(ctx, lp) })
+		case !hasDiagram:
+			issue := ReconciliationIssue{Kind: DriftMissingDiagram, LPID: lpID, DiagramID: lp.DiagramID, Name: lp.Title, Action: "recreate diagram"}
+			r.apply(report, issue, func() error { return r.recreateDiagram(ctx, lp) })
+		case diagram.ID != lp.DiagramID:
+			issue := ReconciliationIssue{Kind: DriftDiagramIDMismatch, LPID: lpID, DiagramID: diagram.ID, Name: lp.Title, Action: "relink LP to diagram"}
+			r.apply(report, issue, func() error { return r.relinkDiagram(ctx, lp, diagram.ID) })
+		}
+	}
+
+	for _, d := range diagrams {
+		lpID := d.LearningPathID
+		if lpID == "" {
+			continue
+		}
+		if _, exists := pathsByID[lpID]; exists {
+			continue
+		}
+		if skip[lpID] {
+			report.Skipped++
+			continue
+		}
+		issue := ReconciliationIssue{Kind: DriftOrphanedDiagram, LPID: lpID, DiagramID: d.ID, Name: d.Name, Action: "delete diagram"}
+		r.apply(report, issue, func() error { return s.deleteDiagramByLP(ctx, lpID, s.ServiceToken) })
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// apply records an issue and, outside dry-run mode, runs its repair
+func (r *Reconciler) apply(report *ReconciliationReport, issue ReconciliationIssue, repair func() error) {
+	if !report.DryRun {
+		if err := repair(); err != nil {
+			issue.Error = err.Error()
+			report.Failed++
+			log.Printf("Reconciliation repair failed (%s, LP %s): %v", issue.Kind, issue.LPID, err)
+		} else {
+			issue.Repaired = true
+			report.Repaired++
+		}
+	}
+	report.Issues = append(report.Issues, issue)
+}
+
+// finishDelete completes a delete whose hard-delete step never ran
+func (r *Reconciler) finishDelete(ctx context.Context, lp *model.LearningPath) error {
+	s := r.LPService
+	if err := s.deleteDiagramByLP(ctx, lp.ID.String(), s.ServiceToken); err != nil {
+		return fmt.Errorf("delete diagram: %w", err)
+	}
+	if err := s.DB.WithContext(ctx).Unscoped().Delete(lp).Error; err != nil {
+		return fmt.Errorf("hard-delete LP: %w", err)
+	}
+	return nil
+}
+
+// recreateDiagram creates a fresh (default template) diagram for an LP that lost its diagram
+func (r *Reconciler) recreateDiagram(ctx context.Context, lp *model.LearningPath) error {
+	s := r.LPService
+	dr, err := s.Editor.CreateDiagram(ctx, lp.ID.String(), lp.Title, s.ServiceToken)
+	if err != nil {
+		return fmt.Errorf("create diagram: %w", err)
+	}
+	return r.relinkDiagram(ctx, lp, dr.ID)
+}
+
+func (r *Reconciler) relinkDiagram(ctx context.Context, lp *model.LearningPath, diagramID string) error {
+	err := r.LPService.DB.WithContext(ctx).
+		Model(&model.LearningPath{}).
+		Where("id = ?", lp.ID).
+		Update("diagram_id", diagramID).Error
+	if err != nil {
+		return fmt.Errorf("update diagram ID: %w", err)
+	}
+	lp.DiagramID = diagramID
+	return nil
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"github.com/google/uuid"
+)
+
+// Saga steps, persisted in SagaLog.Step after each forward step succeeds
+const (
+	sagaStepStarted        = "started"
+	sagaStepDiagramCreated = "diagram_created"
+)
+
+// DefaultSagaHeartbeat is how often a running saga is marked alive unless
+// LearningPathService.SagaHeartbeat says otherwise
+const DefaultSagaHeartbeat = 15 * time.Second
+
+type createSagaPayload struct {
+	Title     string `json:"title"`
+	DiagramID string `json:"diagramId,omitempty"`
+}
+
+// beginSaga persists a new running saga before its first step is executed
+func (s *LearningPathService) beginSaga(ctx context.Context, sagaType string, lpID uuid.UUID, payload interface{}) (*model.SagaLog, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode saga payload: %w", err)
+	}
+
+	saga := &model.SagaLog{
+		ID:      uuid.New(),
+		Type:    sagaType,
+		LPID:    lpID,
+		Step:    sagaStepStarted,
+		Payload: string(encoded),
+		Status:  model.SagaStatusRunning,
+	}
+	if err := s.DB.WithContext(ctx).Create(saga).Error; err != nil {
+		return nil, fmt.Errorf("failed to persist saga log: %w", err)
+	}
+
+	return saga, nil
+}
+
+// advanceSaga records that a forward step succeeded. A nil payload keeps the stored one.
+// Failures are logged rather than returned: the recoverer can still converge from the previous step.
+func (s *LearningPathService) advanceSaga(ctx context.Context, saga *model.SagaLog, step string, payload interface{}) {
+	updates := map[string]interface{}{"step": step}
+	if payload != nil {
+		if encoded, err := json.Marshal(payload); err == nil {
+			updates["payload"] = string(encoded)
+			saga.Payload = string(encoded)
+		}
+	}
+	saga.Step = step
+
+	// Saga bookkeeping must survive the request context being canceled
+	if err := s.DB.WithContext(context.WithoutCancel(ctx)).Model(saga).Updates(updates).Error; err != nil {
+		log.Printf("SAGA LOG WRITE FAILED: saga %s (%s) step %s: %v", saga.ID, saga.Type, step, err)
+	}
+}
+
+// keepSagaAlive touches the saga's updated_at every SagaHeartbeat while it is running, so a
+// request held up by editor retries and backoff is never taken for an abandoned saga. Only a
+// saga whose process died stops being touched. The returned function stops the heartbeat.
+func (s *LearningPathService) keepSagaAlive(ctx context.Context, saga *model.SagaLog) func() {
+	interval := s.SagaHeartbeat
+	if interval <= 0 {
+		interval = DefaultSagaHeartbeat
+	}
+	// Saga bookkeeping must survive the request context being canceled
+	db := s.DB.WithContext(context.WithoutCancel(ctx))
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			err := db.Model(&model.SagaLog{}).
+				Where("id = ? AND status = ?", saga.ID, model.SagaStatusRunning).
+				Update("updated_at", time.Now()).Error
+			if err != nil {
+				log.Printf("SAGA LOG WRITE FAILED: saga %s (%s) heartbeat: %v", saga.ID, saga.Type, err)
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-stopped
+	}
+}
+
+// finishSaga moves a saga to the given status, recording the cause if any
+func (s *LearningPathService) finishSaga(ctx context.Context, saga *model.SagaLog, status string, cause error) {
+	updates := map[string]interface{}{"status": status}
+	if cause != nil {
+		updates["last_error"] = cause.Error()
+		saga.LastError = cause.Error()
+	}
+	saga.Status = status
+
+	if err := s.DB.WithContext(context.WithoutCancel(ctx)).Model(saga).Updates(updates).Error; err != nil {
+		log.Printf("SAGA LOG WRITE FAILED: saga %s (%s) status %s: %v", saga.ID, saga.Type, status, err)
+		return
+	}
+
+	switch status {
+	case model.SagaStatusCompleted:
+		log.Printf("SAGA COMPLETED: %s saga %s for LP %s", saga.Type, saga.ID, saga.LPID)
+	case model.SagaStatusCompensated:
+		log.Printf("SAGA COMPENSATION: %s saga %s for LP %s rolled back: %v", saga.Type, saga.ID, saga.LPID, cause)
+	case model.SagaStatusCompensating:
+		log.Printf("SAGA COMPENSATION PENDING: %s saga %s for LP %s left for recovery: %v", saga.Type, saga.ID, saga.LPID, cause)
+	}
+}
+
+// SagaRecoverer resumes or compensates sagas left unfinished by a crashed or restarted process
+type SagaRecoverer struct {
+	LPService *LearningPathService
+	// StaleAfter is how long a saga must be idle before it is considered abandoned. Running
+	// sagas are touched every LearningPathService.SagaHeartbeat however slow their steps are,
+	// so it only has to exceed the heartbeat by enough to ride out a missed write or two.
+	StaleAfter  time.Duration
+	MaxAttempts int
+}
+
+// NewSagaRecoverer creates a recoverer configured from SAGA_STALE_AFTER_SECONDS and SAGA_MAX_ATTEMPTS
+func NewSagaRecoverer(lpService *LearningPathService) *SagaRecoverer {
+	staleAfter := 60 * time.Second
+	if v, err := strconv.Atoi(os.Getenv("SAGA_STALE_AFTER_SECONDS")); err == nil && v > 0 {
+		staleAfter = time.Duration(v) * time.Second
+	}
+
+	// A live saga must never look stale between two heartbeats
+	heartbeat := lpService.SagaHeartbeat
+	if heartbeat <= 0 {
+		heartbeat = DefaultSagaHeartbeat
+	}
+	if minimum := 3 * heartbeat; staleAfter < minimum {
+		log.Printf("SAGA_STALE_AFTER_SECONDS is below three saga heartbeats, using %s", minimum)
+		staleAfter = minimum
+	}
+
+	maxAttempts := 10
+	if v, err := strconv.Atoi(os.Getenv("SAGA_MAX_ATTEMPTS")); err == nil && v > 0 {
+		maxAttempts = v
+	}
+
+	return &SagaRecoverer{
+		LPService:   lpService,
+		StaleAfter:  staleAfter,
+		MaxAttempts: maxAttempts,
+	}
+}
+
+// Start runs a recovery pass immediately and then on every interval until ctx is canceled
+func (r *SagaRecoverer) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if recovered, err := r.RecoverPending(ctx); err != nil {
+			log.Printf("Saga recovery pass failed: %v", err)
+		} else if recovered > 0 {
+			log.Printf("Saga recovery pass finished %d saga(s)", recovered)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RecoverPending processes every stale unfinished saga once and returns how many reached a final status
+func (r *SagaRecoverer) RecoverPending(ctx context.Context) (int, error) {
+	db := r.LPService.DB.WithContext(ctx)
+
+	staleBefore := time.Now().Add(-r.StaleAfter)
+	var sagas []model.SagaLog
+	err := db.
+		Where("status IN ?", []string{model.SagaStatusRunning, model.SagaStatusCompensating}).
+		Where("updated_at < ?", staleBefore).
+		Order("created_at").
+		Find(&sagas).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load pending sagas: %w", err)
+	}
+
+	finished := 0
+	for i := range sagas {
+		saga := &sagas[i]
+
+		claimed, err := r.claim(ctx, saga, staleBefore)
+		if err != nil {
+			return finished, err
+		}
+		if !claimed {
+			continue // Another replica picked it up
+		}
+
+		if saga.Attempts > r.MaxAttempts {
+			r.LPService.finishSaga(ctx, saga, model.SagaStatusFailed, errors.New("max recovery attempts exceeded"))
+			log.Printf("SAGA RECOVERY GAVE UP: %s saga %s for LP %s requires manual intervention (last error: %s)", saga.Type, saga.ID, saga.LPID, saga.LastError)
+			continue
+		}
+
+		if err := r.recover(ctx, saga); err != nil {
+			log.Printf("Saga recovery attempt %d for %s saga %s failed: %v", saga.Attempts, saga.Type, saga.ID, err)
+			db.Model(saga).Update("last_error", err.Error())
+			continue
+		}
+		finished++
+	}
+
+	return finished, nil
+}
+
+// claim bumps the attempt counter only if nobody touched the saga since it was loaded, and its
+// heartbeat has not resumed
+func (r *SagaRecoverer) claim(ctx context.Context, saga *model.SagaLog, staleBefore time.Time) (bool, error) {
+	now := time.Now()
+	result := r.LPService.DB.WithContext(ctx).
+		Model(&model.SagaLog{}).
+		Where("id = ? AND attempts = ? AND status = ? AND updated_at < ?", saga.ID, saga.Attempts, saga.Status, staleBefore).
+		Updates(map[string]interface{}{"attempts": saga.Attempts + 1, "updated_at": now})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim saga %s: %w", saga.ID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	saga.Attempts++
+	saga.UpdatedAt = now
+	return true, nil
+}
+
+func (r *SagaRecoverer) recover(ctx context.Context, saga *model.SagaLog) error {
+	switch saga.Type {
+	case model.SagaTypeCreateLearningPath:
+		return r.recoverCreate(ctx, saga)
+	default:
+		return fmt.Errorf("unknown saga type %q", saga.Type)
+	}
+}
+
+// recoverCreate completes the saga if the LP was committed, otherwise deletes the diagram
+func (r *SagaRecoverer) recoverCreate(ctx context.Context, saga *model.SagaLog) error {
+	s := r.LPService
+
+	var count int64
+	if err := s.DB.WithContext(ctx).Unscoped().Model(&model.LearningPath{}).Where("id = ?", saga.LPID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to look up learning path: %w", err)
+	}
+	if count > 0 {
+		s.finishSaga(ctx, saga, model.SagaStatusCompleted, nil)
+		return nil
+	}
+
+	// Deleting is idempotent, so this is safe whether or not step 1 reached MongoDB
+	if err := s.deleteDiagramByLP(ctx, saga.LPID.String(), s.ServiceToken); err != nil {
+		return fmt.Errorf("delete orphaned diagram: %w", err)
+	}
+	s.finishSaga(ctx, saga, model.SagaStatusCompensated, errors.New("recovered: learning path was never committed"))
+	return nil
+}
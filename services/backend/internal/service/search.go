@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultSearchLimit is the number of results returned when a search does not ask for a limit
+	DefaultSearchLimit = 20
+	// MaxSearchLimit bounds the number of results a search may ask for
+	MaxSearchLimit = 50
+	// maxSearchTerms bounds the terms taken from a query, so a pasted paragraph cannot build a huge query
+	maxSearchTerms = 10
+	// snippetRadius is the number of characters kept on each side of the first match in a fallback snippet
+	snippetRadius = 80
+)
+
+// Highlight markers used inside the database; they are swapped for <mark> tags after HTML-escaping
+const (
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+)
+
+// SearchQuery is a full-text search over learning paths
+type SearchQuery struct {
+	Viewer    Viewer // Private paths the viewer may not see are left out
+	Text      string
+	Community string // Restricts results to one community, by name or slug (optional)
+	Limit     int    // Maximum number of results (default: DefaultSearchLimit)
+}
+
+// SearchResult is a matching learning path with its relevance and highlighted excerpts.
+// Highlights are HTML-escaped text with matches wrapped in <mark> tags.
+type SearchResult struct {
+	LearningPath   model.LearningPath `json:"learningPath"`
+	Rank           float64            `json:"rank"`
+	TitleHighlight string             `json:"titleHighlight"`
+	Snippet        string             `json:"snippet"` // Excerpt of the description around the matches
+}
+
+// SearchResults is the response of a search
+type SearchResults struct {
+	Items []SearchResult `json:"items"`
+	// Total is the number of matching paths, which may exceed len(Items)
+	Total int64 `json:"total"`
+}
+
+// searchRow is a match as returned by the database, before the learning paths are loaded
+type searchRow struct {
+	ID             uuid.UUID
+	Rank           float64
+	TitleHighlight string
+	Snippet        string
+}
+
+// SearchService searches learning paths by title, description and skill names
+type SearchService struct {
+	DB *gorm.DB
+}
+
+// NewSearchService creates a new SearchService
+func NewSearchService(db *gorm.DB) *SearchService {
+	return &SearchService{DB: db}
+}
+
+// searchTerms splits text into lowercase words. Only letters and digits are kept, so terms are
+// safe to embed in a tsquery and every term can be prefix-matched.
+func searchTerms(text string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if seen[word] {
+			continue
+		}
+		seen[word] = true
+		terms = append(terms, word)
+		if len(terms) == maxSearchTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// Search returns the learning paths matching every term of q (the last characters of a term may be
+// missing, so results appear while the user is still typing), best matches first.
+// PostgreSQL uses the search_vector column; other databases (the SQLite test DB) fall back to LIKE.
+func (s *SearchService) Search(ctx context.Context, q SearchQuery) (*SearchResults, error) {
+	terms := searchTerms(q.Text)
+	if len(terms) == 0 {
+		return nil, apperror.Validation("search_query_required", "Search query is required",
+			apperror.FieldError{Field: "q", Code: "required", Message: "q must contain at least one letter or digit"})
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultSearchLimit
+	}
+	if q.Limit > MaxSearchLimit {
+		q.Limit = MaxSearchLimit
+	}
+
+	var (
+		rows  []searchRow
+		total int64
+		err   error
+	)
+	if s.DB.Dialector.Name() == "postgres" {
+		rows, total, err = s.searchPostgres(ctx, terms, q)
+	} else {
+		rows, total, err = s.searchFallback(ctx, terms, q)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to search learning paths: %w", err)
+	}
+
+	items, err := s.loadResults(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	return &SearchResults{Items: items, Total: total}, nil
+}
+
+// searchPostgres ranks matches with ts_rank and highlights them with ts_headline
+func (s *SearchService) searchPostgres(ctx context.Context, terms []string, q SearchQuery) ([]searchRow, int64, error) {
+	prefixes := make([]string, len(terms))
+	for i, term := range terms {
+		prefixes[i] = term + ":*"
+	}
+	tsQuery := strings.Join(prefixes, " & ")
+	selectors := fmt.Sprintf(`StartSel="%s", StopSel="%s"`, highlightStart, highlightStop)
+
+	base := func() *gorm.DB {
+		db := q.Viewer.scopeVisible(s.DB.WithContext(ctx).Model(&model.LearningPath{})).
+			Where("search_vector @@ to_tsquery('english', ?)", tsQuery)
+		if q.Community != "" {
+			db = db.Where(communityRefFilter, q.Community, q.Community)
+		}
+		return db
+	}
+
+	var total int64
+	if err := base().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []searchRow
+	err := base().
+		Select(`id,
+			ts_rank(search_vector, to_tsquery('english', ?)) AS rank,
+			ts_headline('english', title, to_tsquery('english', ?), ?) AS title_highlight,
+			ts_headline('english', coalesce(description, ''), to_tsquery('english', ?), ?) AS snippet`,
+			tsQuery, tsQuery, selectors+", HighlightAll=true", tsQuery, selectors+", MaxWords=35, MinWords=15").
+		Order("rank DESC, updated_at DESC").
+		Limit(q.Limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range rows {
+		rows[i].TitleHighlight = renderHighlight(rows[i].TitleHighlight)
+		rows[i].Snippet = renderHighlight(rows[i].Snippet)
+	}
+	return rows, total, nil
+}
+
+// searchFallback matches terms with LIKE and ranks in Go by where they matched:
+// title matches weigh most, then skills, then description, mirroring the search_vector weights
+func (s *SearchService) searchFallback(ctx context.Context, terms []string, q SearchQuery) ([]searchRow, int64, error) {
+	db := q.Viewer.scopeVisible(s.DB.WithContext(ctx).Model(&model.LearningPath{}))
+	for _, term := range terms {
+		pattern := "%" + term + "%"
+		db = db.Where("(LOWER(title) LIKE ? OR LOWER(description) LIKE ? OR LOWER(skill_names) LIKE ?)", pattern, pattern, pattern)
+	}
+	if q.Community != "" {
+		db = db.Where(communityRefFilter, q.Community, q.Community)
+	}
+
+	var paths []model.LearningPath
+	if err := db.Order("updated_at DESC").Find(&paths).Error; err != nil {
+		return nil, 0, err
+	}
+
+	rows := make([]searchRow, len(paths))
+	for i, lp := range paths {
+		title, description, skills := strings.ToLower(lp.Title), strings.ToLower(lp.Description), strings.ToLower(lp.SkillNames)
+		var rank float64
+		for _, term := range terms {
+			switch {
+			case strings.Contains(title, term):
+				rank += 1.0
+			case strings.Contains(skills, term):
+				rank += 0.4
+			case strings.Contains(description, term):
+				rank += 0.2
+			}
+		}
+		rows[i] = searchRow{
+			ID:             lp.ID,
+			Rank:           rank / float64(len(terms)),
+			TitleHighlight: renderHighlight(markTerms(lp.Title, terms)),
+			Snippet:        renderHighlight(markTerms(excerpt(lp.Description, terms), terms)),
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Rank > rows[j].Rank })
+
+	total := int64(len(rows))
+	if len(rows) > q.Limit {
+		rows = rows[:q.Limit]
+	}
+	return rows, total, nil
+}
+
+// loadResults loads the matched learning paths with their skills, keeping the ranking order
+func (s *SearchService) loadResults(ctx context.Context, rows []searchRow) ([]SearchResult, error) {
+	results := make([]SearchResult, 0, len(rows))
+	if len(rows) == 0 {
+		return results, nil
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	var paths []model.LearningPath
+	if err := s.DB.WithContext(ctx).Preload("Skills.Skill").Preload("Community").Where("id IN ?", ids).Find(&paths).Error; err != nil {
+		return nil, fmt.Errorf("failed to load search results: %w", err)
+	}
+	populateSkillsListForPaths(paths)
+
+	byID := make(map[uuid.UUID]model.LearningPath, len(paths))
+	for _, lp := range paths {
+		byID[lp.ID] = lp
+	}
+	for _, row := range rows {
+		lp, ok := byID[row.ID]
+		if !ok {
+			continue // Deleted between the search and the load
+		}
+		results = append(results, SearchResult{
+			LearningPath:   lp,
+			Rank:           row.Rank,
+			TitleHighlight: row.TitleHighlight,
+			Snippet:        row.Snippet,
+		})
+	}
+	return results, nil
+}
+
+// markTerms wraps every word of text that starts with a search term in highlight markers
+func markTerms(text string, terms []string) string {
+	var b strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if !unicode.IsLetter(runes[i]) && !unicode.IsDigit(runes[i]) {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+			j++
+		}
+		word := string(runes[i:j])
+		if wordMatches(strings.ToLower(word), terms) {
+			b.WriteString(highlightStart + word + highlightStop)
+		} else {
+			b.WriteString(word)
+		}
+		i = j
+	}
+	return b.String()
+}
+
+func wordMatches(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// excerpt cuts text to the part around the first term it contains, so long descriptions
+// yield a short snippet like ts_headline does
+func excerpt(text string, terms []string) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(runes) != len(lower) || len(runes) <= 2*snippetRadius {
+		return text
+	}
+
+	first := -1
+	for _, term := range terms {
+		if idx := strings.Index(string(lower), term); idx >= 0 {
+			pos := len([]rune(string(lower)[:idx]))
+			if first < 0 || pos < first {
+				first = pos
+			}
+		}
+	}
+	if first < 0 {
+		first = 0
+	}
+
+	start := max(first-snippetRadius, 0)
+	end := min(first+snippetRadius, len(runes))
+	snippet := strings.TrimSpace(string(runes[start:end]))
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// renderHighlight HTML-escapes text and turns the highlight markers into <mark> tags, so clients
+// can render highlights as HTML without trusting user-written titles and descriptions
+func renderHighlight(text string) string {
+	escaped := html.EscapeString(text)
+	escaped = strings.ReplaceAll(escaped, highlightStart, "<mark>")
+	return strings.ReplaceAll(escaped, highlightStop, "</mark>")
+}
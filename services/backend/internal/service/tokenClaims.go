@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"log"
+)
+
+// TokenUserID returns the ID users are stored by: the oid claim of Entra ID tokens, or sub for
+// other OIDC providers (Keycloak, Google, Okta), which have no oid. Entra's sub differs per app,
+// so it is only used when there is no oid.
+func TokenUserID(claims map[string]interface{}) string {
+	if oid, _ := claims["oid"].(string); oid != "" {
+		return oid
+	}
+	sub, _ := claims["sub"].(string)
+	return sub
+}
+
+// TokenID returns the identifier auth-service revokes a token by: jti, or uti on Entra ID tokens
+func TokenID(claims map[string]interface{}) string {
+	if jti, _ := claims["jti"].(string); jti != "" {
+		return jti
+	}
+	uti, _ := claims["uti"].(string)
+	return uti
+}
+
+// TokenGroups reads the groups claim of an Entra ID token. Entra only emits it when the app
+// registration's groupMembershipClaims is set. overage is true when the user is in more groups
+// than fit in a token: Entra then leaves the claim out and points at Microsoft Graph instead,
+// through _claim_names (or hasgroups in implicit-flow tokens).
+func TokenGroups(claims map[string]interface{}) (groups []string, overage bool) {
+	if names, ok := claims["_claim_names"].(map[string]interface{}); ok {
+		if _, ok := names["groups"]; ok {
+			return nil, true
+		}
+	}
+	if hasGroups, _ := claims["hasgroups"].(bool); hasGroups {
+		return nil, true
+	}
+	if _, ok := claims["groups"]; !ok {
+		return nil, false
+	}
+	return stringsClaim(claims, "groups"), false
+}
+
+// TokenAppRoles reads the roles claim: the app roles assigned to the user in the app registration
+func TokenAppRoles(claims map[string]interface{}) []string {
+	return stringsClaim(claims, "roles")
+}
+
+// ResolveGroups returns the IDs of the user's groups, from the token when it carries them and
+// otherwise (no groups claim, or overage) from Graph memberOf. ok is false when neither source is
+// available, i.e. the token has no groups and there is no Graph token.
+func ResolveGroups(ctx context.Context, claims map[string]interface{}, graphService *GraphService, accessToken string) (groupIDs []string, ok bool, err error) {
+	groups, overage := TokenGroups(claims)
+	if groups != nil {
+		log.Printf("✅ Read %d groups from the token", len(groups))
+		return groups, true, nil
+	}
+	if graphService == nil || accessToken == "" {
+		if overage {
+			log.Println("⚠️  Token has too many groups to list them and no Graph token is available")
+		}
+		return nil, false, nil
+	}
+
+	log.Println("========== FETCHING USER GROUPS FROM GRAPH API ==========")
+	fetched, err := graphService.GetUserGroups(ctx, accessToken)
+	if err != nil {
+		log.Printf("❌ Failed to fetch user groups: %v", err)
+		return nil, false, err
+	}
+	log.Printf("✅ Fetched %d groups from Graph API", len(fetched))
+
+	groupIDs = make([]string, len(fetched))
+	for i, group := range fetched {
+		groupIDs[i] = group.ID
+	}
+	return groupIDs, true, nil
+}
+
+// stringsClaim reads a claim holding a list of strings; other values are ignored
+func stringsClaim(claims map[string]interface{}, name string) []string {
+	values := []string{}
+	switch raw := claims[name].(type) {
+	case []string:
+		values = append(values, raw...)
+	case []interface{}:
+		for _, value := range raw {
+			if s, ok := value.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
@@ -4,18 +4,25 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"os"
 	"strconv"
-	"strings"
 	"time"
 
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
 	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type UserService struct {
 	DB *gorm.DB
+	// GraphSyncs batches last_graph_sync writes; when nil they are written right away
+	GraphSyncs *GraphSyncBatcher
+	// OnUserChanged, when set, is called after a user changed their profile or community, e.g.
+	// to drop cached copies of the user
+	OnUserChanged func(user *model.User)
 }
 
 func NewUserService(db *gorm.DB) *UserService {
@@ -25,7 +32,7 @@ func NewUserService(db *gorm.DB) *UserService {
 // GetUserByEntraID finds a user by their Microsoft Entra ID
 func (s *UserService) GetUserByEntraID(entraID string) (*model.User, error) {
 	if entraID == "" {
-		return nil, errors.New("entraID is required")
+		return nil, apperror.Validation("entra_id_required", "entraID is required")
 	}
 
 	var user model.User
@@ -37,20 +44,26 @@ func (s *UserService) GetUserByEntraID(entraID string) (*model.User, error) {
 	return &user, nil
 }
 
-// GetOrCreateUser finds or creates a user based on JWT claims
+// GetUserByID finds a user by their ID
+func (s *UserService) GetUserByID(userID uint) (*model.User, error) {
+	return s.loadUser(userID)
+}
+
+// GetOrCreateUser finds or creates a user based on JWT claims. Communities are re-evaluated from
+// the token's groups claim, or from Microsoft Graph when the token does not list the groups.
 func (s *UserService) GetOrCreateUser(claims map[string]interface{}, graphService *GraphService, accessToken string) (*model.User, error) {
 	email, _ := claims["email"].(string)
 	name, _ := claims["name"].(string)
-	entraID, _ := claims["oid"].(string) // Object ID from Microsoft Entra
+	entraID := TokenUserID(claims) // Object ID from Microsoft Entra, or sub from other providers
 
 	if email == "" || entraID == "" {
-		return nil, errors.New("missing required claims: email or oid")
+		return nil, apperror.Unauthorized("missing_claims", "Token is missing required claims: email or oid/sub")
 	}
 
 	var user model.User
 
 	// Try to find by EntraID first (more reliable)
-	err := s.DB.Where("entra_id = ?", entraID).First(&user).Error
+	err := s.DB.Preload("Community").Preload("Memberships.Community").Preload("Moderates.Community").Preload("PlatformRoles").Where("entra_id = ?", entraID).First(&user).Error
 
 	if err == gorm.ErrRecordNotFound {
 		// User doesn't exist, create new one
@@ -60,62 +73,64 @@ func (s *UserService) GetOrCreateUser(claims map[string]interface{}, graphServic
 			EntraID: entraID,
 		}
 
-		// Fetch user groups and determine community for new users
-		if graphService != nil && accessToken != "" {
-			log.Printf("🆕 New user '%s' - fetching community from Graph API", email)
-			community, err := s.determineCommunityFromGroups(graphService, accessToken)
-			if err == nil && community != "" {
-				user.Community = community
-				log.Printf("✅ Set community '%s' for new user '%s'", community, email)
-			} else {
-				log.Printf("⚠️  No community determined for new user '%s'", email)
-			}
-			// Set LastGraphSync timestamp
+		// Read user groups from the token or Graph and determine communities for new users
+		log.Printf("🆕 New user '%s' - determining communities from groups", email)
+		communities, synced, err := s.determineCommunitiesFromGroups(claims, graphService, accessToken)
+		if synced && len(communities) > 0 {
+			user.CommunityID = &communities[0].ID
+			user.Community = &communities[0]
+			log.Printf("✅ Set community '%s' for new user '%s' (%d communities)", communities[0].Name, email, len(communities))
+		} else {
+			log.Printf("⚠️  No community determined for new user '%s'", email)
+		}
+		if synced || err != nil {
+			// Set LastGraphSync timestamp; a failed Graph call is retried once the data is stale
 			now := time.Now()
 			user.LastGraphSync = &now
 		}
 
-		if err := s.DB.Create(&user).Error; err != nil {
+		if err := s.DB.Omit("Community", "Memberships", "Moderates", "PlatformRoles").Create(&user).Error; err != nil {
 			return nil, err
 		}
+		if err := grantBootstrapAdmin(s.DB, &user, model.AdminEmails(os.Getenv("ADMIN_EMAILS"))); err != nil {
+			return nil, err
+		}
+		if synced {
+			if err := s.syncGraphMemberships(&user, communities); err != nil {
+				return nil, err
+			}
+		}
 	} else if err != nil {
 		return nil, err
 	} else {
 		// User exists, update info if changed
-		shouldUpdate := false
 		if user.Name != name || user.Email != email {
 			user.Name = name
 			user.Email = email
-			shouldUpdate = true
+			if err := s.DB.Model(&user).Updates(map[string]interface{}{"name": name, "email": email}).Error; err != nil {
+				log.Printf("❌ Error updating name and email of '%s': %v", user.Email, err)
+			}
 		}
 
-		// Update community only if data is stale
-		if graphService != nil && accessToken != "" && s.shouldUpdateFromGraph(&user) {
-			log.Printf("🔄 User %s graph data is stale, fetching from Graph API", user.Email)
-			community, err := s.determineCommunityFromGroups(graphService, accessToken)
-			if err == nil {
-				if community != "" && user.Community != community {
-					log.Printf("🔄 Updating community for '%s': '%s' → '%s'", user.Email, user.Community, community)
-					user.Community = community
-					shouldUpdate = true
-				} else if community != "" {
-					log.Printf("✅ Community unchanged for '%s': '%s'", user.Email, user.Community)
+		// Update communities only if data is stale
+		if s.shouldUpdateFromGraph(&user) {
+			communities, synced, err := s.determineCommunitiesFromGroups(claims, graphService, accessToken)
+			if synced {
+				log.Printf("🔄 User %s group data was stale, re-evaluating communities", user.Email)
+				previous := user.CommunityName()
+				if err := s.syncGraphMemberships(&user, communities); err != nil {
+					log.Printf("❌ Error updating communities for '%s': %v", user.Email, err)
+				} else if user.CommunityName() != previous {
+					log.Printf("🔄 Updating community for '%s': '%s' → '%s'", user.Email, previous, user.CommunityName())
 				} else {
-					log.Printf("⚠️  No community determined for '%s' (currently: '%s')", user.Email, user.Community)
+					log.Printf("✅ Community unchanged for '%s': '%s' (member of %v)", user.Email, user.CommunityName(), user.CommunityNames())
 				}
-				// Update LastGraphSync timestamp
-				now := time.Now()
-				user.LastGraphSync = &now
-				shouldUpdate = true
-			} else {
-				log.Printf("❌ Error determining community for '%s': %v", user.Email, err)
+				s.recordGraphSync(&user)
+			} else if err != nil {
+				log.Printf("❌ Error determining communities for '%s': %v", user.Email, err)
 			}
-		} else if graphService != nil && accessToken != "" {
-			log.Printf("✅ User %s graph data is fresh (community: '%s'), skipping Graph API call", user.Email, user.Community)
-		}
-
-		if shouldUpdate {
-			s.DB.Save(&user)
+		} else {
+			log.Printf("✅ User %s group data is fresh (community: '%s'), skipping group lookup", user.Email, user.CommunityName())
 		}
 	}
 
@@ -128,14 +143,16 @@ func (s *UserService) UpdateUser(userID uint, updates map[string]interface{}) (*
 
 	// Find the user first
 	if err := s.DB.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("user_not_found", "User not found")
+		}
 		return nil, err
 	}
 
-	// Only allow updating specific fields
+	// Only allow updating specific fields. The primary community is changed through SetPrimaryCommunity.
 	allowedFields := map[string]bool{
 		"name":      true,
 		"photo_url": true,
-		"community": true,
 	}
 
 	// Filter updates to only allowed fields
@@ -151,11 +168,142 @@ func (s *UserService) UpdateUser(userID uint, updates map[string]interface{}) (*
 		return nil, err
 	}
 
+	return s.reloadChangedUser(userID)
+}
+
+// loadUser reads a user together with their communities
+func (s *UserService) loadUser(userID uint) (*model.User, error) {
+	var user model.User
+	if err := s.DB.Preload("Community").Preload("Memberships.Community").Preload("Moderates.Community").Preload("PlatformRoles").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("user_not_found", "User not found")
+		}
+		return nil, err
+	}
 	return &user, nil
 }
 
+// SetPrimaryCommunity makes communityID the user's primary community. The user becomes a member
+// if they are not one already; such memberships are kept across Graph syncs.
+func (s *UserService) SetPrimaryCommunity(ctx context.Context, userID uint, communityID uuid.UUID) (*model.User, error) {
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperror.NotFound("user_not_found", "User not found")
+			}
+			return err
+		}
+
+		err := tx.Where(model.CommunityMembership{UserID: userID, CommunityID: communityID}).
+			Attrs(model.CommunityMembership{Source: model.MembershipSourceManual}).
+			FirstOrCreate(&model.CommunityMembership{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&user).Update("community_id", communityID).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set primary community: %w", err)
+	}
+	return s.reloadChangedUser(userID)
+}
+
+// reloadChangedUser reads a user after a change and reports the change through OnUserChanged
+func (s *UserService) reloadChangedUser(userID uint) (*model.User, error) {
+	user, err := s.loadUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if s.OnUserChanged != nil {
+		s.OnUserChanged(user)
+	}
+	return user, nil
+}
+
+// syncGraphMemberships replaces the user's Graph-derived memberships with communities, which are
+// in priority order. Memberships the user chose are kept. If the primary community is no longer
+// one of the user's communities, the highest-priority one takes its place.
+func (s *UserService) syncGraphMemberships(user *model.User, communities []model.Community) error {
+	ids := make([]uuid.UUID, len(communities))
+	for i, community := range communities {
+		ids[i] = community.ID
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		stale := tx.Where("user_id = ? AND source = ?", user.ID, model.MembershipSourceGraph)
+		if len(ids) > 0 {
+			stale = stale.Where("community_id NOT IN ?", ids)
+		}
+		if err := stale.Delete(&model.CommunityMembership{}).Error; err != nil {
+			return fmt.Errorf("failed to remove memberships: %w", err)
+		}
+
+		for _, id := range ids {
+			err := tx.Where(model.CommunityMembership{UserID: user.ID, CommunityID: id}).
+				Attrs(model.CommunityMembership{Source: model.MembershipSourceGraph}).
+				FirstOrCreate(&model.CommunityMembership{}).Error
+			if err != nil {
+				return fmt.Errorf("failed to add membership: %w", err)
+			}
+		}
+
+		user.Memberships = nil
+		if err := tx.Preload("Community").Where("user_id = ?", user.ID).Order("created_at ASC").Find(&user.Memberships).Error; err != nil {
+			return fmt.Errorf("failed to load memberships: %w", err)
+		}
+
+		primaryID := user.CommunityID
+		if primaryID != nil && !hasMembership(user.Memberships, *primaryID) {
+			primaryID = nil
+		}
+		if primaryID == nil && len(ids) > 0 {
+			primaryID = &ids[0]
+		}
+		if primaryID == nil && len(user.Memberships) > 0 {
+			primaryID = &user.Memberships[0].CommunityID
+		}
+
+		user.CommunityID = primaryID
+		user.Community = nil
+		for i := range user.Memberships {
+			if primaryID != nil && user.Memberships[i].CommunityID == *primaryID {
+				user.Community = user.Memberships[i].Community
+			}
+		}
+		return tx.Model(user).Update("community_id", primaryID).Error
+	})
+}
+
+func hasMembership(memberships []model.CommunityMembership, communityID uuid.UUID) bool {
+	for _, membership := range memberships {
+		if membership.CommunityID == communityID {
+			return true
+		}
+	}
+	return false
+}
+
+// recordGraphSync stores that the user's groups were just re-evaluated
+func (s *UserService) recordGraphSync(user *model.User) {
+	now := time.Now()
+	user.LastGraphSync = &now
+	if s.GraphSyncs != nil {
+		s.GraphSyncs.Record(user.ID, now)
+		return
+	}
+	if err := s.DB.Model(user).UpdateColumn("last_graph_sync", now).Error; err != nil {
+		log.Printf("❌ Error updating last graph sync of '%s': %v", user.Email, err)
+	}
+}
+
 // shouldUpdateFromGraph checks if user data should be refreshed from Graph API
 func (s *UserService) shouldUpdateFromGraph(user *model.User) bool {
+	if s.GraphSyncs != nil {
+		if at, ok := s.GraphSyncs.Pending(user.ID); ok {
+			user.LastGraphSync = &at // Synced recently, not written yet
+		}
+	}
 	if user.LastGraphSync == nil {
 		return true // Never synced before
 	}
@@ -173,88 +321,35 @@ func (s *UserService) shouldUpdateFromGraph(user *model.User) bool {
 	return time.Since(*user.LastGraphSync) > staleThreshold
 }
 
-// determineCommunityFromGroups fetches user groups and maps them to a community name
-func (s *UserService) determineCommunityFromGroups(graphService *GraphService, accessToken string) (string, error) {
+// determineCommunitiesFromGroups maps the user's groups to communities, in priority order. The
+// groups are read from the token, or from Microsoft Graph when the token does not list them.
+// synced is false when neither source is available. Returns no communities if no group maps to
+// an active community.
+func (s *UserService) determineCommunitiesFromGroups(claims map[string]interface{}, graphService *GraphService, accessToken string) (communities []model.Community, synced bool, err error) {
 	ctx := context.Background()
-	log.Println("========== FETCHING USER GROUPS FROM GRAPH API ==========")
+	groupIDs, ok, err := ResolveGroups(ctx, claims, graphService, accessToken)
+	if err != nil || !ok {
+		return nil, false, err
+	}
 
-	groups, err := graphService.GetUserGroups(ctx, accessToken)
+	// Mappings are managed through /api/admin/community-mappings
+	match, err := NewCommunityMappingService(s.DB).MatchGroups(ctx, groupIDs)
 	if err != nil {
-		log.Printf("❌ Failed to fetch user groups: %v", err)
-		return "", err
+		return nil, false, err
 	}
-
-	log.Printf("✅ Fetched %d groups from Graph API", len(groups))
-	for i, group := range groups {
-		log.Printf("   Group #%d: %s (ID: %s)", i+1, group.DisplayName, group.ID)
+	for _, mapping := range match.Matches {
+		log.Printf("   Mapping #%d (priority %d): Group %s → Community %s", mapping.ID, mapping.Priority, mapping.GroupID, mapping.CommunityID)
 	}
 
-	// Get community group mappings from environment
-	// Format: GROUP_ID_1:CommunityName1,GROUP_ID_2:CommunityName2
-	communityMappings := os.Getenv("COMMUNITY_GROUP_MAPPINGS")
-	log.Printf("📋 COMMUNITY_GROUP_MAPPINGS env var: '%s'", communityMappings)
-
-	if communityMappings == "" {
-		log.Println("⚠️  No COMMUNITY_GROUP_MAPPINGS configured - cannot map groups to communities")
-		return "", nil
+	if len(match.Communities) == 0 {
+		log.Println("⚠️  User is not in any group mapped to an active community")
+		log.Println("==========================================================")
+		return nil, true, nil
 	}
 
-	// Parse the mappings
-	mappingPairs := strings.Split(communityMappings, ",")
-	groupToCommunity := make(map[string]string)
-	log.Printf("📋 Parsing %d mapping pairs", len(mappingPairs))
-	for i, pair := range mappingPairs {
-		parts := strings.Split(strings.TrimSpace(pair), ":")
-		if len(parts) == 2 {
-			groupID := strings.TrimSpace(parts[0])
-			communityName := strings.TrimSpace(parts[1])
-			groupToCommunity[groupID] = communityName
-			log.Printf("   Mapping #%d: Group %s → Community '%s'", i+1, groupID, communityName)
-		} else {
-			log.Printf("   ⚠️  Invalid mapping format: '%s'", pair)
-		}
+	for _, community := range match.Communities {
+		log.Printf("✅ MATCH FOUND! User belongs to community '%s'", community.Name)
 	}
-
-	// Find the first matching group
-	log.Println("🔍 Searching for matching groups...")
-	for _, group := range groups {
-		if communityName, exists := groupToCommunity[group.ID]; exists {
-			log.Printf("✅ MATCH FOUND! User assigned to community '%s' via group '%s' (%s)", communityName, group.DisplayName, group.ID)
-			log.Println("==========================================================")
-			return communityName, nil
-		}
-	}
-
-	log.Println("⚠️  User is not in any configured community groups")
 	log.Println("==========================================================")
-	return "", nil
-}
-
-// IsAdmin checks if a user email is in the admin list
-func (s *UserService) IsAdmin(email string) bool {
-	adminEmails := os.Getenv("ADMIN_EMAILS")
-	log.Printf("🔐 IsAdmin check for email: '%s'", email)
-	log.Printf("🔐 ADMIN_EMAILS env var value: '%s'", adminEmails)
-
-	if adminEmails == "" {
-		log.Printf("⚠️  ADMIN_EMAILS is empty or not set")
-		return false
-	}
-
-	adminList := strings.Split(adminEmails, ",")
-	log.Printf("🔐 Parsed admin list: %v", adminList)
-
-	// Normalize email to lowercase for case-insensitive comparison
-	emailLower := strings.ToLower(strings.TrimSpace(email))
-
-	for _, admin := range adminList {
-		trimmedAdmin := strings.ToLower(strings.TrimSpace(admin))
-		log.Printf("🔐 Comparing: '%s' == '%s'? %v", trimmedAdmin, emailLower, trimmedAdmin == emailLower)
-		if trimmedAdmin == emailLower {
-			log.Printf("✅ Admin match found!")
-			return true
-		}
-	}
-	log.Printf("❌ No admin match found")
-	return false
+	return match.Communities, true, nil
 }
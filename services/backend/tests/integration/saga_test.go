@@ -17,12 +17,19 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 // ============================================================================
 // INTEGRATION TEST HELPERS
 // ============================================================================
 
+// testInternalSecret is the INTERNAL_API_SECRET shared by the backend and the mock backend-editor
+const testInternalSecret = "internal-secret"
+
+// testUserTokens stand for the ID tokens of signed-in users passed to CreateLearningPath
+var testUserTokens = map[string]bool{"token": true, "test-token": true, "valid-token": true}
+
 // mockMongoServer simulates the backend-editor MongoDB service
 type mockMongoServer struct {
 	diagrams      map[string]map[string]interface{} // learningPathId -> diagram data
@@ -30,6 +37,7 @@ type mockMongoServer struct {
 	createCount   int32 // atomic counter for create requests
 	deleteCount   int32 // atomic counter for delete requests
 	updateCount   int32 // atomic counter for update requests
+	serviceCount  int32 // atomic counter for requests authenticated with testInternalSecret
 	failOnCreate  bool
 	failOnDelete  bool
 	failOnUpdate  bool
@@ -66,8 +74,21 @@ func (m *mockMongoServer) validateAuth(r *http.Request, w http.ResponseWriter) b
 		return false
 	}
 
-	// In tests, any non-empty Bearer token is considered valid
-	// (we're testing the backend controller logic, not token validation)
+	// Like backend-editor, accept INTERNAL_API_SECRET from the backend's background work
+	// and otherwise only users' ID tokens
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+	if token == testInternalSecret {
+		atomic.AddInt32(&m.serviceCount, 1)
+		return true
+	}
+	if !testUserTokens[token] {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":   "Unauthorized",
+			"message": "Invalid or expired token",
+		})
+		return false
+	}
 	return true
 }
 
@@ -77,6 +98,8 @@ func (m *mockMongoServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	path := r.URL.Path
 
 	switch {
+	case r.Method == http.MethodGet && path == "/api/diagrams":
+		m.handleList(w, r)
 	case r.Method == http.MethodPost && path == "/api/diagrams/by-lp":
 		m.handleCreate(w, r)
 	case r.Method == http.MethodPatch && len(path) > len("/api/diagrams/by-lp/"):
@@ -151,6 +174,23 @@ func (m *mockMongoServer) handleCreate(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(diagram)
 }
 
+func (m *mockMongoServer) handleList(w http.ResponseWriter, r *http.Request) {
+	// Validate authentication (Zero Trust)
+	if !m.validateAuth(r, w) {
+		return
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	diagrams := make([]map[string]interface{}, 0, len(m.diagrams))
+	for _, d := range m.diagrams {
+		diagrams = append(diagrams, d)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diagrams)
+}
+
 func (m *mockMongoServer) handleDelete(w http.ResponseWriter, r *http.Request, lpId string) {
 	atomic.AddInt32(&m.deleteCount, 1)
 
@@ -225,6 +265,22 @@ func (m *mockMongoServer) handleUpdate(w http.ResponseWriter, r *http.Request, l
 	json.NewEncoder(w).Encode(diagram)
 }
 
+// dispatchOutbox runs one outbox dispatch pass and returns the number of delivered messages
+func dispatchOutbox(t *testing.T, svc *service.LearningPathService) int {
+	t.Helper()
+	delivered, err := service.NewOutboxDispatcher(svc).DispatchPending(context.Background())
+	require.NoError(t, err)
+	return delivered
+}
+
+// retryOutboxNow makes messages waiting for a backoff due immediately
+func retryOutboxNow(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	require.NoError(t, db.Model(&model.OutboxMessage{}).
+		Where("status = ?", model.OutboxStatusPending).
+		Update("next_attempt_at", time.Now().Add(-time.Second)).Error)
+}
+
 func (m *mockMongoServer) getDiagramCount() int {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -250,6 +306,10 @@ func (m *mockMongoServer) getUpdateCount() int32 {
 	return atomic.LoadInt32(&m.updateCount)
 }
 
+func (m *mockMongoServer) getServiceCount() int32 {
+	return atomic.LoadInt32(&m.serviceCount)
+}
+
 func (m *mockMongoServer) getDiagramName(lpId string) string {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -292,7 +352,8 @@ func TestIntegration_CreateLP_FullSagaFlow(t *testing.T) {
 		"thumbnail.png",
 		[]string{"Go", "Testing", "SAGA"},
 		"test-token",
-		"test-community",
+		testutil.CommunityID(t, db, "test-community"),
+		0,
 	)
 
 	// Assert success
@@ -312,7 +373,7 @@ func TestIntegration_CreateLP_FullSagaFlow(t *testing.T) {
 	err = db.Preload("Skills.Skill").First(&dbLP, "id = ?", lp.ID).Error
 	require.NoError(t, err)
 	assert.Equal(t, "Integration Test LP", dbLP.Title)
-	assert.Equal(t, "test-community", dbLP.Community)
+	assert.Equal(t, testutil.CommunityID(t, db, "test-community"), *dbLP.CommunityID)
 }
 
 func TestIntegration_CreateLP_PostgreSQLFails_CompensationDeletesMongoDiagram(t *testing.T) {
@@ -345,7 +406,8 @@ func TestIntegration_CreateLP_PostgreSQLFails_CompensationDeletesMongoDiagram(t
 		"",
 		[]string{},
 		"token",
-		"community",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
 
 	// Assert failure
@@ -382,7 +444,8 @@ func TestIntegration_CreateLP_MongoDBFails_NoOrphanInPostgres(t *testing.T) {
 		"",
 		[]string{},
 		"token",
-		"community",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
 
 	// Assert failure at step 1
@@ -408,6 +471,7 @@ func TestIntegration_CreateLP_ConcurrentRequests_OnlyOneSucceeds(t *testing.T) {
 	defer ts.Close()
 
 	svc := service.NewLearningPathServiceWithClient(db, ts.Client(), ts.URL+"/api")
+	communityID := testutil.CommunityID(t, db, "community")
 
 	const numRequests = 5
 	var wg sync.WaitGroup
@@ -426,7 +490,8 @@ func TestIntegration_CreateLP_ConcurrentRequests_OnlyOneSucceeds(t *testing.T) {
 				"",
 				[]string{},
 				"token",
-				"community",
+				communityID,
+				0,
 			)
 			results <- err
 			if err == nil {
@@ -451,13 +516,14 @@ func TestIntegration_CreateLP_ConcurrentRequests_OnlyOneSucceeds(t *testing.T) {
 // DELETE LEARNING PATH INTEGRATION TESTS
 // ============================================================================
 
-func TestIntegration_DeleteLP_FullSagaFlow(t *testing.T) {
+func TestIntegration_DeleteLP_FullOutboxFlow(t *testing.T) {
 	db := testutil.SetupTestDB(t)
 	mongoServer := newMockMongoServer()
 	ts := httptest.NewServer(mongoServer)
 	defer ts.Close()
 
 	svc := service.NewLearningPathServiceWithClient(db, ts.Client(), ts.URL+"/api")
+	svc.ServiceToken = testInternalSecret
 
 	// First create an LP
 	lp, err := svc.CreateLearningPath(
@@ -468,7 +534,8 @@ func TestIntegration_DeleteLP_FullSagaFlow(t *testing.T) {
 		"",
 		[]string{"Skill1"},
 		"token",
-		"community",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
 	require.NoError(t, err)
 	lpID := lp.ID.String()
@@ -477,10 +544,12 @@ func TestIntegration_DeleteLP_FullSagaFlow(t *testing.T) {
 	assert.True(t, mongoServer.hasDiagram(lpID))
 
 	// Execute delete
-	err = svc.DeleteLearningPath(context.Background(), lpID, "token")
+	err = svc.DeleteLearningPath(context.Background(), lpID)
 
-	// Assert success
+	// Assert success; the diagram is only removed once the outbox is dispatched
 	require.NoError(t, err)
+	assert.True(t, mongoServer.hasDiagram(lpID))
+	assert.Equal(t, 1, dispatchOutbox(t, svc))
 
 	// Verify MongoDB diagram was deleted
 	assert.False(t, mongoServer.hasDiagram(lpID), "Diagram should be deleted from MongoDB")
@@ -492,13 +561,14 @@ func TestIntegration_DeleteLP_FullSagaFlow(t *testing.T) {
 	assert.Equal(t, int64(0), count)
 }
 
-func TestIntegration_DeleteLP_MongoDBFails_LPRestored(t *testing.T) {
+func TestIntegration_DeleteLP_MongoDBFails_RetriedUntilDelivered(t *testing.T) {
 	db := testutil.SetupTestDB(t)
 	mongoServer := newMockMongoServer()
 	ts := httptest.NewServer(mongoServer)
 	defer ts.Close()
 
 	svc := service.NewLearningPathServiceWithClient(db, ts.Client(), ts.URL+"/api")
+	svc.ServiceToken = testInternalSecret
 
 	// First create an LP
 	lp, err := svc.CreateLearningPath(
@@ -509,7 +579,8 @@ func TestIntegration_DeleteLP_MongoDBFails_LPRestored(t *testing.T) {
 		"",
 		[]string{},
 		"token",
-		"community",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
 	require.NoError(t, err)
 	lpID := lp.ID.String()
@@ -517,23 +588,26 @@ func TestIntegration_DeleteLP_MongoDBFails_LPRestored(t *testing.T) {
 	// Now make MongoDB fail on delete
 	mongoServer.failOnDelete = true
 
-	// Execute delete
-	err = svc.DeleteLearningPath(context.Background(), lpID, "token")
-
-	// Assert failure
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "saga step 2 failed")
-	assert.Contains(t, err.Error(), "LP restored")
-
-	// Verify LP was restored (not soft-deleted)
-	var restoredLP model.LearningPath
-	err = db.First(&restoredLP, "id = ?", lpID).Error
+	// Execute delete - succeeds even though backend-editor is failing
+	err = svc.DeleteLearningPath(context.Background(), lpID)
 	require.NoError(t, err)
-	assert.Equal(t, "LP To Fail Delete", restoredLP.Title)
-	assert.True(t, restoredLP.DeletedAt.Time.IsZero(), "DeletedAt should be nil (LP restored)")
+	assert.Equal(t, 0, dispatchOutbox(t, svc))
 
-	// Verify MongoDB diagram still exists (delete failed, but our mock doesn't persist on failure)
+	// Verify LP stays soft-deleted and the diagram still exists
+	var softDeleted model.LearningPath
+	require.NoError(t, db.Unscoped().First(&softDeleted, "id = ?", lpID).Error)
+	assert.True(t, softDeleted.DeletedAt.Valid, "LP should stay soft-deleted while delivery is retried")
 	assert.True(t, mongoServer.hasDiagram(lpID))
+
+	// Once backend-editor recovers, the retry completes the delete
+	mongoServer.failOnDelete = false
+	retryOutboxNow(t, db)
+	assert.Equal(t, 1, dispatchOutbox(t, svc))
+
+	assert.False(t, mongoServer.hasDiagram(lpID))
+	var count int64
+	db.Unscoped().Model(&model.LearningPath{}).Where("id = ?", lpID).Count(&count)
+	assert.Equal(t, int64(0), count)
 }
 
 func TestIntegration_DeleteLP_DiagramAlreadyDeleted_TreatedAsSuccess(t *testing.T) {
@@ -553,13 +627,15 @@ func TestIntegration_DeleteLP_DiagramAlreadyDeleted_TreatedAsSuccess(t *testing.
 	require.NoError(t, db.Create(&lp).Error)
 
 	svc := service.NewLearningPathServiceWithClient(db, ts.Client(), ts.URL+"/api")
+	svc.ServiceToken = testInternalSecret
 
 	// Execute delete - MongoDB will return 404, but should be treated as success
-	err := svc.DeleteLearningPath(context.Background(), lpID.String(), "token")
-
-	// Assert success (404 treated as idempotent)
+	err := svc.DeleteLearningPath(context.Background(), lpID.String())
 	require.NoError(t, err)
 
+	// Assert delivery succeeds (404 treated as idempotent)
+	assert.Equal(t, 1, dispatchOutbox(t, svc))
+
 	// Verify LP is deleted from PostgreSQL
 	var count int64
 	db.Unscoped().Model(&model.LearningPath{}).Where("id = ?", lpID).Count(&count)
@@ -587,7 +663,8 @@ func TestIntegration_CreateLP_IdempotentRetry(t *testing.T) {
 		"",
 		[]string{},
 		"token",
-		"community",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
 	require.NoError(t, err)
 
@@ -630,7 +707,8 @@ func TestIntegration_CreateLP_SkillsCreatedAndAssociated(t *testing.T) {
 		"",
 		[]string{"Go", "Docker", "Kubernetes"},
 		"token",
-		"community",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
 	require.NoError(t, err)
 
@@ -672,7 +750,8 @@ func TestIntegration_CreateLP_ExistingSkillsReused(t *testing.T) {
 		"",
 		[]string{"Go", "Docker"},
 		"token",
-		"community",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
 	require.NoError(t, err)
 
@@ -685,7 +764,8 @@ func TestIntegration_CreateLP_ExistingSkillsReused(t *testing.T) {
 		"",
 		[]string{"Go", "Kubernetes"}, // Go is reused, Kubernetes is new
 		"token",
-		"community",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
 	require.NoError(t, err)
 
@@ -715,7 +795,8 @@ func TestIntegration_DeleteLP_SkillsRemainForOtherLPs(t *testing.T) {
 		"",
 		[]string{"SharedSkill", "UniqueSkill1"},
 		"token",
-		"community",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
 	require.NoError(t, err)
 
@@ -727,13 +808,16 @@ func TestIntegration_DeleteLP_SkillsRemainForOtherLPs(t *testing.T) {
 		"",
 		[]string{"SharedSkill", "UniqueSkill2"},
 		"token",
-		"community",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
 	require.NoError(t, err)
 
 	// Delete first LP
-	err = svc.DeleteLearningPath(context.Background(), lp1.ID.String(), "token")
+	svc.ServiceToken = testInternalSecret
+	err = svc.DeleteLearningPath(context.Background(), lp1.ID.String())
 	require.NoError(t, err)
+	assert.Equal(t, 1, dispatchOutbox(t, svc))
 
 	// Verify skills still exist (they're shared and remain for other LPs)
 	var skillCount int64
@@ -788,7 +872,8 @@ func TestIntegration_CreateLP_CompensationFails_BothErrorsReported(t *testing.T)
 		"",
 		[]string{},
 		"token",
-		"community",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
 
 	// Assert - should get saga step 2 failure (compensation failure is logged)
@@ -813,7 +898,7 @@ func TestIntegration_DeleteLP_InvalidUUIDFormat(t *testing.T) {
 	svc := service.NewLearningPathServiceWithClient(db, ts.Client(), ts.URL+"/api")
 
 	// Execute with invalid UUID format
-	err := svc.DeleteLearningPath(context.Background(), "not-a-valid-uuid", "token")
+	err := svc.DeleteLearningPath(context.Background(), "not-a-valid-uuid")
 
 	// Assert - should fail before making any HTTP calls
 	require.Error(t, err)
@@ -841,7 +926,8 @@ func TestIntegration_CreateLP_SpecialCharactersInTitle(t *testing.T) {
 		"",
 		[]string{},
 		"token",
-		"community",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
 
 	require.NoError(t, err)
@@ -875,7 +961,8 @@ func TestIntegration_CreateLP_ContextCancellation(t *testing.T) {
 		"",
 		[]string{},
 		"token",
-		"community",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
 
 	// Assert context cancellation error
@@ -891,7 +978,7 @@ func TestIntegration_CreateLP_ContextCancellation(t *testing.T) {
 // UPDATE LEARNING PATH INTEGRATION TESTS
 // ============================================================================
 
-func TestIntegration_UpdateLP_FullSagaFlow(t *testing.T) {
+func TestIntegration_UpdateLP_FullOutboxFlow(t *testing.T) {
 	// Setup real database and mock HTTP server
 	db := testutil.SetupTestDB(t)
 	mongoServer := newMockMongoServer()
@@ -899,6 +986,7 @@ func TestIntegration_UpdateLP_FullSagaFlow(t *testing.T) {
 	defer ts.Close()
 
 	svc := service.NewLearningPathServiceWithClient(db, ts.Client(), ts.URL+"/api")
+	svc.ServiceToken = testInternalSecret
 
 	// Pre-create a learning path with diagram
 	lp, err := svc.CreateLearningPath(
@@ -909,7 +997,8 @@ func TestIntegration_UpdateLP_FullSagaFlow(t *testing.T) {
 		"",
 		[]string{},
 		"token",
-		"test-community",
+		testutil.CommunityID(t, db, "test-community"),
+		0,
 	)
 	require.NoError(t, err)
 	require.NotNil(t, lp)
@@ -921,13 +1010,13 @@ func TestIntegration_UpdateLP_FullSagaFlow(t *testing.T) {
 	assert.Equal(t, "Original Description", lp.Description)
 	assert.Equal(t, "Original Title", mongoServer.getDiagramName(lpID))
 
-	// Execute UPDATE SAGA
+	// Execute update
 	updatedLP, err := svc.UpdateLearningPath(
 		context.Background(),
 		lpID,
 		"New Title",
 		"New Description",
-		"token",
+		nil,
 	)
 
 	// Assert success
@@ -936,6 +1025,10 @@ func TestIntegration_UpdateLP_FullSagaFlow(t *testing.T) {
 	assert.Equal(t, "New Title", updatedLP.Title)
 	assert.Equal(t, "New Description", updatedLP.Description)
 
+	// The rename reaches MongoDB through the outbox
+	assert.Equal(t, "Original Title", mongoServer.getDiagramName(lpID))
+	assert.Equal(t, 1, dispatchOutbox(t, svc))
+
 	// Verify MongoDB diagram was updated
 	diagram := mongoServer.getDiagram(lpID)
 	assert.NotNil(t, diagram, "Diagram should exist in MongoDB after update")
@@ -949,7 +1042,7 @@ func TestIntegration_UpdateLP_FullSagaFlow(t *testing.T) {
 	assert.Equal(t, "New Description", dbLP.Description)
 }
 
-func TestIntegration_UpdateLP_MongoDBFails_RollbackPostgreSQL(t *testing.T) {
+func TestIntegration_UpdateLP_MongoDBFails_LPKeptAndRenameRetried(t *testing.T) {
 	// Setup real database and mock HTTP server
 	db := testutil.SetupTestDB(t)
 	mongoServer := newMockMongoServer()
@@ -957,6 +1050,7 @@ func TestIntegration_UpdateLP_MongoDBFails_RollbackPostgreSQL(t *testing.T) {
 	defer ts.Close()
 
 	svc := service.NewLearningPathServiceWithClient(db, ts.Client(), ts.URL+"/api")
+	svc.ServiceToken = testInternalSecret
 
 	// Pre-create a learning path
 	lp, err := svc.CreateLearningPath(
@@ -967,7 +1061,8 @@ func TestIntegration_UpdateLP_MongoDBFails_RollbackPostgreSQL(t *testing.T) {
 		"",
 		[]string{},
 		"token",
-		"test-community",
+		testutil.CommunityID(t, db, "test-community"),
+		0,
 	)
 	require.NoError(t, err)
 
@@ -976,32 +1071,31 @@ func TestIntegration_UpdateLP_MongoDBFails_RollbackPostgreSQL(t *testing.T) {
 	// Configure mock to fail on update
 	mongoServer.failOnUpdate = true
 
-	// Execute UPDATE SAGA (MongoDB will fail)
+	// Execute update - succeeds even though backend-editor is failing
 	updatedLP, err := svc.UpdateLearningPath(
 		context.Background(),
 		lpID,
 		"New Title",
 		"New Description",
-		"token",
+		nil,
 	)
+	require.NoError(t, err)
+	assert.Equal(t, "New Title", updatedLP.Title)
+	assert.Equal(t, 0, dispatchOutbox(t, svc))
 
-	// Assert failure
-	require.Error(t, err)
-	assert.Nil(t, updatedLP)
-	assert.Contains(t, err.Error(), "saga step 2 failed")
-	assert.Contains(t, err.Error(), "LP restored")
-
-	// Verify compensation: PostgreSQL should be rolled back
+	// Verify PostgreSQL keeps the new values while MongoDB lags behind
 	var dbLP model.LearningPath
 	err = db.First(&dbLP, "id = ?", lp.ID).Error
 	require.NoError(t, err)
-	assert.Equal(t, "Original Title", dbLP.Title, "Title should be rolled back")
-	assert.Equal(t, "Original Description", dbLP.Description, "Description should be rolled back")
+	assert.Equal(t, "New Title", dbLP.Title, "Title should not be rolled back")
+	assert.Equal(t, "New Description", dbLP.Description, "Description should not be rolled back")
+	assert.Equal(t, "Original Title", mongoServer.getDiagramName(lpID))
 
-	// Verify MongoDB diagram still has original name (rollback successful)
-	diagram := mongoServer.getDiagram(lpID)
-	assert.NotNil(t, diagram, "Diagram should still exist after rollback")
-	assert.Equal(t, "Original Title", diagram["name"], "Diagram name should be rolled back to original")
+	// Once backend-editor recovers, the retry brings the diagram in sync
+	mongoServer.failOnUpdate = false
+	retryOutboxNow(t, db)
+	assert.Equal(t, 1, dispatchOutbox(t, svc))
+	assert.Equal(t, "New Title", mongoServer.getDiagramName(lpID))
 }
 
 func TestIntegration_UpdateLP_NotFound(t *testing.T) {
@@ -1020,7 +1114,7 @@ func TestIntegration_UpdateLP_NotFound(t *testing.T) {
 		randomID,
 		"New Title",
 		"New Description",
-		"token",
+		nil,
 	)
 
 	// Assert failure
@@ -1047,7 +1141,7 @@ func TestIntegration_UpdateLP_InvalidUUID(t *testing.T) {
 		"not-a-valid-uuid",
 		"New Title",
 		"New Description",
-		"token",
+		nil,
 	)
 
 	// Assert failure
@@ -1068,13 +1162,15 @@ func TestIntegration_UpdateLP_ConcurrentUpdates_DataConsistency(t *testing.T) {
 	defer ts.Close()
 
 	svc := service.NewLearningPathServiceWithClient(db, ts.Client(), ts.URL+"/api")
+	svc.ServiceToken = testInternalSecret
 
 	// Pre-create a learning path
 	lp, err := svc.CreateLearningPath(
 		context.Background(),
 		"Original Title",
 		"Original Description",
-		true, "", []string{}, "token", "test-community",
+		true, "", []string{}, "token", testutil.CommunityID(t, db, "test-community"),
+		0,
 	)
 	require.NoError(t, err)
 	lpID := lp.ID.String()
@@ -1091,7 +1187,7 @@ func TestIntegration_UpdateLP_ConcurrentUpdates_DataConsistency(t *testing.T) {
 			lpID,
 			"Title A",
 			"Description A",
-			"token",
+			nil,
 		)
 		results <- err
 	}()
@@ -1102,7 +1198,7 @@ func TestIntegration_UpdateLP_ConcurrentUpdates_DataConsistency(t *testing.T) {
 			lpID,
 			"Title B",
 			"Description B",
-			"token",
+			nil,
 		)
 		results <- err
 	}()
@@ -1121,6 +1217,9 @@ func TestIntegration_UpdateLP_ConcurrentUpdates_DataConsistency(t *testing.T) {
 	// At least one update should succeed
 	assert.GreaterOrEqual(t, successCount, 1, "At least one concurrent update should succeed")
 
+	// Deliver the queued renames; they are applied in commit order
+	dispatchOutbox(t, svc)
+
 	// Verify final state is consistent between PostgreSQL and MongoDB
 	// Use a fresh query to avoid any potential connection issues from concurrent goroutines
 	var dbLP model.LearningPath
@@ -1156,7 +1255,8 @@ func TestIntegration_CreateLP_MissingAuthToken_Returns401(t *testing.T) {
 		"",
 		[]string{},
 		"", // Empty token
-		"community",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
 
 	// Assert failure
@@ -1173,15 +1273,15 @@ func TestIntegration_CreateLP_MissingAuthToken_Returns401(t *testing.T) {
 	assert.Equal(t, 0, mongoServer.getDiagramCount())
 }
 
-func TestIntegration_UpdateLP_MissingAuthToken_Returns401(t *testing.T) {
+func TestIntegration_UpdateLP_RenameAuthenticatesWithInternalSecret(t *testing.T) {
 	db := testutil.SetupTestDB(t)
 	mongoServer := newMockMongoServer()
 	ts := httptest.NewServer(mongoServer)
 	defer ts.Close()
 
 	svc := service.NewLearningPathServiceWithClient(db, ts.Client(), ts.URL+"/api")
+	svc.ServiceToken = testInternalSecret
 
-	// Create LP with valid token first
 	lp, err := svc.CreateLearningPath(
 		context.Background(),
 		"Original Title",
@@ -1190,38 +1290,35 @@ func TestIntegration_UpdateLP_MissingAuthToken_Returns401(t *testing.T) {
 		"",
 		[]string{},
 		"valid-token",
-		"community",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
 	require.NoError(t, err)
 
-	// Try to update with empty token
-	updatedLP, err := svc.UpdateLearningPath(
+	_, err = svc.UpdateLearningPath(
 		context.Background(),
 		lp.ID.String(),
 		"New Title",
 		"New Description",
-		"", // Empty token
+		nil,
 	)
+	require.NoError(t, err)
 
-	// Assert failure
-	require.Error(t, err)
-	assert.Nil(t, updatedLP)
-
-	// Verify LP unchanged
-	var dbLP model.LearningPath
-	db.First(&dbLP, "id = ?", lp.ID)
-	assert.Equal(t, "Original Title", dbLP.Title)
+	// No user token is available for delivery; backend-editor accepts the internal secret
+	assert.Equal(t, 1, dispatchOutbox(t, svc))
+	assert.Equal(t, "New Title", mongoServer.getDiagramName(lp.ID.String()))
+	assert.Equal(t, int32(1), mongoServer.getServiceCount())
 }
 
-func TestIntegration_DeleteLP_MissingAuthToken_Returns401(t *testing.T) {
+func TestIntegration_DeleteLP_DeleteAuthenticatesWithInternalSecret(t *testing.T) {
 	db := testutil.SetupTestDB(t)
 	mongoServer := newMockMongoServer()
 	ts := httptest.NewServer(mongoServer)
 	defer ts.Close()
 
 	svc := service.NewLearningPathServiceWithClient(db, ts.Client(), ts.URL+"/api")
+	svc.ServiceToken = testInternalSecret
 
-	// Create LP with valid token first
 	lp, err := svc.CreateLearningPath(
 		context.Background(),
 		"Test LP",
@@ -1230,22 +1327,147 @@ func TestIntegration_DeleteLP_MissingAuthToken_Returns401(t *testing.T) {
 		"",
 		[]string{},
 		"valid-token",
-		"community",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
 	require.NoError(t, err)
 
-	// Try to delete with empty token
-	err = svc.DeleteLearningPath(
+	err = svc.DeleteLearningPath(context.Background(), lp.ID.String())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, dispatchOutbox(t, svc))
+	assert.False(t, mongoServer.hasDiagram(lp.ID.String()))
+	assert.Equal(t, int32(1), mongoServer.getServiceCount())
+
+	var count int64
+	db.Unscoped().Model(&model.LearningPath{}).Where("id = ?", lp.ID).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestIntegration_Outbox_WrongInternalSecret_RetriedOnceFixed(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	mongoServer := newMockMongoServer()
+	ts := httptest.NewServer(mongoServer)
+	defer ts.Close()
+
+	// A secret backend-editor does not share is rejected like any other unknown token
+	svc := service.NewLearningPathServiceWithClient(db, ts.Client(), ts.URL+"/api")
+	svc.ServiceToken = "not-the-shared-secret"
+
+	lp, err := svc.CreateLearningPath(
 		context.Background(),
-		lp.ID.String(),
-		"", // Empty token
+		"Test LP",
+		"Description",
+		true,
+		"",
+		[]string{},
+		"valid-token",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteLearningPath(context.Background(), lp.ID.String()))
 
-	// Assert failure
-	require.Error(t, err)
+	assert.Equal(t, 0, dispatchOutbox(t, svc))
+	var msg model.OutboxMessage
+	require.NoError(t, db.First(&msg, "aggregate_id = ?", lp.ID).Error)
+	assert.Equal(t, model.OutboxStatusPending, msg.Status)
+	assert.Contains(t, msg.LastError, "401")
+	assert.True(t, mongoServer.hasDiagram(lp.ID.String()))
+
+	// Once the secret matches, the retry deletes the diagram and the LP
+	svc.ServiceToken = testInternalSecret
+	retryOutboxNow(t, db)
+	assert.Equal(t, 1, dispatchOutbox(t, svc))
+	assert.False(t, mongoServer.hasDiagram(lp.ID.String()))
 
-	// Verify LP still exists
 	var count int64
-	db.Model(&model.LearningPath{}).Where("id = ?", lp.ID).Count(&count)
-	assert.Equal(t, int64(1), count)
+	db.Unscoped().Model(&model.LearningPath{}).Where("id = ?", lp.ID).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+// ============================================================================
+// SAGA RECOVERY INTEGRATION TESTS
+// ============================================================================
+
+func TestIntegration_SagaRecovery_DeletesOrphanedDiagramWithInternalSecret(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	mongoServer := newMockMongoServer()
+	ts := httptest.NewServer(mongoServer)
+	defer ts.Close()
+
+	svc := service.NewLearningPathServiceWithClient(db, ts.Client(), ts.URL+"/api")
+	recoverer := &service.SagaRecoverer{LPService: svc, StaleAfter: time.Minute, MaxAttempts: 3}
+
+	// A create that crashed after the diagram was created but before the LP was committed
+	lpID := uuid.New()
+	mongoServer.diagrams[lpID.String()] = map[string]interface{}{"_id": "mongo-orphan", "learningPathId": lpID.String(), "name": "Crashed LP"}
+	saga := &model.SagaLog{
+		ID:        uuid.New(),
+		Type:      model.SagaTypeCreateLearningPath,
+		LPID:      lpID,
+		Step:      "diagram_created",
+		Payload:   `{"title":"Crashed LP","diagramId":"mongo-orphan"}`,
+		Status:    model.SagaStatusRunning,
+		CreatedAt: time.Now().Add(-time.Hour),
+		UpdatedAt: time.Now().Add(-time.Hour),
+	}
+	require.NoError(t, db.Create(saga).Error)
+
+	// Without the shared secret backend-editor rejects the recovery and the saga stays open
+	recovered, err := recoverer.RecoverPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, recovered)
+	assert.True(t, mongoServer.hasDiagram(lpID.String()))
+
+	svc.ServiceToken = testInternalSecret
+	require.NoError(t, db.Model(saga).Update("updated_at", time.Now().Add(-time.Hour)).Error)
+	recovered, err = recoverer.RecoverPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, recovered)
+
+	assert.False(t, mongoServer.hasDiagram(lpID.String()), "Orphaned diagram should be deleted")
+	assert.Equal(t, int32(1), mongoServer.getServiceCount())
+	var stored model.SagaLog
+	require.NoError(t, db.First(&stored, "id = ?", saga.ID).Error)
+	assert.Equal(t, model.SagaStatusCompensated, stored.Status)
+}
+
+// ============================================================================
+// RECONCILIATION INTEGRATION TESTS
+// ============================================================================
+
+func TestIntegration_Reconcile_RepairsDriftWithInternalSecret(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	mongoServer := newMockMongoServer()
+	ts := httptest.NewServer(mongoServer)
+	defer ts.Close()
+
+	svc := service.NewLearningPathServiceWithClient(db, ts.Client(), ts.URL+"/api")
+	reconciler := service.NewReconciler(svc)
+
+	// A diagram whose LP is gone, and an LP whose diagram is gone
+	orphanLPID := uuid.New().String()
+	mongoServer.diagrams[orphanLPID] = map[string]interface{}{"_id": "mongo-orphan", "learningPathId": orphanLPID, "name": "Orphan"}
+	lp := model.LearningPath{ID: uuid.New(), Title: "Lost Diagram", DiagramID: "mongo-lost", IsPublic: true}
+	require.NoError(t, db.Create(&lp).Error)
+
+	// Without the shared secret backend-editor refuses to list its diagrams
+	_, err := reconciler.Reconcile(context.Background(), false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+
+	svc.ServiceToken = testInternalSecret
+	report, err := reconciler.Reconcile(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Repaired)
+	assert.Equal(t, 0, report.Failed)
+
+	assert.False(t, mongoServer.hasDiagram(orphanLPID), "Orphaned diagram should be deleted")
+	recreated := mongoServer.getDiagram(lp.ID.String())
+	require.NotNil(t, recreated, "Missing diagram should be recreated")
+	var stored model.LearningPath
+	require.NoError(t, db.First(&stored, "id = ?", lp.ID).Error)
+	assert.Equal(t, recreated["_id"], stored.DiagramID)
+	assert.Equal(t, int32(3), mongoServer.getServiceCount(), "list, delete and create all authenticate as the backend")
 }
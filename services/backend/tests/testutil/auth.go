@@ -1,6 +1,10 @@
 package testutil
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"time"
@@ -152,3 +156,14 @@ func DefaultTestAuthEnv() TestAuthEnv {
 		AdminEmails:           "admin@example.com",
 	}
 }
+
+// SignHS256Token signs claims as a compact JWT with the shared secret, for the static verifier
+func SignHS256Token(secret string, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -3,7 +3,9 @@ package testutil
 import (
 	"testing"
 
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/initializer"
 	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -18,8 +20,10 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 	require.NoError(t, err)
 
 	// Migrate the schema
-	err = db.AutoMigrate(&model.LearningPath{}, &model.Skill{}, &model.LPSkill{})
+	err = db.AutoMigrate(&model.Community{}, &model.User{}, &model.CommunityMembership{}, &model.CommunityModerator{}, &model.Role{}, &model.LearningPath{}, &model.Skill{}, &model.LPSkill{}, &model.UserLP{}, &model.SagaLog{}, &model.OutboxMessage{}, &model.IdempotencyRecord{}, &model.CommunityGroupMapping{}, &model.CommunityMappingAudit{}, &model.UserPlatformRole{}, &model.PlatformRoleClaimMapping{})
 	require.NoError(t, err)
+	require.NoError(t, initializer.SeedRoles(db))
+	require.NoError(t, initializer.SeedCommunities(db))
 
 	return db
 }
@@ -31,3 +35,14 @@ func SetupTestDBWithUniqueIndex(t *testing.T) *gorm.DB {
 	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_unique_diagram_id ON learning_paths(diagram_id)")
 	return db
 }
+
+// CommunityID returns the ID of the community with the given name, creating it if needed
+func CommunityID(t *testing.T, db *gorm.DB, name string) uuid.UUID {
+	t.Helper()
+	var community model.Community
+	err := db.Where(model.Community{Name: name}).
+		Attrs(model.Community{ID: uuid.New(), Slug: model.CommunitySlug(name)}).
+		FirstOrCreate(&community).Error
+	require.NoError(t, err)
+	return community.ID
+}
@@ -0,0 +1,221 @@
+package testutil
+
+import (
+	"context"
+	"sync"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"github.com/google/uuid"
+)
+
+// Editor operation names used by FakeEditorClient for failures and call records
+const (
+	EditorOpCreate = "CreateDiagram"
+	EditorOpRename = "RenameDiagram"
+	EditorOpDelete = "DeleteDiagram"
+	EditorOpGet    = "GetDiagram"
+	EditorOpList   = "ListDiagrams"
+)
+
+// EditorCall records a single call made to FakeEditorClient
+type EditorCall struct {
+	Op        string
+	LPID      string
+	Name      string
+	AuthToken string
+}
+
+// FakeEditorClient is an in-memory service.EditorClient that behaves like backend-editor:
+// create is idempotent per LP, names are unique, and missing diagrams yield NotFound errors.
+type FakeEditorClient struct {
+	// NextDiagramIDs are handed out by CreateDiagram, in order, before random IDs are used
+	NextDiagramIDs []string
+
+	mu       sync.Mutex
+	diagrams map[string]service.Diagram // learningPathId -> diagram
+	failures map[string]error
+	// remaining counts down failures set by FailTimes; ops without an entry fail until Recover
+	remaining map[string]int
+	calls     []EditorCall
+}
+
+func NewFakeEditorClient() *FakeEditorClient {
+	return &FakeEditorClient{
+		diagrams:  make(map[string]service.Diagram),
+		failures:  make(map[string]error),
+		remaining: make(map[string]int),
+	}
+}
+
+// Fail makes every call to op return err until Recover is called
+func (f *FakeEditorClient) Fail(op string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[op] = err
+	delete(f.remaining, op)
+}
+
+// FailTimes makes the next n calls to op return err, after which op succeeds again
+func (f *FakeEditorClient) FailTimes(op string, n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[op] = err
+	f.remaining[op] = n
+}
+
+// FailUnavailable makes op fail as if backend-editor were down
+func (f *FakeEditorClient) FailUnavailable(op string) {
+	f.Fail(op, service.NewEditorError(service.EditorErrUnavailable, "connection refused"))
+}
+
+// Recover clears a failure set by Fail
+func (f *FakeEditorClient) Recover(op string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.failures, op)
+	delete(f.remaining, op)
+}
+
+// AddDiagram seeds a diagram without recording a call
+func (f *FakeEditorClient) AddDiagram(d service.Diagram) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.diagrams[d.LearningPathID] = d
+}
+
+// Diagram returns the diagram linked to lpID, if any
+func (f *FakeEditorClient) Diagram(lpID string) (service.Diagram, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	d, ok := f.diagrams[lpID]
+	return d, ok
+}
+
+// DiagramCount returns the number of stored diagrams
+func (f *FakeEditorClient) DiagramCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.diagrams)
+}
+
+// Calls returns the recorded calls, optionally filtered by operation
+func (f *FakeEditorClient) Calls(op string) []EditorCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var result []EditorCall
+	for _, call := range f.calls {
+		if op == "" || call.Op == op {
+			result = append(result, call)
+		}
+	}
+	return result
+}
+
+// record stores the call and returns the failure configured for op, if any. Callers hold f.mu.
+func (f *FakeEditorClient) record(ctx context.Context, op, lpID, name, authToken string) error {
+	f.calls = append(f.calls, EditorCall{
+		Op:        op,
+		LPID:      lpID,
+		Name:      name,
+		AuthToken: authToken,
+	})
+	if err := ctx.Err(); err != nil {
+		return &service.EditorError{Kind: service.EditorErrUnavailable, Err: err}
+	}
+	if authToken == "" {
+		return &service.EditorError{Kind: service.EditorErrUnauthorized, StatusCode: 401, Message: "No access token provided"}
+	}
+	err := f.failures[op]
+	if n, ok := f.remaining[op]; ok && err != nil {
+		if n <= 1 {
+			delete(f.failures, op)
+			delete(f.remaining, op)
+		} else {
+			f.remaining[op] = n - 1
+		}
+	}
+	return err
+}
+
+func (f *FakeEditorClient) CreateDiagram(ctx context.Context, lpID, name, authToken string) (*service.Diagram, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record(ctx, EditorOpCreate, lpID, name, authToken); err != nil {
+		return nil, err
+	}
+
+	if existing, ok := f.diagrams[lpID]; ok {
+		return &existing, nil
+	}
+	for _, d := range f.diagrams {
+		if d.Name == name {
+			return nil, &service.EditorError{Kind: service.EditorErrConflict, StatusCode: 409, Message: "A learning path with this name already exists"}
+		}
+	}
+
+	id := uuid.New().String()[:24]
+	if len(f.NextDiagramIDs) > 0 {
+		id, f.NextDiagramIDs = f.NextDiagramIDs[0], f.NextDiagramIDs[1:]
+	}
+	d := service.Diagram{ID: id, LearningPathID: lpID, Name: name}
+	f.diagrams[lpID] = d
+	return &d, nil
+}
+
+func (f *FakeEditorClient) RenameDiagram(ctx context.Context, lpID, name, authToken string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record(ctx, EditorOpRename, lpID, name, authToken); err != nil {
+		return err
+	}
+
+	d, ok := f.diagrams[lpID]
+	if !ok {
+		return &service.EditorError{Kind: service.EditorErrNotFound, StatusCode: 404, Message: "Diagram not found"}
+	}
+	d.Name = name
+	f.diagrams[lpID] = d
+	return nil
+}
+
+func (f *FakeEditorClient) DeleteDiagram(ctx context.Context, lpID, authToken string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record(ctx, EditorOpDelete, lpID, "", authToken); err != nil {
+		return err
+	}
+
+	if _, ok := f.diagrams[lpID]; !ok {
+		return &service.EditorError{Kind: service.EditorErrNotFound, StatusCode: 404, Message: "Diagram not found"}
+	}
+	delete(f.diagrams, lpID)
+	return nil
+}
+
+func (f *FakeEditorClient) GetDiagram(ctx context.Context, lpID, authToken string) (*service.Diagram, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record(ctx, EditorOpGet, lpID, "", authToken); err != nil {
+		return nil, err
+	}
+
+	d, ok := f.diagrams[lpID]
+	if !ok {
+		return nil, &service.EditorError{Kind: service.EditorErrNotFound, StatusCode: 404, Message: "Diagram not found"}
+	}
+	return &d, nil
+}
+
+func (f *FakeEditorClient) ListDiagrams(ctx context.Context, authToken string) ([]service.Diagram, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record(ctx, EditorOpList, "", "", authToken); err != nil {
+		return nil, err
+	}
+
+	result := make([]service.Diagram, 0, len(f.diagrams))
+	for _, d := range f.diagrams {
+		result = append(result, d)
+	}
+	return result, nil
+}
@@ -1,32 +0,0 @@
-package testutil
-
-import (
-	"io"
-	"net/http"
-	"strings"
-
-	"github.com/stretchr/testify/mock"
-)
-
-// MockHTTPClient is a mock implementation of the HTTPClient interface for testing
-type MockHTTPClient struct {
-	mock.Mock
-}
-
-// Do executes the mock HTTP request
-func (m *MockHTTPClient) Do(req *http.Request) (*http.Response, error) {
-	args := m.Called(req)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*http.Response), args.Error(1)
-}
-
-// CreateMockHTTPResponse creates a mock HTTP response for testing
-func CreateMockHTTPResponse(statusCode int, body string) *http.Response {
-	return &http.Response{
-		StatusCode: statusCode,
-		Body:       io.NopCloser(strings.NewReader(body)),
-		Header:     make(http.Header),
-	}
-}
@@ -0,0 +1,201 @@
+package unit_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/controller"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const accessTestCommunity = "Cloud and Backend"
+
+// grantTestRole gives userID the named role on lp
+func grantTestRole(t *testing.T, db *gorm.DB, lp model.LearningPath, userID uint, roleName string) {
+	t.Helper()
+	var role model.Role
+	require.NoError(t, db.Where("name = ?", roleName).First(&role).Error)
+	require.NoError(t, db.Create(&model.UserLP{UserID: userID, LPID: lp.ID, RoleID: &role.ID}).Error)
+}
+
+// seedOwnedLP inserts a learning path in accessTestCommunity owned by ownerID
+func seedOwnedLP(t *testing.T, db *gorm.DB, title string, ownerID uint, mutate func(*model.LearningPath)) model.LearningPath {
+	t.Helper()
+	lp := seedListLP(t, db, title, 0, func(lp *model.LearningPath) {
+		lp.CreatedByID = &ownerID
+		if mutate != nil {
+			mutate(lp)
+		}
+	})
+	grantTestRole(t, db, lp, ownerID, model.RoleOwner)
+	return lp
+}
+
+// seedPrivateLP inserts a private learning path in accessTestCommunity owned by ownerID
+func seedPrivateLP(t *testing.T, db *gorm.DB, title string, ownerID uint) model.LearningPath {
+	t.Helper()
+	return seedOwnedLP(t, db, title, ownerID, func(lp *model.LearningPath) { lp.IsPublic = false })
+}
+
+func TestViewer_PrivatePathVisibility(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient())
+	seedListLP(t, db, "Public", time.Hour, nil)
+	private := seedPrivateLP(t, db, "Private", 7)
+	grantTestRole(t, db, private, 8, model.RoleReader)
+
+	tests := []struct {
+		name   string
+		viewer service.Viewer
+		want   []string
+	}{
+		{"anonymous", service.Viewer{}, []string{"Public"}},
+		{"other community", service.Viewer{UserID: 1, CommunityIDs: []uuid.UUID{testutil.CommunityID(t, db, "Design")}}, []string{"Public"}},
+		{"community member", service.Viewer{UserID: 1, CommunityIDs: []uuid.UUID{testutil.CommunityID(t, db, accessTestCommunity)}}, []string{"Public", "Private"}},
+		{"owner in another community", service.Viewer{UserID: 7, CommunityIDs: []uuid.UUID{testutil.CommunityID(t, db, "Design")}}, []string{"Public", "Private"}},
+		{"reader in another community", service.Viewer{UserID: 8, CommunityIDs: []uuid.UUID{testutil.CommunityID(t, db, "Design")}}, []string{"Public", "Private"}},
+		{"admin", service.Viewer{UserID: 1, IsAdmin: true}, []string{"Public", "Private"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := svc.ListLearningPaths(context.Background(), service.LearningPathQuery{Viewer: tt.viewer})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, listTitles(page))
+			assert.Equal(t, int64(len(tt.want)), page.Total)
+
+			results, err := service.NewSearchService(db).Search(context.Background(), service.SearchQuery{Viewer: tt.viewer, Text: "p"})
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(tt.want)), results.Total)
+		})
+	}
+}
+
+func TestFavorites_HidePathsTheViewerCannotSee(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient())
+	private := seedPrivateLP(t, db, "Private", 7)
+	outsider := service.Viewer{UserID: 1, CommunityIDs: []uuid.UUID{testutil.CommunityID(t, db, "Design")}}
+
+	err := svc.AddToFavorites(context.Background(), outsider, private.ID.String())
+	assert.True(t, apperror.IsKind(err, apperror.KindNotFound))
+
+	// Favorited while public, then made private
+	require.NoError(t, db.Create(&model.UserLP{UserID: outsider.UserID, LPID: private.ID, IsFavorite: true}).Error)
+	favorites, err := svc.GetUserFavorites(context.Background(), outsider)
+	require.NoError(t, err)
+	assert.Empty(t, favorites)
+}
+
+func TestAuthorize(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient())
+	owned := seedOwnedLP(t, db, "Owned", 7, nil)
+	grantTestRole(t, db, owned, 2, model.RoleAuthor)
+	grantTestRole(t, db, owned, 3, model.RoleEditor)
+	grantTestRole(t, db, owned, 4, model.RoleReader)
+	legacy := seedListLP(t, db, "Legacy", 0, nil)
+	private := seedPrivateLP(t, db, "Private", 7)
+
+	member := service.Viewer{UserID: 1, CommunityIDs: []uuid.UUID{testutil.CommunityID(t, db, accessTestCommunity)}}
+	outsider := service.Viewer{UserID: 1, CommunityIDs: []uuid.UUID{testutil.CommunityID(t, db, "Design")}}
+	tests := []struct {
+		name   string
+		viewer service.Viewer
+		lp     model.LearningPath
+		action service.LearningPathAction
+		kind   apperror.Kind // empty when the action is allowed
+	}{
+		{"owner deletes", service.Viewer{UserID: 7}, owned, service.ActionDelete, ""},
+		{"admin deletes", service.Viewer{UserID: 1, IsAdmin: true}, owned, service.ActionDelete, ""},
+		{"author manages collaborators", service.Viewer{UserID: 2}, owned, service.ActionManageCollaborators, ""},
+		{"author cannot delete", service.Viewer{UserID: 2}, owned, service.ActionDelete, apperror.KindForbidden},
+		{"editor updates", service.Viewer{UserID: 3}, owned, service.ActionUpdate, ""},
+		{"editor cannot manage collaborators", service.Viewer{UserID: 3}, owned, service.ActionManageCollaborators, apperror.KindForbidden},
+		{"reader cannot update", service.Viewer{UserID: 4}, owned, service.ActionUpdate, apperror.KindForbidden},
+		{"community member cannot update owned path", member, owned, service.ActionUpdate, apperror.KindForbidden},
+		{"community member cannot update path without owner", member, legacy, service.ActionUpdate, apperror.KindForbidden},
+		{"community member cannot delete path without owner", member, legacy, service.ActionDelete, apperror.KindForbidden},
+		{"moderator updates path without owner", moderatorViewer(t, db, 1), legacy, service.ActionUpdate, ""},
+		{"outsider cannot update path without owner", outsider, legacy, service.ActionUpdate, apperror.KindForbidden},
+		{"outsider views public path", outsider, owned, service.ActionView, ""},
+		{"outsider cannot see private path", outsider, private, service.ActionView, apperror.KindNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Authorize(context.Background(), tt.viewer, tt.lp.ID.String(), tt.action)
+			if tt.kind == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, apperror.IsKind(err, tt.kind), "got %v", err)
+			}
+		})
+	}
+}
+
+// ============================================================================
+// CONTROLLER ACCESS CHECKS
+// ============================================================================
+
+// newAccessTestRouter serves update and delete for user userID, a member of accessTestCommunity
+func newAccessTestRouter(t *testing.T, userID uint) (*gin.Engine, *gorm.DB) {
+	db := testutil.SetupTestDB(t)
+	user := &model.User{Model: gorm.Model{ID: userID}, CommunityID: communityRef(t, db, accessTestCommunity)}
+	ctrl := controller.NewLearningPathController(service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient()), service.NewIdempotencyService(db))
+
+	r := newErrorTestRouter()
+	setUser := func(c *gin.Context) { c.Set("user", user) }
+	r.PUT("/api/learning-paths/:id", setUser, ctrl.Update)
+	r.DELETE("/api/learning-paths/:id", setUser, ctrl.Delete)
+	return r, db
+}
+
+func TestLearningPathController_CommunityMemberCannotChangeOwnedPath(t *testing.T) {
+	r, db := newAccessTestRouter(t, 1)
+	lp := seedOwnedLP(t, db, "Theirs", 7, nil)
+
+	update := doRequest(r, http.MethodPut, "/api/learning-paths/"+lp.ID.String(), `{"title":"Mine now"}`, nil)
+	del := doRequest(r, http.MethodDelete, "/api/learning-paths/"+lp.ID.String(), "", nil)
+
+	assert.Equal(t, http.StatusForbidden, update.Code)
+	assert.Equal(t, "learning_path_forbidden", decodeProblem(t, update).Code)
+	assert.Equal(t, http.StatusForbidden, del.Code)
+	var stored model.LearningPath
+	require.NoError(t, db.First(&stored, "id = ?", lp.ID).Error)
+	assert.Equal(t, "Theirs", stored.Title)
+}
+
+func TestLearningPathController_OwnerChangesVisibility(t *testing.T) {
+	r, db := newAccessTestRouter(t, 7)
+	lp := seedOwnedLP(t, db, "Mine", 7, nil)
+
+	w := doRequest(r, http.MethodPut, "/api/learning-paths/"+lp.ID.String(), `{"title":"Mine","isPublic":false}`, nil)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var updated model.LearningPath
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.False(t, updated.IsPublic)
+}
+
+func TestCreateLearningPath_IsPublicFalse(t *testing.T) {
+	r, db := newCreateTestRouter(t, testutil.NewFakeEditorClient())
+
+	w := postLearningPath(r, "", `{"pathName":"Internal Only","isPublic":false}`)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var lp model.LearningPath
+	require.NoError(t, db.First(&lp, "title = ?", "Internal Only").Error)
+	assert.False(t, lp.IsPublic)
+	require.NotNil(t, lp.CreatedByID)
+	assert.Equal(t, uint(7), *lp.CreatedByID)
+}
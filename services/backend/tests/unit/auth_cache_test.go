@@ -0,0 +1,183 @@
+package unit_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestAuthCache_HitsAndMisses(t *testing.T) {
+	cache := service.NewAuthCache(time.Minute, 10)
+	user := &model.User{Model: gorm.Model{ID: 1}, EntraID: "oid-1", Name: "Ada"}
+
+	_, ok := cache.Get("token-a")
+	assert.False(t, ok)
+	cache.Put("token-a", user, time.Now().Add(time.Hour))
+	cached, ok := cache.Get("token-a")
+	require.True(t, ok)
+	assert.Equal(t, "Ada", cached.Name)
+
+	cached.Name = "Changed"
+	again, _ := cache.Get("token-a")
+	assert.Equal(t, "Ada", again.Name, "each request gets its own copy")
+
+	snapshot := cache.Snapshot()
+	assert.Equal(t, 1, snapshot.Entries)
+	assert.Equal(t, int64(2), snapshot.Hits)
+	assert.Equal(t, int64(1), snapshot.Misses)
+	assert.InDelta(t, 2.0/3.0, snapshot.HitRatio, 0.001)
+}
+
+func TestAuthCache_EntriesEndWithTheToken(t *testing.T) {
+	cache := service.NewAuthCache(time.Hour, 10)
+	user := &model.User{Model: gorm.Model{ID: 1}, EntraID: "oid-1"}
+
+	cache.Put("expired", user, time.Now().Add(-time.Second))
+	cache.Put("expiring", user, time.Now().Add(20*time.Millisecond))
+	_, ok := cache.Get("expired")
+	assert.False(t, ok)
+	_, ok = cache.Get("expiring")
+	assert.True(t, ok)
+
+	time.Sleep(30 * time.Millisecond)
+	_, ok = cache.Get("expiring")
+	assert.False(t, ok, "the token's expiry bounds the TTL")
+}
+
+func TestAuthCache_InvalidateAndEvict(t *testing.T) {
+	cache := service.NewAuthCache(time.Minute, 2)
+	ada := &model.User{Model: gorm.Model{ID: 1}, EntraID: "oid-ada"}
+	bob := &model.User{Model: gorm.Model{ID: 2}, EntraID: "oid-bob"}
+	expiry := time.Now().Add(time.Hour)
+
+	cache.Put("ada-1", ada, expiry)
+	cache.Put("ada-2", ada, expiry)
+	cache.InvalidateUser("oid-ada")
+	assert.Equal(t, 0, cache.Snapshot().Entries)
+
+	cache.Put("ada-1", ada, expiry)
+	cache.Put("bob-1", bob, expiry)
+	cache.Put("bob-2", bob, expiry)
+	snapshot := cache.Snapshot()
+	assert.Equal(t, 2, snapshot.Entries)
+	assert.Equal(t, int64(1), snapshot.Evictions)
+}
+
+func TestGetOrCreateUser_BatchesGraphSyncWrites(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	seedMembershipMappings(t, db)
+	graph := service.NewGraphServiceWithClient(&http.Client{Transport: &graphGroupsTransport{groupIDs: []string{"group-cloud"}}})
+	users := service.NewUserService(db)
+	users.GraphSyncs = service.NewGraphSyncBatcher(db)
+	user, err := users.GetOrCreateUser(membershipTestClaims, graph, "graph-token")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&model.User{}).Where("id = ?", user.ID).Update("last_graph_sync", nil).Error)
+
+	user, err = users.GetOrCreateUser(membershipTestClaims, graph, "graph-token")
+	require.NoError(t, err)
+	assert.NotNil(t, user.LastGraphSync)
+
+	var stored model.User
+	require.NoError(t, db.First(&stored, user.ID).Error)
+	assert.Nil(t, stored.LastGraphSync, "written on the next flush")
+	_, pending := users.GraphSyncs.Pending(user.ID)
+	assert.True(t, pending)
+
+	flushed, err := users.GraphSyncs.Flush(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, flushed)
+	require.NoError(t, db.First(&stored, user.ID).Error)
+	assert.NotNil(t, stored.LastGraphSync)
+}
+
+func TestSetPrimaryCommunity_ReportsChange(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	users := service.NewUserService(db)
+	user := seedUser(t, db, "Mia", "mia@example.com")
+	var changed []string
+	users.OnUserChanged = func(u *model.User) { changed = append(changed, u.EntraID) }
+
+	_, err := users.SetPrimaryCommunity(context.Background(), user.ID, testutil.CommunityID(t, db, "Connectivity"))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{user.EntraID}, changed)
+}
+
+func TestRoleChanges_InvalidateCachedUser(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	cache := service.NewAuthCache(time.Minute, 10)
+	roles := service.NewRoleService(db)
+	roles.OnRolesChanged = cache.InvalidateUser
+	roles.OnClaimMappingsChanged = cache.Clear
+	communities := service.NewCommunityService(db)
+	communities.OnRolesChanged = cache.InvalidateUser
+	admin := seedUser(t, db, "Ada", "ada@example.com")
+	mia := seedUser(t, db, "Mia", "mia@example.com")
+	expiry := time.Now().Add(time.Hour)
+	cached := func(token string) bool {
+		_, ok := cache.Get(token)
+		return ok
+	}
+	communityID := testutil.CommunityID(t, db, accessTestCommunity).String()
+	ctx := context.Background()
+
+	cache.Put("ada", &admin, expiry)
+	cache.Put("mia", &mia, expiry)
+	_, err := roles.GrantRole(ctx, &admin, mia.Email, model.PlatformRoleAuditor)
+	require.NoError(t, err)
+	assert.False(t, cached("mia"), "granting a role drops the user's tokens")
+	assert.True(t, cached("ada"))
+
+	cache.Put("mia", &mia, expiry)
+	require.NoError(t, roles.RevokeRole(ctx, mia.ID, model.PlatformRoleAuditor))
+	assert.False(t, cached("mia"), "revoking a role drops the user's tokens")
+
+	cache.Put("mia", &mia, expiry)
+	_, err = communities.AddModerator(ctx, communityID, mia.Email)
+	require.NoError(t, err)
+	assert.False(t, cached("mia"), "appointing a moderator drops the user's tokens")
+
+	cache.Put("mia", &mia, expiry)
+	require.NoError(t, communities.RemoveModerator(ctx, communityID, mia.ID))
+	assert.False(t, cached("mia"), "removing a moderator drops the user's tokens")
+
+	cache.Put("mia", &mia, expiry)
+	mapping, err := roles.AddClaimMapping(ctx, model.ClaimTypeGroup, "group-admins", model.PlatformRoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, 0, cache.Snapshot().Entries, "claim mappings may change anyone's roles")
+
+	cache.Put("ada", &admin, expiry)
+	require.NoError(t, roles.RemoveClaimMapping(ctx, mapping.ID))
+	assert.Equal(t, 0, cache.Snapshot().Entries)
+}
+
+func TestCommunityMappingChanges_ClearCache(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	cache := service.NewAuthCache(time.Minute, 10)
+	mappings := service.NewCommunityMappingService(db)
+	mappings.OnMappingsChanged = cache.Clear
+	mia := seedUser(t, db, "Mia", "mia@example.com")
+	expiry := time.Now().Add(time.Hour)
+	ctx := context.Background()
+
+	cache.Put("mia", &mia, expiry)
+	mapping, err := mappings.AddMapping(ctx, nil, "group-cloud", "Cloud", accessTestCommunity, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, cache.Snapshot().Entries, "mappings may change anyone's communities")
+
+	cache.Put("mia", &mia, expiry)
+	_, err = mappings.AddMapping(ctx, nil, "group-cloud", "Cloud", accessTestCommunity, 10)
+	require.Error(t, err)
+	assert.Equal(t, 1, cache.Snapshot().Entries, "a rejected change keeps the cache")
+
+	require.NoError(t, mappings.RemoveMapping(ctx, nil, mapping.ID))
+	assert.Equal(t, 0, cache.Snapshot().Entries)
+}
@@ -0,0 +1,80 @@
+package unit_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/authcookie"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/controller"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/middleware"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// sealedCookieHeader writes value as auth-service would and returns the Cookie header a browser sends back
+func sealedCookieHeader(t *testing.T, codec *authcookie.Codec, name, value string) string {
+	w := httptest.NewRecorder()
+	require.NoError(t, codec.Write(w, nil, http.Cookie{Name: name, Value: value, MaxAge: 3600, Path: "/"}))
+
+	var parts []string
+	for _, cookie := range w.Result().Cookies() {
+		parts = append(parts, cookie.Name+"="+cookie.Value)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func TestAuth_WithEncryptedCookie(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	keys, err := authcookie.NewKeyRing(authcookie.Key{ID: "k1", Secret: bytes.Repeat([]byte{1}, 32)})
+	require.NoError(t, err)
+	codec := authcookie.NewCodec(keys)
+
+	verifier := middleware.NewHMACVerifier(testIssuer, "test-client-id", []byte("secret"))
+	r := newErrorTestRouter()
+	r.GET("/me", middleware.Auth(verifier, nil, nil, codec, service.NewUserService(db), nil), func(c *gin.Context) {
+		c.String(http.StatusOK, c.MustGet("user").(*model.User).Email)
+	})
+
+	// Large enough to be split over several cookies
+	claims := verifierTestClaims(time.Hour)
+	claims["padding"] = strings.Repeat("g", 6000)
+	token := testutil.SignHS256Token("secret", claims)
+
+	w := doRequest(r, http.MethodGet, "/me", "", map[string]string{"Cookie": sealedCookieHeader(t, codec, "id_token", token)})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "test@example.com", w.Body.String())
+
+	// A token the browser put in the cookie itself is not accepted
+	plain := testutil.SignHS256Token("secret", verifierTestClaims(time.Hour))
+	w = doRequest(r, http.MethodGet, "/me", "", map[string]string{"Cookie": "id_token=" + plain})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "authentication_required", decodeProblem(t, w).Code)
+}
+
+func TestLearningPathController_Create_DoesNotReadTheCookieItself(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	editor := testutil.NewFakeEditorClient()
+	ctrl := controller.NewLearningPathController(service.NewLearningPathServiceWithEditor(db, editor), service.NewIdempotencyService(db))
+	communityID := testutil.CommunityID(t, db, idempotencyTestCommunity)
+	r := newErrorTestRouter()
+	r.POST("/api/communities/:communityname/learning-paths", func(c *gin.Context) {
+		c.Set("user", &model.User{Model: gorm.Model{ID: 7}, CommunityID: &communityID})
+	}, ctrl.Create)
+
+	// The cookie holds ciphertext that only the Auth middleware can decode
+	w := doRequest(r, http.MethodPost, "/api/communities/"+url.PathEscape(idempotencyTestCommunity)+"/learning-paths", `{"pathName":"Go Basics"}`, map[string]string{"Cookie": "id_token=sealed"})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "service_token_missing", decodeProblem(t, w).Code)
+	assert.Empty(t, editor.Calls(testutil.EditorOpCreate))
+}
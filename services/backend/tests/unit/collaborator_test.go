@@ -0,0 +1,107 @@
+package unit_test
+
+import (
+	"context"
+	"testing"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func seedUser(t *testing.T, db *gorm.DB, name, email string) model.User {
+	t.Helper()
+	user := model.User{Name: name, Email: email, EntraID: email}
+	require.NoError(t, db.Create(&user).Error)
+	return user
+}
+
+func TestCreateLearningPath_CreatorBecomesOwner(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient())
+	owner := seedUser(t, db, "Olivia", "olivia@example.com")
+
+	lp, err := svc.CreateLearningPath(context.Background(), "Owned LP", "", true, "", nil, "token", testutil.CommunityID(t, db, accessTestCommunity), owner.ID)
+	require.NoError(t, err)
+	collaborators, err := svc.ListCollaborators(context.Background(), service.Viewer{UserID: owner.ID}, lp.ID.String())
+
+	require.NoError(t, err)
+	assert.Equal(t, []service.Collaborator{{UserID: owner.ID, Name: "Olivia", Email: "olivia@example.com", Role: model.RoleOwner}}, collaborators)
+}
+
+func TestSetCollaborator_InvitedEditorCanUpdate(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient())
+	owner := seedUser(t, db, "Olivia", "olivia@example.com")
+	editor := seedUser(t, db, "Eddie", "eddie@example.com")
+	lp := seedOwnedLP(t, db, "Shared", owner.ID, nil)
+	editorViewer := service.Viewer{UserID: editor.ID, CommunityIDs: []uuid.UUID{testutil.CommunityID(t, db, "Design")}}
+
+	_, err := svc.Authorize(context.Background(), editorViewer, lp.ID.String(), service.ActionUpdate)
+	require.True(t, apperror.IsKind(err, apperror.KindForbidden))
+
+	collaborator, err := svc.SetCollaborator(context.Background(), service.Viewer{UserID: owner.ID}, lp.ID.String(), "EDDIE@example.com", model.RoleEditor)
+	require.NoError(t, err)
+	assert.Equal(t, editor.ID, collaborator.UserID)
+
+	_, err = svc.Authorize(context.Background(), editorViewer, lp.ID.String(), service.ActionUpdate)
+	assert.NoError(t, err)
+}
+
+func TestSetCollaborator_ChangesExistingRoleAndKeepsFavorite(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient())
+	owner := seedUser(t, db, "Olivia", "olivia@example.com")
+	reader := seedUser(t, db, "Rita", "rita@example.com")
+	lp := seedOwnedLP(t, db, "Shared", owner.ID, nil)
+	require.NoError(t, svc.AddToFavorites(context.Background(), service.Viewer{UserID: reader.ID}, lp.ID.String()))
+	ownerViewer := service.Viewer{UserID: owner.ID}
+
+	_, err := svc.SetCollaborator(context.Background(), ownerViewer, lp.ID.String(), reader.Email, model.RoleReader)
+	require.NoError(t, err)
+	_, err = svc.SetCollaborator(context.Background(), ownerViewer, lp.ID.String(), reader.Email, model.RoleAuthor)
+	require.NoError(t, err)
+
+	var rows []model.UserLP
+	require.NoError(t, db.Where("user_id = ? AND lp_id = ?", reader.ID, lp.ID).Find(&rows).Error)
+	require.Len(t, rows, 1)
+	assert.True(t, rows[0].IsFavorite)
+
+	require.NoError(t, svc.RemoveCollaborator(context.Background(), ownerViewer, lp.ID.String(), reader.ID))
+	collaborators, err := svc.ListCollaborators(context.Background(), ownerViewer, lp.ID.String())
+	require.NoError(t, err)
+	assert.Len(t, collaborators, 1)
+	favorites, err := svc.GetUserFavorites(context.Background(), service.Viewer{UserID: reader.ID})
+	require.NoError(t, err)
+	assert.Len(t, favorites, 1)
+}
+
+func TestSetCollaborator_Rejections(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient())
+	owner := seedUser(t, db, "Olivia", "olivia@example.com")
+	editor := seedUser(t, db, "Eddie", "eddie@example.com")
+	lp := seedOwnedLP(t, db, "Shared", owner.ID, nil)
+	grantTestRole(t, db, lp, editor.ID, model.RoleEditor)
+	ownerViewer := service.Viewer{UserID: owner.ID}
+
+	_, err := svc.SetCollaborator(context.Background(), ownerViewer, lp.ID.String(), editor.Email, model.RoleOwner)
+	assert.True(t, apperror.IsKind(err, apperror.KindValidation), "ownership cannot be granted")
+
+	_, err = svc.SetCollaborator(context.Background(), ownerViewer, lp.ID.String(), "nobody@example.com", model.RoleReader)
+	assert.True(t, apperror.IsKind(err, apperror.KindNotFound))
+
+	_, err = svc.SetCollaborator(context.Background(), ownerViewer, lp.ID.String(), owner.Email, model.RoleReader)
+	assert.True(t, apperror.IsKind(err, apperror.KindConflict), "the owner cannot be demoted")
+
+	_, err = svc.SetCollaborator(context.Background(), service.Viewer{UserID: editor.ID}, lp.ID.String(), "x@example.com", model.RoleReader)
+	assert.True(t, apperror.IsKind(err, apperror.KindForbidden), "editors cannot share")
+
+	err = svc.RemoveCollaborator(context.Background(), ownerViewer, lp.ID.String(), owner.ID)
+	assert.True(t, apperror.IsKind(err, apperror.KindConflict))
+}
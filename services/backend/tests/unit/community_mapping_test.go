@@ -0,0 +1,138 @@
+package unit_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/controller"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/middleware"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCommunityMappingService_MatchGroupsByPriority(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewCommunityMappingService(db)
+	ctx := context.Background()
+
+	_, err := svc.AddMapping(ctx, nil, "group-frontends", "Frontends", "Frontends and Digital Experiences", 20)
+	require.NoError(t, err)
+	_, err = svc.AddMapping(ctx, nil, "group-cloud", "Cloud", "cloud-and-backend", 10)
+	require.NoError(t, err)
+	_, err = svc.AddMapping(ctx, nil, "group-connect", "Connectivity", "Connectivity", 5)
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&model.Community{}).Where("name = ?", "Connectivity").Update("archived_at", gorm.Expr("CURRENT_TIMESTAMP")).Error)
+
+	match, err := svc.MatchGroups(ctx, []string{"group-frontends", "group-cloud", "group-connect", "group-unknown"})
+
+	require.NoError(t, err)
+	require.NotNil(t, match.Community)
+	assert.Equal(t, "Cloud and Backend", match.Community.Name, "archived communities are skipped")
+	require.Len(t, match.Matches, 3)
+	assert.Equal(t, "group-connect", match.Matches[0].GroupID)
+
+	none, err := svc.MatchGroups(ctx, []string{"group-unknown"})
+	require.NoError(t, err)
+	assert.Nil(t, none.Community)
+}
+
+func TestCommunityMappingService_ChangesAreAuditedAndResync(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewCommunityMappingService(db)
+	ctx := context.Background()
+	admin := seedUser(t, db, "Ada", "ada@example.com")
+	synced := time.Now()
+	require.NoError(t, db.Model(&admin).Update("last_graph_sync", &synced).Error)
+
+	mapping, err := svc.AddMapping(ctx, &admin, "group-frontends", "Frontends", "Frontends and Digital Experiences", 0)
+	require.NoError(t, err)
+	_, err = svc.AddMapping(ctx, &admin, "group-frontends", "Frontends", "Connectivity", 0)
+	assert.True(t, apperror.IsKind(err, apperror.KindConflict), "a group maps to one community")
+	_, err = svc.AddMapping(ctx, &admin, "group-other", "", "Nowhere", 0)
+	assert.True(t, apperror.IsKind(err, apperror.KindNotFound))
+	require.NoError(t, svc.RemoveMapping(ctx, &admin, mapping.ID))
+	assert.True(t, apperror.IsKind(svc.RemoveMapping(ctx, &admin, mapping.ID), apperror.KindNotFound))
+
+	entries, err := svc.GetAudit(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, model.MappingAuditDeleted, entries[0].Action)
+	assert.Equal(t, model.MappingAuditCreated, entries[1].Action)
+	assert.Equal(t, "ada@example.com", entries[1].ActorEmail)
+	assert.Equal(t, "group-frontends", entries[1].GroupID)
+
+	var reloaded model.User
+	require.NoError(t, db.First(&reloaded, admin.ID).Error)
+	assert.Nil(t, reloaded.LastGraphSync, "mapping changes force communities to be re-evaluated")
+}
+
+// ============================================================================
+// COMMUNITY MAPPING ENDPOINTS
+// ============================================================================
+
+func newAdminMappingTestRouter(t *testing.T, roles ...string) *gin.Engine {
+	db := testutil.SetupTestDB(t)
+	ctrl := controller.NewAdminController(service.NewUserService(db), nil, service.NewCommunityService(db), service.NewCommunityMappingService(db), service.NewRoleService(db))
+
+	r := newErrorTestRouter()
+	setUser := setTestUser(1, "admin@example.com", roles...)
+	requireAdmin := middleware.RequireRole(model.PlatformRoleAdmin)
+	r.GET("/api/admin/community-mappings", setUser, requireAdmin, ctrl.GetCommunityMappings)
+	r.POST("/api/admin/community-mappings", setUser, requireAdmin, ctrl.AddCommunityMapping)
+	r.DELETE("/api/admin/community-mappings/:id", setUser, requireAdmin, ctrl.RemoveCommunityMapping)
+	r.POST("/api/admin/community-mappings/test", setUser, requireAdmin, ctrl.TestCommunityMappings)
+	r.GET("/api/admin/community-mappings/audit", setUser, requireAdmin, ctrl.GetCommunityMappingAudit)
+	return r
+}
+
+func TestAdminController_CommunityMappings(t *testing.T) {
+	r := newAdminMappingTestRouter(t, model.PlatformRoleAdmin)
+
+	w := doRequest(r, http.MethodPost, "/api/admin/community-mappings", `{"groupId":"group-frontends","groupName":"Frontends","community":"frontends-and-digital-experiences","priority":1}`, nil)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = doRequest(r, http.MethodPost, "/api/admin/community-mappings/test", `{"groupIds":["group-frontends","group-unknown"]}`, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var result struct {
+		Community *model.Community              `json:"community"`
+		Matches   []model.CommunityGroupMapping `json:"matches"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	require.NotNil(t, result.Community)
+	assert.Equal(t, "Frontends and Digital Experiences", result.Community.Name)
+	assert.Len(t, result.Matches, 1)
+
+	w = doRequest(r, http.MethodGet, "/api/admin/community-mappings/audit?limit=10", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var audit struct {
+		Entries []model.CommunityMappingAudit `json:"entries"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &audit))
+	require.Len(t, audit.Entries, 1)
+	assert.Equal(t, "admin@example.com", audit.Entries[0].ActorEmail)
+}
+
+func TestAdminController_CommunityMappingRequests_Rejected(t *testing.T) {
+	r := newAdminMappingTestRouter(t, model.PlatformRoleAdmin)
+
+	w := doRequest(r, http.MethodDelete, "/api/admin/community-mappings/abc", "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_mapping_id", decodeProblem(t, w).Code)
+
+	w = doRequest(r, http.MethodPost, "/api/admin/community-mappings/test", `{}`, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "user", decodeProblem(t, w).Errors[0].Field)
+
+	nonAdmin := newAdminMappingTestRouter(t)
+	w = doRequest(nonAdmin, http.MethodGet, "/api/admin/community-mappings", "", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,118 @@
+package unit_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/controller"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/middleware"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// graphGroupsTransport answers every Microsoft Graph request with the given groups
+type graphGroupsTransport struct {
+	groupIDs []string
+}
+
+func (g *graphGroupsTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	values := make([]string, len(g.groupIDs))
+	for i, id := range g.groupIDs {
+		values[i] = `{"id":"` + id + `","displayName":"` + id + `"}`
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"value":[` + strings.Join(values, ",") + `]}`)),
+	}, nil
+}
+
+var membershipTestClaims = map[string]interface{}{"email": "mia@example.com", "name": "Mia", "oid": "oid-mia"}
+
+func seedMembershipMappings(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	mappings := service.NewCommunityMappingService(db)
+	_, err := mappings.AddMapping(context.Background(), nil, "group-cloud", "", "Cloud and Backend", 10)
+	require.NoError(t, err)
+	_, err = mappings.AddMapping(context.Background(), nil, "group-security", "", "Cyber Security and Software Update", 20)
+	require.NoError(t, err)
+}
+
+func TestGetOrCreateUser_JoinsEveryMappedCommunity(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	seedMembershipMappings(t, db)
+	graph := service.NewGraphServiceWithClient(&http.Client{Transport: &graphGroupsTransport{groupIDs: []string{"group-security", "group-cloud", "group-other"}}})
+
+	user, err := service.NewUserService(db).GetOrCreateUser(membershipTestClaims, graph, "graph-token")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Cloud and Backend", user.CommunityName(), "the highest-priority community is primary")
+	assert.Equal(t, []string{"Cloud and Backend", "Cyber Security and Software Update"}, user.CommunityNames())
+	assert.True(t, user.IsMemberOf(testutil.CommunityID(t, db, "Cyber Security and Software Update")))
+}
+
+func TestGetOrCreateUser_ResyncDropsUnmappedCommunities(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	seedMembershipMappings(t, db)
+	transport := &graphGroupsTransport{groupIDs: []string{"group-security", "group-cloud"}}
+	graph := service.NewGraphServiceWithClient(&http.Client{Transport: transport})
+	users := service.NewUserService(db)
+	user, err := users.GetOrCreateUser(membershipTestClaims, graph, "graph-token")
+	require.NoError(t, err)
+	_, err = users.SetPrimaryCommunity(context.Background(), user.ID, testutil.CommunityID(t, db, "Connectivity"))
+	require.NoError(t, err)
+
+	transport.groupIDs = []string{"group-security"}
+	require.NoError(t, db.Model(&model.User{}).Where("id = ?", user.ID).Update("last_graph_sync", nil).Error)
+	user, err = users.GetOrCreateUser(membershipTestClaims, graph, "graph-token")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Connectivity", user.CommunityName(), "a community the user chose stays primary")
+	assert.ElementsMatch(t, []string{"Connectivity", "Cyber Security and Software Update"}, user.CommunityNames())
+
+	var memberships []model.CommunityMembership
+	require.NoError(t, db.Where("user_id = ?", user.ID).Find(&memberships).Error)
+	assert.Len(t, memberships, 2)
+}
+
+func TestLearningPathController_Create_AnyMembershipAllowed(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	ctrl := controller.NewLearningPathController(service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient()), service.NewIdempotencyService(db))
+	primaryID := testutil.CommunityID(t, db, "Connectivity")
+	secondaryID := testutil.CommunityID(t, db, idempotencyTestCommunity)
+
+	r := newErrorTestRouter()
+	r.POST("/api/communities/:communityname/learning-paths", func(c *gin.Context) {
+		c.Set("user", &model.User{
+			Model:       gorm.Model{ID: 7},
+			Email:       "author@example.com",
+			CommunityID: &primaryID,
+			Memberships: []model.CommunityMembership{{UserID: 7, CommunityID: primaryID}, {UserID: 7, CommunityID: secondaryID}},
+		})
+		c.Set(middleware.ContextAuthToken, "user-token")
+	}, ctrl.Create)
+
+	w := postLearningPath(r, "", `{"pathName":"Second Home"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = doRequest(r, http.MethodPost, "/api/communities/Autonomous%20Systems/learning-paths", `{"pathName":"Elsewhere"}`, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "community_forbidden", decodeProblem(t, w).Code)
+}
+
+func TestUserCommunityIDs_PrimaryFirstWithoutDuplicates(t *testing.T) {
+	primary, other := uuid.New(), uuid.New()
+	user := model.User{CommunityID: &primary, Memberships: []model.CommunityMembership{{CommunityID: other}, {CommunityID: primary}}}
+
+	assert.Equal(t, []uuid.UUID{primary, other}, user.CommunityIDs())
+	assert.False(t, user.IsMemberOf(uuid.New()))
+}
@@ -0,0 +1,159 @@
+package unit_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/controller"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/middleware"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestCommunityService_DefaultsAreSeeded(t *testing.T) {
+	svc := service.NewCommunityService(testutil.SetupTestDB(t))
+
+	communities, err := svc.GetCommunities(context.Background(), false)
+
+	require.NoError(t, err)
+	require.Len(t, communities, 9)
+	assert.Equal(t, "Autonomous Systems", communities[0].Name)
+	assert.Equal(t, "autonomous-systems", communities[0].Slug)
+}
+
+func TestCommunityService_CreateDerivesSlugAndRejectsDuplicates(t *testing.T) {
+	svc := service.NewCommunityService(testutil.SetupTestDB(t))
+
+	created, err := svc.CreateCommunity(context.Background(), service.CommunityInput{Name: strPtr("Quantum & AI"), Color: strPtr("#1E88E5")})
+	require.NoError(t, err)
+	assert.Equal(t, "quantum-ai", created.Slug)
+
+	_, err = svc.CreateCommunity(context.Background(), service.CommunityInput{Name: strPtr("quantum & ai")})
+	assert.True(t, apperror.IsKind(err, apperror.KindConflict), "names are unique regardless of case")
+	_, err = svc.CreateCommunity(context.Background(), service.CommunityInput{Name: strPtr("Quantum"), Slug: strPtr("quantum-ai")})
+	assert.True(t, apperror.IsKind(err, apperror.KindConflict), "slugs are unique")
+	_, err = svc.CreateCommunity(context.Background(), service.CommunityInput{Name: strPtr("Robots"), Slug: strPtr("Robots!")})
+	assert.True(t, apperror.IsKind(err, apperror.KindValidation))
+}
+
+func TestCommunityService_RenameKeepsLearningPaths(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewCommunityService(db)
+	lpService := service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient())
+	seedListLP(t, db, "Backend Basics", 0, nil)
+	community, err := svc.GetCommunity(context.Background(), "Cloud and Backend")
+	require.NoError(t, err)
+
+	renamed, err := svc.UpdateCommunity(context.Background(), community.ID.String(), service.CommunityInput{Name: strPtr("Cloud Platform")})
+	require.NoError(t, err)
+	assert.Equal(t, "cloud-and-backend", renamed.Slug, "the slug only changes when asked to")
+
+	for _, ref := range []string{"Cloud Platform", "cloud-and-backend"} {
+		page, err := lpService.ListLearningPaths(context.Background(), service.LearningPathQuery{Community: ref})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Backend Basics"}, listTitles(page), ref)
+	}
+	valid, err := svc.IsValidCommunity(context.Background(), "Cloud and Backend")
+	require.NoError(t, err)
+	assert.False(t, valid)
+}
+
+func TestCommunityService_ArchivedCommunityIsHiddenAndClosed(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewCommunityService(db)
+	community, err := svc.GetCommunity(context.Background(), "Connectivity")
+	require.NoError(t, err)
+
+	_, err = svc.SetCommunityArchived(context.Background(), community.ID.String(), true)
+	require.NoError(t, err)
+
+	active, err := svc.GetCommunities(context.Background(), false)
+	require.NoError(t, err)
+	assert.Len(t, active, 8)
+	all, err := svc.GetCommunities(context.Background(), true)
+	require.NoError(t, err)
+	assert.Len(t, all, 9)
+	_, err = svc.GetActiveCommunity(context.Background(), "Connectivity")
+	assert.True(t, apperror.IsKind(err, apperror.KindConflict))
+	valid, err := svc.IsValidCommunity(context.Background(), "Connectivity")
+	require.NoError(t, err)
+	assert.True(t, valid, "archived communities can still be read")
+
+	restored, err := svc.SetCommunityArchived(context.Background(), community.ID.String(), false)
+	require.NoError(t, err)
+	assert.False(t, restored.IsArchived())
+}
+
+// ============================================================================
+// COMMUNITY ENDPOINTS
+// ============================================================================
+
+func newAdminCommunityTestRouter(t *testing.T, roles ...string) (*gin.Engine, *gorm.DB) {
+	db := testutil.SetupTestDB(t)
+	ctrl := controller.NewAdminController(service.NewUserService(db), nil, service.NewCommunityService(db), service.NewCommunityMappingService(db), service.NewRoleService(db))
+
+	r := newErrorTestRouter()
+	setUser := setTestUser(1, "admin@example.com", roles...)
+	requireAdmin := middleware.RequireRole(model.PlatformRoleAdmin)
+	r.POST("/api/admin/communities", setUser, requireAdmin, ctrl.CreateCommunity)
+	r.PUT("/api/admin/communities/:id", setUser, requireAdmin, ctrl.UpdateCommunity)
+	r.POST("/api/admin/communities/:id/archive", setUser, requireAdmin, ctrl.ArchiveCommunity)
+	return r, db
+}
+
+func TestAdminController_CreateCommunity(t *testing.T) {
+	r, _ := newAdminCommunityTestRouter(t, model.PlatformRoleAdmin)
+
+	w := doRequest(r, http.MethodPost, "/api/admin/communities", `{"name":"Quantum Computing","description":"Qubits","icon":"atom","color":"#7B1FA2"}`, nil)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var community model.Community
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &community))
+	assert.Equal(t, "quantum-computing", community.Slug)
+	assert.Equal(t, "#7B1FA2", community.Color)
+}
+
+func TestAdminController_CommunityRequests_Rejected(t *testing.T) {
+	r, _ := newAdminCommunityTestRouter(t, model.PlatformRoleAdmin)
+
+	w := doRequest(r, http.MethodPost, "/api/admin/communities", `{"name":"Design","color":"blue"}`, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "color", decodeProblem(t, w).Errors[0].Field)
+
+	w = doRequest(r, http.MethodPut, "/api/admin/communities/not-a-uuid", `{"name":"Design"}`, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_community_id", decodeProblem(t, w).Code)
+
+	nonAdmin, _ := newAdminCommunityTestRouter(t)
+	w = doRequest(nonAdmin, http.MethodPost, "/api/admin/communities", `{"name":"Design"}`, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestLearningPathController_Create_ArchivedCommunity(t *testing.T) {
+	r, db := newCreateTestRouter(t, testutil.NewFakeEditorClient())
+	require.NoError(t, db.Model(&model.Community{}).Where("name = ?", idempotencyTestCommunity).Update("archived_at", gorm.Expr("CURRENT_TIMESTAMP")).Error)
+
+	w := postLearningPath(r, "", `{"pathName":"Too Late"}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "community_archived", decodeProblem(t, w).Code)
+}
+
+func TestLearningPathController_GetByCommunity_UnknownCommunity(t *testing.T) {
+	r, _ := newListTestRouter(t)
+
+	w := doRequest(r, http.MethodGet, "/api/communities/Nowhere/learning-paths", "", nil)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "community_not_found", decodeProblem(t, w).Code)
+}
@@ -0,0 +1,111 @@
+package unit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestEditorServer returns an HTTPEditorClient pointed at a server that runs handler
+func newTestEditorServer(t *testing.T, handler http.HandlerFunc) *service.HTTPEditorClient {
+	t.Helper()
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+	return service.NewHTTPEditorClient(ts.URL+"/api", ts.Client())
+}
+
+func TestHTTPEditorClient_CreateDiagram_SendsTokenAndDecodes(t *testing.T) {
+	var gotAuth, gotPath string
+	client := newTestEditorServer(t, func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotPath = r.URL.Path
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"_id":"mongo123","learningPathId":"lp-1","name":"Test LP"}`))
+	})
+
+	d, err := client.CreateDiagram(context.Background(), "lp-1", "Test LP", "user-token")
+
+	require.NoError(t, err)
+	assert.Equal(t, "mongo123", d.ID)
+	assert.Equal(t, "Bearer user-token", gotAuth)
+	assert.Equal(t, "/api/diagrams/by-lp", gotPath)
+}
+
+func TestHTTPEditorClient_CreateDiagram_IdempotentRetryReturnsExisting(t *testing.T) {
+	client := newTestEditorServer(t, func(w http.ResponseWriter, r *http.Request) {
+		// 200 OK = diagram already exists for this LP
+		w.Write([]byte(`{"_id":"mongo-existing","learningPathId":"lp-1","name":"Existing LP"}`))
+	})
+
+	d, err := client.CreateDiagram(context.Background(), "lp-1", "Existing LP", "token")
+
+	require.NoError(t, err)
+	assert.Equal(t, "mongo-existing", d.ID)
+}
+
+func TestHTTPEditorClient_RenameDiagram_PatchesName(t *testing.T) {
+	var gotMethod, gotPath, gotAuth string
+	var body map[string]string
+	client := newTestEditorServer(t, func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath, gotAuth = r.Method, r.URL.Path, r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{}`))
+	})
+
+	err := client.RenameDiagram(context.Background(), "lp-1", "New Name", "token")
+
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodPatch, gotMethod)
+	assert.Equal(t, "/api/diagrams/by-lp/lp-1", gotPath)
+	assert.Equal(t, "Bearer token", gotAuth)
+	assert.Equal(t, "New Name", body["name"])
+}
+
+func TestHTTPEditorClient_StatusCodes_MapToErrorKinds(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		kind   service.EditorErrorKind
+	}{
+		{http.StatusNotFound, `{"error":"Diagram not found"}`, service.EditorErrNotFound},
+		{http.StatusConflict, `{"error":"A learning path with this name already exists"}`, service.EditorErrConflict},
+		{http.StatusUnauthorized, `{"error":"Unauthorized","message":"No access token provided"}`, service.EditorErrUnauthorized},
+		{http.StatusForbidden, `{"error":"Forbidden"}`, service.EditorErrUnauthorized},
+		{http.StatusServiceUnavailable, `{"error":"service unavailable"}`, service.EditorErrUnavailable},
+		{http.StatusTooManyRequests, ``, service.EditorErrUnavailable},
+		{http.StatusBadRequest, `{"error":"Name is required"}`, service.EditorErrUnexpected},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			client := newTestEditorServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			err := client.DeleteDiagram(context.Background(), "lp-1", "token")
+
+			var editorErr *service.EditorError
+			require.True(t, errors.As(err, &editorErr))
+			assert.Equal(t, tt.kind, editorErr.Kind)
+			assert.Equal(t, tt.status, editorErr.StatusCode)
+		})
+	}
+}
+
+func TestHTTPEditorClient_ConnectionRefused_IsUnavailable(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	ts.Close()
+	client := service.NewHTTPEditorClient(ts.URL+"/api", ts.Client())
+
+	_, err := client.ListDiagrams(context.Background(), "token")
+
+	assert.True(t, service.IsEditorError(err, service.EditorErrUnavailable))
+}
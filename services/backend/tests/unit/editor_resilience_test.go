@@ -0,0 +1,128 @@
+package unit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/controller"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newResilientTestClient(editor service.EditorClient, maxAttempts, failureThreshold int, openTimeout time.Duration) *service.ResilientEditorClient {
+	return &service.ResilientEditorClient{
+		Next:    editor,
+		Breaker: service.NewCircuitBreaker(failureThreshold, openTimeout),
+		Retry:   service.RetryPolicy{MaxAttempts: maxAttempts, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond},
+	}
+}
+
+func TestResilientEditor_TransientFailure_CreateRetriedWithoutCompensation(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	editor := testutil.NewFakeEditorClient()
+	editor.FailTimes(testutil.EditorOpCreate, 2, service.NewEditorError(service.EditorErrUnavailable, "connection reset"))
+
+	svc := service.NewLearningPathServiceWithEditor(db, newResilientTestClient(editor, 3, 5, time.Minute))
+
+	lp, err := svc.CreateLearningPath(context.Background(), "Retried LP", "", true, "", nil, "token", testutil.CommunityID(t, db, "community"), 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Retried LP", lp.Title)
+	assert.Len(t, editor.Calls(testutil.EditorOpCreate), 3)
+	assert.Empty(t, editor.Calls(testutil.EditorOpDelete), "no compensation after a successful retry")
+
+	var saga model.SagaLog
+	require.NoError(t, db.Where("lp_id = ?", lp.ID).First(&saga).Error)
+	assert.Equal(t, model.SagaStatusCompleted, saga.Status)
+}
+
+func TestResilientEditor_NonTransientError_NotRetried(t *testing.T) {
+	editor := testutil.NewFakeEditorClient()
+	editor.Fail(testutil.EditorOpRename, &service.EditorError{Kind: service.EditorErrConflict, StatusCode: 409})
+	client := newResilientTestClient(editor, 3, 1, time.Minute)
+
+	err := client.RenameDiagram(context.Background(), "lp-1", "Name", "token")
+
+	assert.True(t, service.IsEditorError(err, service.EditorErrConflict))
+	assert.Len(t, editor.Calls(testutil.EditorOpRename), 1)
+	assert.Equal(t, service.CircuitClosed, client.Breaker.Snapshot().State, "an error response proves the editor is reachable")
+}
+
+func TestResilientEditor_OpenCircuit_FailsFast(t *testing.T) {
+	editor := testutil.NewFakeEditorClient()
+	editor.FailUnavailable(testutil.EditorOpList)
+	client := newResilientTestClient(editor, 2, 2, time.Minute)
+
+	_, err := client.ListDiagrams(context.Background(), "token")
+	require.Error(t, err)
+	require.Equal(t, service.CircuitOpen, client.Breaker.Snapshot().State)
+
+	_, err = client.ListDiagrams(context.Background(), "token")
+
+	assert.True(t, service.IsEditorError(err, service.EditorErrUnavailable))
+	assert.True(t, errors.Is(err, service.ErrCircuitOpen))
+	assert.Len(t, editor.Calls(testutil.EditorOpList), 2, "the open circuit must not reach the editor")
+}
+
+func TestResilientEditor_HalfOpenProbe_ClosesOnSuccess(t *testing.T) {
+	editor := testutil.NewFakeEditorClient()
+	editor.FailUnavailable(testutil.EditorOpList)
+	client := newResilientTestClient(editor, 1, 1, 20*time.Millisecond)
+
+	_, err := client.ListDiagrams(context.Background(), "token")
+	require.Error(t, err)
+	require.Equal(t, service.CircuitOpen, client.Breaker.Snapshot().State)
+
+	editor.Recover(testutil.EditorOpList)
+	time.Sleep(30 * time.Millisecond)
+
+	_, err = client.ListDiagrams(context.Background(), "token")
+
+	require.NoError(t, err)
+	snapshot := client.Breaker.Snapshot()
+	assert.Equal(t, service.CircuitClosed, snapshot.State)
+	assert.Equal(t, 0, snapshot.ConsecutiveFailures)
+}
+
+func TestCircuitBreaker_HalfOpen_AllowsSingleProbe(t *testing.T) {
+	breaker := service.NewCircuitBreaker(1, 10*time.Millisecond)
+	breaker.RecordFailure()
+	time.Sleep(20 * time.Millisecond)
+
+	assert.True(t, breaker.Allow(), "first call after the cooldown is the probe")
+	assert.False(t, breaker.Allow(), "other calls wait for the probe's result")
+
+	breaker.RecordFailure()
+	assert.Equal(t, service.CircuitOpen, breaker.Snapshot().State)
+	assert.False(t, breaker.Allow())
+}
+
+func TestHealthController_ReportsBreakerState(t *testing.T) {
+	breaker := service.NewCircuitBreaker(1, time.Minute)
+	r := newErrorTestRouter()
+	r.GET("/api/health", controller.NewHealthController(breaker, nil).Get)
+
+	w := doRequest(r, http.MethodGet, "/api/health", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok","dependencies":{"backendEditor":{"state":"closed","consecutiveFailures":0}}}`, w.Body.String())
+
+	breaker.RecordFailure()
+	w = doRequest(r, http.MethodGet, "/api/health", "", nil)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Status       string                             `json:"status"`
+		Dependencies map[string]service.CircuitSnapshot `json:"dependencies"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "degraded", body.Status)
+	assert.Equal(t, service.CircuitOpen, body.Dependencies["backendEditor"].State)
+	assert.NotNil(t, body.Dependencies["backendEditor"].RetryAt)
+}
@@ -0,0 +1,190 @@
+package unit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/controller"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/middleware"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newErrorTestRouter() *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.ErrorHandler())
+	return r
+}
+
+func doRequest(r *gin.Engine, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) middleware.Problem {
+	t.Helper()
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	var problem middleware.Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	return problem
+}
+
+func TestErrorHandler_DomainError_RendersProblem(t *testing.T) {
+	r := newErrorTestRouter()
+	r.GET("/things/:id", func(c *gin.Context) {
+		_ = c.Error(apperror.NotFound("thing_not_found", "Thing not found"))
+	})
+
+	w := doRequest(r, http.MethodGet, "/things/1", "", map[string]string{middleware.RequestIDHeader: "req-123"})
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "req-123", w.Header().Get(middleware.RequestIDHeader))
+	problem := decodeProblem(t, w)
+	assert.Equal(t, "thing_not_found", problem.Code)
+	assert.Equal(t, "/problems/thing_not_found", problem.Type)
+	assert.Equal(t, "Not Found", problem.Title)
+	assert.Equal(t, "Thing not found", problem.Detail)
+	assert.Equal(t, "/things/1", problem.Instance)
+	assert.Equal(t, "req-123", problem.RequestID)
+}
+
+func TestErrorHandler_UnknownError_HidesDetails(t *testing.T) {
+	r := newErrorTestRouter()
+	r.GET("/boom", func(c *gin.Context) {
+		_ = c.Error(errors.New("pq: connection refused to 10.0.0.5"))
+	})
+
+	w := doRequest(r, http.MethodGet, "/boom", "", nil)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	problem := decodeProblem(t, w)
+	assert.Equal(t, "internal_error", problem.Code)
+	assert.NotContains(t, problem.Detail, "10.0.0.5")
+	assert.NotEmpty(t, problem.RequestID, "a request ID is generated when the caller sends none")
+	assert.Equal(t, problem.RequestID, w.Header().Get(middleware.RequestIDHeader))
+}
+
+func TestErrorHandler_WrappedDependencyError_KeepsStatusOverride(t *testing.T) {
+	r := newErrorTestRouter()
+	r.GET("/editor", func(c *gin.Context) {
+		err := apperror.DependencyFailed("editor_unavailable", "Diagram service is unavailable", errors.New("dial tcp")).
+			WithStatus(http.StatusServiceUnavailable)
+		_ = c.Error(err)
+	})
+
+	w := doRequest(r, http.MethodGet, "/editor", "", nil)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "editor_unavailable", decodeProblem(t, w).Code)
+}
+
+// ============================================================================
+// CONTROLLER ERROR MAPPING
+// ============================================================================
+
+func newLearningPathTestRouter(t *testing.T, editor service.EditorClient) (*gin.Engine, *service.LearningPathService) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewLearningPathServiceWithEditor(db, editor)
+	ctrl := controller.NewLearningPathController(svc, service.NewIdempotencyService(db))
+
+	r := newErrorTestRouter()
+	r.PUT("/api/learning-paths/:id", func(c *gin.Context) {
+		c.Set("user", &model.User{Model: gorm.Model{ID: 7}, CommunityID: communityRef(t, db, "community")})
+	}, ctrl.Update)
+	return r, svc
+}
+
+func TestLearningPathController_Update_NotFound(t *testing.T) {
+	r, _ := newLearningPathTestRouter(t, testutil.NewFakeEditorClient())
+
+	w := doRequest(r, http.MethodPut, "/api/learning-paths/"+uuid.New().String(), `{"title":"New"}`, nil)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "learning_path_not_found", decodeProblem(t, w).Code)
+}
+
+func TestLearningPathController_Update_MissingTitle_ReportsField(t *testing.T) {
+	r, _ := newLearningPathTestRouter(t, testutil.NewFakeEditorClient())
+
+	w := doRequest(r, http.MethodPut, "/api/learning-paths/"+uuid.New().String(), `{"description":"only"}`, nil)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	problem := decodeProblem(t, w)
+	assert.Equal(t, "validation_failed", problem.Code)
+	require.Len(t, problem.Errors, 1)
+	assert.Equal(t, apperror.FieldError{Field: "title", Code: "required", Message: "title is required"}, problem.Errors[0])
+}
+
+func TestLearningPathController_Update_InvalidID(t *testing.T) {
+	r, _ := newLearningPathTestRouter(t, testutil.NewFakeEditorClient())
+
+	w := doRequest(r, http.MethodPut, "/api/learning-paths/not-a-uuid", `{"title":"New"}`, nil)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	problem := decodeProblem(t, w)
+	assert.Equal(t, "invalid_learning_path_id", problem.Code)
+	require.Len(t, problem.Errors, 1)
+	assert.Equal(t, "id", problem.Errors[0].Field)
+}
+
+// ============================================================================
+// SERVICE ERROR KINDS
+// ============================================================================
+
+func TestCreateLearningPath_EditorErrors_MapToDomainKinds(t *testing.T) {
+	tests := []struct {
+		name string
+		fail error
+		kind apperror.Kind
+		code string
+	}{
+		{"conflict", &service.EditorError{Kind: service.EditorErrConflict, StatusCode: 409}, apperror.KindConflict, "learning_path_title_taken"},
+		{"unauthorized", &service.EditorError{Kind: service.EditorErrUnauthorized, StatusCode: 401}, apperror.KindForbidden, "editor_auth_failed"},
+		{"unavailable", &service.EditorError{Kind: service.EditorErrUnavailable, StatusCode: 503}, apperror.KindDependencyFailed, "editor_unavailable"},
+		{"unexpected", &service.EditorError{Kind: service.EditorErrUnexpected, StatusCode: 400}, apperror.KindDependencyFailed, "editor_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testutil.SetupTestDB(t)
+			editor := testutil.NewFakeEditorClient()
+			editor.Fail(testutil.EditorOpCreate, tt.fail)
+			svc := service.NewLearningPathServiceWithEditor(db, editor)
+
+			_, err := svc.CreateLearningPath(context.Background(), "LP", "", true, "", nil, "token", testutil.CommunityID(t, db, "community"), 0)
+
+			var appErr *apperror.Error
+			require.True(t, errors.As(err, &appErr))
+			assert.Equal(t, tt.kind, appErr.Kind)
+			assert.Equal(t, tt.code, appErr.Code)
+		})
+	}
+}
+
+func TestUpdateLearningPath_NotFound_IsNotFoundKind(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient())
+	require.NoError(t, db.Create(&model.LearningPath{ID: uuid.New(), Title: "Other", DiagramID: "d1", IsPublic: true}).Error)
+
+	_, err := svc.UpdateLearningPath(context.Background(), uuid.New().String(), "Title", "", nil)
+
+	assert.True(t, apperror.IsKind(err, apperror.KindNotFound))
+}
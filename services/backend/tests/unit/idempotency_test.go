@@ -0,0 +1,214 @@
+package unit_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/controller"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/middleware"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const idempotencyTestCommunity = "Cloud and Backend"
+
+func newCreateTestRouter(t *testing.T, editor service.EditorClient) (*gin.Engine, *gorm.DB) {
+	db := testutil.SetupTestDB(t)
+	ctrl := controller.NewLearningPathController(service.NewLearningPathServiceWithEditor(db, editor), service.NewIdempotencyService(db))
+
+	communityID := testutil.CommunityID(t, db, idempotencyTestCommunity)
+	r := newErrorTestRouter()
+	r.POST("/api/communities/:communityname/learning-paths", func(c *gin.Context) {
+		c.Set("user", &model.User{Model: gorm.Model{ID: 7}, Email: "author@example.com", CommunityID: &communityID})
+		c.Set(middleware.ContextAuthToken, "user-token")
+	}, ctrl.Create)
+	return r, db
+}
+
+func postLearningPath(r *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/communities/"+url.PathEscape(idempotencyTestCommunity)+"/learning-paths", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(controller.IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateLearningPath_SameIdempotencyKey_ReplaysResponse(t *testing.T) {
+	editor := testutil.NewFakeEditorClient()
+	r, db := newCreateTestRouter(t, editor)
+
+	first := postLearningPath(r, "key-1", `{"pathName":"Go Basics"}`)
+	second := postLearningPath(r, "key-1", `{"pathName":"Go Basics"}`)
+
+	require.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get(controller.IdempotentReplayedHeader))
+	assert.Empty(t, first.Header().Get(controller.IdempotentReplayedHeader))
+	assert.Len(t, editor.Calls(testutil.EditorOpCreate), 1)
+
+	var count int64
+	db.Model(&model.LearningPath{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	var record model.IdempotencyRecord
+	require.NoError(t, db.First(&record, "idempotency_key = ?", "key-1").Error)
+	assert.Equal(t, uint(7), record.UserID)
+	require.NotNil(t, record.LPID)
+}
+
+func TestCreateLearningPath_IdempotencyKeyReusedWithDifferentPayload_Rejected(t *testing.T) {
+	r, _ := newCreateTestRouter(t, testutil.NewFakeEditorClient())
+
+	require.Equal(t, http.StatusCreated, postLearningPath(r, "key-1", `{"pathName":"Go Basics"}`).Code)
+	w := postLearningPath(r, "key-1", `{"pathName":"Rust Basics"}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "idempotency_key_reused", decodeProblem(t, w).Code)
+}
+
+func TestCreateLearningPath_FailedRequest_ReleasesIdempotencyKey(t *testing.T) {
+	editor := testutil.NewFakeEditorClient()
+	editor.FailUnavailable(testutil.EditorOpCreate)
+	r, db := newCreateTestRouter(t, editor)
+
+	require.Equal(t, http.StatusServiceUnavailable, postLearningPath(r, "key-1", `{"pathName":"Go Basics"}`).Code)
+
+	editor.Recover(testutil.EditorOpCreate)
+	w := postLearningPath(r, "key-1", `{"pathName":"Go Basics"}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get(controller.IdempotentReplayedHeader))
+	var count int64
+	db.Model(&model.LearningPath{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestCreateLearningPath_ResponseNotStored_RetryAfterLockTimeoutReplaysPath(t *testing.T) {
+	editor := testutil.NewFakeEditorClient()
+	r, db := newCreateTestRouter(t, editor)
+	// Storing the response fails; linking the path to the key does not
+	failComplete := func(tx *gorm.DB) {
+		if updates, ok := tx.Statement.Dest.(map[string]interface{}); ok && updates["response_body"] != nil {
+			tx.AddError(errors.New("connection lost"))
+		}
+	}
+	require.NoError(t, db.Callback().Update().Before("gorm:update").Register("fail_complete", failComplete))
+
+	first := postLearningPath(r, "key-1", `{"pathName":"Go Basics"}`)
+	require.Equal(t, http.StatusCreated, first.Code, "the path exists even though its response was not stored")
+
+	require.NoError(t, db.Callback().Update().Remove("fail_complete"))
+	var record model.IdempotencyRecord
+	require.NoError(t, db.First(&record, "idempotency_key = ?", "key-1").Error)
+	assert.Equal(t, model.IdempotencyStatusInProgress, record.Status)
+	require.NotNil(t, record.LPID, "the path is linked in the transaction that created it")
+	require.NoError(t, db.Model(&record).UpdateColumn("updated_at", time.Now().Add(-time.Hour)).Error)
+
+	retry := postLearningPath(r, "key-1", `{"pathName":"Go Basics"}`)
+
+	require.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(controller.IdempotentReplayedHeader))
+	assert.JSONEq(t, first.Body.String(), retry.Body.String())
+	assert.Len(t, editor.Calls(testutil.EditorOpCreate), 1)
+	var count int64
+	db.Model(&model.LearningPath{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+	require.NoError(t, db.First(&record, "idempotency_key = ?", "key-1").Error)
+	assert.Equal(t, model.IdempotencyStatusCompleted, record.Status, "later retries replay the stored response")
+}
+
+func TestCreateLearningPath_WithoutIdempotencyKey_CreatesEachTime(t *testing.T) {
+	editor := testutil.NewFakeEditorClient()
+	r, db := newCreateTestRouter(t, editor)
+
+	require.Equal(t, http.StatusCreated, postLearningPath(r, "", `{"pathName":"First"}`).Code)
+	require.Equal(t, http.StatusCreated, postLearningPath(r, "", `{"pathName":"Second"}`).Code)
+
+	var count int64
+	db.Model(&model.IdempotencyRecord{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+// ============================================================================
+// IDEMPOTENCY SERVICE
+// ============================================================================
+
+func TestIdempotencyService_KeysAreScopedPerUser(t *testing.T) {
+	svc := service.NewIdempotencyService(testutil.SetupTestDB(t))
+
+	_, err := svc.Begin(context.Background(), 1, "shared-key", "hash-a")
+	require.NoError(t, err)
+	record, err := svc.Begin(context.Background(), 2, "shared-key", "hash-b")
+
+	require.NoError(t, err)
+	assert.Equal(t, model.IdempotencyStatusInProgress, record.Status)
+}
+
+func TestIdempotencyService_ConcurrentRetry_Conflicts(t *testing.T) {
+	svc := service.NewIdempotencyService(testutil.SetupTestDB(t))
+
+	_, err := svc.Begin(context.Background(), 1, "key", "hash")
+	require.NoError(t, err)
+	_, err = svc.Begin(context.Background(), 1, "key", "hash")
+
+	assert.True(t, apperror.IsKind(err, apperror.KindConflict))
+}
+
+func TestIdempotencyService_ExpiredKey_Reclaimed(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewIdempotencyService(db)
+
+	old, err := svc.Begin(context.Background(), 1, "key", "hash-a")
+	require.NoError(t, err)
+	require.NoError(t, svc.Complete(context.Background(), old, http.StatusCreated, []byte(`{}`), nil))
+	require.NoError(t, db.Model(old).Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+	record, err := svc.Begin(context.Background(), 1, "key", "hash-b")
+
+	require.NoError(t, err)
+	assert.Equal(t, model.IdempotencyStatusInProgress, record.Status)
+	assert.NotEqual(t, old.ID, record.ID)
+}
+
+func TestIdempotencyService_AbandonedRequest_Reclaimed(t *testing.T) {
+	svc := service.NewIdempotencyService(testutil.SetupTestDB(t))
+	svc.LockTimeout = 0
+
+	first, err := svc.Begin(context.Background(), 1, "key", "hash")
+	require.NoError(t, err)
+	record, err := svc.Begin(context.Background(), 1, "key", "hash")
+
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, record.ID)
+}
+
+func TestIdempotencyService_PurgeExpired(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewIdempotencyService(db)
+	expired, err := svc.Begin(context.Background(), 1, "old", "hash")
+	require.NoError(t, err)
+	_, err = svc.Begin(context.Background(), 1, "fresh", "hash")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(expired).Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+	purged, err := svc.PurgeExpired(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+}
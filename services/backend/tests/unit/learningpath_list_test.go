@@ -0,0 +1,260 @@
+package unit_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/controller"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// communityRef returns a reference to the community with the given name, creating it if needed
+func communityRef(t *testing.T, db *gorm.DB, name string) *uuid.UUID {
+	t.Helper()
+	id := testutil.CommunityID(t, db, name)
+	return &id
+}
+
+// seedListLP inserts a learning path in "Cloud and Backend" created at base+offset
+func seedListLP(t *testing.T, db *gorm.DB, title string, offset time.Duration, mutate func(*model.LearningPath)) model.LearningPath {
+	t.Helper()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	lp := model.LearningPath{
+		ID:          uuid.New(),
+		Title:       title,
+		IsPublic:    true,
+		CommunityID: communityRef(t, db, "Cloud and Backend"),
+		DiagramID:   uuid.NewString()[:24],
+		CreatedAt:   base.Add(offset),
+		UpdatedAt:   base.Add(offset),
+	}
+	if mutate != nil {
+		mutate(&lp)
+	}
+	require.NoError(t, db.Create(&lp).Error)
+	return lp
+}
+
+func listTitles(page *service.LearningPathPage) []string {
+	titles := make([]string, len(page.Items))
+	for i, lp := range page.Items {
+		titles[i] = lp.Title
+	}
+	return titles
+}
+
+func TestListLearningPaths_CursorWalksAllPages(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient())
+	for i := 0; i < 5; i++ {
+		seedListLP(t, db, fmt.Sprintf("LP %d", i), time.Duration(i)*time.Hour, nil)
+	}
+
+	var titles []string
+	query := service.LearningPathQuery{Limit: 2}
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 5, "pagination did not terminate")
+		page, err := svc.ListLearningPaths(context.Background(), query)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), page.Total)
+		titles = append(titles, listTitles(page)...)
+		if page.NextCursor == nil {
+			break
+		}
+		query.Cursor = *page.NextCursor
+	}
+
+	// Newest first by default
+	assert.Equal(t, []string{"LP 4", "LP 3", "LP 2", "LP 1", "LP 0"}, titles)
+}
+
+func TestListLearningPaths_TitleSortBreaksTiesByID(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient())
+	for i := 0; i < 3; i++ {
+		seedListLP(t, db, "Same Title", time.Duration(i)*time.Hour, nil)
+	}
+	seedListLP(t, db, "Another", 0, nil)
+
+	first, err := svc.ListLearningPaths(context.Background(), service.LearningPathQuery{Sort: service.LearningPathSortTitle, Limit: 2})
+	require.NoError(t, err)
+	require.NotNil(t, first.NextCursor)
+	second, err := svc.ListLearningPaths(context.Background(), service.LearningPathQuery{Sort: service.LearningPathSortTitle, Limit: 2, Cursor: *first.NextCursor})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Another", "Same Title"}, listTitles(first))
+	assert.Equal(t, []string{"Same Title", "Same Title"}, listTitles(second))
+	assert.Nil(t, second.NextCursor)
+	assert.NotEqual(t, first.Items[1].ID, second.Items[0].ID)
+	assert.NotEqual(t, first.Items[1].ID, second.Items[1].ID)
+}
+
+func TestListLearningPaths_PopularitySortsByFavorites(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient())
+	quiet := seedListLP(t, db, "Quiet", 0, nil)
+	popular := seedListLP(t, db, "Popular", time.Hour, nil)
+	seedListLP(t, db, "Unloved", 2*time.Hour, nil)
+	for userID := uint(1); userID <= 3; userID++ {
+		require.NoError(t, db.Create(&model.UserLP{UserID: userID, LPID: popular.ID, IsFavorite: true}).Error)
+	}
+	require.NoError(t, db.Create(&model.UserLP{UserID: 1, LPID: quiet.ID, IsFavorite: true}).Error)
+	require.NoError(t, db.Create(&model.UserLP{UserID: 2, LPID: quiet.ID, IsFavorite: false}).Error)
+
+	first, err := svc.ListLearningPaths(context.Background(), service.LearningPathQuery{Sort: service.LearningPathSortPopularity, Limit: 2})
+	require.NoError(t, err)
+	require.NotNil(t, first.NextCursor)
+	second, err := svc.ListLearningPaths(context.Background(), service.LearningPathQuery{Sort: service.LearningPathSortPopularity, Limit: 2, Cursor: *first.NextCursor})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Popular", "Quiet"}, listTitles(first))
+	assert.Equal(t, []string{"Unloved"}, listTitles(second))
+}
+
+func TestListLearningPaths_Filters(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient())
+	author := uint(7)
+	goLP := seedListLP(t, db, "Go", 0, func(lp *model.LearningPath) { lp.CreatedByID = &author })
+	privateLP := seedListLP(t, db, "Private", time.Hour, func(lp *model.LearningPath) { lp.IsPublic = false })
+	designLP := seedListLP(t, db, "Design", 2*time.Hour, func(lp *model.LearningPath) { lp.CommunityID = communityRef(t, db, "Design") })
+	require.NoError(t, db.Create(&model.UserLP{UserID: author, LPID: designLP.ID, IsFavorite: true}).Error)
+	require.NoError(t, db.Create(&model.UserLP{UserID: author, LPID: goLP.ID}).Error)
+	skill := model.Skill{Name: "Golang"}
+	require.NoError(t, db.Create(&skill).Error)
+	require.NoError(t, db.Create(&model.LPSkill{LPID: goLP.ID, SkillID: skill.ID}).Error)
+
+	private := false
+	after := time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC)
+	before := time.Date(2025, 1, 1, 2, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		query service.LearningPathQuery
+		want  []string
+	}{
+		{"community", service.LearningPathQuery{Community: "Design"}, []string{"Design"}},
+		{"skill is case-insensitive", service.LearningPathQuery{Skills: []string{"golang"}}, []string{"Go"}},
+		{"private", service.LearningPathQuery{IsPublic: &private}, []string{"Private"}},
+		{"created by", service.LearningPathQuery{CreatedByID: &author}, []string{"Go"}},
+		{"created range", service.LearningPathQuery{CreatedAfter: &after, CreatedBefore: &before}, []string{"Private"}},
+		{"favorited", service.LearningPathQuery{FavoritedBy: &author}, []string{"Design"}},
+		{"ids", service.LearningPathQuery{IDs: []uuid.UUID{goLP.ID, privateLP.ID}}, []string{"Private", "Go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.query.Viewer = service.Viewer{IsAdmin: true}
+			page, err := svc.ListLearningPaths(context.Background(), tt.query)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, listTitles(page))
+			assert.Equal(t, int64(len(tt.want)), page.Total)
+		})
+	}
+}
+
+func TestListLearningPaths_CursorFromOtherSort_Rejected(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient())
+	seedListLP(t, db, "A", 0, nil)
+	seedListLP(t, db, "B", time.Hour, nil)
+
+	page, err := svc.ListLearningPaths(context.Background(), service.LearningPathQuery{Limit: 1})
+	require.NoError(t, err)
+	require.NotNil(t, page.NextCursor)
+	_, err = svc.ListLearningPaths(context.Background(), service.LearningPathQuery{Sort: service.LearningPathSortTitle, Cursor: *page.NextCursor})
+
+	assert.True(t, apperror.IsKind(err, apperror.KindValidation))
+	_, err = svc.ListLearningPaths(context.Background(), service.LearningPathQuery{Cursor: "not-a-cursor"})
+	assert.True(t, apperror.IsKind(err, apperror.KindValidation))
+}
+
+// ============================================================================
+// LISTING ENDPOINTS
+// ============================================================================
+
+func newListTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
+	db := testutil.SetupTestDB(t)
+	ctrl := controller.NewLearningPathController(service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient()), service.NewIdempotencyService(db))
+
+	r := newErrorTestRouter()
+	setUser := func(c *gin.Context) {
+		c.Set("user", &model.User{Model: gorm.Model{ID: 7}, CommunityID: communityRef(t, db, "Cloud and Backend")})
+	}
+	r.GET("/api/learning-paths", setUser, ctrl.Index)
+	r.GET("/api/communities/:communityname/learning-paths", setUser, ctrl.GetByCommunity)
+	return r, db
+}
+
+func TestLearningPathController_GetByCommunity_ReturnsEnvelope(t *testing.T) {
+	r, db := newListTestRouter(t)
+	author := uint(7)
+	seedListLP(t, db, "Mine", 0, func(lp *model.LearningPath) { lp.CreatedByID = &author })
+	seedListLP(t, db, "Theirs", time.Hour, nil)
+	seedListLP(t, db, "Elsewhere", 2*time.Hour, func(lp *model.LearningPath) { lp.CommunityID = communityRef(t, db, "Design") })
+
+	w := doRequest(r, http.MethodGet, "/api/communities/Cloud%20and%20Backend/learning-paths?limit=1", "", nil)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var page service.LearningPathPage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, []string{"Theirs"}, listTitles(&page))
+	assert.Equal(t, int64(2), page.Total)
+	require.NotNil(t, page.NextCursor)
+
+	w = doRequest(r, http.MethodGet, "/api/learning-paths?createdBy=me", "", nil)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, []string{"Mine"}, listTitles(&page))
+	assert.Nil(t, page.NextCursor)
+}
+
+func TestLearningPathController_GetByCommunity_FavoritedAndIDs(t *testing.T) {
+	r, db := newListTestRouter(t)
+	mine := seedListLP(t, db, "Bookmarked", 0, nil)
+	other := seedListLP(t, db, "Bookmarked by someone else", time.Hour, nil)
+	viewed := seedListLP(t, db, "Viewed", 2*time.Hour, nil)
+	require.NoError(t, db.Create(&model.UserLP{UserID: 7, LPID: mine.ID, IsFavorite: true}).Error)
+	require.NoError(t, db.Create(&model.UserLP{UserID: 8, LPID: other.ID, IsFavorite: true}).Error)
+	path := "/api/communities/Cloud%20and%20Backend/learning-paths"
+
+	w := doRequest(r, http.MethodGet, path+"?favorited=true", "", nil)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var page service.LearningPathPage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, []string{"Bookmarked"}, listTitles(&page))
+
+	w = doRequest(r, http.MethodGet, path+"?sort=title&id="+viewed.ID.String()+","+mine.ID.String(), "", nil)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, []string{"Bookmarked", "Viewed"}, listTitles(&page))
+}
+
+func TestLearningPathController_Index_InvalidQuery_ReportsFields(t *testing.T) {
+	r, _ := newListTestRouter(t)
+
+	w := doRequest(r, http.MethodGet, "/api/learning-paths?limit=500&visibility=hidden&favorited=maybe&id=42&createdAfter=yesterday", "", nil)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	problem := decodeProblem(t, w)
+	assert.Equal(t, "invalid_query", problem.Code)
+	fields := make([]string, len(problem.Errors))
+	for i, fe := range problem.Errors {
+		fields[i] = fe.Field
+	}
+	assert.Equal(t, []string{"limit", "visibility", "favorited", "id", "createdAfter"}, fields)
+}
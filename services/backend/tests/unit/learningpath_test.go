@@ -2,17 +2,14 @@ package unit_test
 
 import (
 	"context"
-	"errors"
-	"net/http"
-	"strings"
 	"testing"
+	"time"
 
 	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
 	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
 	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -23,14 +20,10 @@ import (
 func TestCreateLearningPath_ValidData_Success(t *testing.T) {
 	// Setup
 	db := testutil.SetupTestDB(t)
-	mockHTTP := new(testutil.MockHTTPClient)
+	editor := testutil.NewFakeEditorClient()
+	editor.NextDiagramIDs = []string{"mongo123"}
 
-	// Mock successful diagram creation (201 Created)
-	mockHTTP.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-		return req.Method == http.MethodPost && strings.Contains(req.URL.Path, "diagrams/by-lp")
-	})).Return(testutil.CreateMockHTTPResponse(201, `{"_id":"mongo123","learningPathId":"test-uuid","name":"Test LP"}`), nil).Once()
-
-	svc := service.NewLearningPathServiceWithClient(db, mockHTTP, "http://test:3001/api")
+	svc := service.NewLearningPathServiceWithEditor(db, editor)
 
 	// Execute
 	lp, err := svc.CreateLearningPath(
@@ -41,7 +34,8 @@ func TestCreateLearningPath_ValidData_Success(t *testing.T) {
 		"thumbnail.png",
 		[]string{"Go", "Testing"},
 		"auth-token",
-		"test-community",
+		testutil.CommunityID(t, db, "test-community"),
+		0,
 	)
 
 	// Assert
@@ -51,9 +45,14 @@ func TestCreateLearningPath_ValidData_Success(t *testing.T) {
 	assert.Equal(t, "Test Description", lp.Description)
 	assert.Equal(t, true, lp.IsPublic)
 	assert.Equal(t, "mongo123", lp.DiagramID)
-	assert.Equal(t, "test-community", lp.Community)
+	assert.Equal(t, testutil.CommunityID(t, db, "test-community"), *lp.CommunityID)
 	assert.Len(t, lp.SkillsList, 2)
-	mockHTTP.AssertExpectations(t)
+
+	// The user token is forwarded to backend-editor
+	calls := editor.Calls(testutil.EditorOpCreate)
+	require.Len(t, calls, 1)
+	assert.Equal(t, "auth-token", calls[0].AuthToken)
+	assert.Equal(t, lp.ID.String(), calls[0].LPID)
 
 	// Verify LP exists in database
 	var dbLP model.LearningPath
@@ -62,17 +61,13 @@ func TestCreateLearningPath_ValidData_Success(t *testing.T) {
 	assert.Equal(t, "Test LP", dbLP.Title)
 }
 
-func TestCreateLearningPath_DuplicateName_ReturnsError(t *testing.T) {
+func TestCreateLearningPath_DuplicateName_ReturnsConflict(t *testing.T) {
 	// Setup
 	db := testutil.SetupTestDB(t)
-	mockHTTP := new(testutil.MockHTTPClient)
-
-	// Mock diagram creation returning 409 Conflict (duplicate name)
-	mockHTTP.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-		return req.Method == http.MethodPost
-	})).Return(testutil.CreateMockHTTPResponse(409, `{"error":"A learning path with this name already exists"}`), nil).Once()
+	editor := testutil.NewFakeEditorClient()
+	editor.AddDiagram(service.Diagram{ID: "mongo-existing", LearningPathID: uuid.New().String(), Name: "Existing LP"})
 
-	svc := service.NewLearningPathServiceWithClient(db, mockHTTP, "http://test:3001/api")
+	svc := service.NewLearningPathServiceWithEditor(db, editor)
 
 	// Execute
 	lp, err := svc.CreateLearningPath(
@@ -83,28 +78,24 @@ func TestCreateLearningPath_DuplicateName_ReturnsError(t *testing.T) {
 		"",
 		[]string{},
 		"token",
-		"community",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
 
 	// Assert
 	require.Error(t, err)
 	assert.Nil(t, lp)
 	assert.Contains(t, err.Error(), "saga step 1 failed")
-	assert.Contains(t, err.Error(), "already exists")
-	mockHTTP.AssertExpectations(t)
+	assert.True(t, service.IsEditorError(err, service.EditorErrConflict))
 }
 
 func TestCreateLearningPath_MongoDBUnavailable_NoOrphanCreated(t *testing.T) {
 	// Setup
 	db := testutil.SetupTestDB(t)
-	mockHTTP := new(testutil.MockHTTPClient)
+	editor := testutil.NewFakeEditorClient()
+	editor.FailUnavailable(testutil.EditorOpCreate)
 
-	// Mock MongoDB connection failure
-	mockHTTP.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-		return req.Method == http.MethodPost
-	})).Return(nil, errors.New("connection refused")).Once()
-
-	svc := service.NewLearningPathServiceWithClient(db, mockHTTP, "http://test:3001/api")
+	svc := service.NewLearningPathServiceWithEditor(db, editor)
 
 	// Execute
 	lp, err := svc.CreateLearningPath(
@@ -115,14 +106,15 @@ func TestCreateLearningPath_MongoDBUnavailable_NoOrphanCreated(t *testing.T) {
 		"",
 		[]string{},
 		"token",
-		"community",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
 
 	// Assert
 	require.Error(t, err)
 	assert.Nil(t, lp)
 	assert.Contains(t, err.Error(), "saga step 1 failed")
-	mockHTTP.AssertExpectations(t)
+	assert.True(t, service.IsEditorError(err, service.EditorErrUnavailable))
 
 	// Verify NO learning path was created (no orphan)
 	var count int64
@@ -133,28 +125,19 @@ func TestCreateLearningPath_MongoDBUnavailable_NoOrphanCreated(t *testing.T) {
 func TestCreateLearningPath_PostgreSQLFails_CompensationRuns(t *testing.T) {
 	// Setup - create a database with unique constraint
 	db := testutil.SetupTestDBWithUniqueIndex(t)
-	mockHTTP := new(testutil.MockHTTPClient)
+	editor := testutil.NewFakeEditorClient()
 
 	// First, create an LP with a specific diagram ID to cause unique constraint violation
 	existingLP := model.LearningPath{
 		ID:        uuid.New(),
 		Title:     "Existing",
-		DiagramID: "mongo123", // Same diagram ID we'll try to use
+		DiagramID: "mongo123", // Same diagram ID the editor will hand out
 		IsPublic:  true,
 	}
 	require.NoError(t, db.Create(&existingLP).Error)
+	editor.NextDiagramIDs = []string{"mongo123"}
 
-	// Mock successful diagram creation (201 Created)
-	mockHTTP.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-		return req.Method == http.MethodPost && strings.Contains(req.URL.Path, "diagrams/by-lp")
-	})).Return(testutil.CreateMockHTTPResponse(201, `{"_id":"mongo123","learningPathId":"test-uuid","name":"Test LP"}`), nil).Once()
-
-	// Mock compensation delete (should be called when PostgreSQL fails)
-	mockHTTP.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-		return req.Method == http.MethodDelete && strings.Contains(req.URL.Path, "diagrams/by-lp")
-	})).Return(testutil.CreateMockHTTPResponse(204, ""), nil).Once()
-
-	svc := service.NewLearningPathServiceWithClient(db, mockHTTP, "http://test:3001/api")
+	svc := service.NewLearningPathServiceWithEditor(db, editor)
 
 	// Execute - this should fail because DiagramID is unique and already exists
 	lp, err := svc.CreateLearningPath(
@@ -165,7 +148,8 @@ func TestCreateLearningPath_PostgreSQLFails_CompensationRuns(t *testing.T) {
 		"",
 		[]string{},
 		"token",
-		"community",
+		testutil.CommunityID(t, db, "community"),
+		0,
 	)
 
 	// Assert
@@ -173,8 +157,9 @@ func TestCreateLearningPath_PostgreSQLFails_CompensationRuns(t *testing.T) {
 	assert.Nil(t, lp)
 	assert.Contains(t, err.Error(), "saga step 2 failed")
 
-	// Verify compensation DELETE was called
-	mockHTTP.AssertExpectations(t)
+	// Verify compensation removed the diagram
+	assert.Len(t, editor.Calls(testutil.EditorOpDelete), 1)
+	assert.Equal(t, 0, editor.DiagramCount())
 }
 
 // ============================================================================
@@ -184,7 +169,7 @@ func TestCreateLearningPath_PostgreSQLFails_CompensationRuns(t *testing.T) {
 func TestDeleteLearningPath_ValidID_Success(t *testing.T) {
 	// Setup
 	db := testutil.SetupTestDB(t)
-	mockHTTP := new(testutil.MockHTTPClient)
+	editor := testutil.NewFakeEditorClient()
 
 	// Create an existing LP to delete
 	lpID := uuid.New()
@@ -195,125 +180,107 @@ func TestDeleteLearningPath_ValidID_Success(t *testing.T) {
 		IsPublic:  true,
 	}
 	db.Create(&existingLP)
+	editor.AddDiagram(service.Diagram{ID: "mongo456", LearningPathID: lpID.String(), Name: "To Delete"})
 
-	// Mock successful diagram deletion (204 No Content)
-	mockHTTP.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-		return req.Method == http.MethodDelete && strings.Contains(req.URL.Path, "diagrams/by-lp")
-	})).Return(testutil.CreateMockHTTPResponse(204, ""), nil).Once()
-
-	svc := service.NewLearningPathServiceWithClient(db, mockHTTP, "http://test:3001/api")
+	svc := service.NewLearningPathServiceWithEditor(db, editor)
+	svc.ServiceToken = "service-token"
 
 	// Execute
-	err := svc.DeleteLearningPath(context.Background(), lpID.String(), "token")
+	err := svc.DeleteLearningPath(context.Background(), lpID.String())
+	require.NoError(t, err)
+
+	// LP is hidden immediately; the diagram is deleted by the outbox dispatcher
+	var count int64
+	db.Model(&model.LearningPath{}).Where("id = ?", lpID).Count(&count)
+	assert.Equal(t, int64(0), count)
+	assert.Empty(t, editor.Calls(""))
+
+	delivered, err := service.NewOutboxDispatcher(svc).DispatchPending(context.Background())
 
 	// Assert
 	require.NoError(t, err)
-	mockHTTP.AssertExpectations(t)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, 0, editor.DiagramCount())
 
 	// Verify LP is deleted (both soft and hard delete)
-	var count int64
 	db.Unscoped().Model(&model.LearningPath{}).Where("id = ?", lpID).Count(&count)
 	assert.Equal(t, int64(0), count)
 }
 
-func TestDeleteLearningPath_MongoDBUnavailable_LPRestored(t *testing.T) {
+func TestDeleteLearningPath_MongoDBUnavailable_RetriedLater(t *testing.T) {
 	// Setup
 	db := testutil.SetupTestDB(t)
-	mockHTTP := new(testutil.MockHTTPClient)
+	editor := testutil.NewFakeEditorClient()
+	editor.FailUnavailable(testutil.EditorOpDelete)
 
 	// Create an existing LP
 	lpID := uuid.New()
 	existingLP := model.LearningPath{
 		ID:        lpID,
-		Title:     "To Restore",
+		Title:     "To Delete Later",
 		DiagramID: "mongo789",
 		IsPublic:  true,
 	}
 	db.Create(&existingLP)
 
-	// Mock MongoDB connection failure during deletion
-	mockHTTP.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-		return req.Method == http.MethodDelete
-	})).Return(nil, errors.New("connection refused")).Once()
+	svc := service.NewLearningPathServiceWithEditor(db, editor)
+	svc.ServiceToken = "service-token"
 
-	svc := service.NewLearningPathServiceWithClient(db, mockHTTP, "http://test:3001/api")
+	// Execute - the request succeeds even though backend-editor is down
+	err := svc.DeleteLearningPath(context.Background(), lpID.String())
+	require.NoError(t, err)
 
-	// Execute
-	err := svc.DeleteLearningPath(context.Background(), lpID.String(), "token")
+	delivered, err := service.NewOutboxDispatcher(svc).DispatchPending(context.Background())
 
 	// Assert
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "saga step 2 failed")
-	assert.Contains(t, err.Error(), "LP restored")
-	mockHTTP.AssertExpectations(t)
-
-	// Verify LP was restored (not soft-deleted)
-	var restoredLP model.LearningPath
-	err = db.First(&restoredLP, "id = ?", lpID).Error
 	require.NoError(t, err)
-	assert.Equal(t, "To Restore", restoredLP.Title)
-	assert.True(t, restoredLP.DeletedAt.Time.IsZero())
+	assert.Equal(t, 0, delivered)
+	assert.Len(t, editor.Calls(testutil.EditorOpDelete), 1)
+
+	// LP stays soft-deleted and the message is scheduled for a retry
+	var lp model.LearningPath
+	require.NoError(t, db.Unscoped().First(&lp, "id = ?", lpID).Error)
+	assert.True(t, lp.DeletedAt.Valid)
+
+	var msg model.OutboxMessage
+	require.NoError(t, db.First(&msg, "aggregate_id = ?", lpID).Error)
+	assert.Equal(t, model.OutboxStatusPending, msg.Status)
+	assert.Equal(t, 1, msg.Attempts)
+	assert.Contains(t, msg.LastError, "connection refused")
+	assert.True(t, msg.NextAttemptAt.After(time.Now()))
 }
 
 func TestDeleteLearningPath_NotFound_ReturnsError(t *testing.T) {
 	// Setup
 	db := testutil.SetupTestDB(t)
-	mockHTTP := new(testutil.MockHTTPClient)
+	editor := testutil.NewFakeEditorClient()
 
-	svc := service.NewLearningPathServiceWithClient(db, mockHTTP, "http://test:3001/api")
+	svc := service.NewLearningPathServiceWithEditor(db, editor)
 
 	// Execute with non-existent LP ID
-	err := svc.DeleteLearningPath(context.Background(), uuid.New().String(), "token")
+	err := svc.DeleteLearningPath(context.Background(), uuid.New().String())
 
 	// Assert
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "not found")
 
-	// Verify no HTTP calls were made (should fail before reaching MongoDB)
-	mockHTTP.AssertNotCalled(t, "Do")
+	// Verify no editor calls were made and nothing was enqueued
+	assert.Empty(t, editor.Calls(""))
+	var count int64
+	db.Model(&model.OutboxMessage{}).Count(&count)
+	assert.Equal(t, int64(0), count)
 }
 
 // ============================================================================
 // IDEMPOTENCY TESTS
 // ============================================================================
 
-func TestCreateLearningPath_IdempotentRetry_Returns200(t *testing.T) {
-	// Setup
-	db := testutil.SetupTestDB(t)
-	mockHTTP := new(testutil.MockHTTPClient)
-
-	// Mock idempotent response (200 OK - already exists)
-	mockHTTP.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-		return req.Method == http.MethodPost && strings.Contains(req.URL.Path, "diagrams/by-lp")
-	})).Return(testutil.CreateMockHTTPResponse(200, `{"_id":"mongo-existing","learningPathId":"existing-uuid","name":"Existing LP"}`), nil).Once()
-
-	svc := service.NewLearningPathServiceWithClient(db, mockHTTP, "http://test:3001/api")
-
-	// Execute
-	lp, err := svc.CreateLearningPath(
-		context.Background(),
-		"Existing LP",
-		"Description",
-		true,
-		"",
-		[]string{},
-		"token",
-		"community",
-	)
-
-	// Assert - should succeed with idempotent response
-	require.NoError(t, err)
-	require.NotNil(t, lp)
-	assert.Equal(t, "mongo-existing", lp.DiagramID)
-	mockHTTP.AssertExpectations(t)
-}
-
 func TestDeleteLearningPath_DiagramNotFound_TreatedAsSuccess(t *testing.T) {
 	// Setup
 	db := testutil.SetupTestDB(t)
-	mockHTTP := new(testutil.MockHTTPClient)
+	editor := testutil.NewFakeEditorClient()
 
-	// Create an existing LP
+	// Create an existing LP whose diagram is already gone
 	lpID := uuid.New()
 	existingLP := model.LearningPath{
 		ID:        lpID,
@@ -323,19 +290,19 @@ func TestDeleteLearningPath_DiagramNotFound_TreatedAsSuccess(t *testing.T) {
 	}
 	db.Create(&existingLP)
 
-	// Mock diagram not found (404 - idempotent, treated as already deleted)
-	mockHTTP.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-		return req.Method == http.MethodDelete
-	})).Return(testutil.CreateMockHTTPResponse(404, `{"error":"not found"}`), nil).Once()
-
-	svc := service.NewLearningPathServiceWithClient(db, mockHTTP, "http://test:3001/api")
+	svc := service.NewLearningPathServiceWithEditor(db, editor)
+	svc.ServiceToken = "service-token"
 
 	// Execute
-	err := svc.DeleteLearningPath(context.Background(), lpID.String(), "token")
+	err := svc.DeleteLearningPath(context.Background(), lpID.String())
+	require.NoError(t, err)
+
+	delivered, err := service.NewOutboxDispatcher(svc).DispatchPending(context.Background())
 
-	// Assert - should succeed because 404 is treated as already deleted
+	// Assert - should succeed because NotFound is treated as already deleted
 	require.NoError(t, err)
-	mockHTTP.AssertExpectations(t)
+	assert.Equal(t, 1, delivered)
+	assert.Len(t, editor.Calls(testutil.EditorOpDelete), 1)
 
 	// Verify LP is deleted
 	var count int64
@@ -0,0 +1,222 @@
+package unit_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/controller"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// moderatorViewer moderates accessTestCommunity without belonging to it
+func moderatorViewer(t *testing.T, db *gorm.DB, userID uint) service.Viewer {
+	return service.Viewer{UserID: userID, ModeratedCommunityIDs: []uuid.UUID{testutil.CommunityID(t, db, accessTestCommunity)}}
+}
+
+func TestAuthorize_ModeratorManagesPathsOfTheirCommunity(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient())
+	private := seedPrivateLP(t, db, "Private", 7)
+	elsewhere := seedOwnedLP(t, db, "Elsewhere", 7, func(lp *model.LearningPath) { lp.CommunityID = communityRef(t, db, "Connectivity") })
+	moderator := moderatorViewer(t, db, 1)
+
+	for _, action := range []service.LearningPathAction{service.ActionView, service.ActionUpdate, service.ActionDelete, service.ActionFeature, service.ActionReviewPublication} {
+		_, err := svc.Authorize(context.Background(), moderator, private.ID.String(), action)
+		assert.NoError(t, err, action)
+	}
+	_, err := svc.Authorize(context.Background(), moderator, private.ID.String(), service.ActionManageCollaborators)
+	assert.True(t, apperror.IsKind(err, apperror.KindForbidden), "moderators do not share paths")
+	_, err = svc.Authorize(context.Background(), moderator, elsewhere.ID.String(), service.ActionUpdate)
+	assert.True(t, apperror.IsKind(err, apperror.KindForbidden), "only their own community")
+	_, err = svc.Authorize(context.Background(), service.Viewer{UserID: 7}, private.ID.String(), service.ActionFeature)
+	assert.True(t, apperror.IsKind(err, apperror.KindForbidden), "owners cannot feature")
+
+	page, err := svc.ListLearningPaths(context.Background(), service.LearningPathQuery{Viewer: moderator})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Private", "Elsewhere"}, listTitles(page))
+}
+
+func TestSetFeatured(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient())
+	featured := seedOwnedLP(t, db, "Featured", 7, nil)
+	seedOwnedLP(t, db, "Plain", 7, nil)
+	private := seedPrivateLP(t, db, "Private", 7)
+	moderator := moderatorViewer(t, db, 1)
+
+	lp, err := svc.SetFeatured(context.Background(), moderator, featured.ID.String(), true)
+	require.NoError(t, err)
+	assert.NotNil(t, lp.FeaturedAt)
+	_, err = svc.SetFeatured(context.Background(), moderator, private.ID.String(), true)
+	assert.True(t, apperror.IsKind(err, apperror.KindConflict))
+
+	isFeatured := true
+	page, err := svc.ListLearningPaths(context.Background(), service.LearningPathQuery{Featured: &isFeatured})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Featured"}, listTitles(page))
+
+	lp, err = svc.SetFeatured(context.Background(), moderator, featured.ID.String(), false)
+	require.NoError(t, err)
+	assert.Nil(t, lp.FeaturedAt)
+}
+
+// ============================================================================
+// PUBLICATION APPROVAL
+// ============================================================================
+
+// newPublicationTestRouter serves update and publication review. The acting user is looked up
+// by the X-User header: "owner" (ID 7, a member of accessTestCommunity) or "moderator" (ID 1).
+func newPublicationTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
+	db := testutil.SetupTestDB(t)
+	require.NoError(t, db.Model(&model.Community{}).Where("name = ?", accessTestCommunity).Update("requires_approval", true).Error)
+	communityID := testutil.CommunityID(t, db, accessTestCommunity)
+	users := map[string]*model.User{
+		"owner":     {Model: gorm.Model{ID: 7}, CommunityID: &communityID},
+		"moderator": {Model: gorm.Model{ID: 1}, Moderates: []model.CommunityModerator{{UserID: 1, CommunityID: communityID}}},
+	}
+	ctrl := controller.NewLearningPathController(service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient()), service.NewIdempotencyService(db))
+
+	r := newErrorTestRouter()
+	setUser := func(c *gin.Context) { c.Set("user", users[c.GetHeader("X-User")]) }
+	r.PUT("/api/learning-paths/:id", setUser, ctrl.Update)
+	r.POST("/api/learning-paths/:id/publication/approve", setUser, ctrl.ApprovePublication)
+	r.POST("/api/learning-paths/:id/publication/reject", setUser, ctrl.RejectPublication)
+	return r, db
+}
+
+func TestLearningPathController_PublicationNeedsModeratorApproval(t *testing.T) {
+	r, db := newPublicationTestRouter(t)
+	lp := seedPrivateLP(t, db, "Draft", 7)
+	path := "/api/learning-paths/" + lp.ID.String()
+	owner := map[string]string{"X-User": "owner"}
+	moderator := map[string]string{"X-User": "moderator"}
+
+	w := doRequest(r, http.MethodPut, path, `{"title":"Draft","isPublic":true}`, owner)
+	require.Equal(t, http.StatusOK, w.Code)
+	var pending model.LearningPath
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &pending))
+	assert.False(t, pending.IsPublic)
+	assert.Equal(t, model.PublicationPending, pending.PublicationStatus)
+
+	w = doRequest(r, http.MethodPost, path+"/publication/approve", "", owner)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = doRequest(r, http.MethodPost, path+"/publication/approve", "", moderator)
+	require.Equal(t, http.StatusOK, w.Code)
+	var approved model.LearningPath
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &approved))
+	assert.True(t, approved.IsPublic)
+	assert.Equal(t, model.PublicationApproved, approved.PublicationStatus)
+
+	w = doRequest(r, http.MethodPost, path+"/publication/reject", "", moderator)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "publication_not_pending", decodeProblem(t, w).Code)
+}
+
+func TestLearningPathController_CreateRequestsPublicationWithThePath(t *testing.T) {
+	r, db := newCreateTestRouter(t, testutil.NewFakeEditorClient())
+	require.NoError(t, db.Model(&model.Community{}).Where("name = ?", idempotencyTestCommunity).Update("requires_approval", true).Error)
+
+	w := postLearningPath(r, "", `{"pathName":"Draft"}`)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created model.LearningPath
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.False(t, created.IsPublic)
+	assert.Equal(t, model.PublicationPending, created.PublicationStatus)
+	var stored model.LearningPath
+	require.NoError(t, db.First(&stored, "id = ?", created.ID).Error)
+	assert.Equal(t, model.PublicationPending, stored.PublicationStatus)
+}
+
+func TestUpdateAndRequestPublication_MarksPendingWithTheUpdate(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient())
+	lp := seedPrivateLP(t, db, "Draft", 7)
+
+	updated, err := svc.UpdateAndRequestPublication(context.Background(), lp.ID.String(), "Renamed", "Ready for review")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", updated.Title)
+	assert.False(t, updated.IsPublic)
+	assert.Equal(t, model.PublicationPending, updated.PublicationStatus)
+
+	_, err = svc.UpdateAndRequestPublication(context.Background(), uuid.NewString(), "Missing", "")
+	assert.True(t, apperror.IsKind(err, apperror.KindNotFound))
+	var pending int64
+	require.NoError(t, db.Model(&model.LearningPath{}).Where("publication_status = ?", model.PublicationPending).Count(&pending).Error)
+	assert.Equal(t, int64(1), pending)
+}
+
+func TestLearningPathController_ModeratorPublishesDirectly(t *testing.T) {
+	r, db := newPublicationTestRouter(t)
+	lp := seedPrivateLP(t, db, "Draft", 7)
+
+	w := doRequest(r, http.MethodPut, "/api/learning-paths/"+lp.ID.String(), `{"title":"Edited by moderator","isPublic":true}`, map[string]string{"X-User": "moderator"})
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var stored model.LearningPath
+	require.NoError(t, db.First(&stored, "id = ?", lp.ID).Error)
+	assert.True(t, stored.IsPublic)
+	assert.Equal(t, "Edited by moderator", stored.Title)
+	assert.Empty(t, stored.PublicationStatus)
+}
+
+// ============================================================================
+// MODERATOR APPOINTMENT
+// ============================================================================
+
+func TestCommunityService_Moderators(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewCommunityService(db)
+	mia := seedUser(t, db, "Mia", "mia@example.com")
+	id := testutil.CommunityID(t, db, accessTestCommunity).String()
+
+	moderator, err := svc.AddModerator(context.Background(), id, "MIA@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, mia.ID, moderator.UserID)
+	_, err = svc.AddModerator(context.Background(), id, "mia@example.com")
+	require.NoError(t, err, "appointing twice changes nothing")
+	_, err = svc.AddModerator(context.Background(), id, "nobody@example.com")
+	assert.True(t, apperror.IsKind(err, apperror.KindNotFound))
+
+	moderators, err := svc.GetModerators(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, []service.Moderator{{UserID: mia.ID, Name: "Mia", Email: "mia@example.com"}}, moderators)
+
+	require.NoError(t, svc.RemoveModerator(context.Background(), id, mia.ID))
+	assert.True(t, apperror.IsKind(svc.RemoveModerator(context.Background(), id, mia.ID), apperror.KindNotFound))
+}
+
+func TestUserController_GetCurrentUser_ExposesModeratedCommunities(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	ctrl := controller.NewUserController(service.NewUserService(db))
+	community := &model.Community{Name: accessTestCommunity}
+	r := newErrorTestRouter()
+	r.GET("/api/user/me", func(c *gin.Context) {
+		c.Set("user", &model.User{Model: gorm.Model{ID: 1}, Email: "mia@example.com", Moderates: []model.CommunityModerator{{UserID: 1, Community: community}}})
+	}, ctrl.GetCurrentUser)
+
+	w := doRequest(r, http.MethodGet, "/api/user/me", "", nil)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var me struct {
+		IsAdmin              bool
+		IsModerator          bool
+		ModeratedCommunities []string
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &me))
+	assert.False(t, me.IsAdmin)
+	assert.True(t, me.IsModerator)
+	assert.Equal(t, []string{accessTestCommunity}, me.ModeratedCommunities)
+}
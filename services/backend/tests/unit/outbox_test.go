@@ -0,0 +1,173 @@
+package unit_test
+
+import (
+	"context"
+	"testing"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newOutboxTestService(db *gorm.DB, editor service.EditorClient) *service.LearningPathService {
+	svc := service.NewLearningPathServiceWithEditor(db, editor)
+	svc.ServiceToken = "service-token"
+	return svc
+}
+
+// createOutboxTestLP creates an LP together with its diagram in the fake editor
+func createOutboxTestLP(t *testing.T, db *gorm.DB, editor *testutil.FakeEditorClient) model.LearningPath {
+	t.Helper()
+	lp := model.LearningPath{ID: uuid.New(), Title: "Original", DiagramID: "diagram-" + uuid.New().String()[:8], IsPublic: true}
+	require.NoError(t, db.Create(&lp).Error)
+	editor.AddDiagram(service.Diagram{ID: lp.DiagramID, LearningPathID: lp.ID.String(), Name: lp.Title})
+	return lp
+}
+
+func outboxMessages(t *testing.T, db *gorm.DB, lpID uuid.UUID) []model.OutboxMessage {
+	t.Helper()
+	var msgs []model.OutboxMessage
+	require.NoError(t, db.Where("aggregate_id = ?", lpID).Order("created_at").Find(&msgs).Error)
+	return msgs
+}
+
+func TestOutbox_Rename_SendsServiceToken(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	editor := testutil.NewFakeEditorClient()
+	svc := newOutboxTestService(db, editor)
+	lp := createOutboxTestLP(t, db, editor)
+
+	_, err := svc.UpdateLearningPath(context.Background(), lp.ID.String(), "Renamed", "Description", nil)
+	require.NoError(t, err)
+	msgs := outboxMessages(t, db, lp.ID)
+	require.Len(t, msgs, 1)
+
+	delivered, err := service.NewOutboxDispatcher(svc).DispatchPending(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	calls := editor.Calls(testutil.EditorOpRename)
+	require.Len(t, calls, 1)
+	assert.Equal(t, "service-token", calls[0].AuthToken)
+	diagram, _ := editor.Diagram(lp.ID.String())
+	assert.Equal(t, "Renamed", diagram.Name)
+
+	msgs = outboxMessages(t, db, lp.ID)
+	assert.Equal(t, model.OutboxStatusDelivered, msgs[0].Status)
+	assert.NotNil(t, msgs[0].DeliveredAt)
+}
+
+func TestOutbox_Update_DescriptionOnlyEnqueuesNothing(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	editor := testutil.NewFakeEditorClient()
+	svc := newOutboxTestService(db, editor)
+	lp := createOutboxTestLP(t, db, editor)
+
+	updated, err := svc.UpdateLearningPath(context.Background(), lp.ID.String(), lp.Title, "New description", nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "New description", updated.Description)
+	assert.Empty(t, outboxMessages(t, db, lp.ID))
+}
+
+func TestOutbox_RepeatedRenames_OnlyLatestDelivered(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	editor := testutil.NewFakeEditorClient()
+	svc := newOutboxTestService(db, editor)
+	lp := createOutboxTestLP(t, db, editor)
+
+	for _, title := range []string{"First", "Second", "Third"} {
+		_, err := svc.UpdateLearningPath(context.Background(), lp.ID.String(), title, "", nil)
+		require.NoError(t, err)
+	}
+
+	delivered, err := service.NewOutboxDispatcher(svc).DispatchPending(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Len(t, editor.Calls(testutil.EditorOpRename), 1)
+
+	msgs := outboxMessages(t, db, lp.ID)
+	require.Len(t, msgs, 3)
+	assert.Equal(t, model.OutboxStatusSuperseded, msgs[0].Status)
+	assert.Equal(t, model.OutboxStatusSuperseded, msgs[1].Status)
+	assert.Equal(t, model.OutboxStatusDelivered, msgs[2].Status)
+	diagram, _ := editor.Diagram(lp.ID.String())
+	assert.Equal(t, "Third", diagram.Name)
+}
+
+func TestOutbox_FailedRename_BlocksLaterDeleteOfSameLP(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	editor := testutil.NewFakeEditorClient()
+	svc := newOutboxTestService(db, editor)
+	lp := createOutboxTestLP(t, db, editor)
+
+	_, err := svc.UpdateLearningPath(context.Background(), lp.ID.String(), "Renamed", "", nil)
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteLearningPath(context.Background(), lp.ID.String()))
+
+	editor.FailUnavailable(testutil.EditorOpRename)
+
+	delivered, err := service.NewOutboxDispatcher(svc).DispatchPending(context.Background())
+
+	// Only the rename is attempted; the delete must wait for it
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Len(t, editor.Calls(testutil.EditorOpRename), 1)
+	assert.Empty(t, editor.Calls(testutil.EditorOpDelete))
+
+	msgs := outboxMessages(t, db, lp.ID)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, 1, msgs[0].Attempts)
+	assert.Equal(t, 0, msgs[1].Attempts)
+	assert.Equal(t, model.OutboxStatusPending, msgs[1].Status)
+}
+
+func TestOutbox_MaxAttempts_MarksDead(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	editor := testutil.NewFakeEditorClient()
+	svc := newOutboxTestService(db, editor)
+	lp := createOutboxTestLP(t, db, editor)
+
+	require.NoError(t, svc.DeleteLearningPath(context.Background(), lp.ID.String()))
+	require.NoError(t, db.Model(&model.OutboxMessage{}).Where("aggregate_id = ?", lp.ID).Update("attempts", 2).Error)
+
+	editor.Fail(testutil.EditorOpDelete, &service.EditorError{Kind: service.EditorErrUnavailable, StatusCode: 503})
+
+	dispatcher := service.NewOutboxDispatcher(svc)
+	dispatcher.MaxAttempts = 3
+	delivered, err := dispatcher.DispatchPending(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+
+	msgs := outboxMessages(t, db, lp.ID)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, model.OutboxStatusDead, msgs[0].Status)
+	assert.Contains(t, msgs[0].LastError, "503")
+}
+
+func TestOutbox_Delete_RestoredLPNotHardDeleted(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	editor := testutil.NewFakeEditorClient()
+	svc := newOutboxTestService(db, editor)
+	lp := createOutboxTestLP(t, db, editor)
+
+	require.NoError(t, svc.DeleteLearningPath(context.Background(), lp.ID.String()))
+	// Restored by an operator before the message was delivered
+	require.NoError(t, db.Unscoped().Model(&model.LearningPath{}).Where("id = ?", lp.ID).Update("deleted_at", nil).Error)
+
+	delivered, err := service.NewOutboxDispatcher(svc).DispatchPending(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	var count int64
+	db.Model(&model.LearningPath{}).Where("id = ?", lp.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
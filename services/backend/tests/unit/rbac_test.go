@@ -0,0 +1,143 @@
+package unit_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/controller"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/middleware"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// setTestUser puts a user holding the given platform roles in the context, as middleware.Auth does
+func setTestUser(id uint, email string, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := &model.User{Model: gorm.Model{ID: id}, Email: email}
+		for _, role := range roles {
+			user.PlatformRoles = append(user.PlatformRoles, model.UserPlatformRole{UserID: id, Role: role})
+		}
+		c.Set("user", user)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	r := newErrorTestRouter()
+	requireAuditor := middleware.RequireRole(model.PlatformRoleAdmin, model.PlatformRoleAuditor)
+	r.GET("/auditor", setTestUser(1, "a@example.com", model.PlatformRoleAuditor), requireAuditor, ok)
+	r.GET("/support", setTestUser(1, "s@example.com", model.PlatformRoleSupport), requireAuditor, ok)
+	r.GET("/anonymous", requireAuditor, ok)
+
+	assert.Equal(t, http.StatusNoContent, doRequest(r, http.MethodGet, "/auditor", "", nil).Code)
+
+	w := doRequest(r, http.MethodGet, "/support", "", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "role_required", decodeProblem(t, w).Code)
+
+	w = doRequest(r, http.MethodGet, "/anonymous", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRoleService_GrantAndRevoke(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewRoleService(db)
+	admin := seedUser(t, db, "Ada", "ada@example.com")
+	auditor := seedUser(t, db, "Otto", "otto@example.com")
+
+	_, err := svc.GrantRole(context.Background(), nil, admin.Email, model.PlatformRoleAdmin)
+	require.NoError(t, err)
+	assignment, err := svc.GrantRole(context.Background(), &admin, "OTTO@example.com", model.PlatformRoleAuditor)
+	require.NoError(t, err)
+	assert.Equal(t, auditor.ID, assignment.UserID)
+	assert.Equal(t, model.RoleSourceManual, assignment.Source)
+	_, err = svc.GrantRole(context.Background(), &admin, auditor.Email, model.PlatformRoleAuditor)
+	require.NoError(t, err, "granting twice changes nothing")
+
+	_, err = svc.GrantRole(context.Background(), &admin, auditor.Email, "owner")
+	assert.True(t, apperror.IsKind(err, apperror.KindValidation))
+	_, err = svc.GrantRole(context.Background(), &admin, "nobody@example.com", model.PlatformRoleSupport)
+	assert.True(t, apperror.IsKind(err, apperror.KindNotFound))
+
+	assignments, err := svc.ListRoleAssignments(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, assignments, 2)
+
+	err = svc.RevokeRole(context.Background(), admin.ID, model.PlatformRoleAdmin)
+	assert.True(t, apperror.IsKind(err, apperror.KindConflict), "the last admin stays")
+	require.NoError(t, svc.RevokeRole(context.Background(), auditor.ID, model.PlatformRoleAuditor))
+	err = svc.RevokeRole(context.Background(), auditor.ID, model.PlatformRoleAuditor)
+	assert.True(t, apperror.IsKind(err, apperror.KindNotFound))
+}
+
+func TestRoleService_RolesFromClaims(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewRoleService(db)
+	_, err := svc.AddClaimMapping(context.Background(), model.ClaimTypeGroup, "group-auditors", model.PlatformRoleAuditor)
+	require.NoError(t, err)
+	_, err = svc.AddClaimMapping(context.Background(), model.ClaimTypeAppRole, "Rosetta.Admin", model.PlatformRoleAdmin)
+	require.NoError(t, err)
+	_, err = svc.AddClaimMapping(context.Background(), model.ClaimTypeAppRole, "Rosetta.Admin", model.PlatformRoleAdmin)
+	assert.True(t, apperror.IsKind(err, apperror.KindConflict))
+	_, err = svc.AddClaimMapping(context.Background(), "wids", "x", model.PlatformRoleAdmin)
+	assert.True(t, apperror.IsKind(err, apperror.KindValidation))
+
+	roles, err := svc.RolesFromClaims(context.Background(), []string{"group-other", "group-auditors"}, []string{"Rosetta.Admin"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{model.PlatformRoleAdmin, model.PlatformRoleAuditor}, roles)
+
+	roles, err = svc.RolesFromClaims(context.Background(), []string{"group-other"}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, roles)
+}
+
+func TestGetOrCreateUser_BootstrapsAdminEmails(t *testing.T) {
+	t.Setenv("ADMIN_EMAILS", " Boss@example.com ,other@example.com")
+	users := service.NewUserService(testutil.SetupTestDB(t))
+
+	boss, err := users.GetOrCreateUser(map[string]interface{}{"email": "boss@example.com", "name": "Boss", "oid": "oid-boss"}, nil, "")
+	require.NoError(t, err)
+	assert.True(t, boss.HasRole(model.PlatformRoleAdmin))
+	assert.Equal(t, model.RoleSourceBootstrap, boss.PlatformRoles[0].Source)
+
+	again, err := users.GetOrCreateUser(map[string]interface{}{"email": "boss@example.com", "name": "Boss", "oid": "oid-boss"}, nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{model.PlatformRoleAdmin}, again.Roles(), "roles are loaded with the user")
+
+	staff, err := users.GetOrCreateUser(map[string]interface{}{"email": "staff@example.com", "name": "Staff", "oid": "oid-staff"}, nil, "")
+	require.NoError(t, err)
+	assert.False(t, staff.HasRole(model.PlatformRoleAdmin))
+}
+
+func TestAdminController_Roles(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	admin := seedUser(t, db, "Ada", "ada@example.com")
+	seedUser(t, db, "Sam", "sam@example.com")
+	ctrl := controller.NewAdminController(service.NewUserService(db), nil, service.NewCommunityService(db), service.NewCommunityMappingService(db), service.NewRoleService(db))
+
+	r := newErrorTestRouter()
+	asAdmin := setTestUser(admin.ID, admin.Email, model.PlatformRoleAdmin)
+	r.GET("/api/admin/roles", asAdmin, ctrl.GetRoles)
+	r.POST("/api/admin/roles", asAdmin, ctrl.GrantRole)
+	r.DELETE("/api/admin/roles/:userId/:role", asAdmin, ctrl.RevokeRole)
+
+	w := doRequest(r, http.MethodPost, "/api/admin/roles", `{"email":"sam@example.com","role":"support"}`, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = doRequest(r, http.MethodPost, "/api/admin/roles", `{"email":"sam@example.com","role":"superuser"}`, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_role", decodeProblem(t, w).Code)
+
+	w = doRequest(r, http.MethodGet, "/api/admin/roles", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"role":"support"`)
+
+	w = doRequest(r, http.MethodDelete, "/api/admin/roles/abc/support", "", nil)
+	assert.Equal(t, "invalid_user_id", decodeProblem(t, w).Code)
+}
@@ -0,0 +1,139 @@
+package unit_test
+
+import (
+	"context"
+	"testing"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newTestReconciler(db *gorm.DB, editor service.EditorClient) *service.Reconciler {
+	svc := service.NewLearningPathServiceWithEditor(db, editor)
+	svc.ServiceToken = "service-token"
+	return service.NewReconciler(svc)
+}
+
+func issuesByKind(report *service.ReconciliationReport) map[string]service.ReconciliationIssue {
+	result := make(map[string]service.ReconciliationIssue)
+	for _, issue := range report.Issues {
+		result[issue.Kind] = issue
+	}
+	return result
+}
+
+func TestReconcile_DryRun_ReportsDriftWithoutRepairing(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	editor := testutil.NewFakeEditorClient()
+
+	inSync := model.LearningPath{ID: uuid.New(), Title: "In Sync", DiagramID: "diagram-ok", IsPublic: true}
+	missing := model.LearningPath{ID: uuid.New(), Title: "Lost Diagram", DiagramID: "diagram-gone", IsPublic: true}
+	softDeleted := model.LearningPath{ID: uuid.New(), Title: "Half Deleted", DiagramID: "diagram-del", IsPublic: true}
+	require.NoError(t, db.Create(&inSync).Error)
+	require.NoError(t, db.Create(&missing).Error)
+	require.NoError(t, db.Create(&softDeleted).Error)
+	require.NoError(t, db.Delete(&softDeleted).Error)
+
+	orphanLP := uuid.New().String()
+	editor.AddDiagram(service.Diagram{ID: "diagram-ok", LearningPathID: inSync.ID.String(), Name: "In Sync"})
+	editor.AddDiagram(service.Diagram{ID: "diagram-del", LearningPathID: softDeleted.ID.String(), Name: "Half Deleted"})
+	editor.AddDiagram(service.Diagram{ID: "diagram-orphan", LearningPathID: orphanLP, Name: "Orphan"})
+	editor.AddDiagram(service.Diagram{ID: "diagram-legacy", Name: "Legacy"})
+
+	report, err := newTestReconciler(db, editor).Reconcile(context.Background(), true)
+
+	require.NoError(t, err)
+	assert.Len(t, editor.Calls(""), 1)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 4, report.Diagrams)
+	assert.Equal(t, 3, report.LearningPaths)
+	assert.Equal(t, 0, report.Repaired)
+	require.Len(t, report.Issues, 4)
+
+	issues := issuesByKind(report)
+	assert.Equal(t, missing.ID.String(), issues[service.DriftMissingDiagram].LPID)
+	assert.Equal(t, softDeleted.ID.String(), issues[service.DriftSoftDeletedLP].LPID)
+	assert.Equal(t, orphanLP, issues[service.DriftOrphanedDiagram].LPID)
+	assert.Equal(t, "diagram-legacy", issues[service.DriftUnlinkedDiagram].DiagramID)
+	for _, issue := range report.Issues {
+		assert.False(t, issue.Repaired)
+	}
+
+	// Nothing was mutated
+	var count int64
+	db.Unscoped().Model(&model.LearningPath{}).Where("id = ?", softDeleted.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestReconcile_Repair_FixesBothSides(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	editor := testutil.NewFakeEditorClient()
+
+	missing := model.LearningPath{ID: uuid.New(), Title: "Lost Diagram", DiagramID: "diagram-gone", IsPublic: true}
+	softDeleted := model.LearningPath{ID: uuid.New(), Title: "Half Deleted", DiagramID: "diagram-del", IsPublic: true}
+	require.NoError(t, db.Create(&missing).Error)
+	require.NoError(t, db.Create(&softDeleted).Error)
+	require.NoError(t, db.Delete(&softDeleted).Error)
+
+	orphanLP := uuid.New().String()
+	editor.AddDiagram(service.Diagram{ID: "diagram-del", LearningPathID: softDeleted.ID.String(), Name: "Half Deleted"})
+	editor.AddDiagram(service.Diagram{ID: "diagram-orphan", LearningPathID: orphanLP, Name: "Orphan"})
+	editor.NextDiagramIDs = []string{"diagram-new"}
+
+	report, err := newTestReconciler(db, editor).Reconcile(context.Background(), false)
+
+	require.NoError(t, err)
+	assert.Len(t, editor.Calls(testutil.EditorOpCreate), 1)
+	assert.Len(t, editor.Calls(testutil.EditorOpDelete), 2)
+	assert.Equal(t, 1, editor.DiagramCount())
+	assert.Equal(t, 3, report.Repaired)
+	assert.Equal(t, 0, report.Failed)
+
+	var relinked model.LearningPath
+	require.NoError(t, db.First(&relinked, "id = ?", missing.ID).Error)
+	assert.Equal(t, "diagram-new", relinked.DiagramID)
+
+	var count int64
+	db.Unscoped().Model(&model.LearningPath{}).Where("id = ?", softDeleted.ID).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestReconcile_InFlightSaga_Skipped(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	editor := testutil.NewFakeEditorClient()
+
+	// Diagram created by a create saga that has not inserted its LP yet
+	inFlightLP := uuid.New()
+	require.NoError(t, db.Create(&model.SagaLog{
+		ID:     uuid.New(),
+		Type:   model.SagaTypeCreateLearningPath,
+		LPID:   inFlightLP,
+		Step:   "diagram_created",
+		Status: model.SagaStatusRunning,
+	}).Error)
+	editor.AddDiagram(service.Diagram{ID: "diagram-new", LearningPathID: inFlightLP.String(), Name: "Creating"})
+
+	report, err := newTestReconciler(db, editor).Reconcile(context.Background(), false)
+
+	require.NoError(t, err)
+	assert.Len(t, editor.Calls(""), 1)
+	assert.Empty(t, report.Issues)
+	assert.Equal(t, 1, report.Skipped)
+}
+
+func TestReconcile_EditorUnavailable_ReturnsError(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	editor := testutil.NewFakeEditorClient()
+	editor.FailUnavailable(testutil.EditorOpList)
+
+	report, err := newTestReconciler(db, editor).Reconcile(context.Background(), true)
+
+	require.Error(t, err)
+	assert.Nil(t, report)
+	assert.Contains(t, err.Error(), "failed to list diagrams")
+}
@@ -0,0 +1,148 @@
+package unit_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/controller"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/middleware"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRevocationService serves auth-service's revocation list and signs users out into it
+type fakeRevocationService struct {
+	server *httptest.Server
+
+	mu          sync.Mutex
+	revocations []middleware.Revocation
+	signedOut   []string
+}
+
+func newFakeRevocationService(t *testing.T) *fakeRevocationService {
+	f := &fakeRevocationService{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /auth/internal/revocations", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer internal" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{"revocations": f.revocations})
+	})
+	mux.HandleFunc("POST /auth/internal/users/{userId}/sign-out", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer internal" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.revoke(middleware.RevokedUser, r.PathValue("userId"))
+		f.mu.Lock()
+		f.signedOut = append(f.signedOut, r.PathValue("userId"))
+		f.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]int{"sessionsEnded": 2})
+	})
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeRevocationService) revoke(kind, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	f.revocations = append(f.revocations, middleware.Revocation{Kind: kind, Value: value, RevokedAt: now, ExpiresAt: now.Add(time.Hour)})
+}
+
+func TestRemoteRevocationList(t *testing.T) {
+	fake := newFakeRevocationService(t)
+	list := middleware.NewRemoteRevocationList(fake.server.URL, "internal")
+	past := float64(time.Now().Add(-time.Minute).Unix())
+	future := float64(time.Now().Add(time.Minute).Unix())
+
+	fake.revoke(middleware.RevokedSession, "session-1")
+	fake.revoke(middleware.RevokedToken, "jti-1")
+	fake.revoke(middleware.RevokedToken, "uti-1")
+	fake.revoke(middleware.RevokedUser, "oid-1")
+	assert.False(t, list.Revoked("session-1", nil), "nothing is revoked before the first refresh")
+	require.NoError(t, list.Refresh(context.Background()))
+
+	assert.True(t, list.Revoked("session-1", nil))
+	assert.False(t, list.Revoked("session-2", nil))
+	assert.True(t, list.Revoked("", map[string]interface{}{"jti": "jti-1", "oid": "oid-2"}))
+	assert.True(t, list.Revoked("", map[string]interface{}{"uti": "uti-1", "oid": "oid-2"}), "Entra ID tokens carry uti")
+	assert.True(t, list.Revoked("", map[string]interface{}{"oid": "oid-1", "iat": past}), "issued before the user was signed out")
+	assert.False(t, list.Revoked("", map[string]interface{}{"oid": "oid-1", "iat": future}), "signed in again since")
+	assert.False(t, list.Revoked("", map[string]interface{}{"sub": "oid-2", "iat": past}))
+
+	fake.server.Close()
+	assert.Error(t, list.Refresh(context.Background()))
+	assert.True(t, list.Revoked("session-1", nil), "the last copy is kept while auth-service is unreachable")
+
+	wrongSecret := middleware.NewRemoteRevocationList(fake.server.URL, "wrong")
+	assert.Error(t, wrongSecret.Refresh(context.Background()))
+}
+
+func TestAuth_RejectsRevokedTokens(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	verifier := middleware.NewHMACVerifier(testIssuer, "test-client-id", []byte("secret"))
+	fake := newFakeRevocationService(t)
+	list := middleware.NewRemoteRevocationList(fake.server.URL, "internal")
+
+	r := newErrorTestRouter()
+	r.GET("/me", middleware.Auth(verifier, nil, list, nil, service.NewUserService(db), service.NewAuthCache(time.Minute, 100)), func(c *gin.Context) {
+		c.String(http.StatusOK, c.MustGet("user").(*model.User).Email)
+	})
+
+	claims := verifierTestClaims(time.Hour)
+	claims["jti"] = "jti-1"
+	headers := map[string]string{"Authorization": "Bearer " + testutil.SignHS256Token("secret", claims)}
+	w := doRequest(r, http.MethodGet, "/me", "", headers)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	fake.revoke(middleware.RevokedToken, "jti-1")
+	require.NoError(t, list.Refresh(context.Background()))
+	w = doRequest(r, http.MethodGet, "/me", "", headers)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "rejected although the token is cached")
+	assert.Equal(t, "token_revoked", decodeProblem(t, w).Code)
+}
+
+func TestAdminController_SignOutUser(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	admin := seedUser(t, db, "Ada", "ada@example.com")
+	sam := seedUser(t, db, "Sam", "sam@example.com")
+	ctrl := controller.NewAdminController(service.NewUserService(db), nil, service.NewCommunityService(db), service.NewCommunityMappingService(db), service.NewRoleService(db))
+
+	samID := strconv.FormatUint(uint64(sam.ID), 10)
+
+	r := newErrorTestRouter()
+	r.POST("/api/admin/users/:userId/sign-out", setTestUser(admin.ID, admin.Email, model.PlatformRoleAdmin), ctrl.SignOutUser)
+
+	w := doRequest(r, http.MethodPost, "/api/admin/users/"+samID+"/sign-out", "", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "sign_out_unavailable", decodeProblem(t, w).Code)
+
+	fake := newFakeRevocationService(t)
+	ctrl.Revocations = middleware.NewRemoteRevocationList(fake.server.URL, "internal")
+
+	w = doRequest(r, http.MethodPost, "/api/admin/users/"+samID+"/sign-out", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"userId":`+samID+`,"sessionsEnded":2}`, w.Body.String())
+	assert.Equal(t, []string{sam.EntraID}, fake.signedOut)
+	assert.True(t, ctrl.Revocations.Revoked("", map[string]interface{}{"oid": sam.EntraID}), "enforced right away on this instance")
+
+	w = doRequest(r, http.MethodPost, "/api/admin/users/999/sign-out", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doRequest(r, http.MethodPost, "/api/admin/users/sam/sign-out", "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,210 @@
+package unit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// createStaleSaga inserts a saga that has been idle long enough to be picked up by the recoverer
+func createStaleSaga(t *testing.T, db *gorm.DB, sagaType, step, status string, lpID uuid.UUID, payload string) *model.SagaLog {
+	saga := &model.SagaLog{
+		ID:        uuid.New(),
+		Type:      sagaType,
+		LPID:      lpID,
+		Step:      step,
+		Payload:   payload,
+		Status:    status,
+		CreatedAt: time.Now().Add(-time.Hour),
+		UpdatedAt: time.Now().Add(-time.Hour),
+	}
+	require.NoError(t, db.Create(saga).Error)
+	return saga
+}
+
+func newTestRecoverer(db *gorm.DB, editor service.EditorClient) *service.SagaRecoverer {
+	svc := service.NewLearningPathServiceWithEditor(db, editor)
+	svc.ServiceToken = "service-token"
+	return &service.SagaRecoverer{LPService: svc, StaleAfter: time.Minute, MaxAttempts: 3}
+}
+
+func reloadSaga(t *testing.T, db *gorm.DB, id uuid.UUID) model.SagaLog {
+	var saga model.SagaLog
+	require.NoError(t, db.First(&saga, "id = ?", id).Error)
+	return saga
+}
+
+// ============================================================================
+// CREATE SAGA RECOVERY TESTS
+// ============================================================================
+
+func TestSagaRecovery_CreateWithoutLP_DeletesOrphanedDiagram(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	editor := testutil.NewFakeEditorClient()
+	lpID := uuid.New()
+	saga := createStaleSaga(t, db, model.SagaTypeCreateLearningPath, "diagram_created", model.SagaStatusRunning, lpID, `{"title":"Crashed LP","diagramId":"mongo123"}`)
+
+	editor.AddDiagram(service.Diagram{ID: "mongo123", LearningPathID: lpID.String(), Name: "Crashed LP"})
+
+	recovered, err := newTestRecoverer(db, editor).RecoverPending(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, recovered)
+	assert.Equal(t, 0, editor.DiagramCount())
+
+	// Compensation must authenticate with the service token, not a user token
+	calls := editor.Calls(testutil.EditorOpDelete)
+	require.Len(t, calls, 1)
+	assert.Equal(t, "service-token", calls[0].AuthToken)
+
+	stored := reloadSaga(t, db, saga.ID)
+	assert.Equal(t, model.SagaStatusCompensated, stored.Status)
+	assert.Equal(t, 1, stored.Attempts)
+}
+
+func TestSagaRecovery_CreateWithCommittedLP_MarkedCompleted(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	editor := testutil.NewFakeEditorClient()
+	lpID := uuid.New()
+	require.NoError(t, db.Create(&model.LearningPath{ID: lpID, Title: "Committed", DiagramID: "mongo123", IsPublic: true}).Error)
+	saga := createStaleSaga(t, db, model.SagaTypeCreateLearningPath, "diagram_created", model.SagaStatusRunning, lpID, `{"title":"Committed"}`)
+
+	recovered, err := newTestRecoverer(db, editor).RecoverPending(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, recovered)
+	assert.Empty(t, editor.Calls(""))
+	assert.Equal(t, model.SagaStatusCompleted, reloadSaga(t, db, saga.ID).Status)
+}
+
+func TestSagaRecovery_EditorUnavailable_SagaStaysPending(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	editor := testutil.NewFakeEditorClient()
+	saga := createStaleSaga(t, db, model.SagaTypeCreateLearningPath, "started", model.SagaStatusCompensating, uuid.New(), `{"title":"LP"}`)
+
+	editor.FailUnavailable(testutil.EditorOpDelete)
+
+	recovered, err := newTestRecoverer(db, editor).RecoverPending(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, recovered)
+
+	stored := reloadSaga(t, db, saga.ID)
+	assert.Equal(t, model.SagaStatusCompensating, stored.Status)
+	assert.Equal(t, 1, stored.Attempts)
+	assert.Contains(t, stored.LastError, "connection refused")
+}
+
+// ============================================================================
+// RECOVERER BOOKKEEPING TESTS
+// ============================================================================
+
+func TestSagaRecovery_RecentSaga_NotTouched(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	editor := testutil.NewFakeEditorClient()
+
+	// A saga that was just updated may still be running on another replica
+	saga := &model.SagaLog{
+		ID:     uuid.New(),
+		Type:   model.SagaTypeCreateLearningPath,
+		LPID:   uuid.New(),
+		Step:   "started",
+		Status: model.SagaStatusRunning,
+	}
+	require.NoError(t, db.Create(saga).Error)
+
+	recovered, err := newTestRecoverer(db, editor).RecoverPending(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, recovered)
+	assert.Empty(t, editor.Calls(""))
+	assert.Equal(t, 0, reloadSaga(t, db, saga.ID).Attempts)
+}
+
+func TestSagaRecovery_MaxAttemptsExceeded_MarkedFailed(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	editor := testutil.NewFakeEditorClient()
+	saga := createStaleSaga(t, db, model.SagaTypeCreateLearningPath, "started", model.SagaStatusCompensating, uuid.New(), `{"title":"LP"}`)
+	require.NoError(t, db.Model(saga).UpdateColumn("attempts", 3).Error)
+
+	recovered, err := newTestRecoverer(db, editor).RecoverPending(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, recovered)
+	assert.Empty(t, editor.Calls(""))
+	assert.Equal(t, model.SagaStatusFailed, reloadSaga(t, db, saga.ID).Status)
+}
+
+// ============================================================================
+// SAGA LOG WRITES
+// ============================================================================
+
+func TestCreateLearningPath_PersistsCompletedSaga(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	editor := testutil.NewFakeEditorClient()
+	editor.NextDiagramIDs = []string{"mongo123"}
+
+	svc := service.NewLearningPathServiceWithEditor(db, editor)
+	lp, err := svc.CreateLearningPath(context.Background(), "Logged LP", "", true, "", nil, "token", testutil.CommunityID(t, db, "community"), 0)
+	require.NoError(t, err)
+
+	var saga model.SagaLog
+	require.NoError(t, db.Where("lp_id = ?", lp.ID).First(&saga).Error)
+	assert.Equal(t, model.SagaTypeCreateLearningPath, saga.Type)
+	assert.Equal(t, "diagram_created", saga.Step)
+	assert.Equal(t, model.SagaStatusCompleted, saga.Status)
+	assert.Contains(t, saga.Payload, "mongo123")
+}
+
+// slowCreateEditor holds CreateDiagram until release is closed, like backend-editor answering
+// only after several retries and backoff
+type slowCreateEditor struct {
+	*testutil.FakeEditorClient
+	release chan struct{}
+}
+
+func (e *slowCreateEditor) CreateDiagram(ctx context.Context, lpID, name, authToken string) (*service.Diagram, error) {
+	<-e.release
+	return e.FakeEditorClient.CreateDiagram(ctx, lpID, name, authToken)
+}
+
+func TestSagaRecovery_SlowCreateInFlight_NotCompensated(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1) // Every connection to :memory: would open a database of its own
+	editor := &slowCreateEditor{FakeEditorClient: testutil.NewFakeEditorClient(), release: make(chan struct{})}
+	svc := service.NewLearningPathServiceWithEditor(db, editor)
+	svc.SagaHeartbeat = 10 * time.Millisecond
+	recoverer := &service.SagaRecoverer{LPService: svc, StaleAfter: 100 * time.Millisecond, MaxAttempts: 3}
+	communityID := testutil.CommunityID(t, db, "community")
+
+	created := make(chan error, 1)
+	go func() {
+		_, err := svc.CreateLearningPath(context.Background(), "Slow LP", "", true, "", nil, "token", communityID, 0)
+		created <- err
+	}()
+
+	// Well past StaleAfter, the create is still waiting for backend-editor
+	time.Sleep(300 * time.Millisecond)
+	recovered, err := recoverer.RecoverPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, recovered)
+	assert.Empty(t, editor.Calls(testutil.EditorOpDelete))
+
+	close(editor.release)
+	require.NoError(t, <-created)
+	assert.Equal(t, 1, editor.DiagramCount())
+	var saga model.SagaLog
+	require.NoError(t, db.First(&saga).Error)
+	assert.Equal(t, model.SagaStatusCompleted, saga.Status)
+	assert.Equal(t, 0, saga.Attempts)
+}
@@ -0,0 +1,118 @@
+package unit_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/controller"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func searchTitles(results *service.SearchResults) []string {
+	titles := make([]string, len(results.Items))
+	for i, item := range results.Items {
+		titles[i] = item.LearningPath.Title
+	}
+	return titles
+}
+
+func TestSearch_PrefixMatch_RanksTitleAboveSkillsAndDescription(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewSearchService(db)
+	seedListLP(t, db, "Cooking", 0, func(lp *model.LearningPath) { lp.Description = "Kubernetes for chefs" })
+	seedListLP(t, db, "Cloud Basics", time.Hour, func(lp *model.LearningPath) { lp.SkillNames = "Kubernetes Docker" })
+	seedListLP(t, db, "Kubernetes in Depth", 2*time.Hour, nil)
+	seedListLP(t, db, "Unrelated", 3*time.Hour, nil)
+
+	results, err := svc.Search(context.Background(), service.SearchQuery{Text: "kube"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Kubernetes in Depth", "Cloud Basics", "Cooking"}, searchTitles(results))
+	assert.Equal(t, int64(3), results.Total)
+	assert.Equal(t, "<mark>Kubernetes</mark> in Depth", results.Items[0].TitleHighlight)
+	assert.Equal(t, "<mark>Kubernetes</mark> for chefs", results.Items[2].Snippet)
+}
+
+func TestSearch_AllTermsMustMatch_AndCommunityFilters(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewSearchService(db)
+	seedListLP(t, db, "Go Testing", 0, nil)
+	seedListLP(t, db, "Go Concurrency", time.Hour, nil)
+	seedListLP(t, db, "Go Testing for Designers", 2*time.Hour, func(lp *model.LearningPath) { lp.CommunityID = communityRef(t, db, "Design") })
+
+	results, err := svc.Search(context.Background(), service.SearchQuery{Text: "go test", Community: "Cloud and Backend"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Go Testing"}, searchTitles(results))
+}
+
+func TestSearch_HighlightsAreHTMLEscaped(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := service.NewSearchService(db)
+	seedListLP(t, db, `<script>alert("xss")</script> Security`, 0, nil)
+
+	results, err := svc.Search(context.Background(), service.SearchQuery{Text: "secur"})
+
+	require.NoError(t, err)
+	require.Len(t, results.Items, 1)
+	assert.Equal(t, "&lt;script&gt;alert(&#34;xss&#34;)&lt;/script&gt; <mark>Security</mark>", results.Items[0].TitleHighlight)
+}
+
+func TestSearch_CreatedPathIsFoundBySkill(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	lpService := service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient())
+	_, err := lpService.CreateLearningPath(context.Background(), "Backend Track", "", true, "", []string{"PostgreSQL"}, "token", testutil.CommunityID(t, db, "community"), 0)
+	require.NoError(t, err)
+
+	results, err := service.NewSearchService(db).Search(context.Background(), service.SearchQuery{Text: "postgres"})
+
+	require.NoError(t, err)
+	require.Len(t, results.Items, 1)
+	assert.Equal(t, "Backend Track", results.Items[0].LearningPath.Title)
+	require.Len(t, results.Items[0].LearningPath.SkillsList, 1)
+}
+
+func TestSearch_UpdatedPathKeepsItsSkills(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	ctx := context.Background()
+	lpService := service.NewLearningPathServiceWithEditor(db, testutil.NewFakeEditorClient())
+	lp, err := lpService.CreateLearningPath(ctx, "Backend Track", "", true, "", []string{"PostgreSQL", "Redis"}, "token", testutil.CommunityID(t, db, "community"), 0)
+	require.NoError(t, err)
+
+	_, err = lpService.UpdateLearningPath(ctx, lp.ID.String(), "Data Track", "Storage for services", nil)
+	require.NoError(t, err)
+
+	results, err := service.NewSearchService(db).Search(ctx, service.SearchQuery{Text: "redis"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Data Track"}, searchTitles(results))
+}
+
+func TestSearch_EmptyQuery_Rejected(t *testing.T) {
+	svc := service.NewSearchService(testutil.SetupTestDB(t))
+
+	_, err := svc.Search(context.Background(), service.SearchQuery{Text: "  !? "})
+
+	assert.True(t, apperror.IsKind(err, apperror.KindValidation))
+}
+
+func TestSearchController_InvalidLimit(t *testing.T) {
+	ctrl := controller.NewSearchController(service.NewSearchService(testutil.SetupTestDB(t)))
+	r := newErrorTestRouter()
+	r.GET("/api/search", func(c *gin.Context) {
+		c.Set("user", &model.User{Model: gorm.Model{ID: 7}})
+	}, ctrl.Search)
+
+	w := doRequest(r, http.MethodGet, "/api/search?q=go&limit=0", "", nil)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_query", decodeProblem(t, w).Code)
+}
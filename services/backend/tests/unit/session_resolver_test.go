@@ -0,0 +1,97 @@
+package unit_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/middleware"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeAuthService answers session resolves for the secret "valid-secret" with idToken
+func newFakeAuthService(t *testing.T, idToken string, calls *atomic.Int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path != "/auth/internal/sessions/resolve" || r.Header.Get("Authorization") != "Bearer internal" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Session string `json:"session"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.Session != "valid-secret" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(middleware.ResolvedSession{
+			ID:               "session-id",
+			UserID:           "test-entra-id-12345",
+			IDToken:          idToken,
+			GraphAccessToken: "graph-token",
+			ExpiresAt:        time.Now().Add(time.Hour),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRemoteSessionResolver(t *testing.T) {
+	var calls atomic.Int32
+	server := newFakeAuthService(t, "id-token", &calls)
+	resolver := middleware.NewRemoteSessionResolver(server.URL, "internal", time.Minute)
+
+	session, err := resolver.Resolve(context.Background(), "valid-secret")
+	require.NoError(t, err)
+	assert.Equal(t, "id-token", session.IDToken)
+	assert.Equal(t, "graph-token", session.GraphAccessToken)
+
+	_, err = resolver.Resolve(context.Background(), "valid-secret")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load(), "second resolve is cached")
+
+	_, err = resolver.Resolve(context.Background(), "revoked-secret")
+	assert.ErrorIs(t, err, middleware.ErrSessionNotFound)
+
+	wrongSecret := middleware.NewRemoteSessionResolver(server.URL, "wrong", time.Minute)
+	_, err = wrongSecret.Resolve(context.Background(), "valid-secret")
+	assert.True(t, apperror.IsKind(err, apperror.KindDependencyFailed))
+}
+
+func TestAuth_WithSessionCookie(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	verifier := middleware.NewHMACVerifier(testIssuer, "test-client-id", []byte("secret"))
+	var calls atomic.Int32
+	server := newFakeAuthService(t, testutil.SignHS256Token("secret", verifierTestClaims(time.Hour)), &calls)
+	resolver := middleware.NewRemoteSessionResolver(server.URL, "internal", 0)
+
+	r := newErrorTestRouter()
+	r.GET("/me", middleware.Auth(verifier, resolver, nil, nil, service.NewUserService(db), nil), func(c *gin.Context) {
+		assert.Equal(t, "graph-token", c.GetString(middleware.ContextGraphAccessToken))
+		c.String(http.StatusOK, c.MustGet("user").(*model.User).Email)
+	})
+
+	w := doRequest(r, http.MethodGet, "/me", "", map[string]string{"Cookie": middleware.SessionCookie + "=valid-secret"})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "test@example.com", w.Body.String())
+
+	w = doRequest(r, http.MethodGet, "/me", "", map[string]string{"Cookie": middleware.SessionCookie + "=revoked-secret"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "session_expired", decodeProblem(t, w).Code)
+
+	server.Close()
+	w = doRequest(r, http.MethodGet, "/me", "", map[string]string{"Cookie": middleware.SessionCookie + "=valid-secret"})
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.Equal(t, "auth_service_unavailable", decodeProblem(t, w).Code)
+}
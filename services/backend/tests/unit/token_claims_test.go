@@ -0,0 +1,94 @@
+package unit_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagedGraphTransport answers memberOf with one group per page, linking to the next page
+type pagedGraphTransport struct {
+	groupIDs []string
+}
+
+func (p *pagedGraphTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+	next := ""
+	if page+1 < len(p.groupIDs) {
+		next = `,"@odata.nextLink":"https://graph.microsoft.com/v1.0/me/memberOf?page=` + strconv.Itoa(page+1) + `"`
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"value":[{"id":"` + p.groupIDs[page] + `"}]` + next + `}`)),
+	}, nil
+}
+
+func TestTokenGroups(t *testing.T) {
+	tests := []struct {
+		name        string
+		claims      map[string]interface{}
+		wantGroups  []string
+		wantOverage bool
+	}{
+		{"groups claim", map[string]interface{}{"groups": []interface{}{"g1", "g2"}}, []string{"g1", "g2"}, false},
+		{"empty groups claim", map[string]interface{}{"groups": []interface{}{}}, []string{}, false},
+		{"no groups claim", map[string]interface{}{}, nil, false},
+		{"overage", map[string]interface{}{"_claim_names": map[string]interface{}{"groups": "src1"}}, nil, true},
+		{"implicit flow overage", map[string]interface{}{"hasgroups": true}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, overage := service.TokenGroups(tt.claims)
+			assert.Equal(t, tt.wantGroups, groups)
+			assert.Equal(t, tt.wantOverage, overage)
+		})
+	}
+}
+
+func TestGetOrCreateUser_ReadsGroupsFromToken(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	seedMembershipMappings(t, db)
+	claims := map[string]interface{}{"email": "mia@example.com", "name": "Mia", "oid": "oid-mia", "groups": []interface{}{"group-security"}}
+
+	user, err := service.NewUserService(db).GetOrCreateUser(claims, nil, "")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Cyber Security and Software Update", user.CommunityName(), "no Graph token is needed")
+	assert.NotNil(t, user.LastGraphSync)
+}
+
+func TestGetOrCreateUser_GroupOverageFallsBackToGraph(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	seedMembershipMappings(t, db)
+	claims := map[string]interface{}{"email": "mia@example.com", "name": "Mia", "oid": "oid-mia", "_claim_names": map[string]interface{}{"groups": "src1"}}
+	graph := service.NewGraphServiceWithClient(&http.Client{Transport: &pagedGraphTransport{groupIDs: []string{"group-other", "group-security", "group-cloud"}}})
+
+	user, err := service.NewUserService(db).GetOrCreateUser(claims, graph, "graph-token")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Cloud and Backend", "Cyber Security and Software Update"}, user.CommunityNames(), "every page of memberOf is read")
+}
+
+func TestResolveGroups_WithoutTokenGroupsOrGraph(t *testing.T) {
+	groups, ok, err := service.ResolveGroups(context.Background(), map[string]interface{}{"hasgroups": true}, nil, "")
+
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Nil(t, groups)
+}
+
+func TestTokenUserID(t *testing.T) {
+	assert.Equal(t, "oid-mia", service.TokenUserID(map[string]interface{}{"oid": "oid-mia", "sub": "pairwise-sub"}), "Entra's oid wins over its per-app sub")
+	assert.Equal(t, "kc-user-1", service.TokenUserID(map[string]interface{}{"sub": "kc-user-1"}), "providers without oid")
+	assert.Empty(t, service.TokenUserID(map[string]interface{}{"email": "mia@example.com"}))
+}
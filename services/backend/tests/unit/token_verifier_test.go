@@ -0,0 +1,90 @@
+package unit_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/apperror"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/middleware"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/model"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/internal/service"
+	"dev.azure.com/carbyte/Carbyte-Academy/_git/rosetta-monorepo/services/backend/tests/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testIssuer = "http://localhost:9000"
+
+func verifierTestClaims(expiresIn time.Duration) map[string]interface{} {
+	claims := testutil.CreateMockClaimsMap(testutil.DefaultMockClaims())
+	claims["iss"] = testIssuer
+	claims["exp"] = float64(time.Now().Add(expiresIn).Unix())
+	return claims
+}
+
+func TestHMACVerifier(t *testing.T) {
+	verifier := middleware.NewHMACVerifier(testIssuer, "test-client-id", []byte("secret"))
+
+	verified, err := verifier.Verify(context.Background(), testutil.SignHS256Token("secret", verifierTestClaims(time.Hour)))
+	require.NoError(t, err)
+	assert.Equal(t, "test-entra-id-12345", verified.Claims["oid"])
+	assert.WithinDuration(t, time.Now().Add(time.Hour), verified.Expiry, time.Minute)
+
+	_, err = verifier.Verify(context.Background(), testutil.SignHS256Token("other", verifierTestClaims(time.Hour)))
+	assert.Error(t, err, "wrong signature")
+
+	_, err = verifier.Verify(context.Background(), testutil.SignHS256Token("secret", verifierTestClaims(-time.Minute)))
+	assert.Error(t, err, "expired")
+
+	claims := verifierTestClaims(time.Hour)
+	claims["aud"] = "someone-else"
+	_, err = verifier.Verify(context.Background(), testutil.SignHS256Token("secret", claims))
+	assert.Error(t, err, "wrong audience")
+
+	claims = verifierTestClaims(time.Hour)
+	claims["iss"] = "https://evil.example.com"
+	_, err = verifier.Verify(context.Background(), testutil.SignHS256Token("secret", claims))
+	assert.Error(t, err, "wrong issuer")
+}
+
+func TestOIDCVerifier_UnreachableIssuer(t *testing.T) {
+	verifier := middleware.NewOIDCVerifier("http://127.0.0.1:1", "test-client-id")
+
+	_, err := verifier.Verify(context.Background(), "token")
+	assert.True(t, apperror.IsKind(err, apperror.KindDependencyFailed), "startup does not depend on the issuer")
+}
+
+func TestNewTokenVerifierFromEnv(t *testing.T) {
+	t.Setenv("AUTH_VERIFIER", middleware.VerifierStatic)
+	_, err := middleware.NewTokenVerifierFromEnv()
+	assert.Error(t, err, "static needs a key")
+
+	t.Setenv("AUTH_HMAC_SECRET", "secret")
+	_, err = middleware.NewTokenVerifierFromEnv()
+	assert.NoError(t, err)
+
+	t.Setenv("AUTH_VERIFIER", "saml")
+	_, err = middleware.NewTokenVerifierFromEnv()
+	assert.Error(t, err)
+}
+
+func TestAuth_WithStaticVerifier(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	verifier := middleware.NewHMACVerifier(testIssuer, "test-client-id", []byte("secret"))
+	r := newErrorTestRouter()
+	r.GET("/me", middleware.Auth(verifier, nil, nil, nil, service.NewUserService(db), nil), func(c *gin.Context) {
+		c.String(http.StatusOK, c.MustGet("user").(*model.User).Email)
+	})
+
+	token := testutil.SignHS256Token("secret", verifierTestClaims(time.Hour))
+	w := doRequest(r, http.MethodGet, "/me", "", map[string]string{"Authorization": "Bearer " + token})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "test@example.com", w.Body.String())
+
+	w = doRequest(r, http.MethodGet, "/me", "", map[string]string{"Authorization": "Bearer " + token + "x"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "invalid_token", decodeProblem(t, w).Code)
+}
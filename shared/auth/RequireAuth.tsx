@@ -7,13 +7,15 @@ interface RequireAuthProps {
   children: ReactNode;
 }
 
-/** Route guard that redirects to login if not authenticated */
+/** Route guard that redirects to login if not authenticated, returning to this page afterwards */
 const RequireAuth: React.FC<RequireAuthProps> = ({ children }) => {
   const { isAuthenticated, loading } = useAuth();
 
   useEffect(() => {
     if (!loading && !isAuthenticated) {
-      window.location.href = `${AUTH_URL}/auth/login`;
+      const { pathname, search, hash } = window.location;
+      const returnTo = encodeURIComponent(pathname + search + hash);
+      window.location.href = `${AUTH_URL}/auth/login?returnTo=${returnTo}`;
     }
   }, [loading, isAuthenticated]);
 
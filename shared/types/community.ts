@@ -0,0 +1,19 @@
+/**
+ * Community type - matches backend PascalCase format (GET /api/communities)
+ */
+export interface Community {
+  ID: string;
+  Name: string;
+  /** URL-safe name; accepted wherever a community name is */
+  Slug: string;
+  Description: string;
+  Icon: string;
+  /** Hex colour, e.g. #1E88E5; empty when unset */
+  Color: string;
+  /** Public learning paths stay private until a moderator approves them */
+  RequiresApproval: boolean;
+  /** Set when an admin archived the community; it then accepts no new learning paths */
+  ArchivedAt?: string;
+  CreatedAt: string;
+  UpdatedAt: string;
+}
@@ -11,3 +11,25 @@ export interface ApiError extends Error {
   status?: number;
   code?: string;
 }
+
+/** Field-level validation failure reported in a problem response */
+export interface ProblemFieldError {
+  field: string;
+  code: string;
+  message: string;
+}
+
+/**
+ * RFC 7807 problem details returned by the backend API (application/problem+json).
+ * Branch on `code`; `detail` is safe to show to users.
+ */
+export interface ProblemDetails {
+  type: string;
+  title: string;
+  status: number;
+  detail?: string;
+  instance?: string;
+  code: string;
+  requestId?: string;
+  errors?: ProblemFieldError[];
+}
@@ -3,5 +3,6 @@
  */
 export * from './user';
 export * from './learningPath';
+export * from './community';
 export * from './diagram';
 export * from './error';
@@ -1,3 +1,5 @@
+import type { Community } from './community';
+
 /**
  * Unified LearningPath types - matches backend PascalCase format
  * Note: Field naming standardization (Phase 6) will be done separately
@@ -14,9 +16,28 @@ export interface LearningPath {
   IsPublic: boolean;
   Thumbnail: string;
   DiagramID: string;
-  Community?: string;
+  CommunityID?: string;
+  /** Included by listings and search */
+  Community?: Community;
+  CreatedByID?: number;
+  /** Set while a community moderator features the path */
+  FeaturedAt?: string;
+  /** Set once publication was requested in a community that requires approval */
+  PublicationStatus?: 'PENDING' | 'APPROVED' | 'REJECTED';
   CreatedAt: string;
   UpdatedAt: string;
   DeletedAt?: string;
   Skills?: Skill[];
 }
+
+/**
+ * One page of a learning path listing (GET /api/learning-paths and
+ * GET /api/communities/:communityname/learning-paths)
+ */
+export interface LearningPathPage {
+  items: LearningPath[];
+  /** Pass as `cursor` to fetch the next page; null on the last page */
+  nextCursor: string | null;
+  /** Number of paths matching the filters across all pages */
+  total: number;
+}
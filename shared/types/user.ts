@@ -11,8 +11,14 @@ export interface User {
   Email: string;
   EntraID: string;
   PhotoURL: string;
-  Community: string;
+  Community: string; // Primary community
+  Communities: string[]; // Every community the user belongs to, primary first
   IsAdmin: boolean;
+  /** Platform roles: admin, auditor or support */
+  Roles: string[];
+  IsModerator: boolean;
+  /** Communities the user moderates; admins moderate all of them without being listed */
+  ModeratedCommunities: string[];
 }
 
 export interface UpdateUserData {
@@ -1,4 +1,4 @@
-import type { ErrorResponse } from '../types/error';
+import type { ErrorResponse, ProblemDetails } from '../types/error';
 
 /** Safely extracts error message from unknown error types */
 export function getErrorMessage(error: unknown): string {
@@ -11,13 +11,36 @@ export function getErrorMessage(error: unknown): string {
   return 'Unknown error';
 }
 
+/** Reads an RFC 7807 problem body from a failed response, or null if it is not one */
+export async function parseProblem(
+  response: Response,
+): Promise<ProblemDetails | null> {
+  const contentType = response.headers.get('Content-Type') ?? '';
+  if (!contentType.includes('application/problem+json')) {
+    return null;
+  }
+  try {
+    return (await response.clone().json()) as ProblemDetails;
+  } catch {
+    return null;
+  }
+}
+
 /** Parses API error response, falling back to status text if JSON parsing fails */
 export async function parseErrorResponse(
   response: Response,
 ): Promise<string> {
   try {
-    const data = (await response.json()) as ErrorResponse;
-    return data.message || data.error || `Error: ${response.status}`;
+    const data = (await response.json()) as Partial<
+      ErrorResponse & ProblemDetails
+    >;
+    return (
+      data.detail ||
+      data.message ||
+      data.error ||
+      data.title ||
+      `Error: ${response.status}`
+    );
   } catch {
     return `Error: ${response.status} ${response.statusText}`;
   }